@@ -3,11 +3,22 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/nyaruka/phonenumbers"
+	"github.com/ohler55/ojg/jp"
 )
 
 // Config struct which holds the YAML configuration
@@ -15,18 +26,24 @@ import (
 // with appropriate data types.
 
 type Config struct {
+	Version    string `yaml:"version,omitempty"` // Version of the configuration file format, informative only
 	Input      InputConfig
 	Processors []ProcessorConfig
 	Output     OutputConfig
+	Monitoring MonitoringConfig
+	Errors     ErrorsConfig `yaml:"errors,omitempty"`
 }
 
 type Format string
 
 const (
-	FormatJSON   Format = "json"
-	FormatAvro   Format = "avro"
-	FormatProto  Format = "protobuf"
-	FormatString Format = "string"
+	FormatJSON    Format = "json"
+	FormatAvro    Format = "avro"
+	FormatProto   Format = "protobuf"
+	FormatString  Format = "string"
+	FormatCSV     Format = "csv"
+	FormatMsgpack Format = "msgpack"
+	FormatAuto    Format = "auto" // Input only, detected per message
 )
 
 const (
@@ -35,13 +52,49 @@ const (
 	ProcessorTypeTransform       = "transform"
 	ProcessorTypeEnrich          = "enrich"
 	ProcessorTypePassthrough     = "passthrough"
+	ProcessorTypeHeaderField     = "header_field"
+	ProcessorTypeSample          = "sample"
+	ProcessorTypePace            = "pace"
+	ProcessorTypeExtract         = "extract"
+	ProcessorTypeMerge           = "merge"
+	ProcessorTypeCopy            = "copy"
+	ProcessorTypeRoute           = "route"
+	ProcessorTypeFieldExists     = "field_exists"
+	ProcessorTypeMaxAge          = "max_age"
+	ProcessorTypeBucket          = "bucket"
+	ProcessorTypeExplode         = "explode"
+	ProcessorTypeAggregate       = "aggregate"
+	ProcessorTypeProject         = "project"
+	ProcessorTypeEmptyToNull     = "empty_to_null"
+	ProcessorTypeSchema          = "schema"
+	ProcessorTypeRenameKeys      = "rename_keys"
+	ProcessorTypeGenerateID      = "generate_id"
+	ProcessorTypeKeyCase         = "key_case"
+	ProcessorTypeHeadersObject   = "headers_object"
+	ProcessorTypeFieldLimits     = "field_limits"
+	ProcessorTypeCoalesce        = "coalesce"
+	ProcessorTypeDedupAdjacent   = "dedup_adjacent"
+	ProcessorTypeEpochConvert    = "epoch_convert"
+	ProcessorTypeNormalize       = "normalize"
+	ProcessorTypeFingerprint     = "fingerprint"
+	ProcessorTypeTemplate        = "template"
+	ProcessorTypeTap             = "tap"
+	ProcessorTypeRequire         = "require"
+	ProcessorTypeFormatNumber    = "format_number"
+	ProcessorTypeLookup          = "lookup"
 )
 
+// DefaultMaxMessageBytes is the default limit of the input message values, well above the 1MB Kafka default
+const DefaultMaxMessageBytes = 16 << 20
+
 var ValidFormats = map[Format]bool{
-	FormatJSON:   true,
-	FormatAvro:   true,
-	FormatProto:  true,
-	FormatString: true,
+	FormatJSON:    true,
+	FormatAvro:    true,
+	FormatProto:   true,
+	FormatString:  true,
+	FormatCSV:     true,
+	FormatMsgpack: true,
+	FormatAuto:    true,
 }
 
 // InputConfig holds Kafka consumer configuration
@@ -50,28 +103,50 @@ type InputConfig struct {
 	// Mandatory fields
 	Brokers        []string `yaml:"brokers"`             // List of Kafka broker addresses (e.g., ["localhost:9092"])
 	Topic          string   `yaml:"topic"`               // Kafka topic to consume from
+	Topics         []string `yaml:"topics,omitempty"`    // Additional Kafka topics to consume from, merged with Topic
 	ConsumerGroup  string   `yaml:"consumer_group_id"`   // Consumer group ID for offset management
-	Format         string   `yaml:"format"`              // Message format: "json", "avro", "protobuf", or "string"
+	Format         string   `yaml:"format"`              // Message format: "json", "avro", "protobuf", "string", "csv", "msgpack" or "auto"
 	SchemaRegistry string   `yaml:"schema_registry_url"` // Schema registry URL (required for avro/protobuf formats)
-	Workers        int      `yaml:"workers"`             // Number of parallel workers
+	Workers        int      `yaml:"workers"`             // Number of parallel workers, each processing the records of its partitions in order
 
 	// Optional fields
-	Offset_reset         *string `yaml:"offset_reset,omitempty"`         // Offset reset strategy: "earliest" or "latest" (default: "latest")
-	Enable_auto_commit   *bool   `yaml:"enable_auto_commit,omitempty"`   // Auto-commit consumed offsets (default: false)
-	Auto_commit_interval *string `yaml:"auto_commit_interval,omitempty"` // Interval for auto-commit in seconds (default: 5s)
-	Partitions           []int   `yaml:"partitions,omitempty"`           // Specific partitions to consume; if empty, consume all
-	Min_bytes            *int    `yaml:"min_bytes,omitempty"`            // Minimum bytes per fetch request
-	Max_bytes            *int    `yaml:"max_bytes,omitempty"`            // Maximum bytes per fetch request
-	Max_wait_time        *int    `yaml:"max_wait_time,omitempty"`        // Maximum wait time in milliseconds
-	Session_timeout      *string `yaml:"session_timeout,omitempty"`      // Session timeout duration (e.g., "10s", "30000ms")
-	Heartbeat_interval   *string `yaml:"heartbeat_interval,omitempty"`   // Heartbeat interval duration (e.g., "3s")
+	Topic_regex          *string    `yaml:"topic_regex,omitempty"`          // Regular expression of the topics to consume instead of Topic and Topics, consumer group mode only (e.g. "orders-.*")
+	Offset_reset         *string    `yaml:"offset_reset,omitempty"`         // Offset reset strategy: "earliest" or "latest" (default: "latest")
+	Enable_auto_commit   *bool      `yaml:"enable_auto_commit,omitempty"`   // Auto-commit consumed offsets (default: false)
+	Auto_commit_interval *string    `yaml:"auto_commit_interval,omitempty"` // Interval for auto-commit in seconds (default: 5s)
+	Partitions           []int      `yaml:"partitions,omitempty"`           // Specific partitions to consume; if empty, consume all
+	Min_bytes            *int       `yaml:"min_bytes,omitempty"`            // Minimum bytes per fetch request
+	Max_bytes            *int       `yaml:"max_bytes,omitempty"`            // Maximum bytes per fetch request
+	Max_wait_time        *int       `yaml:"max_wait_time,omitempty"`        // Maximum wait time in milliseconds
+	Session_timeout      *string    `yaml:"session_timeout,omitempty"`      // Session timeout duration (e.g., "10s", "30000ms")
+	Heartbeat_interval   *string    `yaml:"heartbeat_interval,omitempty"`   // Heartbeat interval duration (e.g., "3s")
+	Connect_retries      *int       `yaml:"connect_retries,omitempty"`      // Retries of the initial broker connection before giving up (default: 5)
+	Connect_backoff      *string    `yaml:"connect_backoff,omitempty"`      // Initial backoff between connection retries, doubled at each retry (default: 1s)
+	Json_numbers         *string    `yaml:"json_numbers,omitempty"`         // JSON numbers decoding: "int64" keeps integers exact, "float64" decodes every number as float (default: "int64")
+	Csv                  *CSVConfig `yaml:"csv,omitempty"`                  // CSV options, only used with the csv format
+	Payload_compression  *string    `yaml:"payload_compression,omitempty"`  // Compression of each message value, decompressed before decoding: "none", "gzip", "zstd" (default: "none")
+	Max_message_bytes    *int       `yaml:"max_message_bytes,omitempty"`    // Largest message value decoded, larger records are handled by the errors policy (default: 16MB)
+	Worker_affinity      *string    `yaml:"worker_affinity,omitempty"`      // Partition to worker mapping: "hash" (partition modulo workers) or "sticky" (default: "hash")
+	Isolation_level      *string    `yaml:"isolation_level,omitempty"`      // Records fetched: "read_uncommitted" (all) or "read_committed" (committed transactions only) (default: "read_uncommitted")
+	Commit_on_shutdown   *bool      `yaml:"commit_on_shutdown,omitempty"`   // Commit the offsets of the drained records on shutdown, false keeps the last periodic commit (default: true)
+	Key_format           *string    `yaml:"key_format,omitempty"`           // Record key format, decoded into KeyFields unless "string": "string", "json", "msgpack", "avro" or "protobuf" (default: "string")
+	Poll_timeout         *string    `yaml:"poll_timeout,omitempty"`         // Longest a single poll waits for records, bounding the shutdown latency (default: 1s)
+	Empty_fetch_backoff  *string    `yaml:"empty_fetch_backoff,omitempty"`  // Pause after a poll without records before the next one, "0s" disables it (default: 10ms)
+	Metadata_max_age     *string    `yaml:"metadata_max_age,omitempty"`     // Longest time between two metadata refreshes, bounding the time to pick up new partitions, 1s to 1h (default: 5m)
+
+	// Checkpoint: when set, no consumer group is used. The partitions are consumed directly and their offsets are
+	// stored in the local file instead, read on startup to resume. Partitions missing from the file start at offset_reset.
+	Checkpoint_file     *string `yaml:"checkpoint_file,omitempty"`     // Local file storing the offsets to resume from (default: none, consumer group offsets)
+	Checkpoint_interval *string `yaml:"checkpoint_interval,omitempty"` // Interval between two writes of the checkpoint file (default: 5s)
 }
 
 // ProcessorConfig holds the pipeline processor configuration
 // Currently no mandatory or optional fields defined
 type ProcessorConfig struct {
-	Type   string                 `yaml:"type,omitempty"` // Processor type : e.g., "filter", "transform"
-	Config map[string]interface{} `yaml:"config,omitempty"`
+	Type     string                 `yaml:"type,omitempty"` // Processor type : e.g., "filter", "transform"
+	Config   map[string]interface{} `yaml:"config,omitempty"`
+	Priority *int                   `yaml:"priority,omitempty"` // Execution order override, lower runs first, ties keep the config order (default: 0)
+	Topic    string                 `yaml:"topic,omitempty"`    // Input topic whose chain the processor belongs to, see ProcessorChains (default: the default chain)
 }
 
 // OutputConfig holds Kafka producer configuration
@@ -82,17 +157,145 @@ type OutputConfig struct {
 	Brokers        []string `yaml:"brokers"`                       // List of Kafka broker addresses
 	Topic          string   `yaml:"topic"`                         // Kafka topic to produce to
 	Workers        int      `yaml:"workers"`                       // Number of parallel producer workers
-	Format         string   `yaml:"format"`                        // Message format: "json", "avro", "protobuf", or "string"
+	Format         string   `yaml:"format"`                        // Message format: "json", "avro", "protobuf", "string", "csv" or "msgpack"
 	SchemaRegistry string   `yaml:"schema_registry_url,omitempty"` // Schema registry URL (required for avro/protobuf formats)
 
 	// Optional fields
-	Partitions        []int   `yaml:"partitions,omitempty"`        // Target partitions; if empty, use default partitioner
+	Partitions        []int   `yaml:"partitions,omitempty"`        // Target partitions, the records of a key staying together; if empty, use default partitioner
 	Batch_size        *int    `yaml:"batch_size,omitempty"`        // Number of messages to batch before sending (default: 2000)
+	Linger            *string `yaml:"linger,omitempty"`            // Longest a partition batch waits for more records before being sent, "0s" sends at once, up to 1m (default: 10ms)
 	Compression       *string `yaml:"compression,omitempty"`       // Compression algorithm: "none", "gzip", "snappy", "lz4", "zstd" (default: "none")
 	Auto_create_topic *bool   `yaml:"auto_create_topic,omitempty"` // Auto-create topic if it doesn't exist (default: false)
 	Request_timeout   *string `yaml:"request_timeout,omitempty"`   // Request timeout duration (e.g., "30s") (default: 30s)
 	Retry_backoff     *string `yaml:"retry_backoff,omitempty"`     // Backoff duration between retries (e.g., "2s") (default: 2s)
 	Max_retries       *int    `yaml:"max_retries,omitempty"`       // Maximum number of retry attempts (default: 3)
+
+	// Topic routing: the destination topic is read from a message field, Topic being the fallback.
+	// Routed topics are unknown at startup, they must exist unless auto_create_topic is enabled (on both etelgo and the broker).
+	Topic_field *string           `yaml:"topic_field,omitempty"` // ValueFields key holding the destination topic (or the topic_map key)
+	Topic_map   map[string]string `yaml:"topic_map,omitempty"`   // Optional mapping from the topic_field value to a destination topic
+
+	// Circuit breaker: after N consecutive produce failures, records are buffered during the cooldown
+	// then a single probe decides whether to resume. A full buffer blocks the pipeline (backpressure).
+	Breaker_failure_threshold *int    `yaml:"breaker_failure_threshold,omitempty"` // Consecutive failures opening the breaker, 0 disables it (default: 5)
+	Breaker_cooldown          *string `yaml:"breaker_cooldown,omitempty"`          // Time spent open before probing the output (default: 30s)
+	Breaker_buffer_size       *int    `yaml:"breaker_buffer_size,omitempty"`       // Records buffered while the breaker is open (default: 1000)
+
+	// Backpressure: the input fetching is paused while the records pending in the producer exceed the high watermark,
+	// and resumed once they fall below the low watermark.
+	Backpressure_high_watermark *int `yaml:"backpressure_high_watermark,omitempty"` // Pending records pausing the input (default: 80% of batch_size)
+	Backpressure_low_watermark  *int `yaml:"backpressure_low_watermark,omitempty"`  // Pending records resuming the input (default: 50% of batch_size)
+
+	// Ordered mode: the records of a partition are spread over every worker, then released to the output in offset order
+	// through a reorder buffer. A slow record holds back the ones completed after it (head-of-line blocking).
+	Ordered             *bool `yaml:"ordered,omitempty"`             // Produce in input offset order per partition, whatever the worker affinity (default: false)
+	Ordered_buffer_size *int  `yaml:"ordered_buffer_size,omitempty"` // Records dispatched and not yet produced in ordered mode, a full buffer pauses the dispatch (default: 1000)
+
+	Csv                 *CSVConfig `yaml:"csv,omitempty"`                 // CSV options, only used with the csv format
+	Payload_compression *string    `yaml:"payload_compression,omitempty"` // Compression of each message value after encoding, on top of the batch compression: "none", "gzip", "zstd" (default: "none")
+	Key_format          *string    `yaml:"key_format,omitempty"`          // Record key format, KeyFields being encoded unless "string": "string", "json", "msgpack", "avro" or "protobuf" (default: "string")
+	Non_finite_floats   *string    `yaml:"non_finite_floats,omitempty"`   // JSON encoding of the NaN and Inf floats: "error", "null" or "drop_field" (default: "error")
+	Timestamp_source    *string    `yaml:"timestamp_source,omitempty"`    // Timestamp of the produced records: "message" (the message Timestamp) or "now" (produce time) (default: "message")
+
+	// Headers stamped on every produced record (not on the dead letter ones), replacing a message header of the same name.
+	// The values are templates, see HeaderTemplateVariable, e.g. {"pipeline": "etelgo", "source": "${topic}"}.
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// HeaderTemplateVariable matches the ${variable} references of the output headers values, replaced by the message
+// source topic, partition, offset or key, or by a value field with ${value.<dot-path>} (empty when missing)
+var HeaderTemplateVariable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// HeaderValuePrefix prefixes the value fields referenced by the output headers, see HeaderTemplateVariable
+const HeaderValuePrefix = "value."
+
+// headerTemplateVariables are the message metadata the output headers can reference
+var headerTemplateVariables = map[string]bool{"topic": true, "partition": true, "offset": true, "key": true}
+
+// validHeaderTemplate checks the variables referenced by an output header value
+func validHeaderTemplate(template string) error {
+	for _, match := range HeaderTemplateVariable.FindAllStringSubmatch(template, -1) {
+		variable := match[1]
+		if path, ok := strings.CutPrefix(variable, HeaderValuePrefix); ok && validDotPath(path) {
+			continue
+		}
+		if !headerTemplateVariables[variable] {
+			return fmt.Errorf("unknown variable ${%s}, expected topic, partition, offset, key or value.<field>", variable)
+		}
+	}
+	if strings.Contains(HeaderTemplateVariable.ReplaceAllString(template, ""), "${") {
+		return errors.New("unterminated ${ variable")
+	}
+	return nil
+}
+
+// Policies of the NaN and Inf floats in the JSON output, see OutputConfig.Non_finite_floats
+const (
+	NonFiniteError     = "error"      // The message fails to encode, handled by the errors policy
+	NonFiniteNull      = "null"       // The value is encoded as null
+	NonFiniteDropField = "drop_field" // The field is removed, an array element being encoded as null
+)
+
+// Sources of the produced records timestamp, see OutputConfig.Timestamp_source
+const (
+	TimestampSourceMessage = "message" // The message Timestamp: the input record one, as set by the processors (e.g. timestamp_replay)
+	TimestampSourceNow     = "now"     // The produce time
+)
+
+// CSVConfig describes the CSV layout of the messages, one message holding a single data row.
+// The column order comes from the header row when there is one, from Columns otherwise.
+type CSVConfig struct {
+	Delimiter *string  `yaml:"delimiter,omitempty"` // Single character separating the columns (default: ",")
+	Header    *bool    `yaml:"header,omitempty"`    // Each message starts with a header row (default: false)
+	Columns   []string `yaml:"columns,omitempty"`   // Column names in order, required without header row
+}
+
+// MonitoringConfig holds the observability configuration
+type MonitoringConfig struct {
+	Metrics_export MetricsExportConfig `yaml:"metrics_export,omitempty"`
+}
+
+// MetricsExportConfig exposes the metrics (/metrics) and the readiness (/ready) over HTTP
+type MetricsExportConfig struct {
+	Enabled bool   `yaml:"enabled"`        // Serve the metrics endpoint (default: false)
+	Type    string `yaml:"type,omitempty"` // Export format, only "prometheus" is supported (default: "prometheus")
+	Port    int    `yaml:"port,omitempty"` // HTTP port (default: 9090)
+}
+
+// Error policies, applied to the messages failing to decode or to process
+const (
+	ErrorPolicySkip = "skip" // Log the error and continue with the next message
+	ErrorPolicyDrop = "drop" // Discard the message silently, only counted in the metrics
+	ErrorPolicyDLQ  = "dlq"  // Produce the original record to the dead letter topic, then continue
+	ErrorPolicyFail = "fail" // Stop the pipeline, the message is consumed again on restart
+)
+
+// ErrorsConfig holds the policy applied to the messages which can't be decoded or processed.
+// Whatever the policy, the message never reaches the output and a panic in a decoder or processor is handled as an error.
+type ErrorsConfig struct {
+	Policy       string             `yaml:"policy,omitempty"`       // "skip", "drop", "dlq" or "fail" (default: "skip")
+	Dlq_topic    string             `yaml:"dlq_topic,omitempty"`    // Dead letter topic on the output brokers, required by the dlq policy
+	Dlq_envelope *DLQEnvelopeConfig `yaml:"dlq_envelope,omitempty"` // Wraps the dead letter records into a JSON envelope, the original record being produced as is otherwise
+}
+
+// DLQEnvelopeConfig describes the JSON envelope of the dead letter records: the original record with the failure,
+// so that it can be inspected and reprocessed
+type DLQEnvelopeConfig struct {
+	Fields           []string `yaml:"fields,omitempty"`           // Envelope fields, see DLQEnvelopeFields (default: all of them but headers)
+	Payload_encoding *string  `yaml:"payload_encoding,omitempty"` // Encoding of the original key and value: "base64", or "raw" for UTF-8 payloads (default: "base64")
+}
+
+// DLQEnvelopeFields are the fields a dead letter envelope can hold
+var DLQEnvelopeFields = map[string]bool{
+	"key":       true, // Original key, encoded as payload_encoding
+	"value":     true, // Original value, encoded as payload_encoding
+	"topic":     true, // Source topic
+	"partition": true, // Source partition
+	"offset":    true, // Source offset
+	"processor": true, // Type of the failing processor, absent for a decoding or delivery failure
+	"error":     true, // Error message
+	"timestamp": true, // Failure time, RFC3339
+	"headers":   true, // Original headers
 }
 
 // Yaml Parsing function to load configuration from a YAML file
@@ -102,16 +305,70 @@ type Validator interface {
 	Validate(logger *slog.Logger) error
 }
 
+// validationErrors collects the errors of a section, so that a single run reports every field to fix.
+// With failFast it stops at the first one, see LoadOptions.FailFast.
+type validationErrors struct {
+	errs     []error
+	failFast bool
+}
+
+// add records the error, nil being ignored, and returns whether the validation must stop
+func (v *validationErrors) add(err error) bool {
+	if err == nil {
+		return false
+	}
+	v.errs = append(v.errs, err)
+	return v.failFast
+}
+
+// addAll records each error joined in err, prefixed by context, and returns whether the validation must stop
+func (v *validationErrors) addAll(context string, err error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return v.add(wrapError(context, err))
+	}
+	for _, e := range joined.Unwrap() {
+		v.errs = append(v.errs, wrapError(context, e))
+	}
+	return v.failFast
+}
+
+// err returns the errors joined, one per line, nil without any
+func (v *validationErrors) err() error {
+	return errors.Join(v.errs...)
+}
+
 func (ic *InputConfig) Validate(logger *slog.Logger) error {
-	logger.Debug("Validating InputConfig", "topic", ic.Topic)
+	return ic.validate(logger, false)
+}
+
+func (ic *InputConfig) validate(logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	logger.Debug("Validating InputConfig", "topic", ic.Topic, "topics", ic.Topics)
 
 	if len(ic.Brokers) == 0 {
 		logger.Error("InputConfig validation failed: Brokers is required and cannot be empty")
-		return fmt.Errorf("brokers is required and cannot be empty")
+		if errs.add(fmt.Errorf("brokers is required and cannot be empty")) {
+			return errs.err()
+		}
+	}
+	if ic.Topic == "" && len(ic.Topics) == 0 && ic.Topic_regex == nil {
+		logger.Error("InputConfig validation failed: at least one topic is required in Topic or Topics")
+		if errs.add(fmt.Errorf("topic is required and cannot be empty")) {
+			return errs.err()
+		}
+	}
+	if errs.add(ic.validateTopicRegex(logger)) {
+		return errs.err()
 	}
-	if ic.Topic == "" {
-		logger.Error("InputConfig validation failed: Topic is required and cannot be empty")
-		return fmt.Errorf("topic is required and cannot be empty")
+
+	for i, topic := range ic.Topics {
+		if topic == "" {
+			logger.Error("InputConfig validation failed: empty topic in Topics", "index", i)
+			if errs.add(fmt.Errorf("topics[%d] cannot be empty", i)) {
+				return errs.err()
+			}
+		}
 	}
 
 	if ic.ConsumerGroup == "" {
@@ -121,12 +378,32 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 
 	if !ValidFormats[Format(ic.Format)] {
 		logger.Error("InputConfig validation failed: Unsupported format", "format", ic.Format)
-		return fmt.Errorf("unsupported format: %s", ic.Format)
+		if errs.add(fmt.Errorf("unsupported format: %s", ic.Format)) {
+			return errs.err()
+		}
 	}
 
 	if (ic.Format == "avro" || ic.Format == "protobuf") && ic.SchemaRegistry == "" {
 		logger.Error("InputConfig validation failed: schema_registry_url is required for AVRO and PROTOBUF formats")
-		return fmt.Errorf("schema_registry_url is required for AVRO and PROTOBUF formats")
+		if errs.add(fmt.Errorf("schema_registry_url is required for AVRO and PROTOBUF formats")) {
+			return errs.err()
+		}
+	}
+
+	if errs.add(validateKeyFormat(&ic.Key_format, ic.SchemaRegistry, logger)) {
+		return errs.err()
+	}
+
+	if ic.Format == string(FormatCSV) {
+		if ic.Csv == nil {
+			ic.Csv = &CSVConfig{}
+		}
+		if errs.addAll("invalid csv options", ic.Csv.validate(logger, failFast)) {
+			return errs.err()
+		}
+		if *ic.Csv.Header && len(ic.Csv.Columns) > 0 {
+			logger.Warn("CSV columns ignored on input, the header row gives the column order")
+		}
 	}
 
 	if ic.Workers <= 0 {
@@ -134,6 +411,17 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 		ic.Workers = 1
 	}
 
+	if ic.Worker_affinity == nil {
+		defaultValue := "hash"
+		ic.Worker_affinity = &defaultValue
+		logger.Debug("Worker_affinity not provided, using default", "default", defaultValue)
+	} else if *ic.Worker_affinity != "hash" && *ic.Worker_affinity != "sticky" {
+		logger.Error("InputConfig validation failed: Invalid worker_affinity value", "value", *ic.Worker_affinity)
+		if errs.add(fmt.Errorf("worker_affinity must be 'hash' or 'sticky', got: %s", *ic.Worker_affinity)) {
+			return errs.err()
+		}
+	}
+
 	if ic.Offset_reset == nil {
 		defaultValue := "latest"
 		ic.Offset_reset = &defaultValue
@@ -149,7 +437,9 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 		}
 		if !valid {
 			logger.Error("Invalid offset_reset value", "value", *ic.Offset_reset)
-			return fmt.Errorf("offset_reset must be 'earliest' or 'latest', got: %s", *ic.Offset_reset)
+			if errs.add(fmt.Errorf("offset_reset must be 'earliest' or 'latest', got: %s", *ic.Offset_reset)) {
+				return errs.err()
+			}
 		}
 	}
 
@@ -165,10 +455,17 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 			ic.Auto_commit_interval = &defaultValue
 			logger.Debug("Auto_commit_interval not provided, using default", "default", "5s")
 		} else {
-			_, err := time.ParseDuration(*ic.Auto_commit_interval)
+			interval, err := time.ParseDuration(*ic.Auto_commit_interval)
 			if err != nil {
 				logger.Error("Invalid auto_commit_interval format", "value", *ic.Auto_commit_interval)
-				return fmt.Errorf("invalid auto_commit_interval: %w", err)
+				if errs.add(fmt.Errorf("invalid auto_commit_interval: %w", err)) {
+					return errs.err()
+				}
+			} else if interval <= 0 {
+				logger.Error("InputConfig validation failed: auto_commit_interval must be positive", "value", *ic.Auto_commit_interval)
+				if errs.add(fmt.Errorf("auto_commit_interval must be positive when enable_auto_commit is true, got: %s", *ic.Auto_commit_interval)) {
+					return errs.err()
+				}
 			}
 		}
 	} else {
@@ -199,7 +496,9 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 		_, err := time.ParseDuration(*ic.Session_timeout)
 		if err != nil {
 			logger.Error("InputConfig validation failed: Invalid session_timeout format", "value", *ic.Session_timeout)
-			return fmt.Errorf("invalid session_timeout format: %w", err)
+			if errs.add(fmt.Errorf("invalid session_timeout format: %w", err)) {
+				return errs.err()
+			}
 		}
 	} else {
 		defaultValue := "10s"
@@ -211,7 +510,9 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 		_, err := time.ParseDuration(*ic.Heartbeat_interval)
 		if err != nil {
 			logger.Error("InputConfig validation failed: Invalid heartbeat_interval format", "value", *ic.Heartbeat_interval)
-			return fmt.Errorf("invalid heartbeat_interval format: %w", err)
+			if errs.add(fmt.Errorf("invalid heartbeat_interval format: %w", err)) {
+				return errs.err()
+			}
 		}
 	} else {
 		defaultValue := "3s"
@@ -219,25 +520,365 @@ func (ic *InputConfig) Validate(logger *slog.Logger) error {
 		logger.Info("Heartbeat_interval not set, defaulting to", "default", defaultValue)
 	}
 
+	// The broker evicts a member missing its heartbeats for session_timeout, a third leaves room for two lost heartbeats
+	sessionTimeout, sessionErr := time.ParseDuration(*ic.Session_timeout)
+	heartbeatInterval, heartbeatErr := time.ParseDuration(*ic.Heartbeat_interval)
+	if sessionErr != nil || heartbeatErr != nil {
+		// The invalid format is reported above
+	} else if heartbeatInterval <= 0 || sessionTimeout <= 0 {
+		logger.Error("InputConfig validation failed: session_timeout and heartbeat_interval must be positive", "session_timeout", *ic.Session_timeout, "heartbeat_interval", *ic.Heartbeat_interval)
+		if errs.add(fmt.Errorf("session_timeout (%s) and heartbeat_interval (%s) must be positive", *ic.Session_timeout, *ic.Heartbeat_interval)) {
+			return errs.err()
+		}
+	} else if heartbeatInterval*3 > sessionTimeout {
+		logger.Error("InputConfig validation failed: heartbeat_interval too close to session_timeout", "session_timeout", *ic.Session_timeout, "heartbeat_interval", *ic.Heartbeat_interval)
+		if errs.add(fmt.Errorf("heartbeat_interval (%s) must be at most a third of session_timeout (%s)", *ic.Heartbeat_interval, *ic.Session_timeout)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Connect_retries == nil {
+		defaultValue := 5
+		ic.Connect_retries = &defaultValue
+		logger.Info("Connect_retries not set, defaulting to", "default", defaultValue)
+	} else if *ic.Connect_retries < 0 {
+		logger.Error("InputConfig validation failed: connect_retries cannot be negative", "value", *ic.Connect_retries)
+		if errs.add(fmt.Errorf("connect_retries cannot be negative, got: %d", *ic.Connect_retries)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Connect_backoff != nil {
+		backoff, err := time.ParseDuration(*ic.Connect_backoff)
+		if err != nil {
+			logger.Error("InputConfig validation failed: Invalid connect_backoff format", "value", *ic.Connect_backoff)
+			if errs.add(fmt.Errorf("invalid connect_backoff format: %w", err)) {
+				return errs.err()
+			}
+		} else if backoff <= 0 {
+			logger.Error("InputConfig validation failed: connect_backoff must be positive", "value", *ic.Connect_backoff)
+			if errs.add(fmt.Errorf("connect_backoff must be positive, got: %s", *ic.Connect_backoff)) {
+				return errs.err()
+			}
+		}
+	} else {
+		defaultValue := "1s"
+		ic.Connect_backoff = &defaultValue
+		logger.Info("Connect_backoff not set, defaulting to", "default", defaultValue)
+	}
+
+	if errs.add(validatePayloadCompression(&ic.Payload_compression, logger)) {
+		return errs.err()
+	}
+
+	if errs.add(ic.validateCheckpoint(logger)) {
+		return errs.err()
+	}
+
+	if ic.Max_message_bytes == nil {
+		defaultValue := DefaultMaxMessageBytes
+		ic.Max_message_bytes = &defaultValue
+		logger.Debug("Max_message_bytes not provided, using default", "default", defaultValue)
+	} else if *ic.Max_message_bytes <= 0 {
+		logger.Error("InputConfig validation failed: max_message_bytes must be positive", "value", *ic.Max_message_bytes)
+		if errs.add(fmt.Errorf("max_message_bytes must be positive, got: %d", *ic.Max_message_bytes)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Isolation_level == nil {
+		defaultValue := "read_uncommitted"
+		ic.Isolation_level = &defaultValue
+		logger.Debug("Isolation_level not provided, using default", "default", defaultValue)
+	} else if *ic.Isolation_level != "read_uncommitted" && *ic.Isolation_level != "read_committed" {
+		logger.Error("InputConfig validation failed: Invalid isolation_level value", "value", *ic.Isolation_level)
+		if errs.add(fmt.Errorf("isolation_level must be 'read_uncommitted' or 'read_committed', got: %s", *ic.Isolation_level)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Json_numbers == nil {
+		defaultValue := "int64"
+		ic.Json_numbers = &defaultValue
+		logger.Debug("Json_numbers not provided, using default", "default", defaultValue)
+	} else if *ic.Json_numbers != "int64" && *ic.Json_numbers != "float64" {
+		logger.Error("InputConfig validation failed: Invalid json_numbers value", "value", *ic.Json_numbers)
+		if errs.add(fmt.Errorf("json_numbers must be 'int64' or 'float64', got: %s", *ic.Json_numbers)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Commit_on_shutdown == nil {
+		defaultValue := true
+		ic.Commit_on_shutdown = &defaultValue
+		logger.Debug("Commit_on_shutdown not provided, using default", "default", defaultValue)
+	}
+
+	if ic.Poll_timeout == nil {
+		defaultValue := "1s"
+		ic.Poll_timeout = &defaultValue
+		logger.Debug("Poll_timeout not provided, using default", "default", defaultValue)
+	} else if timeout, err := time.ParseDuration(*ic.Poll_timeout); err != nil || timeout <= 0 {
+		logger.Error("InputConfig validation failed: Invalid poll_timeout", "value", *ic.Poll_timeout)
+		if errs.add(fmt.Errorf("poll_timeout must be a positive duration, got: %s", *ic.Poll_timeout)) {
+			return errs.err()
+		}
+	}
+
+	if ic.Empty_fetch_backoff == nil {
+		defaultValue := "10ms"
+		ic.Empty_fetch_backoff = &defaultValue
+		logger.Debug("Empty_fetch_backoff not provided, using default", "default", defaultValue)
+	} else if backoff, err := time.ParseDuration(*ic.Empty_fetch_backoff); err != nil || backoff < 0 {
+		logger.Error("InputConfig validation failed: Invalid empty_fetch_backoff", "value", *ic.Empty_fetch_backoff)
+		if errs.add(fmt.Errorf("empty_fetch_backoff must be a duration, 0s or more, got: %s", *ic.Empty_fetch_backoff)) {
+			return errs.err()
+		}
+	}
+
+	// franz-go refreshes the metadata at least hourly
+	if ic.Metadata_max_age == nil {
+		defaultValue := "5m"
+		ic.Metadata_max_age = &defaultValue
+		logger.Debug("Metadata_max_age not provided, using default", "default", defaultValue)
+	} else if age, err := time.ParseDuration(*ic.Metadata_max_age); err != nil || age < time.Second || age > time.Hour {
+		logger.Error("InputConfig validation failed: Invalid metadata_max_age", "value", *ic.Metadata_max_age)
+		if errs.add(fmt.Errorf("metadata_max_age must be a duration between 1s and 1h, got: %s", *ic.Metadata_max_age)) {
+			return errs.err()
+		}
+	}
+
 	logger.Info("InputConfig validation successful")
+	return errs.err()
+}
+
+var validPayloadCompressions = map[string]bool{
+	"none": true,
+	"gzip": true,
+	"zstd": true,
+}
+
+// validatePayloadCompression applies the default codec of the message level compression and checks its name
+func validatePayloadCompression(codec **string, logger *slog.Logger) error {
+	if *codec == nil {
+		defaultValue := "none"
+		*codec = &defaultValue
+		logger.Debug("Payload_compression not provided, using default", "default", defaultValue)
+		return nil
+	}
+	if !validPayloadCompressions[**codec] {
+		logger.Error("Invalid payload_compression", "value", **codec)
+		return fmt.Errorf("payload_compression must be one of: none, gzip, zstd; got: %s", **codec)
+	}
+	return nil
+}
+
+var validKeyFormats = map[Format]bool{
+	FormatString:  true,
+	FormatJSON:    true,
+	FormatMsgpack: true,
+	FormatAvro:    true,
+	FormatProto:   true,
+}
+
+// validateKeyFormat applies the default format of the record keys, string keeping the existing raw keys,
+// and checks the schema registry is set for the avro and protobuf keys
+func validateKeyFormat(keyFormat **string, schemaRegistry string, logger *slog.Logger) error {
+	if *keyFormat == nil {
+		defaultValue := string(FormatString)
+		*keyFormat = &defaultValue
+		logger.Debug("Key_format not provided, using default", "default", defaultValue)
+		return nil
+	}
+	if !validKeyFormats[Format(**keyFormat)] {
+		logger.Error("Invalid key_format", "value", **keyFormat)
+		return fmt.Errorf("key_format must be one of: string, json, msgpack, avro, protobuf; got: %s", **keyFormat)
+	}
+	if (**keyFormat == string(FormatAvro) || **keyFormat == string(FormatProto)) && schemaRegistry == "" {
+		logger.Error("schema_registry_url is required for AVRO and PROTOBUF key formats", "key_format", **keyFormat)
+		return fmt.Errorf("schema_registry_url is required for AVRO and PROTOBUF key formats")
+	}
+	return nil
+}
+
+// Validate applies the CSV defaults and ensures the column order is derivable, from the header row or Columns.
+func (cc *CSVConfig) Validate(logger *slog.Logger) error {
+	return cc.validate(logger, false)
+}
+
+func (cc *CSVConfig) validate(logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if cc.Delimiter == nil {
+		defaultValue := ","
+		cc.Delimiter = &defaultValue
+		logger.Debug("CSV delimiter not provided, using default", "default", defaultValue)
+	} else if d := []rune(*cc.Delimiter); len(d) != 1 || d[0] == '"' || d[0] == '\r' || d[0] == '\n' {
+		logger.Error("CSV validation failed: delimiter must be a single character", "value", *cc.Delimiter)
+		if errs.add(fmt.Errorf("delimiter must be a single character other than a quote or a newline, got: %q", *cc.Delimiter)) {
+			return errs.err()
+		}
+	}
+
+	if cc.Header == nil {
+		defaultValue := false
+		cc.Header = &defaultValue
+		logger.Debug("CSV header not provided, using default", "default", defaultValue)
+	}
+
+	if !*cc.Header && len(cc.Columns) == 0 {
+		logger.Error("CSV validation failed: columns are required without header row")
+		if errs.add(fmt.Errorf("columns are required when there is no header row")) {
+			return errs.err()
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, column := range cc.Columns {
+		if column == "" {
+			logger.Error("CSV validation failed: empty column name", "index", i)
+			if errs.add(fmt.Errorf("columns[%d] cannot be empty", i)) {
+				return errs.err()
+			}
+		}
+		if seen[column] {
+			logger.Error("CSV validation failed: duplicated column", "column", column)
+			if errs.add(fmt.Errorf("column %q is duplicated", column)) {
+				return errs.err()
+			}
+		}
+		seen[column] = true
+	}
+
+	return errs.err()
+}
+
+// AllTopics returns the deduplicated list of input topics from both Topic and Topics.
+// It is empty with Topic_regex, the matching topics being only known to the consumer group.
+func (ic *InputConfig) AllTopics() []string {
+	seen := make(map[string]bool)
+	topics := make([]string, 0, len(ic.Topics)+1)
+	for _, topic := range append([]string{ic.Topic}, ic.Topics...) {
+		if topic == "" || seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// validateProcessorTopic checks the topic selected by a processor (ProcessorConfig.Topic) is consumed by the input:
+// one of its topics, or a topic matching its topic_regex
+func (ic *InputConfig) validateProcessorTopic(topic string, logger *slog.Logger) error {
+	if topic == "" {
+		return nil
+	}
+
+	if ic.Topic_regex != nil {
+		if regex, err := regexp.Compile(*ic.Topic_regex); err == nil && regex.MatchString(topic) {
+			return nil
+		}
+		logger.Error("ProcessorConfig validation failed: topic not matching the input topic_regex", "topic", topic, "topic_regex", *ic.Topic_regex)
+		return fmt.Errorf("topic %q does not match the input topic_regex %q", topic, *ic.Topic_regex)
+	}
+	if !slices.Contains(ic.AllTopics(), topic) {
+		logger.Error("ProcessorConfig validation failed: topic not consumed by the input", "topic", topic, "topics", ic.AllTopics())
+		return fmt.Errorf("topic %q is not an input topic", topic)
+	}
+	return nil
+}
+
+// validateTopicRegex checks the topic_regex subscription, which replaces the topic list.
+// The matching topics are subscribed through the consumer group (franz-go ConsumeRegex), new ones being picked up
+// on the metadata refreshes (metadata_max_age), so it requires the consumer group mode.
+func (ic *InputConfig) validateTopicRegex(logger *slog.Logger) error {
+	if ic.Topic_regex == nil {
+		return nil
+	}
+
+	if *ic.Topic_regex == "" {
+		logger.Error("InputConfig validation failed: topic_regex cannot be empty")
+		return fmt.Errorf("topic_regex cannot be empty")
+	}
+	if _, err := regexp.Compile(*ic.Topic_regex); err != nil {
+		logger.Error("InputConfig validation failed: Invalid topic_regex", "value", *ic.Topic_regex, "error", err)
+		return fmt.Errorf("invalid topic_regex: %w", err)
+	}
+	if ic.Topic != "" || len(ic.Topics) > 0 {
+		logger.Error("InputConfig validation failed: topic_regex cannot be combined with topic or topics")
+		return fmt.Errorf("topic_regex cannot be combined with topic or topics")
+	}
+	if ic.Checkpoint_file != nil {
+		logger.Error("InputConfig validation failed: topic_regex requires the consumer group mode, incompatible with checkpoint_file")
+		return fmt.Errorf("topic_regex requires the consumer group mode, it can't be used with checkpoint_file")
+	}
+	if len(ic.Partitions) > 0 {
+		logger.Error("InputConfig validation failed: partitions cannot be combined with topic_regex")
+		return fmt.Errorf("partitions cannot be combined with topic_regex, the matching topics are unknown")
+	}
+
+	logger.Info("Topic regex subscription enabled", "topic_regex", *ic.Topic_regex)
+	return nil
+}
+
+// validateCheckpoint checks the checkpoint options, only used when consuming without consumer group
+func (ic *InputConfig) validateCheckpoint(logger *slog.Logger) error {
+	if ic.Checkpoint_file == nil {
+		if ic.Checkpoint_interval != nil {
+			logger.Warn("Checkpoint_interval ignored because checkpoint_file is not set")
+		}
+		return nil
+	}
+
+	if *ic.Checkpoint_file == "" {
+		logger.Error("InputConfig validation failed: checkpoint_file cannot be empty")
+		return fmt.Errorf("checkpoint_file cannot be empty")
+	}
+	dir := filepath.Dir(*ic.Checkpoint_file)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		logger.Error("InputConfig validation failed: checkpoint_file directory does not exist", "dir", dir)
+		return fmt.Errorf("checkpoint_file directory %s does not exist", dir)
+	}
+
+	if ic.Checkpoint_interval == nil {
+		defaultValue := "5s"
+		ic.Checkpoint_interval = &defaultValue
+		logger.Debug("Checkpoint_interval not provided, using default", "default", defaultValue)
+	} else if interval, err := time.ParseDuration(*ic.Checkpoint_interval); err != nil || interval <= 0 {
+		logger.Error("InputConfig validation failed: Invalid checkpoint_interval", "value", *ic.Checkpoint_interval)
+		return fmt.Errorf("checkpoint_interval must be a positive duration, got: %s", *ic.Checkpoint_interval)
+	}
+
+	logger.Info("Checkpoint file enabled, consuming without consumer group (consumer_group_id ignored)", "file", *ic.Checkpoint_file)
 	return nil
 }
 
 func (oc *OutputConfig) Validate(logger *slog.Logger) error {
+	return oc.validate(logger, false)
+}
+
+func (oc *OutputConfig) validate(logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
 	logger.Debug("Validating OutputConfig", "topic", oc.Topic)
 	if oc.Type != "kafka" {
 		logger.Error("OutputConfig validation failed: Unsupported output type", "type", oc.Type)
-		return fmt.Errorf("unsupported output type: %s", oc.Type)
+		if errs.add(fmt.Errorf("unsupported output type: %s", oc.Type)) {
+			return errs.err()
+		}
 	}
 
 	if len(oc.Brokers) == 0 {
 		logger.Error("OutputConfig validation failed: Brokers is required and cannot be empty")
-		return fmt.Errorf("brokers is required and cannot be empty")
+		if errs.add(fmt.Errorf("brokers is required and cannot be empty")) {
+			return errs.err()
+		}
 	}
 
 	if oc.Topic == "" {
 		logger.Error("OutputConfig validation failed: Topic is required and cannot be empty")
-		return fmt.Errorf("topic is required and cannot be empty")
+		if errs.add(fmt.Errorf("topic is required and cannot be empty")) {
+			return errs.err()
+		}
 	}
 
 	if oc.Workers <= 0 {
@@ -245,14 +886,46 @@ func (oc *OutputConfig) Validate(logger *slog.Logger) error {
 		oc.Workers = 1
 	}
 
-	if !ValidFormats[Format(oc.Format)] {
+	seen := make(map[int]bool, len(oc.Partitions))
+	for _, partition := range oc.Partitions {
+		if partition < 0 || seen[partition] {
+			logger.Error("OutputConfig validation failed: invalid or duplicate partition", "partition", partition)
+			if errs.add(fmt.Errorf("partitions must be distinct non-negative partition numbers, got: %v", oc.Partitions)) {
+				return errs.err()
+			}
+			break
+		}
+		seen[partition] = true
+	}
+
+	if !ValidFormats[Format(oc.Format)] || oc.Format == string(FormatAuto) {
 		logger.Error("OutputConfig validation failed: Unsupported format", "format", oc.Format)
-		return fmt.Errorf("unsupported format: %s", oc.Format)
+		if errs.add(fmt.Errorf("unsupported format: %s", oc.Format)) {
+			return errs.err()
+		}
 	}
 
 	if (oc.Format == "avro" || oc.Format == "protobuf") && oc.SchemaRegistry == "" {
 		logger.Error("OutputConfig validation failed: schema_registry_url is required for AVRO and PROTOBUF formats")
-		return fmt.Errorf("schema_registry_url is required for AVRO and PROTOBUF formats")
+		if errs.add(fmt.Errorf("schema_registry_url is required for AVRO and PROTOBUF formats")) {
+			return errs.err()
+		}
+	}
+
+	if errs.add(validateKeyFormat(&oc.Key_format, oc.SchemaRegistry, logger)) {
+		return errs.err()
+	}
+
+	if oc.Format == string(FormatCSV) {
+		if oc.Csv == nil {
+			oc.Csv = &CSVConfig{}
+		}
+		if errs.addAll("invalid csv options", oc.Csv.validate(logger, failFast)) {
+			return errs.err()
+		}
+		if *oc.Csv.Header && len(oc.Csv.Columns) == 0 {
+			logger.Info("CSV columns not set, fields are written in alphabetical order")
+		}
 	}
 
 	if oc.Batch_size == nil {
@@ -265,6 +938,18 @@ func (oc *OutputConfig) Validate(logger *slog.Logger) error {
 		oc.Batch_size = &defaultValue
 	}
 
+	// The records wait for the linger, unless the buffered records (batch_size) are flushed first
+	if oc.Linger == nil {
+		defaultValue := "10ms"
+		oc.Linger = &defaultValue
+		logger.Debug("Linger not provided, using default", "default", defaultValue)
+	} else if linger, err := time.ParseDuration(*oc.Linger); err != nil || linger < 0 || linger > time.Minute {
+		logger.Error("OutputConfig validation failed: Invalid linger", "value", *oc.Linger)
+		if errs.add(fmt.Errorf("linger must be a non-negative duration up to 1m, got: %s", *oc.Linger)) {
+			return errs.err()
+		}
+	}
+
 	if oc.Compression == nil {
 		defaultValue := "none"
 		oc.Compression = &defaultValue
@@ -281,7 +966,9 @@ func (oc *OutputConfig) Validate(logger *slog.Logger) error {
 		}
 		if !valid {
 			logger.Error("Invalid compression", "value", *oc.Compression)
-			return fmt.Errorf("compression must be one of: none, gzip, snappy, lz4, zstd; got: %s", *oc.Compression)
+			if errs.add(fmt.Errorf("compression must be one of: none, gzip, snappy, lz4, zstd; got: %s", *oc.Compression)) {
+				return errs.err()
+			}
 		}
 	}
 
@@ -295,7 +982,9 @@ func (oc *OutputConfig) Validate(logger *slog.Logger) error {
 		_, err := time.ParseDuration(*oc.Retry_backoff)
 		if err != nil {
 			logger.Error("Invalid retry_backoff format", "value", *oc.Retry_backoff)
-			return fmt.Errorf("invalid retry_backoff: %w", err)
+			if errs.add(fmt.Errorf("invalid retry_backoff: %w", err)) {
+				return errs.err()
+			}
 		}
 	} else {
 		defaultValue := "2s"
@@ -316,73 +1005,369 @@ func (oc *OutputConfig) Validate(logger *slog.Logger) error {
 		oc.Request_timeout = &defaultValue
 	}
 
+	// A request timing out before the backoff elapses leaves no time for the retries
+	retryBackoff, retryErr := time.ParseDuration(*oc.Retry_backoff)
+	requestTimeout, _ := time.ParseDuration(*oc.Request_timeout)
+	if retryErr == nil && requestTimeout <= retryBackoff {
+		logger.Error("OutputConfig validation failed: request_timeout must exceed retry_backoff", "request_timeout", *oc.Request_timeout, "retry_backoff", *oc.Retry_backoff)
+		if errs.add(fmt.Errorf("request_timeout (%s) must exceed retry_backoff (%s)", *oc.Request_timeout, *oc.Retry_backoff)) {
+			return errs.err()
+		}
+	}
+
 	if oc.Max_retries == nil {
 		defaultValue := 3
 		oc.Max_retries = &defaultValue
 		logger.Info("Max_retries not set, defaulting to", "default", defaultValue)
 	}
 
-	logger.Info("InputConfig validation successful")
-	return nil
-}
-
-type ProcessorValidator interface {
-	Validate(config map[string]interface{}, logger *slog.Logger) error
-}
+	if oc.Breaker_failure_threshold == nil {
+		defaultValue := 5
+		oc.Breaker_failure_threshold = &defaultValue
+		logger.Info("Breaker_failure_threshold not set, defaulting to", "default", defaultValue)
+	} else if *oc.Breaker_failure_threshold < 0 {
+		logger.Error("OutputConfig validation failed: breaker_failure_threshold cannot be negative", "value", *oc.Breaker_failure_threshold)
+		if errs.add(fmt.Errorf("breaker_failure_threshold cannot be negative, got: %d", *oc.Breaker_failure_threshold)) {
+			return errs.err()
+		}
+	}
 
-// Validators mapping for different processor types and to provide an easier implementation of the Validate method
-var processorValidators = map[string]ProcessorValidator{
-	ProcessorTypeTimestampReplay: &TimestampReplayValidator{},
-	ProcessorTypeTransform:       &TransformValidator{},
-	ProcessorTypeDrop:            &DropValidator{},
-	ProcessorTypeEnrich:          &EnrichValidator{},
-	ProcessorTypePassthrough:     &PassthroughValidator{},
-}
+	if oc.Breaker_cooldown != nil {
+		cooldown, err := time.ParseDuration(*oc.Breaker_cooldown)
+		if err != nil {
+			logger.Error("OutputConfig validation failed: Invalid breaker_cooldown format", "value", *oc.Breaker_cooldown)
+			if errs.add(fmt.Errorf("invalid breaker_cooldown format: %w", err)) {
+				return errs.err()
+			}
+		} else if cooldown <= 0 {
+			logger.Error("OutputConfig validation failed: breaker_cooldown must be positive", "value", *oc.Breaker_cooldown)
+			if errs.add(fmt.Errorf("breaker_cooldown must be positive, got: %s", *oc.Breaker_cooldown)) {
+				return errs.err()
+			}
+		}
+	} else {
+		defaultValue := "30s"
+		oc.Breaker_cooldown = &defaultValue
+		logger.Info("Breaker_cooldown not set, defaulting to", "default", defaultValue)
+	}
 
-// ====== TIMESTAMP REPLAY VALIDATOR ====== //
+	if oc.Breaker_buffer_size == nil {
+		defaultValue := 1000
+		oc.Breaker_buffer_size = &defaultValue
+		logger.Info("Breaker_buffer_size not set, defaulting to", "default", defaultValue)
+	} else if *oc.Breaker_buffer_size <= 0 {
+		logger.Error("OutputConfig validation failed: breaker_buffer_size must be positive", "value", *oc.Breaker_buffer_size)
+		if errs.add(fmt.Errorf("breaker_buffer_size must be positive, got: %d", *oc.Breaker_buffer_size)) {
+			return errs.err()
+		}
+	}
 
-type TimestampReplayValidator struct{}
+	if oc.Backpressure_high_watermark == nil {
+		defaultValue := max(*oc.Batch_size*8/10, 1)
+		oc.Backpressure_high_watermark = &defaultValue
+		logger.Debug("Backpressure_high_watermark not provided, using default", "default", defaultValue)
+	} else if *oc.Backpressure_high_watermark <= 0 {
+		logger.Error("OutputConfig validation failed: backpressure_high_watermark must be positive", "value", *oc.Backpressure_high_watermark)
+		if errs.add(fmt.Errorf("backpressure_high_watermark must be positive, got: %d", *oc.Backpressure_high_watermark)) {
+			return errs.err()
+		}
+	} else if *oc.Backpressure_high_watermark > *oc.Batch_size {
+		logger.Warn("backpressure_high_watermark above batch_size, only reached while the circuit breaker buffers records",
+			"high_watermark", *oc.Backpressure_high_watermark, "batch_size", *oc.Batch_size)
+	}
 
-var availableUnits = map[string]bool{
-	"microseconds": true,
-	"milliseconds": true,
-	"seconds":      true,
-	"minutes":      true,
-	"hours":        true,
-	"days":         true,
-	"weeks":        true,
-	"months":       true,
-	"years":        true,
-}
+	if oc.Backpressure_low_watermark == nil {
+		defaultValue := min(*oc.Batch_size/2, *oc.Backpressure_high_watermark-1)
+		oc.Backpressure_low_watermark = &defaultValue
+		logger.Debug("Backpressure_low_watermark not provided, using default", "default", defaultValue)
+	} else if *oc.Backpressure_low_watermark < 0 || *oc.Backpressure_low_watermark >= *oc.Backpressure_high_watermark {
+		logger.Error("OutputConfig validation failed: backpressure_low_watermark must be below the high watermark",
+			"low_watermark", *oc.Backpressure_low_watermark, "high_watermark", *oc.Backpressure_high_watermark)
+		if errs.add(fmt.Errorf("backpressure_low_watermark must be between 0 and backpressure_high_watermark (%d), got: %d",
+			*oc.Backpressure_high_watermark, *oc.Backpressure_low_watermark)) {
+			return errs.err()
+		}
+	}
 
-func (v *TimestampReplayValidator) Validate(cfg map[string]interface{}, logger *slog.Logger) error {
-	hasTargetTimestamp := cfg["target_timestamp"] != nil
-	hasOffset := cfg["offset"] != nil
-	hasUnit := cfg["unit"] != nil
+	if oc.Ordered == nil {
+		defaultValue := false
+		oc.Ordered = &defaultValue
+		logger.Debug("Ordered not provided, using default", "default", defaultValue)
+	}
+	if oc.Ordered_buffer_size == nil {
+		defaultValue := 1000
+		oc.Ordered_buffer_size = &defaultValue
+		logger.Debug("Ordered_buffer_size not provided, using default", "default", defaultValue)
+	} else if *oc.Ordered_buffer_size <= 0 {
+		logger.Error("OutputConfig validation failed: ordered_buffer_size must be positive", "value", *oc.Ordered_buffer_size)
+		if errs.add(fmt.Errorf("ordered_buffer_size must be positive, got: %d", *oc.Ordered_buffer_size)) {
+			return errs.err()
+		}
+	}
 
-	if !hasTargetTimestamp && !hasOffset {
-		logger.Error("timestamp_replay validation failed: must provide either 'target_timestamp' or 'offset'")
-		return fmt.Errorf("timestamp_replay: must provide either 'target_timestamp' or 'offset' but not both")
+	if errs.add(validatePayloadCompression(&oc.Payload_compression, logger)) {
+		return errs.err()
 	}
 
-	if hasTargetTimestamp && hasOffset {
-		logger.Error("timestamp_replay validation failed: cannot provide both 'target_timestamp' and 'offset'")
-		return fmt.Errorf("timestamp_replay: cannot provide both 'target_timestamp' and 'offset'")
+	if oc.Non_finite_floats == nil {
+		defaultValue := NonFiniteError
+		oc.Non_finite_floats = &defaultValue
+		logger.Debug("Non_finite_floats not provided, using default", "default", defaultValue)
+	} else if v := *oc.Non_finite_floats; v != NonFiniteError && v != NonFiniteNull && v != NonFiniteDropField {
+		logger.Error("OutputConfig validation failed: Invalid non_finite_floats value", "value", v)
+		if errs.add(fmt.Errorf("non_finite_floats must be 'error', 'null' or 'drop_field', got: %s", v)) {
+			return errs.err()
+		}
 	}
 
-	if hasOffset && !hasUnit {
-		logger.Error("timestamp_replay validation failed: 'unit' is required when using 'offset'")
-		return fmt.Errorf("timestamp_replay: 'unit' is required when using 'offset'")
+	if oc.Timestamp_source == nil {
+		defaultValue := TimestampSourceMessage
+		oc.Timestamp_source = &defaultValue
+		logger.Debug("Timestamp_source not provided, using default", "default", defaultValue)
+	} else if v := *oc.Timestamp_source; v != TimestampSourceMessage && v != TimestampSourceNow {
+		logger.Error("OutputConfig validation failed: Invalid timestamp_source value", "value", v)
+		if errs.add(fmt.Errorf("timestamp_source must be 'message' or 'now', got: %s", v)) {
+			return errs.err()
+		}
+	}
+
+	if oc.Topic_field != nil && *oc.Topic_field == "" {
+		logger.Error("OutputConfig validation failed: topic_field cannot be empty")
+		if errs.add(fmt.Errorf("topic_field cannot be empty")) {
+			return errs.err()
+		}
+	}
+
+	if len(oc.Topic_map) > 0 {
+		if oc.Topic_field == nil {
+			logger.Error("OutputConfig validation failed: topic_map requires topic_field")
+			if errs.add(fmt.Errorf("topic_map requires topic_field to be set")) {
+				return errs.err()
+			}
+		}
+		for key, topic := range oc.Topic_map {
+			if topic == "" {
+				logger.Error("OutputConfig validation failed: empty topic in topic_map", "key", key)
+				if errs.add(fmt.Errorf("topic_map entry %q has an empty topic", key)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+
+	for name, template := range oc.Headers {
+		if name == "" {
+			logger.Error("OutputConfig validation failed: empty header name")
+			if errs.add(fmt.Errorf("headers names cannot be empty")) {
+				return errs.err()
+			}
+		}
+		if err := validHeaderTemplate(template); err != nil {
+			logger.Error("OutputConfig validation failed: invalid header value", "header", name, "error", err)
+			if errs.add(fmt.Errorf("header %q: %w", name, err)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if oc.Topic_field != nil {
+		logger.Info("Topic routing enabled", "topic_field", *oc.Topic_field, "mapped_values", len(oc.Topic_map), "fallback_topic", oc.Topic)
+		if !*oc.Auto_create_topic {
+			logger.Warn("Topic routing without auto_create_topic: routed topics must already exist")
+		}
+	}
+
+	logger.Info("InputConfig validation successful")
+	return errs.err()
+}
+
+func (ec *ErrorsConfig) Validate(logger *slog.Logger) error {
+	return ec.validate(logger, false)
+}
+
+func (ec *ErrorsConfig) validate(logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	switch ec.Policy {
+	case "":
+		ec.Policy = ErrorPolicySkip
+		logger.Debug("Error policy not provided, using default", "default", ErrorPolicySkip)
+	case ErrorPolicySkip, ErrorPolicyDrop, ErrorPolicyFail:
+	case ErrorPolicyDLQ:
+		if ec.Dlq_topic == "" {
+			logger.Error("ErrorsConfig validation failed: dlq_topic is required by the dlq policy")
+			if errs.add(fmt.Errorf("dlq_topic is required by the dlq policy")) {
+				return errs.err()
+			}
+		}
+	default:
+		logger.Error("ErrorsConfig validation failed: Invalid policy", "policy", ec.Policy)
+		if errs.add(fmt.Errorf("error policy must be one of: skip, drop, dlq, fail; got: %s", ec.Policy)) {
+			return errs.err()
+		}
+	}
+
+	if ec.Dlq_topic != "" && ec.Policy != ErrorPolicyDLQ {
+		logger.Warn("Dlq_topic ignored because the error policy is not dlq", "policy", ec.Policy)
+	}
+
+	if env := ec.Dlq_envelope; env != nil {
+		if len(env.Fields) == 0 {
+			env.Fields = []string{"key", "value", "topic", "partition", "offset", "processor", "error", "timestamp"}
+		}
+		for _, field := range env.Fields {
+			if !DLQEnvelopeFields[field] {
+				logger.Error("ErrorsConfig validation failed: Unknown dlq_envelope field", "field", field)
+				if errs.add(fmt.Errorf("unknown dlq_envelope field: %s", field)) {
+					return errs.err()
+				}
+			}
+		}
+
+		if env.Payload_encoding == nil {
+			defaultValue := "base64"
+			env.Payload_encoding = &defaultValue
+		} else if *env.Payload_encoding != "base64" && *env.Payload_encoding != "raw" {
+			logger.Error("ErrorsConfig validation failed: Invalid dlq_envelope payload_encoding", "payload_encoding", *env.Payload_encoding)
+			if errs.add(fmt.Errorf("dlq_envelope payload_encoding must be 'base64' or 'raw', got: %s", *env.Payload_encoding)) {
+				return errs.err()
+			}
+		}
+	}
+	return errs.err()
+}
+
+func (mc *MonitoringConfig) Validate(logger *slog.Logger) error {
+	return mc.validate(logger, false)
+}
+
+func (mc *MonitoringConfig) validate(logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	me := &mc.Metrics_export
+	if !me.Enabled {
+		return errs.err()
+	}
+
+	if me.Type == "" {
+		me.Type = "prometheus"
+	} else if me.Type != "prometheus" {
+		logger.Error("MonitoringConfig validation failed: Unsupported metrics export type", "type", me.Type)
+		if errs.add(fmt.Errorf("unsupported metrics export type: %s", me.Type)) {
+			return errs.err()
+		}
+	}
+
+	if me.Port == 0 {
+		me.Port = 9090
+		logger.Info("Metrics port not set, defaulting to", "default", me.Port)
+	} else if me.Port < 0 || me.Port > 65535 {
+		logger.Error("MonitoringConfig validation failed: Invalid metrics port", "port", me.Port)
+		if errs.add(fmt.Errorf("invalid metrics port: %d", me.Port)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ProcessorValidator checks the config of a processor type and applies its defaults. It reports every invalid parameter,
+// or only the first one with failFast, see validationErrors.
+type ProcessorValidator interface {
+	Validate(config map[string]interface{}, logger *slog.Logger, failFast bool) error
+}
+
+// Validators mapping for different processor types and to provide an easier implementation of the Validate method
+var processorValidators = map[string]ProcessorValidator{
+	ProcessorTypeTimestampReplay: &TimestampReplayValidator{},
+	ProcessorTypeTransform:       &TransformValidator{},
+	ProcessorTypeDrop:            &DropValidator{},
+	ProcessorTypeEnrich:          &EnrichValidator{},
+	ProcessorTypePassthrough:     &PassthroughValidator{},
+	ProcessorTypeHeaderField:     &HeaderFieldValidator{},
+	ProcessorTypeSample:          &SampleValidator{},
+	ProcessorTypePace:            &PaceValidator{},
+	ProcessorTypeExtract:         &ExtractValidator{},
+	ProcessorTypeMerge:           &MergeValidator{},
+	ProcessorTypeCopy:            &CopyValidator{},
+	ProcessorTypeRoute:           &RouteValidator{},
+	ProcessorTypeFieldExists:     &FieldExistsValidator{},
+	ProcessorTypeMaxAge:          &MaxAgeValidator{},
+	ProcessorTypeBucket:          &BucketValidator{},
+	ProcessorTypeExplode:         &ExplodeValidator{},
+	ProcessorTypeAggregate:       &AggregateValidator{},
+	ProcessorTypeProject:         &ProjectValidator{},
+	ProcessorTypeEmptyToNull:     &EmptyToNullValidator{},
+	ProcessorTypeSchema:          &SchemaValidator{},
+	ProcessorTypeRenameKeys:      &RenameKeysValidator{},
+	ProcessorTypeGenerateID:      &GenerateIDValidator{},
+	ProcessorTypeKeyCase:         &KeyCaseValidator{},
+	ProcessorTypeHeadersObject:   &HeadersObjectValidator{},
+	ProcessorTypeFieldLimits:     &FieldLimitsValidator{},
+	ProcessorTypeCoalesce:        &CoalesceValidator{},
+	ProcessorTypeDedupAdjacent:   &DedupAdjacentValidator{},
+	ProcessorTypeEpochConvert:    &EpochConvertValidator{},
+	ProcessorTypeNormalize:       &NormalizeValidator{},
+	ProcessorTypeFingerprint:     &FingerprintValidator{},
+	ProcessorTypeTemplate:        &TemplateValidator{},
+	ProcessorTypeTap:             &TapValidator{},
+	ProcessorTypeRequire:         &RequireValidator{},
+	ProcessorTypeFormatNumber:    &FormatNumberValidator{},
+	ProcessorTypeLookup:          &LookupValidator{},
+}
+
+// ====== TIMESTAMP REPLAY VALIDATOR ====== //
+
+type TimestampReplayValidator struct{}
+
+var availableUnits = map[string]bool{
+	"microseconds": true,
+	"milliseconds": true,
+	"seconds":      true,
+	"minutes":      true,
+	"hours":        true,
+	"days":         true,
+	"weeks":        true,
+	"months":       true,
+	"years":        true,
+}
+
+func (v *TimestampReplayValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	hasTargetTimestamp := cfg["target_timestamp"] != nil
+	hasOffset := cfg["offset"] != nil
+	hasUnit := cfg["unit"] != nil
+
+	if !hasTargetTimestamp && !hasOffset {
+		logger.Error("timestamp_replay validation failed: must provide either 'target_timestamp' or 'offset'")
+		if errs.add(fmt.Errorf("timestamp_replay: must provide either 'target_timestamp' or 'offset' but not both")) {
+			return errs.err()
+		}
+	}
+
+	if hasTargetTimestamp && hasOffset {
+		logger.Error("timestamp_replay validation failed: cannot provide both 'target_timestamp' and 'offset'")
+		if errs.add(fmt.Errorf("timestamp_replay: cannot provide both 'target_timestamp' and 'offset'")) {
+			return errs.err()
+		}
+	}
+
+	if hasOffset && !hasUnit {
+		logger.Error("timestamp_replay validation failed: 'unit' is required when using 'offset'")
+		if errs.add(fmt.Errorf("timestamp_replay: 'unit' is required when using 'offset'")) {
+			return errs.err()
+		}
 	}
 
 	if hasTargetTimestamp {
-		parsedtimestamp, err := time.Parse(time.RFC3339, cfg["target_timestamp"].(string))
+		target, _ := cfg["target_timestamp"].(string)
+		parsedtimestamp, err := time.Parse(time.RFC3339, target)
 		if err != nil {
 			logger.Error("timestamp_replay validation failed: invalid target_timestamp format", "error", err)
-			return fmt.Errorf("timestamp_replay: invalid target_timestamp format: %w", err)
+			if errs.add(fmt.Errorf("timestamp_replay: invalid target_timestamp format: %w", err)) {
+				return errs.err()
+			}
+		} else {
+			cfg["parsed_timestamp"] = parsedtimestamp
 		}
-
-		cfg["parsed_timestamp"] = parsedtimestamp
 	}
 
 	if hasOffset {
@@ -391,45 +1376,138 @@ func (v *TimestampReplayValidator) Validate(cfg map[string]interface{}, logger *
 		case int, int64:
 		default:
 			logger.Error("timestamp_replay validation failed: 'offset' must be an integer")
-			return fmt.Errorf("offset must be an integer")
+			if errs.add(fmt.Errorf("offset must be an integer")) {
+				return errs.err()
+			}
 		}
 		unitStr, ok := cfg["unit"].(string)
-		if !ok || !availableUnits[unitStr] {
+		if hasUnit && (!ok || !availableUnits[unitStr]) {
 			logger.Error("timestamp_replay validation failed: invalid 'unit' value", "value", cfg["unit"])
-			return fmt.Errorf("timestamp_replay: invalid 'unit' value: %v", cfg["unit"])
+			if errs.add(fmt.Errorf("timestamp_replay: invalid 'unit' value: %v", cfg["unit"])) {
+				return errs.err()
+			}
 		}
 	}
 
-	return nil
+	return errs.err()
 }
 
 // ====== DROP VALIDATOR ====== //
 
 type DropValidator struct{}
 
-// DropValidator has two specifics fields :
+// DropValidator has five specifics fields, field_name and filter_criteria being the shorthand of a single condition :
 // filterCriteria : string (e.g., "field_name=<filterCriteria")
 // fieldName : string (e.g., "<field_name>=filterCriteria")
-func (v *DropValidator) Validate(cfg map[string]interface{}, logger *slog.Logger) error {
+// valueType : string (optional, "string", "int", "float" or "bool" the criteria and field are compared as, default "string")
+// conditions : list of {field, operator, value} (instead of field_name and filter_criteria)
+// match : string (optional with conditions, "all" or "any" of them dropping the message, default "all")
+func (v *DropValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if conditions, ok := cfg["conditions"]; ok {
+		return v.validateConditions(cfg, conditions, logger, failFast)
+	}
+	if cfg["match"] != nil {
+		logger.Error("drop validation failed: 'match' requires 'conditions'")
+		if errs.add(fmt.Errorf("drop: 'match' requires 'conditions'")) {
+			return errs.err()
+		}
+	}
+
 	hasFieldName := cfg["field_name"] != nil
 	hasFilterCriteria := cfg["filter_criteria"] != nil
 
 	if !hasFieldName || !hasFilterCriteria {
 		logger.Error("drop validation failed: both 'field_name' and 'filter_criteria' are required")
-		return fmt.Errorf("drop: both 'field_name' and 'filter_criteria' are required")
+		if errs.add(fmt.Errorf("drop: both 'field_name' and 'filter_criteria' are required, or 'conditions'")) {
+			return errs.err()
+		}
 	}
 
-	if _, ok := cfg["filter_criteria"].(string); !ok {
+	if _, ok := cfg["filter_criteria"].(string); hasFilterCriteria && !ok {
 		logger.Error("drop validation failed: 'filter_criteria' must be a string")
-		return fmt.Errorf("drop: 'filter_criteria' must be a string")
+		if errs.add(fmt.Errorf("drop: 'filter_criteria' must be a string")) {
+			return errs.err()
+		}
 	}
 
-	if _, ok := cfg["field_name"].(string); !ok {
+	if _, ok := cfg["field_name"].(string); hasFieldName && !ok {
 		logger.Error("drop validation failed: 'field_name' must be a string")
-		return fmt.Errorf("drop: 'field_name' must be a string")
+		if errs.add(fmt.Errorf("drop: 'field_name' must be a string")) {
+			return errs.err()
+		}
 	}
 
-	return nil
+	if val, ok := cfg["value_type"]; ok {
+		criteria, _ := cfg["filter_criteria"].(string)
+		var err error
+		switch val {
+		case "string":
+		case "int":
+			_, err = strconv.ParseInt(criteria, 10, 64)
+		case "float":
+			_, err = strconv.ParseFloat(criteria, 64)
+		case "bool":
+			_, err = strconv.ParseBool(criteria)
+		default:
+			logger.Error("drop validation failed: invalid 'value_type' value", "value", val)
+			if errs.add(fmt.Errorf("drop: 'value_type' must be one of string, int, float, bool, got: %v", val)) {
+				return errs.err()
+			}
+		}
+		if err != nil {
+			logger.Error("drop validation failed: 'filter_criteria' doesn't match 'value_type'", "filter_criteria", criteria, "value_type", val)
+			if errs.add(fmt.Errorf("drop: 'filter_criteria' %q is not a valid %v", criteria, val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// validateConditions checks the conditions form, exclusive with the field_name and filter_criteria shorthand
+func (v *DropValidator) validateConditions(cfg map[string]interface{}, conditions interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	for _, shorthand := range []string{"field_name", "filter_criteria", "value_type"} {
+		if cfg[shorthand] != nil {
+			logger.Error("drop validation failed: 'conditions' can't be combined with the shorthand", "field", shorthand)
+			if errs.add(fmt.Errorf("drop: '%s' can't be combined with 'conditions'", shorthand)) {
+				return errs.err()
+			}
+		}
+	}
+
+	items, ok := conditions.([]interface{})
+	if !ok || len(items) == 0 {
+		logger.Error("drop validation failed: 'conditions' must be a non empty list")
+		if errs.add(fmt.Errorf("drop: 'conditions' must be a non empty list")) {
+			return errs.err()
+		}
+	}
+	for i, item := range items {
+		when, ok := item.(map[string]interface{})
+		if !ok {
+			if errs.add(fmt.Errorf("drop: conditions[%d] must be an object", i)) {
+				return errs.err()
+			}
+			continue
+		}
+		if err := validateCondition(when); err != nil {
+			logger.Error("drop validation failed: invalid condition", "index", i, "error", err)
+			if errs.add(fmt.Errorf("drop: conditions[%d] %w", i, err)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if match, ok := cfg["match"]; ok && match != "all" && match != "any" {
+		logger.Error("drop validation failed: invalid 'match' value", "value", match)
+		if errs.add(fmt.Errorf("drop: 'match' must be 'all' or 'any', got: %v", match)) {
+			return errs.err()
+		}
+	}
+	return errs.err()
 }
 
 // ====== TRANSFORM VALIDATOR ====== //
@@ -437,61 +1515,90 @@ func (v *DropValidator) Validate(cfg map[string]interface{}, logger *slog.Logger
 type TransformValidator struct{}
 
 var availableOperations = map[string]bool{
-	"uppercase":  true,
-	"lowercase":  true,
-	"add_prefix": true,
-	"add_suffix": true,
+	"uppercase":      true,
+	"lowercase":      true,
+	"add_prefix":     true,
+	"add_suffix":     true,
+	"base64_encode":  true,
+	"base64_decode":  true,
+	"json_parse":     true,
+	"json_stringify": true,
 }
 
 // TransformValidator has two specifics fields :
 // fieldName : string (the field to modify/transform)
-// operation : string (e.g., "uppercase", "lowercase", "add_prefix", "add_suffix")
+// operation : string (e.g., "uppercase", "lowercase", "add_prefix", "add_suffix", "base64_encode", "base64_decode", "json_parse", "json_stringify")
 // prefix : string (the prefix to add, required if operation is "add_prefix")
 // suffix : string (the suffix to add, required if operation is "add_suffix")
-func (v *TransformValidator) Validate(cfg map[string]interface{}, logger *slog.Logger) error {
+// keep_original_on_error : bool (optional, a failing transformation keeps the field unchanged instead of failing the message, default false)
+func (v *TransformValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
 	hasFieldName := cfg["field_name"] != nil
 	hasOperation := cfg["operation"] != nil
 
 	if !hasFieldName || !hasOperation {
 		logger.Error("transform validation failed: both 'field_name' and 'operation' are required")
-		return fmt.Errorf("transform: both 'field_name' and 'operation' are required")
+		if errs.add(fmt.Errorf("transform: both 'field_name' and 'operation' are required")) {
+			return errs.err()
+		}
 	}
 
-	if _, ok := cfg["field_name"].(string); !ok {
+	if _, ok := cfg["field_name"].(string); hasFieldName && !ok {
 		logger.Error("transform validation failed: 'field_name' must be a string")
-		return fmt.Errorf("transform: 'field_name' must be a string")
+		if errs.add(fmt.Errorf("transform: 'field_name' must be a string")) {
+			return errs.err()
+		}
 	}
 
-	if _, ok := cfg["operation"].(string); !ok {
+	if _, ok := cfg["operation"].(string); hasOperation && !ok {
 		logger.Error("transform validation failed: 'operation' must be a string")
-		return fmt.Errorf("transform: 'operation' must be a string")
+		if errs.add(fmt.Errorf("transform: 'operation' must be a string")) {
+			return errs.err()
+		}
 	}
 
-	if availableOperations[cfg["operation"].(string)] == false {
+	if operation, ok := cfg["operation"].(string); ok && !availableOperations[operation] {
 		logger.Error("transform validation failed: invalid 'operation' value", "value", cfg["operation"])
-		return fmt.Errorf("transform: invalid 'operation' value: %v", cfg["operation"])
+		if errs.add(fmt.Errorf("transform: invalid 'operation' value: %v", cfg["operation"])) {
+			return errs.err()
+		}
 	}
 
 	if (cfg["operation"] == "add_prefix" && cfg["prefix"] == nil) || (cfg["operation"] == "add_suffix" && cfg["suffix"] == nil) {
 		logger.Error("transform validation failed: 'prefix' or 'suffix' is required for 'add_prefix' or 'add_suffix'")
-		return fmt.Errorf("transform: 'prefix' or 'suffix' is required for 'add_prefix' or 'add_suffix'")
+		if errs.add(fmt.Errorf("transform: 'prefix' or 'suffix' is required for 'add_prefix' or 'add_suffix'")) {
+			return errs.err()
+		}
 	}
 
 	if cfg["operation"] == "add_prefix" {
 		if _, ok := cfg["prefix"].(string); !ok {
 			logger.Error("transform validation failed: 'prefix' must be a string")
-			return fmt.Errorf("transform: 'prefix' must be a string")
+			if errs.add(fmt.Errorf("transform: 'prefix' must be a string")) {
+				return errs.err()
+			}
 		}
 	}
 
 	if cfg["operation"] == "add_suffix" {
 		if _, ok := cfg["suffix"].(string); !ok {
 			logger.Error("transform validation failed: 'suffix' must be a string")
-			return fmt.Errorf("transform: 'suffix' must be a string")
+			if errs.add(fmt.Errorf("transform: 'suffix' must be a string")) {
+				return errs.err()
+			}
 		}
 	}
 
-	return nil
+	if val, ok := cfg["keep_original_on_error"]; ok {
+		if _, ok := val.(bool); !ok {
+			logger.Error("transform validation failed: 'keep_original_on_error' must be a boolean", "value", val)
+			if errs.add(fmt.Errorf("transform: 'keep_original_on_error' must be a boolean, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
 }
 
 // ====== ENRICH VALIDATOR ====== //
@@ -501,20 +1608,25 @@ type EnrichValidator struct{}
 // EnrichValidator has two specifics fields :
 // fieldName : string (A new for the new field to add)
 // fieldValue : interface{} (The value to set to the new field)
-func (v *EnrichValidator) Validate(cfg map[string]interface{}, logger *slog.Logger) error {
+func (v *EnrichValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
 	hasFieldName := cfg["field_name"] != nil
 	hasFieldValue := cfg["field_value"] != nil
 	if !hasFieldName || !hasFieldValue {
 		logger.Error("enrich validation failed: both 'field_name' and 'field_value' are required")
-		return fmt.Errorf("enrich: both 'field_name' and 'field_value' are required")
+		if errs.add(fmt.Errorf("enrich: both 'field_name' and 'field_value' are required")) {
+			return errs.err()
+		}
 	}
 
-	if _, ok := cfg["field_name"].(string); !ok {
+	if _, ok := cfg["field_name"].(string); hasFieldName && !ok {
 		logger.Error("enrich validation failed: 'field_name' must be a string")
-		return fmt.Errorf("enrich: 'field_name' must be a string")
+		if errs.add(fmt.Errorf("enrich: 'field_name' must be a string")) {
+			return errs.err()
+		}
 	}
 
-	return nil
+	return errs.err()
 }
 
 // ====== PASSTHROUGH VALIDATOR ====== //
@@ -523,55 +1635,1810 @@ type PassthroughValidator struct{}
 
 // PassthroughValidator has no specific fields.
 // Simply passes messages without any modifications.
-func (v *PassthroughValidator) Validate(cfg map[string]interface{}, logger *slog.Logger) error {
+func (v *PassthroughValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
 	return nil
 }
 
-// Validate method for ProcessorConfig
-func (pc *ProcessorConfig) Validate(logger *slog.Logger) error {
-	if pc.Type == "" {
-		logger.Warn("ProcessorConfig validation skipped: Type is empty")
+// ====== HEADER FIELD VALIDATOR ====== //
+
+type HeaderFieldValidator struct{}
+
+var availableDirections = map[string]bool{
+	"to_header": true,
+	"to_field":  true,
+	"delete":    true,
+}
+
+// HeaderFieldValidator has three specifics fields :
+// header : string (the message header to read, write or delete)
+// direction : string (e.g., "to_header", "to_field", "delete")
+// field_name : string (the value field to read or write, required unless direction is "delete")
+func (v *HeaderFieldValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if header, ok := cfg["header"].(string); !ok || header == "" {
+		logger.Error("header_field validation failed: 'header' is required and must be a string")
+		if errs.add(fmt.Errorf("header_field: 'header' is required and must be a string")) {
+			return errs.err()
+		}
 	}
 
-	validator, exists := processorValidators[pc.Type]
-	if !exists {
-		logger.Error("Unknown processor type, skipping validation", "type", pc.Type)
-		return errors.New("unknown processor type: " + pc.Type)
+	direction, ok := cfg["direction"].(string)
+	if !ok || !availableDirections[direction] {
+		logger.Error("header_field validation failed: invalid 'direction' value", "value", cfg["direction"])
+		if errs.add(fmt.Errorf("header_field: invalid 'direction' value: %v", cfg["direction"])) {
+			return errs.err()
+		}
+	}
+
+	if direction == "delete" {
+		return errs.err()
+	}
+
+	if fieldName, ok := cfg["field_name"].(string); !ok || fieldName == "" {
+		logger.Error("header_field validation failed: 'field_name' is required for direction", "direction", direction)
+		if errs.add(fmt.Errorf("header_field: 'field_name' is required and must be a string for direction %s", direction)) {
+			return errs.err()
+		}
 	}
 
-	return validator.Validate(pc.Config, logger)
+	return errs.err()
 }
 
-func LoadConfig(filePath string, logger *slog.Logger) (*Config, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
+// ====== SAMPLE VALIDATOR ====== //
+
+type SampleValidator struct{}
+
+// SampleValidator has three specifics fields, rate and every being mutually exclusive :
+// rate : number (fraction of the messages passed, in [0,1])
+// seed : integer (optional with rate, makes the sampling reproducible)
+// every : integer (passes exactly one of every N messages per partition)
+func (v *SampleValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	hasRate := cfg["rate"] != nil
+	hasEvery := cfg["every"] != nil
+
+	if hasRate == hasEvery {
+		logger.Error("sample validation failed: must provide either 'rate' or 'every' but not both")
+		if errs.add(fmt.Errorf("sample: must provide either 'rate' or 'every' but not both")) {
+			return errs.err()
+		}
 	}
 
-	cfg := &Config{}
+	if hasEvery {
+		var every int64
+		switch e := cfg["every"].(type) {
+		case int:
+			every = int64(e)
+		case int64:
+			every = e
+		case uint64:
+			every = int64(e)
+		default:
+			logger.Error("sample validation failed: 'every' must be an integer")
+			errs.add(fmt.Errorf("sample: 'every' must be an integer"))
+			return errs.err()
+		}
+		if every < 1 {
+			logger.Error("sample validation failed: 'every' must be positive", "value", every)
+			if errs.add(fmt.Errorf("sample: 'every' must be positive, got: %d", every)) {
+				return errs.err()
+			}
+		}
+		if cfg["seed"] != nil {
+			logger.Warn("sample: 'seed' ignored with 'every'")
+		}
+		return errs.err()
+	}
 
-	err = yaml.Unmarshal(content, cfg)
+	var rate float64
+	isNumber := true
+	switch r := cfg["rate"].(type) {
+	case float64:
+		rate = r
+	case int:
+		rate = float64(r)
+	case int64:
+		rate = float64(r)
+	case uint64:
+		rate = float64(r)
+	default:
+		isNumber = false
+		logger.Error("sample validation failed: 'rate' must be a number")
+		if errs.add(fmt.Errorf("sample: 'rate' must be a number")) {
+			return errs.err()
+		}
+	}
 
-	if err != nil {
-		logger.Error("Failed to parse YAML", "error", err)
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if isNumber && (rate < 0 || rate > 1) {
+		logger.Error("sample validation failed: 'rate' must be in [0,1]", "value", rate)
+		if errs.add(fmt.Errorf("sample: 'rate' must be in [0,1], got: %v", rate)) {
+			return errs.err()
+		}
+	}
+
+	if seed, ok := cfg["seed"]; ok {
+		switch seed.(type) {
+		case int, int64, uint64:
+		default:
+			logger.Error("sample validation failed: 'seed' must be an integer")
+			if errs.add(fmt.Errorf("sample: 'seed' must be an integer")) {
+				return errs.err()
+			}
+		}
 	}
 
-	if err := cfg.Input.Validate(logger); err != nil {
-		return nil, fmt.Errorf("input validation failed: %w", err)
+	return errs.err()
+}
+
+// ====== PACE VALIDATOR ====== //
+
+type PaceValidator struct{}
+
+// PaceValidator has one optional field :
+// speed : number (replay speed factor, 2.0 being twice as fast as the original timing, default 1.0)
+func (v *PaceValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	val, ok := cfg["speed"]
+	if !ok {
+		return errs.err()
 	}
 
-	if err := cfg.Output.Validate(logger); err != nil {
-		return nil, fmt.Errorf("output validation failed: %w", err)
+	var speed float64
+	switch s := val.(type) {
+	case float64:
+		speed = s
+	case int:
+		speed = float64(s)
+	case int64:
+		speed = float64(s)
+	case uint64:
+		speed = float64(s)
+	default:
+		logger.Error("pace validation failed: 'speed' must be a number")
+		errs.add(fmt.Errorf("pace: 'speed' must be a number"))
+		return errs.err()
 	}
 
-	for i, processorcfg := range cfg.Processors {
-		logger.Info("Validating processor", "type", processorcfg.Type)
-		err := processorcfg.Validate(logger)
-		if err != nil {
-			return nil, fmt.Errorf("processor %d validation failed: %w", i, err)
+	if speed <= 0 {
+		logger.Error("pace validation failed: 'speed' must be positive", "value", speed)
+		if errs.add(fmt.Errorf("pace: 'speed' must be positive, got: %v", speed)) {
+			return errs.err()
 		}
 	}
 
-	return cfg, nil
+	return errs.err()
+}
+
+// ====== EXTRACT VALIDATOR ====== //
+
+type ExtractValidator struct{}
+
+// ExtractValidator has two specifics fields :
+// path : string (JSONPath expression, e.g. "$.items[*].sku")
+// target_field : string (the field receiving the result)
+func (v *ExtractValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	path, ok := cfg["path"].(string)
+	if !ok || path == "" {
+		logger.Error("extract validation failed: 'path' is required and must be a string")
+		if errs.add(fmt.Errorf("extract: 'path' is required and must be a string")) {
+			return errs.err()
+		}
+	} else if _, err := jp.ParseString(path); err != nil {
+		logger.Error("extract validation failed: invalid JSONPath", "path", path, "error", err)
+		if errs.add(fmt.Errorf("extract: invalid JSONPath %q: %w", path, err)) {
+			return errs.err()
+		}
+	}
+
+	if target, ok := cfg["target_field"].(string); !ok || target == "" {
+		logger.Error("extract validation failed: 'target_field' is required and must be a string")
+		if errs.add(fmt.Errorf("extract: 'target_field' is required and must be a string")) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== MERGE VALIDATOR ====== //
+
+type MergeValidator struct{}
+
+// MergeValidator has five specifics fields :
+// source_fields : list of strings (at least two fields to combine)
+// target_field : string (the field receiving the result)
+// separator : string (optional, joins the source fields)
+// template : string (optional, e.g. "{first} {last}", takes precedence over separator)
+// missing : string (optional, "skip" or "empty", default "skip")
+func (v *MergeValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	sources, ok := cfg["source_fields"].([]interface{})
+	if !ok || len(sources) < 2 {
+		logger.Error("merge validation failed: at least two 'source_fields' are required")
+		if errs.add(fmt.Errorf("merge: at least two 'source_fields' are required")) {
+			return errs.err()
+		}
+	}
+	for i, source := range sources {
+		if field, ok := source.(string); !ok || field == "" {
+			logger.Error("merge validation failed: source field must be a non empty string", "index", i)
+			if errs.add(fmt.Errorf("merge: source_fields[%d] must be a non empty string", i)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if target, ok := cfg["target_field"].(string); !ok || target == "" {
+		logger.Error("merge validation failed: 'target_field' is required and must be a string")
+		if errs.add(fmt.Errorf("merge: 'target_field' is required and must be a string")) {
+			return errs.err()
+		}
+	}
+
+	for _, key := range []string{"separator", "template"} {
+		if val, ok := cfg[key]; ok {
+			if _, ok := val.(string); !ok {
+				logger.Error("merge validation failed: must be a string", "field", key)
+				if errs.add(fmt.Errorf("merge: '%s' must be a string", key)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+
+	if missing, ok := cfg["missing"]; ok && missing != "skip" && missing != "empty" {
+		logger.Error("merge validation failed: invalid 'missing' value", "value", missing)
+		if errs.add(fmt.Errorf("merge: invalid 'missing' value: %v", missing)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== COPY VALIDATOR ====== //
+
+type CopyValidator struct{}
+
+// CopyValidator has three specifics fields :
+// source_field : string (dot-path of the field to copy)
+// target_field : string (dot-path of the copy)
+// overwrite : bool (optional, replaces an existing target field, default false)
+func (v *CopyValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	for _, key := range []string{"source_field", "target_field"} {
+		path, ok := cfg[key].(string)
+		if !ok || path == "" {
+			logger.Error("copy validation failed: field is required and must be a string", "field", key)
+			if errs.add(fmt.Errorf("copy: '%s' is required and must be a string", key)) {
+				return errs.err()
+			}
+		} else {
+			for _, part := range strings.Split(path, ".") {
+				if part == "" {
+					logger.Error("copy validation failed: invalid dot-path", "field", key, "value", path)
+					if errs.add(fmt.Errorf("copy: invalid '%s' dot-path: %q", key, path)) {
+						return errs.err()
+					}
+					break
+				}
+			}
+		}
+	}
+
+	if cfg["source_field"] != nil && cfg["source_field"] == cfg["target_field"] {
+		logger.Error("copy validation failed: 'source_field' and 'target_field' are the same")
+		if errs.add(fmt.Errorf("copy: 'source_field' and 'target_field' must differ")) {
+			return errs.err()
+		}
+	}
+
+	if overwrite, ok := cfg["overwrite"]; ok {
+		if _, ok := overwrite.(bool); !ok {
+			logger.Error("copy validation failed: 'overwrite' must be a boolean")
+			if errs.add(fmt.Errorf("copy: 'overwrite' must be a boolean")) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== ROUTE VALIDATOR ====== //
+
+type RouteValidator struct{}
+
+var availableConditionOperators = map[string]bool{
+	"eq":       true,
+	"ne":       true,
+	"gt":       true,
+	"gte":      true,
+	"lt":       true,
+	"lte":      true,
+	"contains": true,
+	"exists":   true,
+}
+
+// RouteValidator has two specifics fields :
+// target_field : string (optional, the field receiving the label, default "_route")
+// rules : list of {label, when: {field, operator, value}} evaluated in order,
+// the last one may be a {label, default: true} catch-all
+func (v *RouteValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if target, ok := cfg["target_field"]; ok {
+		if str, ok := target.(string); !ok || str == "" {
+			logger.Error("route validation failed: 'target_field' must be a non empty string")
+			if errs.add(fmt.Errorf("route: 'target_field' must be a non empty string")) {
+				return errs.err()
+			}
+		}
+	}
+
+	rules, ok := cfg["rules"].([]interface{})
+	if !ok || len(rules) == 0 {
+		logger.Error("route validation failed: at least one rule is required")
+		if errs.add(fmt.Errorf("route: at least one rule is required")) {
+			return errs.err()
+		}
+	}
+
+	defaults := 0
+	for i, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			if errs.add(fmt.Errorf("route: rules[%d] must be an object", i)) {
+				return errs.err()
+			}
+			continue
+		}
+		if label, ok := rule["label"].(string); !ok || label == "" {
+			logger.Error("route validation failed: rule label is required", "index", i)
+			if errs.add(fmt.Errorf("route: rules[%d] requires a 'label'", i)) {
+				return errs.err()
+			}
+		}
+
+		if isDefault, _ := rule["default"].(bool); isDefault {
+			defaults++
+			if defaults > 1 {
+				logger.Error("route validation failed: only one default rule is allowed")
+				if errs.add(fmt.Errorf("route: only one default rule is allowed")) {
+					return errs.err()
+				}
+			}
+			if i != len(rules)-1 {
+				logger.Error("route validation failed: the default rule must be the last one", "index", i)
+				if errs.add(fmt.Errorf("route: the default rule must be the last one")) {
+					return errs.err()
+				}
+			}
+			continue
+		}
+
+		when, ok := rule["when"].(map[string]interface{})
+		if !ok {
+			logger.Error("route validation failed: rule condition is required", "index", i)
+			if errs.add(fmt.Errorf("route: rules[%d] requires a 'when' condition", i)) {
+				return errs.err()
+			}
+		} else if err := validateCondition(when); err != nil {
+			logger.Error("route validation failed: invalid condition", "index", i, "error", err)
+			if errs.add(fmt.Errorf("route: rules[%d] %w", i, err)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// validateCondition checks a {field, operator, value} condition, the operator defaulting to eq
+func validateCondition(when map[string]interface{}) error {
+	if field, ok := when["field"].(string); !ok || field == "" {
+		return fmt.Errorf("condition requires a 'field'")
+	}
+	operator := "eq"
+	if op, ok := when["operator"]; ok {
+		operator, _ = op.(string)
+		if !availableConditionOperators[operator] {
+			return fmt.Errorf("invalid operator: %v", op)
+		}
+	}
+	if _, ok := when["value"]; !ok && operator != "exists" {
+		return fmt.Errorf("condition requires a 'value' for operator %s", operator)
+	}
+	return nil
+}
+
+// ====== FIELD EXISTS VALIDATOR ====== //
+
+type FieldExistsValidator struct{}
+
+// FieldExistsValidator has three specifics fields :
+// field_name : string (dot-path of the field, present when it exists and is not null)
+// require_present : bool (optional, whether matching messages have the field or lack it, default true)
+// action : string (optional, "keep" forwards only the matching messages, "drop" discards them, default "keep")
+func (v *FieldExistsValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	path, ok := cfg["field_name"].(string)
+	if !ok || path == "" {
+		logger.Error("field_exists validation failed: 'field_name' is required and must be a string")
+		if errs.add(fmt.Errorf("field_exists: 'field_name' is required and must be a string")) {
+			return errs.err()
+		}
+	} else {
+		for _, part := range strings.Split(path, ".") {
+			if part == "" {
+				logger.Error("field_exists validation failed: invalid dot-path", "value", path)
+				if errs.add(fmt.Errorf("field_exists: invalid 'field_name' dot-path: %q", path)) {
+					return errs.err()
+				}
+				break
+			}
+		}
+	}
+
+	if requirePresent, ok := cfg["require_present"]; ok {
+		if _, ok := requirePresent.(bool); !ok {
+			logger.Error("field_exists validation failed: 'require_present' must be a boolean")
+			if errs.add(fmt.Errorf("field_exists: 'require_present' must be a boolean")) {
+				return errs.err()
+			}
+		}
+	}
+
+	if action, ok := cfg["action"]; ok && action != "keep" && action != "drop" {
+		logger.Error("field_exists validation failed: invalid action", "action", action)
+		if errs.add(fmt.Errorf("field_exists: 'action' must be 'keep' or 'drop', got: %v", action)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== MAX AGE VALIDATOR ====== //
+
+type MaxAgeValidator struct{}
+
+// MaxAgeValidator has three specifics fields :
+// max_age : string (positive duration, older messages are dropped, e.g. "24h")
+// timestamp_field : string (optional dot-path of the payload field holding the message time, default the Kafka timestamp)
+// timestamp_unit : string (optional unit of a numeric timestamp_field, "s" or "ms", default "ms")
+func (v *MaxAgeValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	maxAge, ok := cfg["max_age"].(string)
+	if !ok {
+		logger.Error("max_age validation failed: 'max_age' is required and must be a duration string")
+		if errs.add(fmt.Errorf("max_age: 'max_age' is required and must be a duration string")) {
+			return errs.err()
+		}
+	} else if duration, err := time.ParseDuration(maxAge); err != nil || duration <= 0 {
+		logger.Error("max_age validation failed: invalid 'max_age'", "value", maxAge)
+		if errs.add(fmt.Errorf("max_age: 'max_age' must be a positive duration, got: %s", maxAge)) {
+			return errs.err()
+		}
+	}
+
+	if field, ok := cfg["timestamp_field"]; ok {
+		path, ok := field.(string)
+		if !ok || path == "" {
+			logger.Error("max_age validation failed: 'timestamp_field' must be a string")
+			if errs.add(fmt.Errorf("max_age: 'timestamp_field' must be a non empty string")) {
+				return errs.err()
+			}
+		} else {
+			for _, part := range strings.Split(path, ".") {
+				if part == "" {
+					logger.Error("max_age validation failed: invalid dot-path", "value", path)
+					if errs.add(fmt.Errorf("max_age: invalid 'timestamp_field' dot-path: %q", path)) {
+						return errs.err()
+					}
+					break
+				}
+			}
+		}
+	}
+
+	if unit, ok := cfg["timestamp_unit"]; ok && unit != "s" && unit != "ms" {
+		logger.Error("max_age validation failed: invalid timestamp_unit", "value", unit)
+		if errs.add(fmt.Errorf("max_age: 'timestamp_unit' must be 's' or 'ms', got: %v", unit)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== BUCKET VALIDATOR ====== //
+
+type BucketValidator struct{}
+
+// BucketValidator has three specifics fields :
+// field_name : string (dot-path of the numeric field)
+// target_field : string (dot-path of the field receiving the bucket label)
+// buckets : list of {max, label} with strictly ascending max, ended by a catch-all {label} without max
+func (v *BucketValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	for _, key := range []string{"field_name", "target_field"} {
+		path, ok := cfg[key].(string)
+		if !ok || path == "" {
+			logger.Error("bucket validation failed: missing field", "field", key)
+			if errs.add(fmt.Errorf("bucket: '%s' is required and must be a string", key)) {
+				return errs.err()
+			}
+		} else {
+			for _, part := range strings.Split(path, ".") {
+				if part == "" {
+					logger.Error("bucket validation failed: invalid dot-path", "field", key, "value", path)
+					if errs.add(fmt.Errorf("bucket: invalid '%s' dot-path: %q", key, path)) {
+						return errs.err()
+					}
+					break
+				}
+			}
+		}
+	}
+
+	buckets, _ := cfg["buckets"].([]interface{})
+	if len(buckets) < 2 {
+		logger.Error("bucket validation failed: at least one bucket and the catch-all are required")
+		if errs.add(fmt.Errorf("bucket: at least one bucket and the catch-all are required")) {
+			return errs.err()
+		}
+	}
+
+	var previous *float64
+	for i, b := range buckets {
+		bucket, ok := b.(map[string]interface{})
+		if !ok {
+			logger.Error("bucket validation failed: bucket must be an object", "index", i)
+			if errs.add(fmt.Errorf("bucket: buckets[%d] must be an object", i)) {
+				return errs.err()
+			}
+			continue
+		}
+		if label, ok := bucket["label"].(string); !ok || label == "" {
+			logger.Error("bucket validation failed: bucket requires a label", "index", i)
+			if errs.add(fmt.Errorf("bucket: buckets[%d] requires a 'label'", i)) {
+				return errs.err()
+			}
+		}
+
+		maxVal, hasMax := bucket["max"]
+		if i == len(buckets)-1 {
+			if hasMax {
+				logger.Error("bucket validation failed: the last bucket must be the catch-all, without max")
+				if errs.add(fmt.Errorf("bucket: the last bucket must be the catch-all, without 'max'")) {
+					return errs.err()
+				}
+			}
+			break
+		}
+		if !hasMax {
+			logger.Error("bucket validation failed: only the last bucket can omit max", "index", i)
+			if errs.add(fmt.Errorf("bucket: buckets[%d] requires a 'max', only the last bucket is the catch-all", i)) {
+				return errs.err()
+			}
+			continue
+		}
+
+		var threshold float64
+		switch m := maxVal.(type) {
+		case float64:
+			threshold = m
+		case int:
+			threshold = float64(m)
+		case int64:
+			threshold = float64(m)
+		case uint64:
+			threshold = float64(m)
+		default:
+			logger.Error("bucket validation failed: max must be a number", "index", i)
+			if errs.add(fmt.Errorf("bucket: buckets[%d] 'max' must be a number", i)) {
+				return errs.err()
+			}
+			continue
+		}
+		if previous != nil && threshold <= *previous {
+			logger.Error("bucket validation failed: thresholds must be ascending", "index", i, "max", threshold, "previous", *previous)
+			if errs.add(fmt.Errorf("bucket: buckets[%d] 'max' (%v) must be greater than the previous one (%v)", i, threshold, *previous)) {
+				return errs.err()
+			}
+		}
+		previous = &threshold
+	}
+
+	return errs.err()
+}
+
+// ====== EXPLODE VALIDATOR ====== //
+
+type ExplodeValidator struct{}
+
+// ExplodeValidator has one specific field :
+// field_name : string (dot-path of the map field, one message being emitted per entry)
+func (v *ExplodeValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	path, ok := cfg["field_name"].(string)
+	if !ok || path == "" {
+		logger.Error("explode validation failed: missing field", "field", "field_name")
+		if errs.add(fmt.Errorf("explode: 'field_name' is required and must be a string")) {
+			return errs.err()
+		}
+	} else {
+		for _, part := range strings.Split(path, ".") {
+			if part == "" {
+				logger.Error("explode validation failed: invalid dot-path", "field", "field_name", "value", path)
+				if errs.add(fmt.Errorf("explode: invalid 'field_name' dot-path: %q", path)) {
+					return errs.err()
+				}
+				break
+			}
+		}
+	}
+	return errs.err()
+}
+
+// ====== AGGREGATE VALIDATOR ====== //
+
+type AggregateValidator struct{}
+
+var availableAggregateFunctions = map[string]bool{
+	"count": true,
+	"sum":   true,
+	"min":   true,
+	"max":   true,
+	"avg":   true,
+}
+
+// AggregateValidator has six specifics fields :
+// group_by : string (dot-path of the field grouping the messages)
+// functions : list of string (count, sum, min, max, avg)
+// agg_field : string (dot-path of the numeric field, required unless only counting)
+// window : string (positive duration of the tumbling windows, e.g. "1m")
+// allowed_lateness : string (optional duration a window stays open after its end, default 0)
+// max_groups : int (optional maximum of open windows and groups held in memory, default 10000)
+func (v *AggregateValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	for _, key := range []string{"group_by", "agg_field"} {
+		val, ok := cfg[key]
+		if !ok && key == "agg_field" {
+			continue
+		}
+		path, ok := val.(string)
+		if !ok || path == "" {
+			logger.Error("aggregate validation failed: missing field", "field", key)
+			if errs.add(fmt.Errorf("aggregate: '%s' must be a non empty string", key)) {
+				return errs.err()
+			}
+		} else {
+			for _, part := range strings.Split(path, ".") {
+				if part == "" {
+					logger.Error("aggregate validation failed: invalid dot-path", "field", key, "value", path)
+					if errs.add(fmt.Errorf("aggregate: invalid '%s' dot-path: %q", key, path)) {
+						return errs.err()
+					}
+					break
+				}
+			}
+		}
+	}
+
+	functions, _ := cfg["functions"].([]interface{})
+	if len(functions) == 0 {
+		logger.Error("aggregate validation failed: 'functions' is required")
+		if errs.add(fmt.Errorf("aggregate: 'functions' is required, one or more of: count, sum, min, max, avg")) {
+			return errs.err()
+		}
+	}
+	for _, f := range functions {
+		name, _ := f.(string)
+		if !availableAggregateFunctions[name] {
+			logger.Error("aggregate validation failed: invalid function", "function", f)
+			if errs.add(fmt.Errorf("aggregate: function must be one of: count, sum, min, max, avg; got: %v", f)) {
+				return errs.err()
+			}
+		}
+		if name != "count" && cfg["agg_field"] == nil {
+			logger.Error("aggregate validation failed: 'agg_field' is required", "function", name)
+			if errs.add(fmt.Errorf("aggregate: 'agg_field' is required by the %s function", name)) {
+				return errs.err()
+			}
+		}
+	}
+
+	window, _ := cfg["window"].(string)
+	if duration, err := time.ParseDuration(window); err != nil || duration <= 0 {
+		logger.Error("aggregate validation failed: invalid 'window'", "value", cfg["window"])
+		if errs.add(fmt.Errorf("aggregate: 'window' must be a positive duration, got: %v", cfg["window"])) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["allowed_lateness"]; ok {
+		lateness, _ := val.(string)
+		if duration, err := time.ParseDuration(lateness); err != nil || duration < 0 {
+			logger.Error("aggregate validation failed: invalid 'allowed_lateness'", "value", val)
+			if errs.add(fmt.Errorf("aggregate: 'allowed_lateness' must be a duration, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if val, ok := cfg["max_groups"]; ok {
+		var size int64
+		switch m := val.(type) {
+		case int:
+			size = int64(m)
+		case int64:
+			size = m
+		case uint64:
+			size = int64(m)
+		}
+		if size < 1 {
+			logger.Error("aggregate validation failed: invalid 'max_groups'", "value", val)
+			if errs.add(fmt.Errorf("aggregate: 'max_groups' must be a positive integer, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== PROJECT VALIDATOR ====== //
+
+type ProjectValidator struct{}
+
+// ProjectValidator has two specifics fields, exactly one of them being set :
+// include : list of string (dot-paths of the fields kept, the others being removed)
+// exclude : list of string (dot-paths of the fields removed)
+func (v *ProjectValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	include, hasInclude := cfg["include"]
+	exclude, hasExclude := cfg["exclude"]
+	if hasInclude == hasExclude {
+		logger.Error("project validation failed: exactly one of 'include' or 'exclude' must be set")
+		if errs.add(fmt.Errorf("project: exactly one of 'include' or 'exclude' must be set")) {
+			return errs.err()
+		}
+	}
+
+	key, list := "include", include
+	if hasExclude {
+		key, list = "exclude", exclude
+	}
+	items, ok := list.([]interface{})
+	if (hasInclude || hasExclude) && (!ok || len(items) == 0) {
+		logger.Error("project validation failed: must be a non empty list", "field", key)
+		if errs.add(fmt.Errorf("project: '%s' must be a non empty list of fields", key)) {
+			return errs.err()
+		}
+	}
+	for _, item := range items {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			logger.Error("project validation failed: invalid field", "field", key, "value", item)
+			if errs.add(fmt.Errorf("project: '%s' fields must be non empty strings, got: %v", key, item)) {
+				return errs.err()
+			}
+		} else {
+			for _, part := range strings.Split(path, ".") {
+				if part == "" {
+					logger.Error("project validation failed: invalid dot-path", "field", key, "value", path)
+					if errs.add(fmt.Errorf("project: invalid '%s' dot-path: %q", key, path)) {
+						return errs.err()
+					}
+					break
+				}
+			}
+		}
+	}
+	return errs.err()
+}
+
+// ====== EMPTY TO NULL VALIDATOR ====== //
+
+type EmptyToNullValidator struct{}
+
+// EmptyToNullValidator has two specifics fields :
+// fields : list of string (optional dot-paths of the fields converted, default every string field)
+// mode : string (optional, "nullify" sets the field to null, "remove" deletes it, default "nullify")
+func (v *EmptyToNullValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if val, ok := cfg["fields"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			logger.Error("empty_to_null validation failed: 'fields' must be a non empty list")
+			if errs.add(fmt.Errorf("empty_to_null: 'fields' must be a non empty list of fields")) {
+				return errs.err()
+			}
+		}
+		for _, item := range items {
+			path, ok := item.(string)
+			if !ok || path == "" {
+				logger.Error("empty_to_null validation failed: invalid field", "value", item)
+				if errs.add(fmt.Errorf("empty_to_null: 'fields' must be non empty strings, got: %v", item)) {
+					return errs.err()
+				}
+			} else {
+				for _, part := range strings.Split(path, ".") {
+					if part == "" {
+						logger.Error("empty_to_null validation failed: invalid dot-path", "value", path)
+						if errs.add(fmt.Errorf("empty_to_null: invalid field dot-path: %q", path)) {
+							return errs.err()
+						}
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if mode, ok := cfg["mode"]; ok && mode != "nullify" && mode != "remove" {
+		logger.Error("empty_to_null validation failed: invalid mode", "value", mode)
+		if errs.add(fmt.Errorf("empty_to_null: 'mode' must be 'nullify' or 'remove', got: %v", mode)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== SCHEMA VALIDATOR ====== //
+
+type SchemaValidator struct{}
+
+var validSchemaTypes = map[string]bool{
+	"string": true,
+	"int":    true,
+	"float":  true,
+	"bool":   true,
+	"object": true,
+	"array":  true,
+}
+
+// SchemaValidator has two specifics fields :
+// schema : map of string (dot-path of a field to its type: string, int, float, bool, object or array)
+// mode : string (optional, "reject" fails the mismatching messages, "coerce" converts them when possible, default "reject")
+func (v *SchemaValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	schema, ok := cfg["schema"].(map[string]interface{})
+	if !ok || len(schema) == 0 {
+		logger.Error("schema validation failed: 'schema' must be a non empty map of field types")
+		if errs.add(fmt.Errorf("schema: 'schema' must be a non empty map of field types")) {
+			return errs.err()
+		}
+	}
+	for field, val := range schema {
+		for _, part := range strings.Split(field, ".") {
+			if part == "" {
+				logger.Error("schema validation failed: invalid dot-path", "value", field)
+				if errs.add(fmt.Errorf("schema: invalid field dot-path: %q", field)) {
+					return errs.err()
+				}
+				break
+			}
+		}
+		if fieldType, _ := val.(string); !validSchemaTypes[fieldType] {
+			logger.Error("schema validation failed: invalid type", "field", field, "value", val)
+			if errs.add(fmt.Errorf("schema: type of field %q must be one of: string, int, float, bool, object, array; got: %v", field, val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if mode, ok := cfg["mode"]; ok && mode != "reject" && mode != "coerce" {
+		logger.Error("schema validation failed: invalid mode", "value", mode)
+		if errs.add(fmt.Errorf("schema: 'mode' must be 'reject' or 'coerce', got: %v", mode)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== RENAME KEYS VALIDATOR ====== //
+
+type RenameKeysValidator struct{}
+
+// RenameKeysValidator has three specifics fields :
+// pattern : string (regular expression matched against each top-level field key)
+// replacement : string (replacement of the matches, $1 expanding to the first group, can be empty)
+// replacement_case : string (optional, "upper" or "lower" case applied to the replaced text)
+func (v *RenameKeysValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	pattern, ok := cfg["pattern"].(string)
+	if !ok || pattern == "" {
+		logger.Error("rename_keys validation failed: missing or invalid 'pattern' field")
+		if errs.add(fmt.Errorf("rename_keys: missing or invalid 'pattern' field")) {
+			return errs.err()
+		}
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		logger.Error("rename_keys validation failed: invalid pattern", "value", pattern, "error", err)
+		if errs.add(fmt.Errorf("rename_keys: invalid 'pattern': %w", err)) {
+			return errs.err()
+		}
+	}
+
+	if _, ok := cfg["replacement"].(string); !ok {
+		logger.Error("rename_keys validation failed: missing or invalid 'replacement' field")
+		if errs.add(fmt.Errorf("rename_keys: missing or invalid 'replacement' field")) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["replacement_case"]; ok && val != "upper" && val != "lower" {
+		logger.Error("rename_keys validation failed: invalid replacement_case", "value", val)
+		if errs.add(fmt.Errorf("rename_keys: 'replacement_case' must be 'upper' or 'lower', got: %v", val)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== GENERATE ID VALIDATOR ====== //
+
+type GenerateIDValidator struct{}
+
+// GenerateIDValidator has three specifics fields :
+// target_field : string (dot-path of the field receiving the ID)
+// generator : string (optional, "uuid" for a random UUID v4 or "sequence" for an increasing number, default "uuid")
+// overwrite : bool (optional, replaces an existing ID, default false)
+func (v *GenerateIDValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	path, ok := cfg["target_field"].(string)
+	if !ok || path == "" {
+		logger.Error("generate_id validation failed: 'target_field' is required and must be a string")
+		if errs.add(fmt.Errorf("generate_id: 'target_field' is required and must be a string")) {
+			return errs.err()
+		}
+	} else {
+		for _, part := range strings.Split(path, ".") {
+			if part == "" {
+				logger.Error("generate_id validation failed: invalid dot-path", "value", path)
+				if errs.add(fmt.Errorf("generate_id: invalid 'target_field' dot-path: %q", path)) {
+					return errs.err()
+				}
+				break
+			}
+		}
+	}
+
+	if generator, ok := cfg["generator"]; ok && generator != "uuid" && generator != "sequence" {
+		logger.Error("generate_id validation failed: invalid generator", "value", generator)
+		if errs.add(fmt.Errorf("generate_id: 'generator' must be 'uuid' or 'sequence', got: %v", generator)) {
+			return errs.err()
+		}
+	}
+
+	if overwrite, ok := cfg["overwrite"]; ok {
+		if _, ok := overwrite.(bool); !ok {
+			logger.Error("generate_id validation failed: 'overwrite' must be a boolean")
+			if errs.add(fmt.Errorf("generate_id: 'overwrite' must be a boolean")) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== KEY CASE VALIDATOR ====== //
+
+type KeyCaseValidator struct{}
+
+// KeyCaseValidator has two specifics fields :
+// mode : string ("upper" or "lower", case applied to the keys)
+// fields : list of string (optional top-level keys converted, default every key)
+func (v *KeyCaseValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if mode := cfg["mode"]; mode != "upper" && mode != "lower" {
+		logger.Error("key_case validation failed: invalid mode", "value", mode)
+		if errs.add(fmt.Errorf("key_case: 'mode' must be 'upper' or 'lower', got: %v", mode)) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["fields"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			logger.Error("key_case validation failed: 'fields' must be a non empty list")
+			if errs.add(fmt.Errorf("key_case: 'fields' must be a non empty list of keys")) {
+				return errs.err()
+			}
+		}
+		for _, item := range items {
+			if key, ok := item.(string); !ok || key == "" {
+				logger.Error("key_case validation failed: invalid field", "value", item)
+				if errs.add(fmt.Errorf("key_case: 'fields' must be non empty strings, got: %v", item)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== HEADERS OBJECT VALIDATOR ====== //
+
+type HeadersObjectValidator struct{}
+
+// HeadersObjectValidator has three specifics fields :
+// direction : string ("to_field" copies the headers into the object, "to_headers" copies the object fields into the headers)
+// target_field : string (dot-path of the object field)
+// headers : list of string (optional header names copied, default every header or object field)
+func (v *HeadersObjectValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if direction := cfg["direction"]; direction != "to_field" && direction != "to_headers" {
+		logger.Error("headers_object validation failed: invalid 'direction' value", "value", direction)
+		if errs.add(fmt.Errorf("headers_object: 'direction' must be 'to_field' or 'to_headers', got: %v", direction)) {
+			return errs.err()
+		}
+	}
+
+	path, ok := cfg["target_field"].(string)
+	if !ok || path == "" {
+		logger.Error("headers_object validation failed: 'target_field' is required and must be a string")
+		if errs.add(fmt.Errorf("headers_object: 'target_field' is required and must be a string")) {
+			return errs.err()
+		}
+	} else {
+		for _, part := range strings.Split(path, ".") {
+			if part == "" {
+				logger.Error("headers_object validation failed: invalid dot-path", "value", path)
+				if errs.add(fmt.Errorf("headers_object: invalid 'target_field' dot-path: %q", path)) {
+					return errs.err()
+				}
+				break
+			}
+		}
+	}
+
+	if val, ok := cfg["headers"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			logger.Error("headers_object validation failed: 'headers' must be a non empty list")
+			if errs.add(fmt.Errorf("headers_object: 'headers' must be a non empty list of header names")) {
+				return errs.err()
+			}
+		}
+		for _, item := range items {
+			if name, ok := item.(string); !ok || name == "" {
+				logger.Error("headers_object validation failed: invalid header", "value", item)
+				if errs.add(fmt.Errorf("headers_object: 'headers' must be non empty strings, got: %v", item)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== FIELD LIMITS VALIDATOR ====== //
+
+type FieldLimitsValidator struct{}
+
+// FieldLimitsValidator has two specifics fields, at least one of them being set :
+// max_depth : int (optional maximum nesting of the fields, the top-level fields being at depth 1)
+// max_fields : int (optional maximum number of fields, counted at every level)
+func (v *FieldLimitsValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	hasLimit := false
+	for _, key := range []string{"max_depth", "max_fields"} {
+		val, ok := cfg[key]
+		if !ok {
+			continue
+		}
+		hasLimit = true
+		var limit int64
+		switch l := val.(type) {
+		case int:
+			limit = int64(l)
+		case int64:
+			limit = l
+		case uint64:
+			limit = int64(l)
+		}
+		if limit < 1 {
+			logger.Error("field_limits validation failed: invalid limit", "field", key, "value", val)
+			if errs.add(fmt.Errorf("field_limits: '%s' must be a positive integer, got: %v", key, val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if !hasLimit {
+		logger.Error("field_limits validation failed: no limit set")
+		if errs.add(fmt.Errorf("field_limits: 'max_depth' or 'max_fields' is required")) {
+			return errs.err()
+		}
+	}
+	return errs.err()
+}
+
+// ====== COALESCE VALIDATOR ====== //
+
+type CoalesceValidator struct{}
+
+// CoalesceValidator has three specifics fields :
+// source_fields : list of strings (dot-paths of the candidate fields, by order of preference)
+// target_field : string (dot-path of the field receiving the first non-null value, can be one of the sources)
+// default : any (optional, written when every source is absent or null, the target being left as is otherwise)
+func (v *CoalesceValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	sources, ok := cfg["source_fields"].([]interface{})
+	if !ok || len(sources) == 0 {
+		logger.Error("coalesce validation failed: at least one of 'source_fields' is required")
+		if errs.add(fmt.Errorf("coalesce: at least one of 'source_fields' is required")) {
+			return errs.err()
+		}
+	}
+	for i, source := range sources {
+		if field, ok := source.(string); !ok || !validDotPath(field) {
+			logger.Error("coalesce validation failed: source field must be a dot-path", "index", i, "value", source)
+			if errs.add(fmt.Errorf("coalesce: source_fields[%d] must be a non empty dot-path, got: %v", i, source)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if target, ok := cfg["target_field"].(string); !ok || !validDotPath(target) {
+		logger.Error("coalesce validation failed: 'target_field' is required and must be a dot-path")
+		if errs.add(fmt.Errorf("coalesce: 'target_field' is required and must be a non empty dot-path")) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== DEDUP ADJACENT VALIDATOR ====== //
+
+type DedupAdjacentValidator struct{}
+
+// DedupAdjacentValidator has one specific field :
+// key_field : string (optional dot-path of the field compared, the whole value being compared otherwise)
+func (v *DedupAdjacentValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if val, ok := cfg["key_field"]; ok {
+		if field, ok := val.(string); !ok || !validDotPath(field) {
+			logger.Error("dedup_adjacent validation failed: 'key_field' must be a dot-path", "value", val)
+			if errs.add(fmt.Errorf("dedup_adjacent: 'key_field' must be a non empty dot-path, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+	return errs.err()
+}
+
+// ====== EPOCH CONVERT VALIDATOR ====== //
+
+type EpochConvertValidator struct{}
+
+// EpochConvertValidator has four specifics fields :
+// field_name : string (dot-path of the timestamp to convert)
+// direction : string ("to_rfc3339" converts an epoch number, "to_epoch" an RFC3339 string)
+// unit : string (optional unit of the epoch, "s", "ms", "us" or "ns", default "ms")
+// target_field : string (optional dot-path of the converted timestamp, default field_name)
+func (v *EpochConvertValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if field, ok := cfg["field_name"].(string); !ok || !validDotPath(field) {
+		logger.Error("epoch_convert validation failed: 'field_name' is required and must be a dot-path")
+		if errs.add(fmt.Errorf("epoch_convert: 'field_name' is required and must be a non empty dot-path")) {
+			return errs.err()
+		}
+	}
+
+	if direction := cfg["direction"]; direction != "to_rfc3339" && direction != "to_epoch" {
+		logger.Error("epoch_convert validation failed: invalid direction", "value", direction)
+		if errs.add(fmt.Errorf("epoch_convert: 'direction' must be 'to_rfc3339' or 'to_epoch', got: %v", direction)) {
+			return errs.err()
+		}
+	}
+
+	if unit, ok := cfg["unit"]; ok && unit != "s" && unit != "ms" && unit != "us" && unit != "ns" {
+		logger.Error("epoch_convert validation failed: invalid unit", "value", unit)
+		if errs.add(fmt.Errorf("epoch_convert: 'unit' must be 's', 'ms', 'us' or 'ns', got: %v", unit)) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["target_field"]; ok {
+		if field, ok := val.(string); !ok || !validDotPath(field) {
+			logger.Error("epoch_convert validation failed: 'target_field' must be a dot-path", "value", val)
+			if errs.add(fmt.Errorf("epoch_convert: 'target_field' must be a non empty dot-path, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== NORMALIZE VALIDATOR ====== //
+
+type NormalizeValidator struct{}
+
+// NormalizeValidator has four specifics fields :
+// field_name : string (dot-path of the value to normalize in place)
+// kind : string ("email" or "phone")
+// default_country_code : string (optional country calling code of the national phone numbers, e.g. "33", phone only)
+// flag_field : string (optional dot-path of a boolean set to whether the value is invalid, the invalid values being errors without it)
+func (v *NormalizeValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if field, ok := cfg["field_name"].(string); !ok || !validDotPath(field) {
+		logger.Error("normalize validation failed: 'field_name' is required and must be a dot-path")
+		if errs.add(fmt.Errorf("normalize: 'field_name' is required and must be a non empty dot-path")) {
+			return errs.err()
+		}
+	}
+
+	kind := cfg["kind"]
+	if kind != "email" && kind != "phone" {
+		logger.Error("normalize validation failed: invalid kind", "value", kind)
+		if errs.add(fmt.Errorf("normalize: 'kind' must be 'email' or 'phone', got: %v", kind)) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["default_country_code"]; ok {
+		if kind == "email" {
+			logger.Error("normalize validation failed: 'default_country_code' only applies to the phone kind")
+			if errs.add(fmt.Errorf("normalize: 'default_country_code' only applies to the phone kind")) {
+				return errs.err()
+			}
+		}
+		if code, ok := val.(string); !ok || !isCountryCallingCode(code) {
+			logger.Error("normalize validation failed: invalid default_country_code", "value", val)
+			if errs.add(fmt.Errorf("normalize: 'default_country_code' must be an assigned country calling code (e.g. \"33\"), got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if val, ok := cfg["flag_field"]; ok {
+		if field, ok := val.(string); !ok || !validDotPath(field) {
+			logger.Error("normalize validation failed: 'flag_field' must be a dot-path", "value", val)
+			if errs.add(fmt.Errorf("normalize: 'flag_field' must be a non empty dot-path, got: %v", val)) {
+				return errs.err()
+			}
+		}
+		if val == cfg["field_name"] {
+			logger.Error("normalize validation failed: 'flag_field' is the normalized field")
+			if errs.add(fmt.Errorf("normalize: 'flag_field' must differ from 'field_name'")) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// isCountryCallingCode reports whether code is an assigned country calling code, 1 to 3 digits without leading 0
+func isCountryCallingCode(code string) bool {
+	if len(code) < 1 || len(code) > 3 || code[0] == '0' || strings.Trim(code, "0123456789") != "" {
+		return false
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && phonenumbers.GetRegionCodeForCountryCode(n) != phonenumbers.UNKNOWN_REGION
+}
+
+// ====== FINGERPRINT VALIDATOR ====== //
+
+type FingerprintValidator struct{}
+
+// FingerprintValidator has three specifics fields :
+// fields : []string (optional dot-paths of the fields hashed, every field by default)
+// algorithm : string (optional, "sha256", "sha1", "md5" or "fnv64a", default "sha256")
+// target_field : string (optional dot-path of the fingerprint, default "_fingerprint")
+func (v *FingerprintValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if val, ok := cfg["fields"]; ok {
+		fields, ok := val.([]interface{})
+		if !ok || len(fields) == 0 {
+			logger.Error("fingerprint validation failed: 'fields' must be a non empty list", "value", val)
+			if errs.add(fmt.Errorf("fingerprint: 'fields' must be a non empty list of dot-paths (omit it to fingerprint every field), got: %v", val)) {
+				return errs.err()
+			}
+		}
+		for i, f := range fields {
+			if field, ok := f.(string); !ok || !validDotPath(field) {
+				logger.Error("fingerprint validation failed: field must be a dot-path", "index", i, "value", f)
+				if errs.add(fmt.Errorf("fingerprint: fields[%d] must be a non empty dot-path, got: %v", i, f)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+
+	if algorithm, ok := cfg["algorithm"]; ok && algorithm != "sha256" && algorithm != "sha1" && algorithm != "md5" && algorithm != "fnv64a" {
+		logger.Error("fingerprint validation failed: invalid algorithm", "value", algorithm)
+		if errs.add(fmt.Errorf("fingerprint: 'algorithm' must be 'sha256', 'sha1', 'md5' or 'fnv64a', got: %v", algorithm)) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["target_field"]; ok {
+		if field, ok := val.(string); !ok || !validDotPath(field) {
+			logger.Error("fingerprint validation failed: 'target_field' must be a dot-path", "value", val)
+			if errs.add(fmt.Errorf("fingerprint: 'target_field' must be a non empty dot-path, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== TEMPLATE VALIDATOR ====== //
+
+type TemplateValidator struct{}
+
+// TemplateValidator has three specifics fields :
+// template : string (Go text/template rendered against the value fields, e.g. "User {{.name}} from {{.country}}")
+// target_field : string (dot-path of the rendered string)
+// missing : string (optional, "empty" renders the missing fields as empty strings, "error" fails the message, default "empty")
+func (v *TemplateValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	text, ok := cfg["template"].(string)
+	if !ok || text == "" {
+		logger.Error("template validation failed: 'template' is required and must be a non empty string")
+		if errs.add(fmt.Errorf("template: 'template' is required and must be a non empty string")) {
+			return errs.err()
+		}
+	}
+	if _, err := template.New(ProcessorTypeTemplate).Parse(text); err != nil {
+		logger.Error("template validation failed: invalid template", "error", err)
+		if errs.add(fmt.Errorf("template: invalid template: %w", err)) {
+			return errs.err()
+		}
+	}
+
+	if target, ok := cfg["target_field"].(string); !ok || !validDotPath(target) {
+		logger.Error("template validation failed: 'target_field' is required and must be a dot-path")
+		if errs.add(fmt.Errorf("template: 'target_field' is required and must be a non empty dot-path")) {
+			return errs.err()
+		}
+	}
+
+	if missing, ok := cfg["missing"]; ok && missing != "empty" && missing != "error" {
+		logger.Error("template validation failed: invalid missing", "value", missing)
+		if errs.add(fmt.Errorf("template: 'missing' must be 'empty' or 'error', got: %v", missing)) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== TAP VALIDATOR ====== //
+
+type TapValidator struct{}
+
+// TapValidator has two specifics fields :
+// name : string (label of the etelgo_tap_messages_total counter of the messages reaching the tap)
+// sample_rate : float (optional probability in [0,1] of logging each message, default 0)
+func (v *TapValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if name, ok := cfg["name"].(string); !ok || name == "" {
+		logger.Error("tap validation failed: 'name' is required and must be a non empty string")
+		if errs.add(fmt.Errorf("tap: 'name' is required and must be a non empty string")) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["sample_rate"]; ok {
+		var rate float64
+		switch r := val.(type) {
+		case float64:
+			rate = r
+		case int:
+			rate = float64(r)
+		case int64:
+			rate = float64(r)
+		case uint64:
+			rate = float64(r)
+		default:
+			logger.Error("tap validation failed: 'sample_rate' must be a number", "value", val)
+			errs.add(fmt.Errorf("tap: 'sample_rate' must be a number, got: %v", val))
+			return errs.err()
+		}
+		if rate < 0 || rate > 1 {
+			logger.Error("tap validation failed: 'sample_rate' must be in [0,1]", "value", rate)
+			if errs.add(fmt.Errorf("tap: 'sample_rate' must be in [0,1], got: %v", rate)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== REQUIRE VALIDATOR ====== //
+
+type RequireValidator struct{}
+
+// RequireValidator has two specifics fields :
+// fields : []string (dot-paths of the fields every message must hold, not null)
+// log_missing : bool (optional, log the missing fields of the failing messages, default false)
+func (v *RequireValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	fields, ok := cfg["fields"].([]interface{})
+	if !ok || len(fields) == 0 {
+		logger.Error("require validation failed: 'fields' is required and must be a non empty list")
+		if errs.add(fmt.Errorf("require: 'fields' is required and must be a non empty list of dot-paths")) {
+			return errs.err()
+		}
+	}
+	for i, f := range fields {
+		if field, ok := f.(string); !ok || !validDotPath(field) {
+			logger.Error("require validation failed: field must be a dot-path", "index", i, "value", f)
+			if errs.add(fmt.Errorf("require: fields[%d] must be a non empty dot-path, got: %v", i, f)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if val, ok := cfg["log_missing"]; ok {
+		if _, ok := val.(bool); !ok {
+			logger.Error("require validation failed: 'log_missing' must be a boolean", "value", val)
+			if errs.add(fmt.Errorf("require: 'log_missing' must be a boolean, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== FORMAT NUMBER VALIDATOR ====== //
+
+type FormatNumberValidator struct{}
+
+// FormatNumberValidator has six specifics fields :
+// field_name : string (dot-path of the number to format)
+// target_field : string (optional dot-path of the formatted string, default field_name)
+// precision : int (optional number of decimals in [0,10], default 2)
+// thousands_separator : string (optional separator of the thousands, default none)
+// decimal_separator : string (optional separator of the decimals, differing from thousands_separator, default ".")
+// currency_prefix : string (optional prefix following the sign, e.g. "$", default none)
+func (v *FormatNumberValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	if field, ok := cfg["field_name"].(string); !ok || !validDotPath(field) {
+		logger.Error("format_number validation failed: 'field_name' is required and must be a dot-path")
+		if errs.add(fmt.Errorf("format_number: 'field_name' is required and must be a non empty dot-path")) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["target_field"]; ok {
+		if field, ok := val.(string); !ok || !validDotPath(field) {
+			logger.Error("format_number validation failed: 'target_field' must be a dot-path", "value", val)
+			if errs.add(fmt.Errorf("format_number: 'target_field' must be a non empty dot-path, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if val, ok := cfg["precision"]; ok {
+		var precision int64
+		isInteger := true
+		switch p := val.(type) {
+		case int:
+			precision = int64(p)
+		case int64:
+			precision = p
+		case uint64:
+			precision = int64(min(p, 11)) // Out of range either way, without overflowing
+		default:
+			isInteger = false
+			logger.Error("format_number validation failed: 'precision' must be an integer", "value", val)
+			if errs.add(fmt.Errorf("format_number: 'precision' must be an integer, got: %v", val)) {
+				return errs.err()
+			}
+		}
+		if isInteger && (precision < 0 || precision > 10) {
+			logger.Error("format_number validation failed: 'precision' must be in [0,10]", "value", precision)
+			if errs.add(fmt.Errorf("format_number: 'precision' must be in [0,10], got: %d", precision)) {
+				return errs.err()
+			}
+		}
+	}
+
+	for _, key := range []string{"thousands_separator", "decimal_separator", "currency_prefix"} {
+		if val, ok := cfg[key]; ok {
+			if _, ok := val.(string); !ok {
+				logger.Error("format_number validation failed: option must be a string", "option", key, "value", val)
+				if errs.add(fmt.Errorf("format_number: '%s' must be a string, got: %v", key, val)) {
+					return errs.err()
+				}
+			}
+		}
+	}
+	if val, ok := cfg["decimal_separator"]; ok && (val == "" || val == cfg["thousands_separator"]) {
+		logger.Error("format_number validation failed: invalid 'decimal_separator'", "value", val)
+		if errs.add(fmt.Errorf("format_number: 'decimal_separator' must be non empty and differ from the thousands_separator, got: %q", val)) {
+			return errs.err()
+		}
+	}
+	if cfg["thousands_separator"] == "." && cfg["decimal_separator"] == nil {
+		logger.Error("format_number validation failed: 'thousands_separator' is the default decimal separator")
+		if errs.add(fmt.Errorf("format_number: 'thousands_separator' \".\" requires another 'decimal_separator', e.g. \",\"")) {
+			return errs.err()
+		}
+	}
+
+	return errs.err()
+}
+
+// ====== LOOKUP VALIDATOR ====== //
+
+type LookupValidator struct{}
+
+// LookupValidator has five specifics fields :
+// key_field : string (dot-path of the key looked up)
+// target_field : string (dot-path of the value found)
+// file : string (path of the JSON file holding an object of the values by key)
+// cache_ttl : string (optional positive duration the entries are reused before reading the file again, default "5m")
+// cache_max_size : int (optional maximum of entries cached for the file, default 10000)
+func (v *LookupValidator) Validate(cfg map[string]interface{}, logger *slog.Logger, failFast bool) error {
+	errs := validationErrors{failFast: failFast}
+	for _, key := range []string{"key_field", "target_field"} {
+		if field, ok := cfg[key].(string); !ok || !validDotPath(field) {
+			logger.Error("lookup validation failed: field is required and must be a dot-path", "field", key)
+			if errs.add(fmt.Errorf("lookup: '%s' is required and must be a non empty dot-path", key)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if file, ok := cfg["file"].(string); !ok || file == "" {
+		logger.Error("lookup validation failed: 'file' is required and must be a string")
+		if errs.add(fmt.Errorf("lookup: 'file' is required and must be a non empty string")) {
+			return errs.err()
+		}
+	} else if info, err := os.Stat(file); err != nil || info.IsDir() {
+		logger.Error("lookup validation failed: 'file' does not exist", "file", file)
+		if errs.add(fmt.Errorf("lookup: file %s does not exist", file)) {
+			return errs.err()
+		}
+	}
+
+	if val, ok := cfg["cache_ttl"]; ok {
+		ttl, _ := val.(string)
+		if duration, err := time.ParseDuration(ttl); err != nil || duration <= 0 {
+			logger.Error("lookup validation failed: invalid 'cache_ttl'", "value", val)
+			if errs.add(fmt.Errorf("lookup: 'cache_ttl' must be a positive duration, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	if val, ok := cfg["cache_max_size"]; ok {
+		var size int64
+		switch m := val.(type) {
+		case int:
+			size = int64(m)
+		case int64:
+			size = m
+		case uint64:
+			size = int64(min(m, 1<<32)) // Large enough either way, without overflowing
+		}
+		if size < 1 {
+			logger.Error("lookup validation failed: invalid 'cache_max_size'", "value", val)
+			if errs.add(fmt.Errorf("lookup: 'cache_max_size' must be a positive integer, got: %v", val)) {
+				return errs.err()
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// validDotPath reports whether path is a non empty dot-path without empty segment, e.g. "user.address.city"
+func validDotPath(path string) bool {
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate method for ProcessorConfig
+func (pc *ProcessorConfig) Validate(logger *slog.Logger) error {
+	return pc.validate(logger, false)
+}
+
+func (pc *ProcessorConfig) validate(logger *slog.Logger, failFast bool) error {
+	if pc.Type == "" {
+		logger.Warn("ProcessorConfig validation skipped: Type is empty")
+	}
+
+	validator, exists := processorValidators[pc.Type]
+	if !exists {
+		logger.Error("Unknown processor type, skipping validation", "type", pc.Type)
+		return errors.New("unknown processor type: " + pc.Type)
+	}
+
+	return validator.Validate(pc.Config, logger, failFast)
+}
+
+// LoadOptions tunes the configuration loading
+type LoadOptions struct {
+	Strict   bool // Unknown fields and processors lint issues fail the loading instead of being ignored or logged as warnings
+	FailFast bool // Stop at the first validation error instead of collecting the errors of every section and processor
+}
+
+// LoadConfig loads and validates the configuration file at filePath, a local path or an http(s) URL (see readConfig)
+func LoadConfig(filePath string, logger *slog.Logger) (*Config, error) {
+	return LoadConfigWithOptions(filePath, logger, LoadOptions{})
+}
+
+func LoadConfigWithOptions(filePath string, logger *slog.Logger, opts LoadOptions) (*Config, error) {
+	content, err := readConfig(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+
+	// Unknown fields are ignored by default, so that a configuration written for a newer version still loads.
+	// In strict mode they fail with their name and position, catching the typos (e.g. broker instead of brokers).
+	var decodeOpts []yaml.DecodeOption
+	if opts.Strict {
+		decodeOpts = append(decodeOpts, yaml.DisallowUnknownField())
+	}
+	err = yaml.UnmarshalWithOptions(content, cfg, decodeOpts...)
+
+	if err != nil {
+		if parseErr := newParseError(redactURL(filePath), err); parseErr != nil {
+			logger.Error("Failed to parse YAML", "file", parseErr.File, "line", parseErr.Line, "column", parseErr.Column, "error", parseErr.Message, "source", parseErr.Source)
+			err = parseErr
+		} else {
+			logger.Error("Failed to parse YAML", "error", err)
+		}
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := cfg.Validate(logger, opts); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configFetchTimeout bounds the fetch of a configuration given by URL, from the connection to the end of the body
+var configFetchTimeout = 10 * time.Second
+
+// readConfig returns the content of the configuration file, fetched over HTTP when filePath is an http:// or https://
+// URL (e.g. a config service, or an object store with a presigned URL), read from the disk otherwise.
+// The request is a plain GET without authentication: the URL itself grants the access, so it is as sensitive as
+// the secrets of the configuration and shouldn't be logged or shared, and plain http exposes the configuration
+// (credentials included) to the network, https being the only option outside a trusted network.
+func readConfig(filePath string) ([]byte, error) {
+	if !strings.HasPrefix(filePath, "http://") && !strings.HasPrefix(filePath, "https://") {
+		return os.ReadFile(filePath)
+	}
+
+	client := &http.Client{Timeout: configFetchTimeout}
+	resp, err := client.Get(filePath)
+	if err != nil {
+		// The URL is left out of the error, a presigned one holding its signature
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to fetch the configuration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch the configuration: %s", resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the configuration: %w", err)
+	}
+	return content, nil
+}
+
+// ParseError is a YAML error of the configuration file, located so that the editors can jump to it
+type ParseError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Source  string // Lines around the error, the faulty one marked with ">" and the column with "^"
+	Err     error
+}
+
+// Error formats the error as file:line:column: message, followed by the source lines
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s\n%s", e.File, e.Line, e.Column, e.Message, e.Source)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError locates the YAML error in the file, nil for an error without position
+func newParseError(filePath string, err error) *ParseError {
+	var yamlErr yaml.Error
+	if !errors.As(err, &yamlErr) || yamlErr.GetToken() == nil || yamlErr.GetToken().Position == nil {
+		return nil
+	}
+	position := yamlErr.GetToken().Position
+	// The formatted error starts with its [line:column] and message, the source lines follow
+	_, source, _ := strings.Cut(yamlErr.FormatError(false, true), "\n")
+	return &ParseError{
+		File:    filePath,
+		Line:    position.Line,
+		Column:  position.Column,
+		Message: yamlErr.GetMessage(),
+		Source:  source,
+		Err:     err,
+	}
+}
+
+// wrapError prefixes a non nil error with the context, nil otherwise
+// Validate checks the configuration and applies its defaults, as LoadConfigWithOptions does after parsing the file.
+// It is meant for applications building the configuration in code, which must validate it before running a pipeline.
+func (c *Config) Validate(logger *slog.Logger, opts LoadOptions) error {
+	// The input, output, monitoring, errors and each processor are validated independently, each reporting all its errors.
+	// They are joined, one per line prefixed by its section, so that a single run reports everything to fix.
+	errs := validationErrors{failFast: opts.FailFast}
+	if errs.addAll("input validation failed", c.Input.validate(logger, opts.FailFast)) {
+		return errs.err()
+	}
+	if errs.addAll("output validation failed", c.Output.validate(logger, opts.FailFast)) {
+		return errs.err()
+	}
+	if errs.addAll("monitoring validation failed", c.Monitoring.validate(logger, opts.FailFast)) {
+		return errs.err()
+	}
+	if errs.addAll("errors validation failed", c.Errors.validate(logger, opts.FailFast)) {
+		return errs.err()
+	}
+	for i := range c.Processors {
+		logger.Info("Validating processor", "type", c.Processors[i].Type)
+		context := fmt.Sprintf("processor %d validation failed", i)
+		if errs.addAll(context, c.Processors[i].validate(logger, opts.FailFast)) {
+			return errs.err()
+		}
+		if errs.addAll(context, c.Input.validateProcessorTopic(c.Processors[i].Topic, logger)) {
+			return errs.err()
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+
+	issues := Lint(c.Processors)
+	for _, issue := range issues {
+		logger.Warn("Suspicious processors chain", "processors", issue.Processors, "issue", issue.Message)
+	}
+	if opts.Strict && len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.String()
+		}
+		return fmt.Errorf("processors lint failed (strict mode): %s", strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func wrapError(context string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", context, err)
 }
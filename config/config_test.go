@@ -1,8 +1,14 @@
 package config
 
 import (
+	"errors"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -28,7 +34,240 @@ func TestValidateInput(t *testing.T) {
 				Workers:        2},
 			false,
 		},
+		{"Valid InputConfig - Topics only",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topics:  []string{"orders", "payments"},
+				Format:  "json"},
+			false,
+		},
+		{"Valid InputConfig - Checkpoint file",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "test-topic",
+				Format:          "json",
+				Checkpoint_file: stringPtr(os.TempDir() + "/offsets.json")},
+			false,
+		},
+		{"Invalid InputConfig - Checkpoint file in a missing directory",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "test-topic",
+				Format:          "json",
+				Checkpoint_file: stringPtr("/does/not/exist/offsets.json")},
+			true,
+		},
+		{"Invalid InputConfig - Checkpoint interval",
+			InputConfig{
+				Brokers:             []string{"localhost:9092"},
+				Topic:               "test-topic",
+				Format:              "json",
+				Checkpoint_file:     stringPtr(os.TempDir() + "/offsets.json"),
+				Checkpoint_interval: stringPtr("0s")},
+			true,
+		},
+		{"Invalid InputConfig - Heartbeat above a third of session timeout",
+			InputConfig{
+				Brokers:            []string{"localhost:9092"},
+				Topic:              "test-topic",
+				Format:             "json",
+				Session_timeout:    stringPtr("10s"),
+				Heartbeat_interval: stringPtr("5s")},
+			true,
+		},
+		{"Invalid InputConfig - Heartbeat with default session timeout",
+			InputConfig{
+				Brokers:            []string{"localhost:9092"},
+				Topic:              "test-topic",
+				Format:             "json",
+				Heartbeat_interval: stringPtr("4s")},
+			true,
+		},
+		{"Invalid InputConfig - Auto commit interval not positive",
+			InputConfig{
+				Brokers:              []string{"localhost:9092"},
+				Topic:                "test-topic",
+				Format:               "json",
+				Enable_auto_commit:   boolPtr(true),
+				Auto_commit_interval: stringPtr("0s")},
+			true,
+		},
+		{"Invalid InputConfig - Worker affinity",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "test-topic",
+				Format:          "json",
+				Worker_affinity: stringPtr("round_robin")},
+			true,
+		},
+		{"Valid InputConfig - Read committed",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "test-topic",
+				Format:          "json",
+				Isolation_level: stringPtr("read_committed")},
+			false,
+		},
+		{"Invalid InputConfig - Isolation level",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "test-topic",
+				Format:          "json",
+				Isolation_level: stringPtr("serializable")},
+			true,
+		},
+		{"Invalid InputConfig - Max message bytes",
+			InputConfig{
+				Brokers:           []string{"localhost:9092"},
+				Topic:             "test-topic",
+				Format:            "json",
+				Max_message_bytes: intPtr(0)},
+			true,
+		},
+		{"Valid InputConfig - JSON key format",
+			InputConfig{
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "test-topic",
+				Format:     "json",
+				Key_format: stringPtr("json")},
+			false,
+		},
+		{"Invalid InputConfig - CSV key format",
+			InputConfig{
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "test-topic",
+				Format:     "json",
+				Key_format: stringPtr("csv")},
+			true,
+		},
+		{"Invalid InputConfig - Avro key format without schema registry",
+			InputConfig{
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "test-topic",
+				Format:     "json",
+				Key_format: stringPtr("avro")},
+			true,
+		},
+		{"Valid InputConfig - No commit on shutdown",
+			InputConfig{
+				Brokers:            []string{"localhost:9092"},
+				Topic:              "test-topic",
+				Format:             "json",
+				Commit_on_shutdown: boolPtr(false)},
+			false,
+		},
+		{"Valid InputConfig - Polling without empty fetch backoff",
+			InputConfig{
+				Brokers:             []string{"localhost:9092"},
+				Topic:               "test-topic",
+				Format:              "json",
+				Poll_timeout:        stringPtr("500ms"),
+				Empty_fetch_backoff: stringPtr("0s")},
+			false,
+		},
+		{"Invalid InputConfig - Zero poll timeout",
+			InputConfig{
+				Brokers:      []string{"localhost:9092"},
+				Topic:        "test-topic",
+				Format:       "json",
+				Poll_timeout: stringPtr("0s")},
+			true,
+		},
+		{"Invalid InputConfig - Negative empty fetch backoff",
+			InputConfig{
+				Brokers:             []string{"localhost:9092"},
+				Topic:               "test-topic",
+				Format:              "json",
+				Empty_fetch_backoff: stringPtr("-1ms")},
+			true,
+		},
+		{"Valid InputConfig - Metadata refreshed every 30s",
+			InputConfig{
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "test-topic",
+				Format:           "json",
+				Metadata_max_age: stringPtr("30s")},
+			false,
+		},
+		{"Invalid InputConfig - Zero metadata max age",
+			InputConfig{
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "test-topic",
+				Format:           "json",
+				Metadata_max_age: stringPtr("0s")},
+			true,
+		},
+		{"Invalid InputConfig - Metadata max age above 1h",
+			InputConfig{
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "test-topic",
+				Format:           "json",
+				Metadata_max_age: stringPtr("2h")},
+			true,
+		},
+		{"Valid InputConfig - Topic regex",
+			InputConfig{
+				Brokers:     []string{"localhost:9092"},
+				Format:      "json",
+				Topic_regex: stringPtr("orders-.*")},
+			false,
+		},
+		{"Invalid InputConfig - Topic regex not compiling",
+			InputConfig{
+				Brokers:     []string{"localhost:9092"},
+				Format:      "json",
+				Topic_regex: stringPtr("orders-(")},
+			true,
+		},
+		{"Invalid InputConfig - Empty topic regex",
+			InputConfig{
+				Brokers:     []string{"localhost:9092"},
+				Format:      "json",
+				Topic_regex: stringPtr("")},
+			true,
+		},
+		{"Invalid InputConfig - Topic regex with topic",
+			InputConfig{
+				Brokers:     []string{"localhost:9092"},
+				Topic:       "test-topic",
+				Format:      "json",
+				Topic_regex: stringPtr("orders-.*")},
+			true,
+		},
+		{"Invalid InputConfig - Topic regex with checkpoint file",
+			InputConfig{
+				Brokers:         []string{"localhost:9092"},
+				Format:          "json",
+				Topic_regex:     stringPtr("orders-.*"),
+				Checkpoint_file: stringPtr("offsets.json")},
+			true,
+		},
+		{"Invalid InputConfig - Topic regex with partitions",
+			InputConfig{
+				Brokers:     []string{"localhost:9092"},
+				Format:      "json",
+				Topic_regex: stringPtr("orders-.*"),
+				Partitions:  []int{0}},
+			true,
+		},
 		// Invalid Cases
+		{
+			"Invalid InputConfig - No topic",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Format:  "json",
+			},
+			true,
+		},
+		{
+			"Invalid InputConfig - Empty topic in Topics",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topics:  []string{"orders", ""},
+				Format:  "json",
+			},
+			true,
+		},
 		{
 			"Invalid InputConfig - Unsupported Format",
 			InputConfig{
@@ -37,6 +276,63 @@ func TestValidateInput(t *testing.T) {
 			},
 			true,
 		},
+		{"Valid InputConfig - CSV with header",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "test-topic",
+				Format:  "csv",
+				Csv:     &CSVConfig{Header: boolPtr(true)}},
+			false,
+		},
+		{
+			"Invalid InputConfig - CSV without header nor columns",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "test-topic",
+				Format:  "csv",
+			},
+			true,
+		},
+		{
+			"Invalid InputConfig - CSV delimiter",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "test-topic",
+				Format:  "csv",
+				Csv:     &CSVConfig{Delimiter: stringPtr("::"), Columns: []string{"id"}},
+			},
+			true,
+		},
+		{
+			"Invalid InputConfig - CSV duplicated column",
+			InputConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "test-topic",
+				Format:  "csv",
+				Csv:     &CSVConfig{Columns: []string{"id", "id"}},
+			},
+			true,
+		},
+		{
+			"Invalid InputConfig - Unsupported payload_compression",
+			InputConfig{
+				Brokers:             []string{"localhost:9092"},
+				Topic:               "test-topic",
+				Format:              "json",
+				Payload_compression: stringPtr("brotli"),
+			},
+			true,
+		},
+		{
+			"Invalid InputConfig - Unsupported json_numbers",
+			InputConfig{
+				Brokers:      []string{"localhost:9092"},
+				Topic:        "test-topic",
+				Format:       "json",
+				Json_numbers: stringPtr("decimal"),
+			},
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,6 +355,36 @@ func TestValidateInput(t *testing.T) {
 	}
 }
 
+func TestInputAllTopics(t *testing.T) {
+	ic := InputConfig{
+		Topic:  "orders",
+		Topics: []string{"payments", "orders", "refunds"},
+	}
+
+	got := ic.AllTopics()
+	want := []string{"orders", "payments", "refunds"}
+	if len(got) != len(want) {
+		t.Fatalf("AllTopics() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AllTopics()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 // Output Validation tests for OutputConfig
 func TestValidateOutput(t *testing.T) {
 
@@ -104,6 +430,134 @@ func TestValidateOutput(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Valid - Msgpack key format",
+			config: OutputConfig{
+				Type:       "kafka",
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "output-topic",
+				Format:     "json",
+				Key_format: stringPtr("msgpack"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Protobuf key format without Schema Registry",
+			config: OutputConfig{
+				Type:       "kafka",
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "output-topic",
+				Format:     "json",
+				Key_format: stringPtr("protobuf"),
+			},
+			wantErr:    true,
+			wantErrMsg: "schema_registry_url is required for AVRO and PROTOBUF key formats",
+		},
+		{
+			name: "Invalid - Non finite floats policy",
+			config: OutputConfig{
+				Type:              "kafka",
+				Brokers:           []string{"localhost:9092"},
+				Topic:             "output-topic",
+				Format:            "json",
+				Non_finite_floats: stringPtr("zero"),
+			},
+			wantErr:    true,
+			wantErrMsg: "non_finite_floats must be 'error', 'null' or 'drop_field', got: zero",
+		},
+		{
+			name: "Valid - Produce time timestamps",
+			config: OutputConfig{
+				Type:             "kafka",
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "output-topic",
+				Format:           "json",
+				Timestamp_source: stringPtr("now"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Timestamp source",
+			config: OutputConfig{
+				Type:             "kafka",
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "output-topic",
+				Format:           "json",
+				Timestamp_source: stringPtr("broker"),
+			},
+			wantErr:    true,
+			wantErrMsg: "timestamp_source must be 'message' or 'now', got: broker",
+		},
+		{
+			name: "Valid - Restricted partitions",
+			config: OutputConfig{
+				Type:       "kafka",
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "output-topic",
+				Format:     "json",
+				Partitions: []int{0, 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Duplicate partition",
+			config: OutputConfig{
+				Type:       "kafka",
+				Brokers:    []string{"localhost:9092"},
+				Topic:      "output-topic",
+				Format:     "json",
+				Partitions: []int{0, 2, 0},
+			},
+			wantErr:    true,
+			wantErrMsg: "partitions must be distinct non-negative partition numbers, got: [0 2 0]",
+		},
+		{
+			name: "Valid - Templated headers",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Headers: map[string]string{"pipeline": "etelgo", "source": "${topic}/${partition}", "tenant": "${value.tenant.id}"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Empty header name",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Headers: map[string]string{"": "etelgo"},
+			},
+			wantErr:    true,
+			wantErrMsg: "headers names cannot be empty",
+		},
+		{
+			name: "Invalid - Unknown header variable",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Headers: map[string]string{"source": "${source_topic}"},
+			},
+			wantErr:    true,
+			wantErrMsg: `header "source": unknown variable ${source_topic}, expected topic, partition, offset, key or value.<field>`,
+		},
+		{
+			name: "Invalid - Unterminated header variable",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Headers: map[string]string{"source": "${topic"},
+			},
+			wantErr:    true,
+			wantErrMsg: `header "source": unterminated ${ variable`,
+		},
 		// Missing mandatory fields
 		{
 			name: "Invalid - Missing Type",
@@ -179,6 +633,29 @@ func TestValidateOutput(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "unsupported format: xml",
 		},
+		{
+			name: "Invalid - Request timeout below retry backoff",
+			config: OutputConfig{
+				Type:            "kafka",
+				Brokers:         []string{"localhost:9092"},
+				Topic:           "output-topic",
+				Format:          "json",
+				Request_timeout: stringPtr("1s"),
+			},
+			wantErr:    true,
+			wantErrMsg: "request_timeout (1s) must exceed retry_backoff (2s)",
+		},
+		{
+			name: "Invalid - Auto format on output",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "auto",
+			},
+			wantErr:    true,
+			wantErrMsg: "unsupported format: auto",
+		},
 		{
 			name: "Invalid - AVRO without Schema Registry",
 			config: OutputConfig{
@@ -202,14 +679,152 @@ func TestValidateOutput(t *testing.T) {
 			wantErrMsg: "schema_registry_url is required for AVRO and PROTOBUF formats",
 		},
 
-		// valeurs par défault
+		// Topic routing
 		{
-			name: "Valid - Workers zero should default to 1",
+			name: "Valid - Topic routing with map",
 			config: OutputConfig{
-				Type:    "kafka",
-				Brokers: []string{"localhost:9092"},
-				Topic:   "output-topic",
-				Format:  "json",
+				Type:        "kafka",
+				Brokers:     []string{"localhost:9092"},
+				Topic:       "output-topic",
+				Format:      "json",
+				Topic_field: stringPtr("event_type"),
+				Topic_map:   map[string]string{"order": "orders", "payment": "payments"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Topic map without topic field",
+			config: OutputConfig{
+				Type:      "kafka",
+				Brokers:   []string{"localhost:9092"},
+				Topic:     "output-topic",
+				Format:    "json",
+				Topic_map: map[string]string{"order": "orders"},
+			},
+			wantErr:    true,
+			wantErrMsg: "topic_map requires topic_field to be set",
+		},
+		{
+			name: "Invalid - Empty topic in topic map",
+			config: OutputConfig{
+				Type:        "kafka",
+				Brokers:     []string{"localhost:9092"},
+				Topic:       "output-topic",
+				Format:      "json",
+				Topic_field: stringPtr("event_type"),
+				Topic_map:   map[string]string{"order": ""},
+			},
+			wantErr:    true,
+			wantErrMsg: `topic_map entry "order" has an empty topic`,
+		},
+
+		// Circuit breaker
+		{
+			name: "Invalid - Negative breaker threshold",
+			config: OutputConfig{
+				Type:                      "kafka",
+				Brokers:                   []string{"localhost:9092"},
+				Topic:                     "output-topic",
+				Format:                    "json",
+				Breaker_failure_threshold: intPtr(-1),
+			},
+			wantErr:    true,
+			wantErrMsg: "breaker_failure_threshold cannot be negative, got: -1",
+		},
+		{
+			name: "Invalid - Zero breaker cooldown",
+			config: OutputConfig{
+				Type:             "kafka",
+				Brokers:          []string{"localhost:9092"},
+				Topic:            "output-topic",
+				Format:           "json",
+				Breaker_cooldown: stringPtr("0s"),
+			},
+			wantErr:    true,
+			wantErrMsg: "breaker_cooldown must be positive, got: 0s",
+		},
+		{
+			name: "Valid - Linger disabled",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Linger:  stringPtr("0s"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Negative linger",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Linger:  stringPtr("-5ms"),
+			},
+			wantErr:    true,
+			wantErrMsg: "linger must be a non-negative duration up to 1m, got: -5ms",
+		},
+		{
+			name: "Invalid - Linger above 1m",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
+				Linger:  stringPtr("2m"),
+			},
+			wantErr:    true,
+			wantErrMsg: "linger must be a non-negative duration up to 1m, got: 2m",
+		},
+		{
+			name: "Valid - Backpressure watermarks",
+			config: OutputConfig{
+				Type:                        "kafka",
+				Brokers:                     []string{"localhost:9092"},
+				Topic:                       "output-topic",
+				Format:                      "json",
+				Backpressure_high_watermark: intPtr(1500),
+				Backpressure_low_watermark:  intPtr(500),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid - Backpressure low watermark above high watermark",
+			config: OutputConfig{
+				Type:                        "kafka",
+				Brokers:                     []string{"localhost:9092"},
+				Topic:                       "output-topic",
+				Format:                      "json",
+				Backpressure_high_watermark: intPtr(500),
+				Backpressure_low_watermark:  intPtr(500),
+			},
+			wantErr:    true,
+			wantErrMsg: "backpressure_low_watermark must be between 0 and backpressure_high_watermark (500), got: 500",
+		},
+		{
+			name: "Invalid - Ordered buffer size zero",
+			config: OutputConfig{
+				Type:                "kafka",
+				Brokers:             []string{"localhost:9092"},
+				Topic:               "output-topic",
+				Format:              "json",
+				Ordered:             boolPtr(true),
+				Ordered_buffer_size: intPtr(0),
+			},
+			wantErr:    true,
+			wantErrMsg: "ordered_buffer_size must be positive, got: 0",
+		},
+
+		// valeurs par défault
+		{
+			name: "Valid - Workers zero should default to 1",
+			config: OutputConfig{
+				Type:    "kafka",
+				Brokers: []string{"localhost:9092"},
+				Topic:   "output-topic",
+				Format:  "json",
 				Workers: 0,
 			},
 			wantErr: false,
@@ -257,16 +872,91 @@ func TestValidateOutput(t *testing.T) {
 				if *configCopy.Batch_size <= 0 {
 					t.Errorf("Batch_size should be at least 2000, got %d", configCopy.Batch_size)
 				}
+				if *configCopy.Backpressure_low_watermark >= *configCopy.Backpressure_high_watermark {
+					t.Errorf("Backpressure watermarks should be ordered, got low %d and high %d",
+						*configCopy.Backpressure_low_watermark, *configCopy.Backpressure_high_watermark)
+				}
 			}
 		})
 	}
 
 }
 
+func TestValidateMonitoring(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mc := MonitoringConfig{Metrics_export: MetricsExportConfig{Enabled: true}}
+	if err := mc.Validate(logger); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if mc.Metrics_export.Port != 9090 || mc.Metrics_export.Type != "prometheus" {
+		t.Errorf("expected defaults prometheus:9090, got %s:%d", mc.Metrics_export.Type, mc.Metrics_export.Port)
+	}
+
+	mc = MonitoringConfig{Metrics_export: MetricsExportConfig{Enabled: true, Type: "statsd"}}
+	if err := mc.Validate(logger); err == nil {
+		t.Errorf("Validate() error = nil, want unsupported type error")
+	}
+
+	mc = MonitoringConfig{Metrics_export: MetricsExportConfig{Enabled: true, Port: 70000}}
+	if err := mc.Validate(logger); err == nil {
+		t.Errorf("Validate() error = nil, want invalid port error")
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ec := ErrorsConfig{}
+	if err := ec.Validate(logger); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if ec.Policy != ErrorPolicySkip {
+		t.Errorf("expected default policy skip, got %s", ec.Policy)
+	}
+
+	ec = ErrorsConfig{Policy: ErrorPolicyDLQ, Dlq_topic: "orders-dlq"}
+	if err := ec.Validate(logger); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+
+	ec = ErrorsConfig{Policy: ErrorPolicyDLQ}
+	if err := ec.Validate(logger); err == nil {
+		t.Errorf("Validate() error = nil, want missing dlq_topic error")
+	}
+
+	ec = ErrorsConfig{Policy: "retry"}
+	if err := ec.Validate(logger); err == nil || !strings.Contains(err.Error(), "got: retry") {
+		t.Errorf("Validate() error = %v, want invalid policy error", err)
+	}
+
+	ec = ErrorsConfig{Policy: ErrorPolicyDLQ, Dlq_topic: "orders-dlq", Dlq_envelope: &DLQEnvelopeConfig{}}
+	if err := ec.Validate(logger); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if len(ec.Dlq_envelope.Fields) != 8 || *ec.Dlq_envelope.Payload_encoding != "base64" {
+		t.Errorf("expected the default envelope fields and base64 payloads, got %v and %s", ec.Dlq_envelope.Fields, *ec.Dlq_envelope.Payload_encoding)
+	}
+
+	ec = ErrorsConfig{Policy: ErrorPolicyDLQ, Dlq_topic: "orders-dlq", Dlq_envelope: &DLQEnvelopeConfig{Fields: []string{"value", "stack"}}}
+	if err := ec.Validate(logger); err == nil || !strings.Contains(err.Error(), "unknown dlq_envelope field: stack") {
+		t.Errorf("Validate() error = %v, want unknown envelope field error", err)
+	}
+
+	ec = ErrorsConfig{Policy: ErrorPolicyDLQ, Dlq_topic: "orders-dlq", Dlq_envelope: &DLQEnvelopeConfig{Payload_encoding: stringPtr("hex")}}
+	if err := ec.Validate(logger); err == nil || !strings.Contains(err.Error(), "got: hex") {
+		t.Errorf("Validate() error = %v, want invalid payload encoding error", err)
+	}
+}
+
 // Validations tests for ProcessorConfig
 func TestValidateProcessors(t *testing.T) {
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lookupFile := filepath.Join(t.TempDir(), "cities.json")
+	if err := os.WriteFile(lookupFile, []byte(`{"42": "Paris"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
 	tests := []struct {
 		name    string
@@ -347,6 +1037,75 @@ func TestValidateProcessors(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "[DropValidator] Valid value_type parameter",
+			config: ProcessorConfig{
+				Type:   "drop",
+				Config: map[string]interface{}{"field_name": "count", "filter_criteria": "100", "value_type": "int"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[DropValidator] Invalid value_type parameter",
+			config: ProcessorConfig{
+				Type:   "drop",
+				Config: map[string]interface{}{"field_name": "count", "filter_criteria": "100", "value_type": "number"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[DropValidator] filter_criteria not matching value_type",
+			config: ProcessorConfig{
+				Type:   "drop",
+				Config: map[string]interface{}{"field_name": "active", "filter_criteria": "yes", "value_type": "bool"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[DropValidator] Valid conditions parameter",
+			config: ProcessorConfig{
+				Type: "drop",
+				Config: map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"field": "status", "value": "inactive"},
+						map[string]interface{}{"field": "region", "operator": "ne", "value": "us"},
+					},
+					"match": "any",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[DropValidator] Conditions combined with the shorthand",
+			config: ProcessorConfig{
+				Type: "drop",
+				Config: map[string]interface{}{
+					"conditions":      []interface{}{map[string]interface{}{"field": "status", "value": "inactive"}},
+					"field_name":      "region",
+					"filter_criteria": "eu",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[DropValidator] Invalid match parameter",
+			config: ProcessorConfig{
+				Type: "drop",
+				Config: map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"field": "status", "value": "inactive"}},
+					"match":      "some",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[DropValidator] Condition without value",
+			config: ProcessorConfig{
+				Type:   "drop",
+				Config: map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"field": "status", "operator": "gt"}}},
+			},
+			wantErr: true,
+		},
 
 		// Enrich Validator processor tests
 		{
@@ -386,29 +1145,1259 @@ func TestValidateProcessors(t *testing.T) {
 			},
 			wantErr: false,
 		},
-		// Unknown Processor Type
+		// HeaderField Validator processor tests
 		{
-			name: "Unknown Processor Type",
+			name: "[HeaderFieldValidator] Valid to_header parameters",
 			config: ProcessorConfig{
-				Type:   "unknown_processor",
+				Type:   "header_field",
+				Config: map[string]interface{}{"header": "routing-key", "direction": "to_header", "field_name": "type"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[HeaderFieldValidator] Valid delete without field_name",
+			config: ProcessorConfig{
+				Type:   "header_field",
+				Config: map[string]interface{}{"header": "routing-key", "direction": "delete"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[HeaderFieldValidator] Missing field_name",
+			config: ProcessorConfig{
+				Type:   "header_field",
+				Config: map[string]interface{}{"header": "routing-key", "direction": "to_field"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[HeaderFieldValidator] Invalid direction",
+			config: ProcessorConfig{
+				Type:   "header_field",
+				Config: map[string]interface{}{"header": "routing-key", "direction": "both", "field_name": "type"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[HeaderFieldValidator] Missing header",
+			config: ProcessorConfig{
+				Type:   "header_field",
+				Config: map[string]interface{}{"direction": "delete"},
+			},
+			wantErr: true,
+		},
+		// Sample Validator processor tests
+		{
+			name: "[SampleValidator] Valid rate",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"rate": 0.1, "seed": 42},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[SampleValidator] Missing rate",
+			config: ProcessorConfig{
+				Type:   "sample",
 				Config: map[string]interface{}{},
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.Validate(logger)
-
-			if tt.wantErr && err == nil {
-				t.Errorf("Validate() error = nil, wantErr = true")
-				return
-			}
-
-			if !tt.wantErr && err != nil {
-				t.Errorf("Validate() unexpected error = %v", err)
-				return
+		{
+			name: "[SampleValidator] Rate out of range",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"rate": 2},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SampleValidator] Invalid seed",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"rate": 0.5, "seed": 1.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SampleValidator] Valid every",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"every": 10},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[SampleValidator] Both rate and every",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"rate": 0.5, "every": 10},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SampleValidator] Negative every",
+			config: ProcessorConfig{
+				Type:   "sample",
+				Config: map[string]interface{}{"every": -1},
+			},
+			wantErr: true,
+		},
+		// Pace Validator processor tests
+		{
+			name: "[PaceValidator] Default speed",
+			config: ProcessorConfig{
+				Type:   "pace",
+				Config: map[string]interface{}{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[PaceValidator] Valid speed",
+			config: ProcessorConfig{
+				Type:   "pace",
+				Config: map[string]interface{}{"speed": 2.0},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[PaceValidator] Negative speed",
+			config: ProcessorConfig{
+				Type:   "pace",
+				Config: map[string]interface{}{"speed": -1},
+			},
+			wantErr: true,
+		},
+		// Extract Validator processor tests
+		{
+			name: "[ExtractValidator] Valid path",
+			config: ProcessorConfig{
+				Type:   "extract",
+				Config: map[string]interface{}{"path": "$.items[*].sku", "target_field": "skus"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[ExtractValidator] Invalid path",
+			config: ProcessorConfig{
+				Type:   "extract",
+				Config: map[string]interface{}{"path": "$.items[?(", "target_field": "skus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ExtractValidator] Missing target_field",
+			config: ProcessorConfig{
+				Type:   "extract",
+				Config: map[string]interface{}{"path": "$.items[*].sku"},
+			},
+			wantErr: true,
+		},
+		// Merge Validator processor tests
+		{
+			name: "[MergeValidator] Valid parameters",
+			config: ProcessorConfig{
+				Type:   "merge",
+				Config: map[string]interface{}{"source_fields": []interface{}{"first", "last"}, "target_field": "full_name", "separator": " "},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[MergeValidator] Single source field",
+			config: ProcessorConfig{
+				Type:   "merge",
+				Config: map[string]interface{}{"source_fields": []interface{}{"first"}, "target_field": "full_name"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[MergeValidator] Missing target_field",
+			config: ProcessorConfig{
+				Type:   "merge",
+				Config: map[string]interface{}{"source_fields": []interface{}{"first", "last"}},
+			},
+			wantErr: true,
+		},
+		// Copy Validator processor tests
+		{
+			name: "[CopyValidator] Valid dot-paths",
+			config: ProcessorConfig{
+				Type:   "copy",
+				Config: map[string]interface{}{"source_field": "user.name", "target_field": "backup.name", "overwrite": true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[CopyValidator] Invalid dot-path",
+			config: ProcessorConfig{
+				Type:   "copy",
+				Config: map[string]interface{}{"source_field": "user..name", "target_field": "name"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[CopyValidator] Same source and target",
+			config: ProcessorConfig{
+				Type:   "copy",
+				Config: map[string]interface{}{"source_field": "name", "target_field": "name"},
+			},
+			wantErr: true,
+		},
+		// Route Validator processor tests
+		{
+			name: "[RouteValidator] Valid rules with default",
+			config: ProcessorConfig{
+				Type: "route",
+				Config: map[string]interface{}{"rules": []interface{}{
+					map[string]interface{}{"label": "errors", "when": map[string]interface{}{"field": "level", "operator": "eq", "value": "error"}},
+					map[string]interface{}{"label": "other", "default": true},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[RouteValidator] Invalid operator",
+			config: ProcessorConfig{
+				Type: "route",
+				Config: map[string]interface{}{"rules": []interface{}{
+					map[string]interface{}{"label": "errors", "when": map[string]interface{}{"field": "level", "operator": "like", "value": "error"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RouteValidator] Two default rules",
+			config: ProcessorConfig{
+				Type: "route",
+				Config: map[string]interface{}{"rules": []interface{}{
+					map[string]interface{}{"label": "a", "default": true},
+					map[string]interface{}{"label": "b", "default": true},
+				}},
+			},
+			wantErr: true,
+		},
+		// Field Exists Validator processor tests
+		{
+			name: "[FieldExistsValidator] Valid configuration",
+			config: ProcessorConfig{
+				Type:   "field_exists",
+				Config: map[string]interface{}{"field_name": "contact.email", "require_present": true, "action": "keep"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[FieldExistsValidator] Missing field_name",
+			config: ProcessorConfig{
+				Type:   "field_exists",
+				Config: map[string]interface{}{"require_present": true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FieldExistsValidator] Invalid action",
+			config: ProcessorConfig{
+				Type:   "field_exists",
+				Config: map[string]interface{}{"field_name": "email", "action": "forward"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[MaxAgeValidator] Valid with timestamp field",
+			config: ProcessorConfig{
+				Type:   "max_age",
+				Config: map[string]interface{}{"max_age": "24h", "timestamp_field": "event.time", "timestamp_unit": "s"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[MaxAgeValidator] Missing max_age",
+			config: ProcessorConfig{
+				Type:   "max_age",
+				Config: map[string]interface{}{"timestamp_field": "time"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[MaxAgeValidator] Negative max_age",
+			config: ProcessorConfig{
+				Type:   "max_age",
+				Config: map[string]interface{}{"max_age": "-1h"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[MaxAgeValidator] Invalid timestamp unit",
+			config: ProcessorConfig{
+				Type:   "max_age",
+				Config: map[string]interface{}{"max_age": "1h", "timestamp_field": "time", "timestamp_unit": "us"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[BucketValidator] Valid",
+			config: ProcessorConfig{
+				Type: "bucket",
+				Config: map[string]interface{}{"field_name": "age", "target_field": "age_group", "buckets": []interface{}{
+					map[string]interface{}{"max": 17, "label": "child"},
+					map[string]interface{}{"max": 64.5, "label": "adult"},
+					map[string]interface{}{"label": "senior"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[BucketValidator] Thresholds not ascending",
+			config: ProcessorConfig{
+				Type: "bucket",
+				Config: map[string]interface{}{"field_name": "age", "target_field": "age_group", "buckets": []interface{}{
+					map[string]interface{}{"max": 64, "label": "adult"},
+					map[string]interface{}{"max": 17, "label": "child"},
+					map[string]interface{}{"label": "senior"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[BucketValidator] Missing catch-all",
+			config: ProcessorConfig{
+				Type: "bucket",
+				Config: map[string]interface{}{"field_name": "age", "target_field": "age_group", "buckets": []interface{}{
+					map[string]interface{}{"max": 17, "label": "child"},
+					map[string]interface{}{"max": 64, "label": "adult"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[BucketValidator] Missing target field",
+			config: ProcessorConfig{
+				Type: "bucket",
+				Config: map[string]interface{}{"field_name": "age", "buckets": []interface{}{
+					map[string]interface{}{"max": 17, "label": "child"},
+					map[string]interface{}{"label": "adult"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ExplodeValidator] Valid",
+			config: ProcessorConfig{
+				Type:   "explode",
+				Config: map[string]interface{}{"field_name": "metrics.scores"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[ExplodeValidator] Missing field name",
+			config: ProcessorConfig{
+				Type:   "explode",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ExplodeValidator] Invalid dot-path",
+			config: ProcessorConfig{
+				Type:   "explode",
+				Config: map[string]interface{}{"field_name": "metrics..scores"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[AggregateValidator] Valid",
+			config: ProcessorConfig{
+				Type: "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "agg_field": "amount", "functions": []interface{}{"count", "avg"},
+					"window": "1m", "allowed_lateness": "10s", "max_groups": 500},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[AggregateValidator] Count without agg_field",
+			config: ProcessorConfig{
+				Type:   "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "functions": []interface{}{"count"}, "window": "1m"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[AggregateValidator] Sum without agg_field",
+			config: ProcessorConfig{
+				Type:   "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "functions": []interface{}{"sum"}, "window": "1m"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[AggregateValidator] Unknown function",
+			config: ProcessorConfig{
+				Type:   "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "agg_field": "amount", "functions": []interface{}{"p99"}, "window": "1m"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[AggregateValidator] Missing window",
+			config: ProcessorConfig{
+				Type:   "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "functions": []interface{}{"count"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[AggregateValidator] Invalid max_groups",
+			config: ProcessorConfig{
+				Type:   "aggregate",
+				Config: map[string]interface{}{"group_by": "user.id", "functions": []interface{}{"count"}, "window": "1m", "max_groups": -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ProjectValidator] Valid include",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{"include": []interface{}{"id", "user.name"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[ProjectValidator] Valid exclude",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{"exclude": []interface{}{"user.ssn"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[ProjectValidator] Both include and exclude",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{"include": []interface{}{"id"}, "exclude": []interface{}{"user"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ProjectValidator] Neither include nor exclude",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ProjectValidator] Empty list",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{"include": []interface{}{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[ProjectValidator] Invalid dot-path",
+			config: ProcessorConfig{
+				Type:   "project",
+				Config: map[string]interface{}{"exclude": []interface{}{"user..ssn"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[EmptyToNullValidator] Every field",
+			config: ProcessorConfig{
+				Type:   "empty_to_null",
+				Config: map[string]interface{}{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[EmptyToNullValidator] Configured fields removed",
+			config: ProcessorConfig{
+				Type:   "empty_to_null",
+				Config: map[string]interface{}{"fields": []interface{}{"email", "address.zip"}, "mode": "remove"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[EmptyToNullValidator] Invalid mode",
+			config: ProcessorConfig{
+				Type:   "empty_to_null",
+				Config: map[string]interface{}{"mode": "drop"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[EmptyToNullValidator] Empty fields list",
+			config: ProcessorConfig{
+				Type:   "empty_to_null",
+				Config: map[string]interface{}{"fields": []interface{}{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SchemaValidator] Valid schema",
+			config: ProcessorConfig{
+				Type:   "schema",
+				Config: map[string]interface{}{"schema": map[string]interface{}{"id": "int", "user.name": "string"}, "mode": "coerce"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[SchemaValidator] Missing schema",
+			config: ProcessorConfig{
+				Type:   "schema",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SchemaValidator] Unknown type",
+			config: ProcessorConfig{
+				Type:   "schema",
+				Config: map[string]interface{}{"schema": map[string]interface{}{"id": "integer"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[SchemaValidator] Invalid mode",
+			config: ProcessorConfig{
+				Type:   "schema",
+				Config: map[string]interface{}{"schema": map[string]interface{}{"id": "int"}, "mode": "drop"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RenameKeysValidator] Snake case to camel case",
+			config: ProcessorConfig{
+				Type:   "rename_keys",
+				Config: map[string]interface{}{"pattern": `_(\w)`, "replacement": "$1", "replacement_case": "upper"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[RenameKeysValidator] Invalid pattern",
+			config: ProcessorConfig{
+				Type:   "rename_keys",
+				Config: map[string]interface{}{"pattern": "(", "replacement": ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RenameKeysValidator] Missing replacement",
+			config: ProcessorConfig{
+				Type:   "rename_keys",
+				Config: map[string]interface{}{"pattern": "^old_"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RenameKeysValidator] Invalid replacement_case",
+			config: ProcessorConfig{
+				Type:   "rename_keys",
+				Config: map[string]interface{}{"pattern": "^old_", "replacement": "", "replacement_case": "title"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[GenerateIDValidator] Sequence",
+			config: ProcessorConfig{
+				Type:   "generate_id",
+				Config: map[string]interface{}{"target_field": "meta.id", "generator": "sequence"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[GenerateIDValidator] Missing target_field",
+			config: ProcessorConfig{
+				Type:   "generate_id",
+				Config: map[string]interface{}{"generator": "uuid"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[GenerateIDValidator] Unknown generator",
+			config: ProcessorConfig{
+				Type:   "generate_id",
+				Config: map[string]interface{}{"target_field": "id", "generator": "ulid"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[KeyCaseValidator] Lower listed keys",
+			config: ProcessorConfig{
+				Type:   "key_case",
+				Config: map[string]interface{}{"mode": "lower", "fields": []interface{}{"UserID", "Email"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[KeyCaseValidator] Missing mode",
+			config: ProcessorConfig{
+				Type:   "key_case",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[KeyCaseValidator] Empty fields",
+			config: ProcessorConfig{
+				Type:   "key_case",
+				Config: map[string]interface{}{"mode": "upper", "fields": []interface{}{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[HeadersObjectValidator] Listed headers to field",
+			config: ProcessorConfig{
+				Type:   "headers_object",
+				Config: map[string]interface{}{"direction": "to_field", "target_field": "meta.headers", "headers": []interface{}{"tenant"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[HeadersObjectValidator] Invalid direction",
+			config: ProcessorConfig{
+				Type:   "headers_object",
+				Config: map[string]interface{}{"direction": "to_header", "target_field": "headers"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[HeadersObjectValidator] Missing target_field",
+			config: ProcessorConfig{
+				Type:   "headers_object",
+				Config: map[string]interface{}{"direction": "to_headers"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[HeadersObjectValidator] Empty header name",
+			config: ProcessorConfig{
+				Type:   "headers_object",
+				Config: map[string]interface{}{"direction": "to_field", "target_field": "headers", "headers": []interface{}{""}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FieldLimitsValidator] Both limits",
+			config: ProcessorConfig{
+				Type:   "field_limits",
+				Config: map[string]interface{}{"max_depth": 8, "max_fields": uint64(500)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[FieldLimitsValidator] No limit",
+			config: ProcessorConfig{
+				Type:   "field_limits",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FieldLimitsValidator] Zero max_depth",
+			config: ProcessorConfig{
+				Type:   "field_limits",
+				Config: map[string]interface{}{"max_depth": 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FieldLimitsValidator] Non integer max_fields",
+			config: ProcessorConfig{
+				Type:   "field_limits",
+				Config: map[string]interface{}{"max_fields": "100"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[CoalesceValidator] Sources with default",
+			config: ProcessorConfig{
+				Type:   "coalesce",
+				Config: map[string]interface{}{"source_fields": []interface{}{"user_id", "userId", "user.id"}, "target_field": "user_id", "default": "anonymous"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[CoalesceValidator] Missing source_fields",
+			config: ProcessorConfig{
+				Type:   "coalesce",
+				Config: map[string]interface{}{"target_field": "user_id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[CoalesceValidator] Invalid source dot-path",
+			config: ProcessorConfig{
+				Type:   "coalesce",
+				Config: map[string]interface{}{"source_fields": []interface{}{"user..id"}, "target_field": "user_id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[CoalesceValidator] Missing target_field",
+			config: ProcessorConfig{
+				Type:   "coalesce",
+				Config: map[string]interface{}{"source_fields": []interface{}{"user_id", "userId"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[DedupAdjacentValidator] Whole value",
+			config: ProcessorConfig{
+				Type:   "dedup_adjacent",
+				Config: map[string]interface{}{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[DedupAdjacentValidator] Key field",
+			config: ProcessorConfig{
+				Type:   "dedup_adjacent",
+				Config: map[string]interface{}{"key_field": "state.hash"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[DedupAdjacentValidator] Invalid key field",
+			config: ProcessorConfig{
+				Type:   "dedup_adjacent",
+				Config: map[string]interface{}{"key_field": 42},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[EpochConvertValidator] Epoch to RFC3339",
+			config: ProcessorConfig{
+				Type:   "epoch_convert",
+				Config: map[string]interface{}{"field_name": "created_at", "direction": "to_rfc3339", "unit": "us", "target_field": "created"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[EpochConvertValidator] Invalid direction",
+			config: ProcessorConfig{
+				Type:   "epoch_convert",
+				Config: map[string]interface{}{"field_name": "created_at", "direction": "both"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[EpochConvertValidator] Invalid unit",
+			config: ProcessorConfig{
+				Type:   "epoch_convert",
+				Config: map[string]interface{}{"field_name": "created_at", "direction": "to_epoch", "unit": "min"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[EpochConvertValidator] Missing field_name",
+			config: ProcessorConfig{
+				Type:   "epoch_convert",
+				Config: map[string]interface{}{"direction": "to_epoch"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[NormalizeValidator] Phone with default country code",
+			config: ProcessorConfig{
+				Type:   "normalize",
+				Config: map[string]interface{}{"field_name": "contact.phone", "kind": "phone", "default_country_code": "33", "flag_field": "invalid_phone"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[NormalizeValidator] Unknown kind",
+			config: ProcessorConfig{
+				Type:   "normalize",
+				Config: map[string]interface{}{"field_name": "address", "kind": "address"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[NormalizeValidator] Country code with the email kind",
+			config: ProcessorConfig{
+				Type:   "normalize",
+				Config: map[string]interface{}{"field_name": "email", "kind": "email", "default_country_code": "33"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[NormalizeValidator] Invalid country code",
+			config: ProcessorConfig{
+				Type:   "normalize",
+				Config: map[string]interface{}{"field_name": "phone", "kind": "phone", "default_country_code": "+33"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[NormalizeValidator] Flag field is the normalized field",
+			config: ProcessorConfig{
+				Type:   "normalize",
+				Config: map[string]interface{}{"field_name": "phone", "kind": "phone", "flag_field": "phone"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FingerprintValidator] Subset of fields",
+			config: ProcessorConfig{
+				Type:   "fingerprint",
+				Config: map[string]interface{}{"fields": []interface{}{"id", "user.email"}, "algorithm": "fnv64a", "target_field": "meta.fingerprint"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[FingerprintValidator] Every field by default",
+			config: ProcessorConfig{
+				Type:   "fingerprint",
+				Config: map[string]interface{}{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[FingerprintValidator] Empty fields",
+			config: ProcessorConfig{
+				Type:   "fingerprint",
+				Config: map[string]interface{}{"fields": []interface{}{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FingerprintValidator] Unknown algorithm",
+			config: ProcessorConfig{
+				Type:   "fingerprint",
+				Config: map[string]interface{}{"algorithm": "crc32"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TemplateValidator] Valid template",
+			config: ProcessorConfig{
+				Type:   "template",
+				Config: map[string]interface{}{"template": "User {{.name}} from {{.country}}", "target_field": "summary", "missing": "error"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[TemplateValidator] Template failing to compile",
+			config: ProcessorConfig{
+				Type:   "template",
+				Config: map[string]interface{}{"template": "User {{.name", "target_field": "summary"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TemplateValidator] Missing target field",
+			config: ProcessorConfig{
+				Type:   "template",
+				Config: map[string]interface{}{"template": "User {{.name}}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TemplateValidator] Invalid missing",
+			config: ProcessorConfig{
+				Type:   "template",
+				Config: map[string]interface{}{"template": "User {{.name}}", "target_field": "summary", "missing": "skip"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TapValidator] Sampled tap",
+			config: ProcessorConfig{
+				Type:   "tap",
+				Config: map[string]interface{}{"name": "after_drop", "sample_rate": 0.01},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[TapValidator] Missing name",
+			config: ProcessorConfig{
+				Type:   "tap",
+				Config: map[string]interface{}{"sample_rate": 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TapValidator] Sample rate above 1",
+			config: ProcessorConfig{
+				Type:   "tap",
+				Config: map[string]interface{}{"name": "after_drop", "sample_rate": 1.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RequireValidator] Required fields",
+			config: ProcessorConfig{
+				Type:   "require",
+				Config: map[string]interface{}{"fields": []interface{}{"id", "customer.email"}, "log_missing": true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[RequireValidator] Missing fields",
+			config: ProcessorConfig{
+				Type:   "require",
+				Config: map[string]interface{}{"log_missing": true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RequireValidator] Empty field path",
+			config: ProcessorConfig{
+				Type:   "require",
+				Config: map[string]interface{}{"fields": []interface{}{"id", ""}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[RequireValidator] Invalid log_missing",
+			config: ProcessorConfig{
+				Type:   "require",
+				Config: map[string]interface{}{"fields": []interface{}{"id"}, "log_missing": "yes"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[TransformValidator] Keep original on error",
+			config: ProcessorConfig{
+				Type:   "transform",
+				Config: map[string]interface{}{"field_name": "payload", "operation": "json_parse", "keep_original_on_error": true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[TransformValidator] Invalid keep_original_on_error",
+			config: ProcessorConfig{
+				Type:   "transform",
+				Config: map[string]interface{}{"field_name": "payload", "operation": "json_parse", "keep_original_on_error": "yes"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FormatNumberValidator] Currency format",
+			config: ProcessorConfig{
+				Type: "format_number",
+				Config: map[string]interface{}{"field_name": "amount", "target_field": "amount_display", "precision": uint64(2),
+					"thousands_separator": ",", "currency_prefix": "$"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[FormatNumberValidator] Missing field_name",
+			config: ProcessorConfig{
+				Type:   "format_number",
+				Config: map[string]interface{}{"precision": uint64(2)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FormatNumberValidator] Precision out of range",
+			config: ProcessorConfig{
+				Type:   "format_number",
+				Config: map[string]interface{}{"field_name": "amount", "precision": int64(-1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FormatNumberValidator] Same separators",
+			config: ProcessorConfig{
+				Type:   "format_number",
+				Config: map[string]interface{}{"field_name": "amount", "thousands_separator": ".", "decimal_separator": "."},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[FormatNumberValidator] Thousands separator clashing with the default decimal separator",
+			config: ProcessorConfig{
+				Type:   "format_number",
+				Config: map[string]interface{}{"field_name": "amount", "thousands_separator": "."},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[LookupValidator] Cached lookup",
+			config: ProcessorConfig{
+				Type: "lookup",
+				Config: map[string]interface{}{"key_field": "city_id", "target_field": "city", "file": lookupFile,
+					"cache_ttl": "30s", "cache_max_size": uint64(100)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "[LookupValidator] Missing file",
+			config: ProcessorConfig{
+				Type:   "lookup",
+				Config: map[string]interface{}{"key_field": "city_id", "target_field": "city", "file": lookupFile + ".missing"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[LookupValidator] Invalid cache_ttl",
+			config: ProcessorConfig{
+				Type:   "lookup",
+				Config: map[string]interface{}{"key_field": "city_id", "target_field": "city", "file": lookupFile, "cache_ttl": "-1m"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "[LookupValidator] Invalid cache_max_size",
+			config: ProcessorConfig{
+				Type:   "lookup",
+				Config: map[string]interface{}{"key_field": "city_id", "target_field": "city", "file": lookupFile, "cache_max_size": uint64(0)},
+			},
+			wantErr: true,
+		},
+		// Unknown Processor Type
+		{
+			name: "Unknown Processor Type",
+			config: ProcessorConfig{
+				Type:   "unknown_processor",
+				Config: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate(logger)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("Validate() error = nil, wantErr = true")
+				return
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+				return
+			}
+		})
+	}
+}
+
+// ==================== LoadConfig errors accumulation ====================
+func TestLoadConfigErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Two errors in the input and the second processor, one in the output
+	content := `
+input:
+  brokers: ["localhost:9092"]
+  format: xml
+output:
+  type: kafka
+  brokers: ["localhost:9092"]
+  topic: out
+  format: xml
+processors:
+  - type: passthrough
+  - type: drop
+    config:
+      field_name: status
+      value_type: decimal
+`
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path, logger)
+	if err == nil {
+		t.Fatal("LoadConfig() error = nil, want the errors of every section")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	want := []string{
+		"input validation failed: topic is required",
+		"input validation failed: unsupported format",
+		"output validation failed: unsupported format",
+		"processor 1 validation failed: drop: both 'field_name' and 'filter_criteria' are required",
+		"processor 1 validation failed: drop: 'value_type' must be one of",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("LoadConfig() errors = %q, want %d errors", lines, len(want))
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("error %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+
+	_, err = LoadConfigWithOptions(path, logger, LoadOptions{FailFast: true})
+	if err == nil || strings.Contains(err.Error(), "\n") || !strings.HasPrefix(err.Error(), "input validation failed: topic is required") {
+		t.Errorf("LoadConfigWithOptions(FailFast) error = %v, want only the input error", err)
+	}
+}
+
+// ==================== YAML parse errors ====================
+func TestLoadConfigParseError(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	content := `
+input:
+  brokers: ["localhost:9092"
+  topic: orders
+output:
+  type: kafka
+`
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path, logger)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("LoadConfig() error = %v, want a ParseError", err)
+	}
+	if parseErr.Line != 4 || parseErr.Column != 3 {
+		t.Errorf("ParseError at %d:%d, want 4:3", parseErr.Line, parseErr.Column)
+	}
+	if !strings.Contains(err.Error(), path+":4:3: ") || !strings.Contains(err.Error(), ">  4 |   topic: orders") {
+		t.Errorf("LoadConfig() error = %v, want the position and the source line", err)
+	}
+}
+
+// ==================== Strict parsing ====================
+func TestLoadConfigStrict(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	content := `
+version: "1.0"
+input:
+  brokers: ["localhost:9092"]
+  topic: orders
+  format: json
+  workers: 1
+  max_pol_records: 500
+output:
+  type: kafka
+  brokers: ["localhost:9092"]
+  topic: out
+  format: json
+`
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path, logger); err != nil {
+		t.Fatalf("LoadConfig() error = %v, want the unknown field ignored", err)
+	}
+
+	_, err := LoadConfigWithOptions(path, logger, LoadOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "max_pol_records") || !strings.Contains(err.Error(), "config.yml:8:3:") {
+		t.Errorf("LoadConfigWithOptions(Strict) error = %v, want the unknown field and its position", err)
+	}
+}
+
+// ==================== Config fetched by URL ====================
+func TestLoadConfigURL(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	content := `
+input:
+  brokers: ["localhost:9092"]
+  topic: orders
+  format: json
+output:
+  type: kafka
+  brokers: ["localhost:9092"]
+  topic: out
+  format: json
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.yml":
+			io.WriteString(w, content)
+		case "/invalid.yml":
+			io.WriteString(w, "input: [")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg, err := LoadConfig(server.URL+"/config.yml", logger)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Input.Topic != "orders" {
+		t.Errorf("Input.Topic = %q, want %q", cfg.Input.Topic, "orders")
+	}
+
+	_, err = LoadConfig(server.URL+"/missing.yml?signature=secret", logger)
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Errorf("LoadConfig() error = %v, want the response status", err)
+	}
+
+	// The credentials of the URL are masked in the location of a parse error
+	withUser := strings.Replace(server.URL, "://", "://user:secret@", 1) + "/invalid.yml"
+	_, err = LoadConfig(withUser, logger)
+	if err == nil || strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "user:xxxxx@") {
+		t.Errorf("LoadConfig() error = %v, want a parse error without the URL password", err)
+	}
+
+	unreachable := server.URL + "/config.yml?signature=secret"
+	server.Close()
+	_, err = LoadConfig(unreachable, logger)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("LoadConfig() error = %v, want an error without the URL", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// A configuration built in code, as an application embedding the pipeline does
+	cfg := &Config{
+		Input:  InputConfig{Brokers: []string{"localhost:9092"}, Topic: "orders", Format: "json", Workers: 1},
+		Output: OutputConfig{Type: "kafka", Brokers: []string{"localhost:9092"}, Topic: "orders-out", Format: "json"},
+		Processors: []ProcessorConfig{
+			{Type: "drop", Config: map[string]interface{}{"field_name": "status"}},
+		},
+	}
+	if err := cfg.Validate(logger, LoadOptions{}); err == nil || !strings.Contains(err.Error(), "processor 0 validation failed") {
+		t.Fatalf("Validate() error = %v, want the processor error", err)
+	}
+
+	cfg.Processors[0].Config["filter_criteria"] = "test"
+	if err := cfg.Validate(logger, LoadOptions{}); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if cfg.Input.Offset_reset == nil || *cfg.Output.Batch_size != 2000 {
+		t.Error("Validate() did not apply the defaults")
+	}
+
+	cfg.Processors[0].Topic = "payments"
+	if err := cfg.Validate(logger, LoadOptions{}); err == nil || !strings.Contains(err.Error(), "not an input topic") {
+		t.Fatalf("Validate() error = %v, want the processor topic error", err)
+	}
+	cfg.Input.Topics = []string{"payments"}
+	if err := cfg.Validate(logger, LoadOptions{}); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateProcessorTopic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		input   InputConfig
+		topic   string
+		wantErr bool
+	}{
+		{"Default chain", InputConfig{Topic: "orders"}, "", false},
+		{"Input topic", InputConfig{Topic: "orders", Topics: []string{"payments"}}, "payments", false},
+		{"Unknown topic", InputConfig{Topic: "orders"}, "payments", true},
+		{"Topic matching the regex", InputConfig{Topic_regex: stringPtr("^orders-")}, "orders-eu", false},
+		{"Topic not matching the regex", InputConfig{Topic_regex: stringPtr("^orders-")}, "payments", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.validateProcessorTopic(tt.topic, logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateProcessorTopic() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintIssue is a suspicious processors chain, each processor being valid on its own.
+// Processors holds the config indices of the processors involved.
+type LintIssue struct {
+	Processors []int
+	Message    string
+}
+
+func (li LintIssue) String() string {
+	indices := make([]string, len(li.Processors))
+	for i, p := range li.Processors {
+		indices[i] = fmt.Sprint(p)
+	}
+	return fmt.Sprintf("processors %s: %s", strings.Join(indices, ", "), li.Message)
+}
+
+// ProcessorOrder returns the config indices of the processors in execution order:
+// ascending priority, then config order.
+func ProcessorOrder(processors []ProcessorConfig) []int {
+	order := make([]int, len(processors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return processors[order[a]].priority() < processors[order[b]].priority()
+	})
+	return order
+}
+
+// ProcessorChains returns the config indices of the processors of each chain in execution order, by topic:
+// the processors selecting a topic (ProcessorConfig.Topic) make up the chain of its messages, the others
+// the default chain (under ""), which the messages of the topics without chain go through.
+func ProcessorChains(processors []ProcessorConfig) map[string][]int {
+	chains := make(map[string][]int)
+	for _, i := range ProcessorOrder(processors) {
+		chains[processors[i].Topic] = append(chains[processors[i].Topic], i)
+	}
+	return chains
+}
+
+func (pc ProcessorConfig) priority() int {
+	if pc.Priority == nil {
+		return 0
+	}
+	return *pc.Priority
+}
+
+// Lint looks for conflicts between the processors of each chain (see ProcessorChains), in execution order:
+// a field read after a processor only keeping the messages without it, two processors writing the same field,
+// and a timestamp_replay after a drop on a timestamp field.
+func Lint(processors []ProcessorConfig) []LintIssue {
+	chains := ProcessorChains(processors)
+	topics := make([]string, 0, len(chains))
+	for topic := range chains {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	var issues []LintIssue
+	for _, topic := range topics {
+		issues = append(issues, lintChain(processors, chains[topic])...)
+	}
+	return issues
+}
+
+// lintChain looks for the Lint conflicts between the processors of a chain, given by config index in execution order
+func lintChain(processors []ProcessorConfig, chain []int) []LintIssue {
+	var issues []LintIssue
+
+	absent := make(map[string]int)  // Fields missing from every kept message, with the index of the processor dropping the others
+	written := make(map[string]int) // Fields written, with the index of the last writer
+	timestampDrop := -1
+
+	for _, i := range chain {
+		pc := processors[i]
+
+		for _, field := range pc.readFields() {
+			if j, ok := absent[field]; ok {
+				issues = append(issues, LintIssue{
+					Processors: []int{j, i},
+					Message:    fmt.Sprintf("%s reads field %q, absent from every message kept by %s", pc.Type, field, processors[j].Type),
+				})
+			}
+		}
+
+		if pc.Type == ProcessorTypeTimestampReplay && timestampDrop >= 0 {
+			issues = append(issues, LintIssue{
+				Processors: []int{timestampDrop, i},
+				Message:    fmt.Sprintf("timestamp_replay runs after %s filtering on a timestamp field, the filter applies to the original time", processors[timestampDrop].Type),
+			})
+		}
+		if field := pc.dropField(); field != "" && isTimestampField(field) && timestampDrop < 0 {
+			timestampDrop = i
+		}
+
+		for _, field := range pc.writtenFields() {
+			if j, ok := written[field]; ok {
+				issues = append(issues, LintIssue{
+					Processors: []int{j, i},
+					Message:    fmt.Sprintf("%s and %s both write field %q", processors[j].Type, pc.Type, field),
+				})
+			}
+			written[field] = i
+			delete(absent, field)
+		}
+
+		if field, ok := pc.removedField(); ok {
+			absent[field] = i
+		}
+	}
+
+	return issues
+}
+
+// readFields returns the fields the processor reads
+func (pc ProcessorConfig) readFields() []string {
+	switch pc.Type {
+	case ProcessorTypeTransform, ProcessorTypeBucket, ProcessorTypeExplode, ProcessorTypeEpochConvert, ProcessorTypeNormalize, ProcessorTypeFormatNumber:
+		return pc.stringFields("field_name")
+	case ProcessorTypeDrop:
+		conditions, ok := pc.Config["conditions"].([]interface{})
+		if !ok {
+			return pc.stringFields("field_name")
+		}
+		var names []string
+		for _, c := range conditions {
+			when, _ := c.(map[string]interface{})
+			if field, ok := when["field"].(string); ok {
+				names = append(names, field)
+			}
+		}
+		return names
+	case ProcessorTypeCopy:
+		return pc.stringFields("source_field")
+	case ProcessorTypeDedupAdjacent, ProcessorTypeLookup:
+		return pc.stringFields("key_field")
+	case ProcessorTypeAggregate:
+		return append(pc.stringFields("group_by"), pc.stringFields("agg_field")...)
+	case ProcessorTypeFingerprint, ProcessorTypeRequire:
+		return pc.stringList("fields")
+	case ProcessorTypeMerge, ProcessorTypeCoalesce:
+		return pc.stringList("source_fields")
+	case ProcessorTypeHeaderField:
+		if pc.Config["direction"] == "to_header" {
+			return pc.stringFields("field_name")
+		}
+	case ProcessorTypeHeadersObject:
+		if pc.Config["direction"] == "to_headers" {
+			return pc.stringFields("target_field")
+		}
+	case ProcessorTypeRoute:
+		rules, _ := pc.Config["rules"].([]interface{})
+		var names []string
+		for _, r := range rules {
+			rule, _ := r.(map[string]interface{})
+			when, _ := rule["when"].(map[string]interface{})
+			if field, ok := when["field"].(string); ok {
+				names = append(names, field)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// writtenFields returns the fields the processor sets
+func (pc ProcessorConfig) writtenFields() []string {
+	switch pc.Type {
+	case ProcessorTypeEnrich:
+		if fields := pc.stringFields("added_field_name"); len(fields) > 0 {
+			return fields
+		}
+		return pc.stringFields("field_name")
+	case ProcessorTypeExtract, ProcessorTypeMerge, ProcessorTypeCopy, ProcessorTypeBucket, ProcessorTypeGenerateID, ProcessorTypeCoalesce, ProcessorTypeEpochConvert, ProcessorTypeTemplate, ProcessorTypeFormatNumber, ProcessorTypeLookup:
+		return pc.stringFields("target_field")
+	case ProcessorTypeNormalize:
+		return pc.stringFields("flag_field")
+	case ProcessorTypeFingerprint:
+		if fields := pc.stringFields("target_field"); len(fields) > 0 {
+			return fields
+		}
+		return []string{"_fingerprint"}
+	case ProcessorTypeRoute:
+		if fields := pc.stringFields("target_field"); len(fields) > 0 {
+			return fields
+		}
+		return []string{"_route"}
+	case ProcessorTypeExplode:
+		return []string{"_key", "_value"}
+	case ProcessorTypeHeaderField:
+		if pc.Config["direction"] == "to_field" {
+			return pc.stringFields("field_name")
+		}
+	case ProcessorTypeHeadersObject:
+		if pc.Config["direction"] == "to_field" {
+			return pc.stringFields("target_field")
+		}
+	}
+	return nil
+}
+
+// removedField returns the field missing from every message the processor keeps
+func (pc ProcessorConfig) removedField() (string, bool) {
+	if pc.Type != ProcessorTypeFieldExists {
+		return "", false
+	}
+	field, ok := pc.Config["field_name"].(string)
+	if !ok {
+		return "", false
+	}
+	requirePresent, ok := pc.Config["require_present"].(bool)
+	if !ok {
+		requirePresent = true
+	}
+	keep := pc.Config["action"] != "drop"
+	return field, requirePresent != keep
+}
+
+// dropField returns the field the processor drops messages on
+func (pc ProcessorConfig) dropField() string {
+	if pc.Type != ProcessorTypeDrop && pc.Type != ProcessorTypeFieldExists {
+		return ""
+	}
+	field, _ := pc.Config["field_name"].(string)
+	return field
+}
+
+func (pc ProcessorConfig) stringFields(key string) []string {
+	if field, ok := pc.Config[key].(string); ok && field != "" {
+		return []string{field}
+	}
+	return nil
+}
+
+// stringList returns the strings of the list option key
+func (pc ProcessorConfig) stringList(key string) []string {
+	list, _ := pc.Config[key].([]interface{})
+	var names []string
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isTimestampField guesses from its name whether a field holds a time, e.g. "timestamp", "event_time" or "ts"
+func isTimestampField(field string) bool {
+	name := strings.ToLower(field[strings.LastIndex(field, ".")+1:])
+	return strings.Contains(name, "time") || name == "ts" || strings.HasSuffix(name, "_ts") || strings.HasSuffix(name, "_at")
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name       string
+		processors []ProcessorConfig
+		want       [][]int // Processors of each expected issue
+	}{
+		{
+			name: "No conflict",
+			processors: []ProcessorConfig{
+				{Type: "field_exists", Config: map[string]interface{}{"field_name": "email"}},
+				{Type: "transform", Config: map[string]interface{}{"field_name": "email", "operation": "lowercase"}},
+				{Type: "copy", Config: map[string]interface{}{"source_field": "email", "target_field": "contact"}},
+			},
+		},
+		{
+			name: "Transform on a field filtered out",
+			processors: []ProcessorConfig{
+				{Type: "field_exists", Config: map[string]interface{}{"field_name": "email", "action": "drop"}},
+				{Type: "passthrough"},
+				{Type: "transform", Config: map[string]interface{}{"field_name": "email", "operation": "lowercase"}},
+			},
+			want: [][]int{{0, 2}},
+		},
+		{
+			name: "Field written again after being filtered out",
+			processors: []ProcessorConfig{
+				{Type: "field_exists", Config: map[string]interface{}{"field_name": "email", "require_present": false}},
+				{Type: "enrich", Config: map[string]interface{}{"added_field_name": "email", "added_field_value": "none"}},
+				{Type: "transform", Config: map[string]interface{}{"field_name": "email", "operation": "uppercase"}},
+			},
+		},
+		{
+			name: "Two processors writing the same field",
+			processors: []ProcessorConfig{
+				{Type: "merge", Config: map[string]interface{}{"source_fields": []interface{}{"first", "last"}, "target_field": "name"}},
+				{Type: "copy", Config: map[string]interface{}{"source_field": "nickname", "target_field": "name"}},
+			},
+			want: [][]int{{0, 1}},
+		},
+		{
+			name: "Timestamp replay after a drop on timestamp",
+			processors: []ProcessorConfig{
+				{Type: "drop", Config: map[string]interface{}{"field_name": "event_time", "filter_criteria": "0"}},
+				{Type: "timestamp_replay", Config: map[string]interface{}{"offset": 1, "unit": "hours"}},
+			},
+			want: [][]int{{0, 1}},
+		},
+		{
+			name: "Execution order follows priority",
+			processors: []ProcessorConfig{
+				{Type: "timestamp_replay", Config: map[string]interface{}{"offset": 1, "unit": "hours"}},
+				{Type: "drop", Priority: intPtr(-1), Config: map[string]interface{}{"field_name": "timestamp", "filter_criteria": "0"}},
+			},
+			want: [][]int{{1, 0}},
+		},
+		{
+			name: "Chains of different topics linted apart",
+			processors: []ProcessorConfig{
+				{Type: "copy", Topic: "orders", Config: map[string]interface{}{"source_field": "id", "target_field": "ref"}},
+				{Type: "copy", Topic: "payments", Config: map[string]interface{}{"source_field": "id", "target_field": "ref"}},
+				{Type: "copy", Config: map[string]interface{}{"source_field": "code", "target_field": "ref"}},
+				{Type: "merge", Topic: "orders", Config: map[string]interface{}{"source_fields": []interface{}{"a", "b"}, "target_field": "ref"}},
+			},
+			want: [][]int{{0, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			for _, issue := range Lint(tt.processors) {
+				got = append(got, issue.Processors)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() issues processors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessorChains(t *testing.T) {
+	processors := []ProcessorConfig{
+		{Type: "passthrough", Topic: "orders"},
+		{Type: "passthrough"},
+		{Type: "passthrough", Topic: "orders", Priority: intPtr(-1)},
+		{Type: "passthrough", Topic: "payments"},
+	}
+
+	want := map[string][]int{"": {1}, "orders": {2, 0}, "payments": {3}}
+	if got := ProcessorChains(processors); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProcessorChains() = %v, want %v", got, want)
+	}
+}
+
+func TestLintIssueString(t *testing.T) {
+	issue := LintIssue{Processors: []int{0, 2}, Message: "conflict"}
+	if got := issue.String(); got != "processors 0, 2: conflict" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schemaField describes a processor config field: its JSON type (any type when empty), the values accepted,
+// the items of an array or the values of a map, and the fields of an object with known keys
+type schemaField struct {
+	Type       string
+	Enum       []string
+	Items      *schemaField
+	Properties map[string]schemaField
+}
+
+var (
+	stringField  = schemaField{Type: "string"}
+	integerField = schemaField{Type: "integer"}
+	numberField  = schemaField{Type: "number"}
+	boolField    = schemaField{Type: "boolean"}
+	stringsField = schemaField{Type: "array", Items: &stringField}
+)
+
+func enumField(values ...string) schemaField {
+	return schemaField{Type: "string", Enum: values}
+}
+
+// conditionField is a {field, operator, value} condition, see RouteValidator and DropValidator
+var conditionField = schemaField{Type: "object", Properties: map[string]schemaField{
+	"field":    stringField,
+	"operator": enumField(mapKeys(availableConditionOperators)...),
+	"value":    {},
+}}
+
+// processorConfigFields describes the config of each processor type, as read by its validator and its processor.
+// Every processor type has an entry, see TestProcessorConfigFields.
+var processorConfigFields = map[string]map[string]schemaField{
+	ProcessorTypeTimestampReplay: {
+		"target_timestamp": stringField,
+		"offset":           integerField,
+		"unit":             enumField(mapKeys(availableUnits)...),
+	},
+	ProcessorTypeDrop: {
+		"field_name":      stringField,
+		"filter_criteria": stringField,
+		"value_type":      enumField("string", "int", "float", "bool"),
+		"conditions":      {Type: "array", Items: &conditionField},
+		"match":           enumField("all", "any"),
+	},
+	ProcessorTypeTransform: {
+		"field_name": stringField,
+		"operation":  enumField(mapKeys(availableOperations)...),
+		"prefix":     stringField,
+		"suffix":     stringField,
+		"params":     {Type: "object"},
+
+		"keep_original_on_error": boolField,
+	},
+	ProcessorTypeEnrich: {
+		"field_name":        stringField,
+		"field_value":       {},
+		"added_field_name":  stringField,
+		"added_field_value": {},
+	},
+	ProcessorTypePassthrough: {},
+	ProcessorTypeHeaderField: {
+		"header":     stringField,
+		"direction":  enumField(mapKeys(availableDirections)...),
+		"field_name": stringField,
+	},
+	ProcessorTypeSample: {
+		"rate":  numberField,
+		"seed":  integerField,
+		"every": integerField,
+	},
+	ProcessorTypePace: {
+		"speed": numberField,
+	},
+	ProcessorTypeExtract: {
+		"path":         stringField,
+		"target_field": stringField,
+	},
+	ProcessorTypeMerge: {
+		"source_fields": stringsField,
+		"target_field":  stringField,
+		"separator":     stringField,
+		"template":      stringField,
+		"missing":       enumField("skip", "empty"),
+	},
+	ProcessorTypeCopy: {
+		"source_field": stringField,
+		"target_field": stringField,
+		"overwrite":    boolField,
+	},
+	ProcessorTypeRoute: {
+		"target_field": stringField,
+		"rules": {Type: "array", Items: &schemaField{Type: "object", Properties: map[string]schemaField{
+			"label":   stringField,
+			"default": boolField,
+			"when":    conditionField,
+		}}},
+	},
+	ProcessorTypeFieldExists: {
+		"field_name":      stringField,
+		"require_present": boolField,
+		"action":          enumField("keep", "drop"),
+	},
+	ProcessorTypeMaxAge: {
+		"max_age":         stringField,
+		"timestamp_field": stringField,
+		"timestamp_unit":  enumField("s", "ms"),
+	},
+	ProcessorTypeBucket: {
+		"field_name":   stringField,
+		"target_field": stringField,
+		"buckets": {Type: "array", Items: &schemaField{Type: "object", Properties: map[string]schemaField{
+			"max":   numberField,
+			"label": stringField,
+		}}},
+	},
+	ProcessorTypeExplode: {
+		"field_name": stringField,
+	},
+	ProcessorTypeAggregate: {
+		"group_by":         stringField,
+		"functions":        {Type: "array", Items: &schemaField{Type: "string", Enum: mapKeys(availableAggregateFunctions)}},
+		"agg_field":        stringField,
+		"window":           stringField,
+		"allowed_lateness": stringField,
+		"max_groups":       integerField,
+	},
+	ProcessorTypeProject: {
+		"include": stringsField,
+		"exclude": stringsField,
+	},
+	ProcessorTypeEmptyToNull: {
+		"fields": stringsField,
+		"mode":   enumField("nullify", "remove"),
+	},
+	ProcessorTypeSchema: {
+		"schema": {Type: "object", Items: &schemaField{Type: "string", Enum: mapKeys(validSchemaTypes)}},
+		"mode":   enumField("reject", "coerce"),
+	},
+	ProcessorTypeRenameKeys: {
+		"pattern":          stringField,
+		"replacement":      stringField,
+		"replacement_case": enumField("upper", "lower"),
+	},
+	ProcessorTypeGenerateID: {
+		"target_field": stringField,
+		"generator":    enumField("uuid", "sequence"),
+		"overwrite":    boolField,
+	},
+	ProcessorTypeKeyCase: {
+		"mode":   enumField("upper", "lower"),
+		"fields": stringsField,
+	},
+	ProcessorTypeHeadersObject: {
+		"direction":    enumField("to_field", "to_headers"),
+		"target_field": stringField,
+		"headers":      stringsField,
+	},
+	ProcessorTypeFieldLimits: {
+		"max_depth":  integerField,
+		"max_fields": integerField,
+	},
+	ProcessorTypeCoalesce: {
+		"source_fields": stringsField,
+		"target_field":  stringField,
+		"default":       {},
+	},
+	ProcessorTypeDedupAdjacent: {
+		"key_field": stringField,
+	},
+	ProcessorTypeEpochConvert: {
+		"field_name":   stringField,
+		"direction":    enumField("to_rfc3339", "to_epoch"),
+		"unit":         enumField("s", "ms", "us", "ns"),
+		"target_field": stringField,
+	},
+	ProcessorTypeNormalize: {
+		"field_name":           stringField,
+		"kind":                 enumField("email", "phone"),
+		"default_country_code": stringField,
+		"flag_field":           stringField,
+	},
+	ProcessorTypeFingerprint: {
+		"fields":       stringsField,
+		"algorithm":    enumField("sha256", "sha1", "md5", "fnv64a"),
+		"target_field": stringField,
+	},
+	ProcessorTypeTemplate: {
+		"template":     stringField,
+		"target_field": stringField,
+		"missing":      enumField("empty", "error"),
+	},
+	ProcessorTypeTap: {
+		"name":        stringField,
+		"sample_rate": numberField,
+	},
+	ProcessorTypeRequire: {
+		"fields":      stringsField,
+		"log_missing": boolField,
+	},
+	ProcessorTypeFormatNumber: {
+		"field_name":          stringField,
+		"target_field":        stringField,
+		"precision":           integerField,
+		"thousands_separator": stringField,
+		"decimal_separator":   stringField,
+		"currency_prefix":     stringField,
+	},
+	ProcessorTypeLookup: {
+		"key_field":      stringField,
+		"target_field":   stringField,
+		"file":           stringField,
+		"cache_ttl":      stringField,
+		"cache_max_size": integerField,
+	},
+}
+
+// sectionEnums holds the values accepted by the string options of the sections (or by the items of the lists of strings),
+// keyed by their yaml path
+func sectionEnums() map[string][]string {
+	formats := mapKeys(ValidFormats)
+	outputFormats := make([]string, 0, len(formats))
+	for _, format := range formats {
+		if format != string(FormatAuto) {
+			outputFormats = append(outputFormats, format)
+		}
+	}
+
+	return map[string][]string{
+		"input.format":                         formats,
+		"input.offset_reset":                   {"earliest", "latest"},
+		"input.json_numbers":                   {"int64", "float64"},
+		"input.payload_compression":            mapKeys(validPayloadCompressions),
+		"input.worker_affinity":                {"hash", "sticky"},
+		"input.isolation_level":                {"read_uncommitted", "read_committed"},
+		"input.key_format":                     mapKeys(validKeyFormats),
+		"output.type":                          {"kafka"},
+		"output.format":                        outputFormats,
+		"output.compression":                   {"none", "gzip", "snappy", "lz4", "zstd"},
+		"output.payload_compression":           mapKeys(validPayloadCompressions),
+		"output.key_format":                    mapKeys(validKeyFormats),
+		"output.non_finite_floats":             {NonFiniteError, NonFiniteNull, NonFiniteDropField},
+		"output.timestamp_source":              {TimestampSourceMessage, TimestampSourceNow},
+		"monitoring.metrics_export.type":       {"prometheus"},
+		"errors.policy":                        {ErrorPolicySkip, ErrorPolicyDrop, ErrorPolicyDLQ, ErrorPolicyFail},
+		"errors.dlq_envelope.fields":           mapKeys(DLQEnvelopeFields),
+		"errors.dlq_envelope.payload_encoding": {"base64", "raw"},
+	}
+}
+
+// JSONSchema describes the configuration file as a JSON Schema (draft-07), for the editors to validate and
+// autocomplete it. The sections are derived from the config structs and their yaml tags, the processors config
+// from processorConfigFields, keyed by type. Unknown fields are rejected, catching the typos before a run.
+func JSONSchema() map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(Config{}), "", sectionEnums())
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "etelgo configuration"
+	return schema
+}
+
+// structSchema describes the fields of a config struct, named by their yaml tag (the lowercased field name without)
+func structSchema(t reflect.Type, path string, enums map[string][]string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fieldPath := strings.TrimPrefix(path+"."+name, ".")
+
+		if field.Type == reflect.TypeOf([]ProcessorConfig{}) {
+			properties[name] = map[string]interface{}{"type": "array", "items": processorSchema()}
+			continue
+		}
+		prop := typeSchema(field.Type, fieldPath, enums)
+		if values, ok := enums[fieldPath]; ok {
+			// The values of a list apply to its items
+			if items, ok := prop["items"].(map[string]interface{}); ok {
+				items["enum"] = values
+			} else {
+				prop["enum"] = values
+			}
+		}
+		properties[name] = prop
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// typeSchema describes a field of a config struct by its Go type
+func typeSchema(t reflect.Type, path string, enums map[string][]string) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t, path, enums)
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), path, enums)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), path, enums)}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// processorSchema describes a processor entry, its config depending on its type
+func processorSchema() map[string]interface{} {
+	types := mapKeys(processorValidators)
+
+	var byType []interface{}
+	for _, processorType := range types {
+		byType = append(byType, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": processorType}},
+				"required":   []string{"type"},
+			},
+			"then": map[string]interface{}{"properties": map[string]interface{}{"config": objectSchema(processorConfigFields[processorType])}},
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":     map[string]interface{}{"type": "string", "enum": types},
+			"config":   map[string]interface{}{"type": "object"},
+			"priority": map[string]interface{}{"type": "integer"},
+			"topic":    map[string]interface{}{"type": "string"},
+		},
+		"required":             []string{"type"},
+		"additionalProperties": false,
+		"allOf":                byType,
+	}
+}
+
+// objectSchema describes an object with known fields, any other field being rejected
+func objectSchema(fields map[string]schemaField) map[string]interface{} {
+	properties := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		properties[name] = field.schema()
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (f schemaField) schema() map[string]interface{} {
+	if f.Properties != nil {
+		return objectSchema(f.Properties)
+	}
+
+	schema := make(map[string]interface{})
+	if f.Type != "" {
+		schema["type"] = f.Type
+	}
+	if f.Enum != nil {
+		schema["enum"] = f.Enum
+	}
+	if f.Items != nil {
+		if f.Type == "object" {
+			schema["additionalProperties"] = f.Items.schema()
+		} else {
+			schema["items"] = f.Items.schema()
+		}
+	}
+	return schema
+}
+
+// mapKeys returns the keys of a set, sorted
+func mapKeys[K ~string, V any](m map[K]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProcessorConfigFields(t *testing.T) {
+	for processorType := range processorValidators {
+		if _, ok := processorConfigFields[processorType]; !ok {
+			t.Errorf("processor %q has no config fields in the schema", processorType)
+		}
+	}
+	for processorType := range processorConfigFields {
+		if _, ok := processorValidators[processorType]; !ok {
+			t.Errorf("schema describes the config of %q, which is not a processor type", processorType)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+
+	// Every enum names a field of the config structs, a renamed field failing here
+	for path, values := range sectionEnums() {
+		node := schema
+		for _, name := range strings.Split(path, ".") {
+			properties, _ := node["properties"].(map[string]interface{})
+			node, _ = properties[name].(map[string]interface{})
+			if node == nil {
+				t.Fatalf("enum %s: no such field in the schema", path)
+			}
+		}
+		if items, ok := node["items"].(map[string]interface{}); ok {
+			node = items // The values of a list apply to its items
+		}
+		if got, _ := node["enum"].([]string); len(got) != len(values) {
+			t.Errorf("enum %s = %v, want %v", path, got, values)
+		}
+	}
+
+	processors := schema["properties"].(map[string]interface{})["processors"].(map[string]interface{})
+	items := processors["items"].(map[string]interface{})
+	if got := len(items["allOf"].([]interface{})); got != len(processorValidators) {
+		t.Errorf("expected a config shape per processor type, got %d for %d types", got, len(processorValidators))
+	}
+}
+
+func TestSchemaFieldSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		field schemaField
+		want  string
+	}{
+		{"Enum", enumField("all", "any"), `{"enum":["all","any"],"type":"string"}`},
+		{"Array", stringsField, `{"items":{"type":"string"},"type":"array"}`},
+		{"Map", processorConfigFields[ProcessorTypeSchema]["schema"], `{"additionalProperties":{"enum":["array","bool","float","int","object","string"],"type":"string"},"type":"object"}`},
+		{"Object", schemaField{Type: "object", Properties: map[string]schemaField{"label": stringField}}, `{"additionalProperties":false,"properties":{"label":{"type":"string"}},"type":"object"}`},
+		{"Any", schemaField{}, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.field.schema())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("schema() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"etelgo/metrics"
+	"fmt"
+)
+
+// StringValueField is the field holding the payload of a message decoded as plain text
+const StringValueField = "value"
+
+// confluentMagicByte starts the payloads framed by the Confluent schema registry serializers,
+// followed by the 4 bytes schema id then the Avro or Protobuf encoded record.
+const confluentMagicByte = 0x00
+
+// Payload formats detected by the AutoDeserializer, counted in etelgo_consumed_records_by_format_total
+// so that a format drift in a topic shows up over time
+const (
+	autoFormatJSON           = "json"
+	autoFormatJSONArray      = "json_array"
+	autoFormatMsgpack        = "msgpack"
+	autoFormatSchemaRegistry = "schema_registry"
+	autoFormatText           = "text"
+)
+
+var formatRecords = func() map[string]*metrics.Counter {
+	counters := make(map[string]*metrics.Counter)
+	for _, format := range []string{autoFormatJSON, autoFormatJSONArray, autoFormatMsgpack, autoFormatSchemaRegistry, autoFormatText} {
+		counters[format] = metrics.Default.Counter("etelgo_consumed_records_by_format_total", "format", format)
+	}
+	return counters
+}()
+
+// AutoDeserializer picks the decoder of each message from its first bytes, for topics of unknown or mixed formats:
+//   - '{' (after whitespace) : JSON object
+//   - '[' (after whitespace) : JSON array, wrapped as {"value": [...]}
+//   - 0x80-0x8f, 0xde, 0xdf : MessagePack map, those bytes can't start an UTF-8 text
+//   - 0x00 followed by at least 4 bytes : Confluent schema registry framing, Avro and Protobuf can't be told
+//     apart without the registry and are not supported yet, the message is reported as a decoding error
+//   - anything else, or a JSON/MessagePack candidate failing to decode : plain text, as {"value": "<payload>"}
+//
+// Limits: a text payload starting with '{' or '[' but not valid JSON falls back to text, a JSON scalar
+// (number, string, boolean) is taken as text, and a binary payload that is neither framed nor MessagePack
+// is kept as text, its invalid UTF-8 sequences being replaced once encoded as JSON.
+type AutoDeserializer struct {
+	JSON *JSONDeserializer
+}
+
+func (d *AutoDeserializer) Deserialize(data []byte) (map[string]interface{}, error) {
+	result, format, err := d.detect(data)
+	formatRecords[format].Inc()
+	return result, err
+}
+
+// detect decodes the payload, returning the format it was decoded as
+func (d *AutoDeserializer) detect(data []byte) (map[string]interface{}, string, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		if result, err := d.JSON.Deserialize(data); err == nil {
+			return result, autoFormatJSON, nil
+		}
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		wrapped := make([]byte, 0, len(data)+len(StringValueField)+5)
+		wrapped = append(wrapped, `{"`+StringValueField+`":`...)
+		wrapped = append(wrapped, data...)
+		wrapped = append(wrapped, '}')
+		if result, err := d.JSON.Deserialize(wrapped); err == nil {
+			return result, autoFormatJSONArray, nil
+		}
+	case len(data) > 0 && isMsgpackMap(data[0]):
+		if result, err := (&MsgpackDeserializer{}).Deserialize(data); err == nil {
+			return result, autoFormatMsgpack, nil
+		}
+	case len(data) > 4 && data[0] == confluentMagicByte:
+		schemaID := binary.BigEndian.Uint32(data[1:5])
+		return nil, autoFormatSchemaRegistry, fmt.Errorf("schema registry payload (schema id %d): avro/protobuf decoding is not supported", schemaID)
+	}
+
+	return map[string]interface{}{StringValueField: string(data)}, autoFormatText, nil
+}
+
+// isMsgpackMap reports whether b is the first byte of a MessagePack map (fixmap, map 16 or map 32)
+func isMsgpackMap(b byte) bool {
+	return b&0xf0 == 0x80 || b == 0xde || b == 0xdf
+}
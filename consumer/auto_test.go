@@ -0,0 +1,70 @@
+package consumer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutoDeserializer(t *testing.T) {
+	d := &AutoDeserializer{JSON: &JSONDeserializer{}}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"JSON object", []byte(` {"id": 1}`), map[string]interface{}{"id": int64(1)}, ""},
+		{"JSON array", []byte(`[1, "a"]`), map[string]interface{}{"value": []interface{}{int64(1), "a"}}, ""},
+		{"Invalid JSON falls back to text", []byte(`{not json`), map[string]interface{}{"value": "{not json"}, ""},
+		{"MessagePack map", []byte{0x81, 0xa2, 'i', 'd', 0x01}, map[string]interface{}{"id": int64(1)}, ""},
+		{"Invalid MessagePack falls back to text", []byte{0x81, 0xa2}, map[string]interface{}{"value": "\x81\xa2"}, ""},
+		{"Plain text", []byte("hello world"), map[string]interface{}{"value": "hello world"}, ""},
+		{"JSON scalar read as text", []byte("42"), map[string]interface{}{"value": "42"}, ""},
+		{"Empty payload", []byte{}, map[string]interface{}{"value": ""}, ""},
+		{"Schema registry framing", []byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x02}, nil, "schema id 7"},
+		{"Short payload starting with 0x00", []byte{0x00, 0x01}, map[string]interface{}{"value": "\x00\x01"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.Deserialize(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Deserialize() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Deserialize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDeserializerFormatMetrics(t *testing.T) {
+	d := &AutoDeserializer{JSON: &JSONDeserializer{}}
+
+	tests := []struct {
+		data   []byte
+		format string
+	}{
+		{[]byte(`{"id": 1}`), autoFormatJSON},
+		{[]byte(`[1]`), autoFormatJSONArray},
+		{[]byte{0x81, 0xa2, 'i', 'd', 0x01}, autoFormatMsgpack},
+		{[]byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x02}, autoFormatSchemaRegistry},
+		{[]byte(`{not json`), autoFormatText},
+	}
+
+	for _, tt := range tests {
+		before := formatRecords[tt.format].Value()
+		d.Deserialize(tt.data)
+		if got := formatRecords[tt.format].Value() - before; got != 1 {
+			t.Errorf("%q: expected 1 record counted as %s, got %d", tt.data, tt.format, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// checkpointState persists the offsets of a consumer reading its partitions directly, outside of any consumer group.
+// The file holds the next offset to consume of each partition, e.g. {"orders": {"0": 42, "1": 17}}.
+// It is written periodically and on shutdown, a crash replaying at most the records processed since the last write.
+type checkpointState struct {
+	path     string
+	interval time.Duration
+	reset    kgo.Offset // Start of the partitions missing from the file
+
+	mu      sync.Mutex
+	offsets map[string]map[int32]int64
+	dirty   bool
+}
+
+func newCheckpointState(path string, interval time.Duration, offsetReset string) *checkpointState {
+	reset := kgo.NewOffset().AtEnd()
+	if offsetReset == "earliest" {
+		reset = kgo.NewOffset().AtStart()
+	}
+	return &checkpointState{
+		path:     path,
+		interval: interval,
+		reset:    reset,
+		offsets:  make(map[string]map[int32]int64),
+	}
+}
+
+// set records the next offset to consume of the partition
+func (cs *checkpointState) set(topic string, partition int32, next int64) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.offsets[topic] == nil {
+		cs.offsets[topic] = make(map[int32]int64)
+	}
+	cs.offsets[topic][partition] = next
+	cs.dirty = true
+}
+
+// save writes the offsets when they changed since the last write
+func (cs *checkpointState) save() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !cs.dirty {
+		return nil
+	}
+	if err := writeCheckpoint(cs.path, cs.offsets); err != nil {
+		return err
+	}
+	cs.dirty = false
+	return nil
+}
+
+// readCheckpoint reads the offsets of the checkpoint file, a missing file meaning no offsets yet
+func readCheckpoint(path string) (map[string]map[int32]int64, error) {
+	offsets := make(map[string]map[int32]int64)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return offsets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored map[string]map[string]int64
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	for topic, partitions := range stored {
+		offsets[topic] = make(map[int32]int64)
+		for partition, offset := range partitions {
+			p, err := strconv.ParseInt(partition, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid checkpoint file %s: partition %q of topic %s", path, partition, topic)
+			}
+			offsets[topic][int32(p)] = offset
+		}
+	}
+	return offsets, nil
+}
+
+// writeCheckpoint replaces the checkpoint file atomically: the offsets are written to a temporary file
+// in the same directory, synced, then renamed over the previous file. A crash leaves either version, never a partial one.
+func writeCheckpoint(path string, offsets map[string]map[int32]int64) error {
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// startFromCheckpoint starts consuming every partition of the input topics from the checkpoint file,
+// the partitions missing from it starting according to offset_reset.
+func (kc *KafkaConsumer) startFromCheckpoint(ctx context.Context) error {
+	stored, err := readCheckpoint(kc.checkpoint.path)
+	if err != nil {
+		return err
+	}
+
+	details, err := kadm.NewClient(kc.client).ListTopics(ctx, kc.topics...)
+	if err != nil {
+		return fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	consume := planCheckpoint(details, kc.partitions, stored, kc.checkpoint.reset)
+	kc.checkpoint.mu.Lock()
+	kc.checkpoint.offsets = stored
+	kc.checkpoint.mu.Unlock()
+
+	kc.logger.Info("Resuming from checkpoint", "file", kc.checkpoint.path, "stored_partitions", countPartitions(stored))
+	kc.client.AddConsumePartitions(consume)
+	return nil
+}
+
+// planCheckpoint computes the start offset of each partition, restricted to the configured partitions if any
+func planCheckpoint(details kadm.TopicDetails, partitions []int, stored map[string]map[int32]int64, reset kgo.Offset) map[string]map[int32]kgo.Offset {
+	allowed := make(map[int32]bool)
+	for _, p := range partitions {
+		allowed[int32(p)] = true
+	}
+
+	consume := make(map[string]map[int32]kgo.Offset)
+	for topic, detail := range details {
+		if detail.Err != nil {
+			continue
+		}
+		for partition := range detail.Partitions {
+			if len(allowed) > 0 && !allowed[partition] {
+				continue
+			}
+			if consume[topic] == nil {
+				consume[topic] = make(map[int32]kgo.Offset)
+			}
+			if next, ok := stored[topic][partition]; ok {
+				consume[topic][partition] = kgo.NewOffset().At(next)
+			} else {
+				consume[topic][partition] = reset
+			}
+		}
+	}
+	return consume
+}
+
+// saveCheckpoints writes the checkpoint file every interval until ctx is done
+func (kc *KafkaConsumer) saveCheckpoints(ctx context.Context) {
+	ticker := time.NewTicker(kc.checkpoint.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := kc.checkpoint.save(); err != nil {
+				kc.logger.Error("failed to save checkpoint", "file", kc.checkpoint.path, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package consumer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offsets.json")
+
+	offsets, err := readCheckpoint(path)
+	if err != nil || len(offsets) != 0 {
+		t.Fatalf("readCheckpoint() on a missing file = %v, %v, want no offsets", offsets, err)
+	}
+
+	cs := newCheckpointState(path, time.Second, "earliest")
+	cs.set("orders", 0, 42)
+	cs.set("orders", 3, 7)
+	if err := cs.save(); err != nil {
+		t.Fatalf("save() unexpected error = %v", err)
+	}
+
+	offsets, err = readCheckpoint(path)
+	if err != nil {
+		t.Fatalf("readCheckpoint() unexpected error = %v", err)
+	}
+	if offsets["orders"][0] != 42 || offsets["orders"][3] != 7 {
+		t.Errorf("readCheckpoint() = %v, want orders 0:42 3:7", offsets)
+	}
+
+	// No temporary file is left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the checkpoint file, got %d entries", len(entries))
+	}
+}
+
+func TestReadCheckpointInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offsets.json")
+	os.WriteFile(path, []byte(`{"orders": {"zero": 1}}`), 0o644)
+
+	if _, err := readCheckpoint(path); err == nil {
+		t.Errorf("readCheckpoint() error = nil, want an invalid partition error")
+	}
+}
+
+func TestPlanCheckpoint(t *testing.T) {
+	details := kadm.TopicDetails{
+		"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{0: {}, 1: {}, 2: {}}},
+	}
+	stored := map[string]map[int32]int64{"orders": {0: 42}}
+	reset := kgo.NewOffset().AtStart()
+
+	consume := planCheckpoint(details, nil, stored, reset)
+	if len(consume["orders"]) != 3 {
+		t.Fatalf("planCheckpoint() = %v, want the 3 partitions", consume)
+	}
+	if consume["orders"][0] != kgo.NewOffset().At(42) {
+		t.Errorf("planCheckpoint() partition 0 = %v, want the stored offset", consume["orders"][0])
+	}
+	if consume["orders"][1] != reset {
+		t.Errorf("planCheckpoint() partition 1 = %v, want the reset offset", consume["orders"][1])
+	}
+
+	consume = planCheckpoint(details, []int{0}, stored, reset)
+	if len(consume["orders"]) != 1 {
+		t.Errorf("planCheckpoint() with partitions = %v, want only partition 0", consume)
+	}
+}
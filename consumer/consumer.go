@@ -1,8 +1,13 @@
 package consumer
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"etelgo/config"
+	"fmt"
+	"io"
 	"time"
 )
 
@@ -18,8 +23,18 @@ type Message struct {
 	// Deserialized fields
 	KeyFields   map[string]interface{}
 	ValueFields map[string]interface{}
+
+	// Err is the decoding error of a message that could not be decompressed or deserialized,
+	// delivered without fields so that the workers apply the error policy to it
+	Err error
 }
 
+// W3C trace context headers, propagated from the input to the output messages
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
 type Consumer interface {
 	Start(ctx context.Context) error
 
@@ -34,18 +49,121 @@ type Deserializer interface {
 	Deserialize(data []byte) (map[string]interface{}, error)
 }
 
-type JSONDeserializer struct{}
+// JSONDeserializer decodes the JSON numbers into int64 when they are integers fitting in an int64, float64 otherwise.
+// Integers up to 2^63-1 keep their exact value, larger integers and decimals are subject to float64 precision (53 bits).
+// FloatNumbers restores the encoding/json behaviour of decoding every number into float64.
+type JSONDeserializer struct {
+	FloatNumbers bool
+}
 
 func (d *JSONDeserializer) Deserialize(data []byte) (map[string]interface{}, error) {
 	var result map[string]interface{}
-	err := json.Unmarshal(data, &result)
-	return result, err
+	if d.FloatNumbers {
+		err := json.Unmarshal(data, &result)
+		return result, err
+	}
+
+	if err := decodeJSON(data, &result); err != nil {
+		return nil, err
+	}
+	if _, err := normalizeNumbers(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DecodeJSON decodes any JSON value (object, array or scalar) with the JSONDeserializer numbers, int64 or float64.
+// The objects are decoded as map[string]interface{}, as the message fields.
+func DecodeJSON(data []byte) (interface{}, error) {
+	var result interface{}
+	if err := decodeJSON(data, &result); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(result)
+}
+
+// decodeJSON decodes a single JSON value into result, keeping the numbers as json.Number
+func decodeJSON(data []byte, result interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(result); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid JSON: unexpected data after top-level value")
+	}
+	return nil
+}
+
+// normalizeNumbers replaces recursively the json.Number values by int64 or float64.
+// Numbers out of the float64 range are rejected, as encoding/json does.
+func normalizeNumbers(value interface{}) (interface{}, error) {
+	var err error
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON number %s: %w", v, err)
+		}
+		return f, nil
+	case map[string]interface{}:
+		for key, val := range v {
+			if v[key], err = normalizeNumbers(val); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			if v[i], err = normalizeNumbers(val); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+// deserializerFor creates the deserializer of the input messages from a validated InputConfig
+func deserializerFor(cfg *config.InputConfig) Deserializer {
+	if cfg.Format == string(config.FormatCSV) {
+		return &CSVDeserializer{
+			Delimiter: []rune(*cfg.Csv.Delimiter)[0],
+			Header:    *cfg.Csv.Header,
+			Columns:   cfg.Csv.Columns,
+		}
+	}
+	if cfg.Format == string(config.FormatMsgpack) {
+		return &MsgpackDeserializer{}
+	}
+	jsonDeserializer := &JSONDeserializer{FloatNumbers: *cfg.Json_numbers == "float64"}
+	if cfg.Format == string(config.FormatAuto) {
+		return &AutoDeserializer{JSON: jsonDeserializer}
+	}
+	// For now, every other format is decoded as JSON
+	return jsonDeserializer
+}
+
+// keyDeserializerFor creates the deserializer of the input record keys from a validated InputConfig,
+// nil with the string key format: the raw key is kept and KeyFields is not set
+func keyDeserializerFor(cfg *config.InputConfig) Deserializer {
+	switch *cfg.Key_format {
+	case string(config.FormatString):
+		return nil
+	case string(config.FormatMsgpack):
+		return &MsgpackDeserializer{}
+	}
+	// As for the values, avro and protobuf are decoded as JSON for now
+	return &JSONDeserializer{FloatNumbers: *cfg.Json_numbers == "float64"}
 }
 
 func NewDeserializer(format string) Deserializer {
 	switch format {
 	case "json":
 		return &JSONDeserializer{}
+	case "msgpack":
+		return &MsgpackDeserializer{}
 	// case "avro":
 	//	return &AvroDeserializer{}
 	// case "protobuf":
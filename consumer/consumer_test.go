@@ -0,0 +1,109 @@
+package consumer
+
+import (
+	"testing"
+)
+
+func TestJSONDeserializerNumbers(t *testing.T) {
+	data := []byte(`{"count": 100, "price": 9.99, "id": 9007199254740993, "nested": {"items": [1, 2.5]}}`)
+
+	fields, err := (&JSONDeserializer{}).Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize() unexpected error = %v", err)
+	}
+	if v, ok := fields["count"].(int64); !ok || v != 100 {
+		t.Errorf("count = %#v, want int64(100)", fields["count"])
+	}
+	if v, ok := fields["price"].(float64); !ok || v != 9.99 {
+		t.Errorf("price = %#v, want float64(9.99)", fields["price"])
+	}
+	// 2^53 + 1, not representable as a float64
+	if v, ok := fields["id"].(int64); !ok || v != 9007199254740993 {
+		t.Errorf("id = %#v, want int64(9007199254740993)", fields["id"])
+	}
+	items := fields["nested"].(map[string]interface{})["items"].([]interface{})
+	if _, ok := items[0].(int64); !ok {
+		t.Errorf("nested items[0] = %#v, want an int64", items[0])
+	}
+	if _, ok := items[1].(float64); !ok {
+		t.Errorf("nested items[1] = %#v, want a float64", items[1])
+	}
+
+	fields, err = (&JSONDeserializer{FloatNumbers: true}).Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize() unexpected error = %v", err)
+	}
+	if _, ok := fields["count"].(float64); !ok {
+		t.Errorf("count = %#v, want a float64 with FloatNumbers", fields["count"])
+	}
+}
+
+func TestJSONDeserializerInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Malformed", `{"count": }`},
+		{"Trailing data", `{"count": 1} {}`},
+		{"Number out of range", `{"count": 1e400}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&JSONDeserializer{}).Deserialize([]byte(tt.data)); err == nil {
+				t.Errorf("Deserialize(%s) error = nil, wantErr = true", tt.data)
+			}
+		})
+	}
+}
+
+func TestCSVDeserializer(t *testing.T) {
+	tests := []struct {
+		name         string
+		deserializer *CSVDeserializer
+		data         string
+		want         map[string]interface{}
+		wantErr      bool
+	}{
+		{
+			"Header row",
+			&CSVDeserializer{Delimiter: ',', Header: true},
+			"id,name\n1,\"Doe, John\"\n",
+			map[string]interface{}{"id": "1", "name": "Doe, John"},
+			false,
+		},
+		{
+			"Configured columns",
+			&CSVDeserializer{Delimiter: ';', Columns: []string{"id", "name"}},
+			"1;John",
+			map[string]interface{}{"id": "1", "name": "John"},
+			false,
+		},
+		{"Missing value", &CSVDeserializer{Delimiter: ',', Columns: []string{"id", "name"}}, "1", nil, true},
+		{"Several data rows", &CSVDeserializer{Delimiter: ',', Header: true}, "id\n1\n2\n", nil, true},
+		{"Empty message", &CSVDeserializer{Delimiter: ',', Header: true}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.deserializer.Deserialize([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Deserialize() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deserialize() unexpected error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Deserialize() = %v, want %v", got, tt.want)
+			}
+			for key, val := range tt.want {
+				if got[key] != val {
+					t.Errorf("Deserialize()[%s] = %v, want %v", key, got[key], val)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVDeserializer decodes a message holding a single CSV data row, optionally preceded by a header row.
+// Every value is decoded as a string, no type is inferred.
+type CSVDeserializer struct {
+	Delimiter rune
+	Header    bool     // The message starts with a header row giving the column names
+	Columns   []string // Column names when there is no header row
+}
+
+func (d *CSVDeserializer) Deserialize(data []byte) (map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = d.Delimiter
+
+	columns := d.Columns
+	if d.Header {
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header row: %w", err)
+		}
+		columns = header
+	}
+
+	row, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV data row: %w", err)
+	}
+	if len(row) != len(columns) {
+		return nil, fmt.Errorf("CSV row has %d values, expected %d columns", len(row), len(columns))
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		return nil, fmt.Errorf("CSV message must hold a single data row")
+	}
+
+	fields := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		fields[column] = row[i]
+	}
+	return fields, nil
+}
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"etelgo/config"
+	"fmt"
+)
+
+// Headers added to the dead letter records by the producer (see outputs.HeaderDLQError), removed once unwrapped
+var deadLetterHeaders = []string{"etelgo-error", "etelgo-source-topic", "etelgo-source-partition", "etelgo-source-offset"}
+
+// deadLetterUnwrapper restores the original record of the dead letter records, see UnwrapDeadLetters
+type deadLetterUnwrapper struct {
+	envelope bool // The records are JSON envelopes, the original record being produced as is otherwise
+	raw      bool // The envelope payloads are strings rather than base64
+	key      bool // The envelope holds the original key
+	headers  bool // The envelope holds the original headers
+}
+
+// deadLetterRecord is the part of a dead letter envelope describing the original record
+type deadLetterRecord struct {
+	Key     *string           `json:"key"`
+	Value   *string           `json:"value"`
+	Headers map[string]string `json:"headers"`
+}
+
+// UnwrapDeadLetters delivers the original records of the dead letter records consumed instead, their key, value
+// and headers, to be processed again. envelope is the (validated) envelope the records were produced with, nil when
+// produced as consumed. The messages keep the position of the dead letter records, the offsets committed being theirs.
+// A record that can't be unwrapped is delivered with its error. It must be called before Start.
+func (kc *KafkaConsumer) UnwrapDeadLetters(envelope *config.DLQEnvelopeConfig) error {
+	unwrapper := &deadLetterUnwrapper{}
+	if envelope != nil {
+		fields := make(map[string]bool, len(envelope.Fields))
+		for _, field := range envelope.Fields {
+			fields[field] = true
+		}
+		if !fields["value"] {
+			return errors.New("the dead letter envelope has no value field, the original records can't be restored")
+		}
+		unwrapper.envelope = true
+		unwrapper.raw = *envelope.Payload_encoding == "raw"
+		unwrapper.key = fields["key"]
+		unwrapper.headers = fields["headers"]
+	}
+	kc.unwrapper = unwrapper
+	return nil
+}
+
+// unwrap replaces the dead letter record of msg by the original one
+func (u *deadLetterUnwrapper) unwrap(msg *Message) error {
+	for _, header := range deadLetterHeaders {
+		delete(msg.Headers, header)
+	}
+	if !u.envelope {
+		return nil
+	}
+
+	var record deadLetterRecord
+	if err := json.Unmarshal(msg.Value, &record); err != nil {
+		return fmt.Errorf("invalid dead letter envelope: %w", err)
+	}
+
+	value, err := u.payload(record.Value)
+	if err != nil {
+		return fmt.Errorf("invalid dead letter envelope value: %w", err)
+	}
+	msg.Value = value
+
+	// Without key in the envelope, the key of the dead letter record is the original one
+	if u.key {
+		if msg.Key, err = u.payload(record.Key); err != nil {
+			return fmt.Errorf("invalid dead letter envelope key: %w", err)
+		}
+	}
+	if u.headers {
+		msg.Headers = record.Headers
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+	}
+	return nil
+}
+
+func (u *deadLetterUnwrapper) payload(data *string) ([]byte, error) {
+	switch {
+	case data == nil:
+		return nil, nil
+	case u.raw:
+		return []byte(*data), nil
+	}
+	return base64.StdEncoding.DecodeString(*data)
+}
@@ -0,0 +1,71 @@
+package consumer
+
+import (
+	"encoding/base64"
+	"etelgo/config"
+	"reflect"
+	"testing"
+)
+
+func TestUnwrapDeadLetters(t *testing.T) {
+	base64Encoding, raw := "base64", "raw"
+	withHeaders := &config.DLQEnvelopeConfig{Fields: []string{"key", "value", "headers", "error"}, Payload_encoding: &base64Encoding}
+	rawValue := &config.DLQEnvelopeConfig{Fields: []string{"value", "topic"}, Payload_encoding: &raw}
+	dlqHeaders := map[string]string{"etelgo-error": "boom", "etelgo-source-topic": "orders", "etelgo-source-partition": "1", "etelgo-source-offset": "42", "trace": "abc"}
+	encoded := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name        string
+		envelope    *config.DLQEnvelopeConfig
+		key         string
+		value       string
+		wantKey     string
+		wantValue   string
+		wantHeaders map[string]string
+		wantErr     bool
+	}{
+		{"Record as consumed", nil, "k1", `{"id":1}`, "k1", `{"id":1}`, map[string]string{"trace": "abc"}, false},
+		{"Base64 envelope", withHeaders, "k1", `{"key":"` + encoded("k2") + `","value":"` + encoded(`{"id":2}`) + `","headers":{"h":"v"},"error":"boom"}`, "k2", `{"id":2}`, map[string]string{"h": "v"}, false},
+		{"Envelope without headers", withHeaders, "k1", `{"key":null,"value":"` + encoded(`{"id":3}`) + `"}`, "", `{"id":3}`, map[string]string{}, false},
+		{"Raw envelope without key", rawValue, "k1", `{"value":"{\"id\":4}","topic":"orders"}`, "k1", `{"id":4}`, map[string]string{"trace": "abc"}, false},
+		{"Tombstone", rawValue, "k1", `{"value":null}`, "k1", "", map[string]string{"trace": "abc"}, false},
+		{"Not an envelope", rawValue, "k1", `{"id":`, "", "", nil, true},
+		{"Invalid base64", withHeaders, "k1", `{"value":"%%%"}`, "", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := &KafkaConsumer{}
+			if err := kc.UnwrapDeadLetters(tt.envelope); err != nil {
+				t.Fatalf("UnwrapDeadLetters() unexpected error = %v", err)
+			}
+
+			headers := make(map[string]string, len(dlqHeaders))
+			for key, value := range dlqHeaders {
+				headers[key] = value
+			}
+			msg := &Message{Key: []byte(tt.key), Value: []byte(tt.value), Headers: headers}
+			err := kc.unwrapper.unwrap(msg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("unwrap() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unwrap() unexpected error = %v", err)
+			}
+			if string(msg.Key) != tt.wantKey || string(msg.Value) != tt.wantValue {
+				t.Errorf("unwrap() = key %q value %q, want key %q value %q", msg.Key, msg.Value, tt.wantKey, tt.wantValue)
+			}
+			if !reflect.DeepEqual(msg.Headers, tt.wantHeaders) {
+				t.Errorf("unwrap() headers = %v, want %v", msg.Headers, tt.wantHeaders)
+			}
+		})
+	}
+
+	noValue := &config.DLQEnvelopeConfig{Fields: []string{"key", "error"}, Payload_encoding: &base64Encoding}
+	if err := (&KafkaConsumer{}).UnwrapDeadLetters(noValue); err == nil {
+		t.Errorf("UnwrapDeadLetters() without value field error = nil, want an error")
+	}
+}
@@ -0,0 +1,53 @@
+package consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompressor decodes the message level compression of a payload.
+// It is distinct from the Kafka batch compression, which franz-go handles transparently.
+type decompressor func(data []byte) ([]byte, error)
+
+// newDecompressor returns the decompressor of the codec, nil when the payloads are not compressed
+func newDecompressor(codec string) (decompressor, error) {
+	switch codec {
+	case "", "none":
+		return nil, nil
+	case "gzip":
+		return gunzip, nil
+	case "zstd":
+		// A single decoder is safe for concurrent use through DecodeAll
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		return func(data []byte) ([]byte, error) {
+			out, err := decoder.DecodeAll(data, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid zstd payload: %w", err)
+			}
+			return out, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload compression: %s", codec)
+	}
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip payload: %w", err)
+	}
+	defer reader.Close()
+
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip payload: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompressor(t *testing.T) {
+	payload := []byte(`{"id": 1}`)
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(payload)
+	writer.Close()
+
+	encoder, _ := zstd.NewWriter(nil)
+	zstded := encoder.EncodeAll(payload, nil)
+
+	tests := []struct {
+		name    string
+		codec   string
+		data    []byte
+		wantErr bool
+	}{
+		{"Gzip", "gzip", gzipped.Bytes(), false},
+		{"Zstd", "zstd", zstded, false},
+		{"Truncated gzip", "gzip", gzipped.Bytes()[:gzipped.Len()-4], true},
+		{"Corrupt zstd", "zstd", append([]byte{0x00}, zstded...), true},
+		{"Not compressed", "gzip", payload, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decompress, err := newDecompressor(tt.codec)
+			if err != nil {
+				t.Fatalf("newDecompressor() unexpected error = %v", err)
+			}
+			got, err := decompress(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decompress() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decompress() unexpected error = %v", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("decompress() = %q, want %q", got, payload)
+			}
+		})
+	}
+
+	if decompress, err := newDecompressor("none"); err != nil || decompress != nil {
+		t.Errorf("newDecompressor(none) = %v, %v, want no decompressor", decompress, err)
+	}
+	if _, err := newDecompressor("brotli"); err == nil {
+		t.Errorf("newDecompressor(brotli) error = nil, wantErr = true")
+	}
+}
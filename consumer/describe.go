@@ -0,0 +1,114 @@
+package consumer
+
+import (
+	"context"
+	"etelgo/config"
+	"fmt"
+	"log/slog"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// TopicDescription describes the partitions of a topic, with the progress of the consumer group when one is configured
+type TopicDescription struct {
+	Topic      string                 `json:"topic"`
+	Group      string                 `json:"group,omitempty"`
+	Partitions []PartitionDescription `json:"partitions"`
+}
+
+// PartitionDescription describes a partition: its replicas, its watermarks and, with a consumer group,
+// the committed offset and the lag (nil when the group has no committed offset on the partition).
+type PartitionDescription struct {
+	Partition int32   `json:"partition"`
+	Leader    int32   `json:"leader"`
+	Replicas  []int32 `json:"replicas"`
+	ISR       []int32 `json:"isr"`
+	Low       int64   `json:"low_watermark"`
+	High      int64   `json:"high_watermark"`
+	Committed *int64  `json:"committed,omitempty"`
+	Lag       *int64  `json:"lag,omitempty"`
+}
+
+// DescribeTopics describes the topics with the input brokers, ordered as given.
+// The consumer group offsets are described unless the input uses a checkpoint file instead.
+func DescribeTopics(ctx context.Context, cfg *config.InputConfig, topics []string, logger *slog.Logger) ([]TopicDescription, error) {
+	client, err := kgo.NewClient(kgo.SeedBrokers(cfg.Brokers...))
+	if err != nil {
+		logger.Error("failed to create Kafka client", "error", err)
+		return nil, err
+	}
+	defer client.Close()
+	admin := kadm.NewClient(client)
+
+	details, err := admin.ListTopics(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+	for _, topic := range topics {
+		detail, ok := details[topic]
+		if !ok {
+			return nil, fmt.Errorf("topic %q does not exist", topic)
+		}
+		if detail.Err != nil {
+			return nil, fmt.Errorf("topic %q is not available: %w", topic, detail.Err)
+		}
+	}
+
+	starts, err := admin.ListStartOffsets(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list start offsets: %w", err)
+	}
+	ends, err := admin.ListEndOffsets(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list end offsets: %w", err)
+	}
+
+	group := ""
+	var committed kadm.OffsetResponses
+	if cfg.Checkpoint_file == nil {
+		group = cfg.ConsumerGroup
+		committed, err = admin.FetchOffsets(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the offsets of consumer group %q: %w", group, err)
+		}
+	}
+
+	return describeTopics(topics, details, starts, ends, group, committed), nil
+}
+
+// describeTopics assembles the descriptions from the admin responses, partitions in order.
+// The lag is the distance from the committed offset to the high watermark.
+func describeTopics(topics []string, details kadm.TopicDetails, starts, ends kadm.ListedOffsets, group string, committed kadm.OffsetResponses) []TopicDescription {
+	descriptions := make([]TopicDescription, 0, len(topics))
+	for _, topic := range topics {
+		description := TopicDescription{Topic: topic, Group: group, Partitions: []PartitionDescription{}}
+		for _, p := range details[topic].Partitions.Sorted() {
+			partition := PartitionDescription{
+				Partition: p.Partition,
+				Leader:    p.Leader,
+				Replicas:  p.Replicas,
+				ISR:       p.ISR,
+				Low:       -1,
+				High:      -1,
+			}
+			if start, ok := starts.Lookup(topic, p.Partition); ok && start.Err == nil {
+				partition.Low = start.Offset
+			}
+			if end, ok := ends.Lookup(topic, p.Partition); ok && end.Err == nil {
+				partition.High = end.Offset
+			}
+			if offset, ok := committed.Lookup(topic, p.Partition); ok && offset.Err == nil && offset.At >= 0 {
+				at := offset.At
+				partition.Committed = &at
+				if partition.High >= 0 {
+					lag := max(partition.High-at, 0)
+					partition.Lag = &lag
+				}
+			}
+			description.Partitions = append(description.Partitions, partition)
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
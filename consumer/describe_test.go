@@ -0,0 +1,53 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestDescribeTopics(t *testing.T) {
+	details := kadm.TopicDetails{
+		"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+			1: {Topic: "orders", Partition: 1, Leader: 2, Replicas: []int32{2, 3}, ISR: []int32{2}},
+			0: {Topic: "orders", Partition: 0, Leader: 1, Replicas: []int32{1, 2}, ISR: []int32{1, 2}},
+		}},
+	}
+	starts := kadm.ListedOffsets{"orders": {
+		0: {Topic: "orders", Partition: 0, Offset: 5},
+		1: {Topic: "orders", Partition: 1, Err: kerr.NotLeaderForPartition},
+	}}
+	ends := kadm.ListedOffsets{"orders": {
+		0: {Topic: "orders", Partition: 0, Offset: 100},
+		1: {Topic: "orders", Partition: 1, Offset: 30},
+	}}
+	committed := kadm.OffsetResponses{"orders": {
+		0: {Offset: kadm.Offset{Topic: "orders", Partition: 0, At: 90}},
+	}}
+
+	descriptions := describeTopics([]string{"orders"}, details, starts, ends, "etl", committed)
+	if len(descriptions) != 1 || len(descriptions[0].Partitions) != 2 {
+		t.Fatalf("describeTopics() = %+v, want one topic with 2 partitions", descriptions)
+	}
+	if descriptions[0].Group != "etl" {
+		t.Errorf("describeTopics() group = %q, want etl", descriptions[0].Group)
+	}
+
+	p0, p1 := descriptions[0].Partitions[0], descriptions[0].Partitions[1]
+	if p0.Partition != 0 || p0.Leader != 1 || p0.Low != 5 || p0.High != 100 {
+		t.Errorf("describeTopics() partition 0 = %+v", p0)
+	}
+	if p0.Committed == nil || *p0.Committed != 90 || p0.Lag == nil || *p0.Lag != 10 {
+		t.Errorf("describeTopics() partition 0 committed = %v, lag = %v, want 90 and 10", p0.Committed, p0.Lag)
+	}
+	if p1.Low != -1 || p1.High != 30 || p1.Committed != nil || p1.Lag != nil {
+		t.Errorf("describeTopics() partition 1 = %+v, want unknown low watermark and no committed offset", p1)
+	}
+
+	// Without a consumer group
+	descriptions = describeTopics([]string{"orders"}, details, starts, ends, "", nil)
+	if descriptions[0].Group != "" || descriptions[0].Partitions[0].Lag != nil {
+		t.Errorf("describeTopics() without group = %+v", descriptions[0])
+	}
+}
@@ -2,9 +2,20 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"etelgo/config"
+	"etelgo/metrics"
+	"fmt"
 	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
 )
 
@@ -31,21 +42,136 @@ func FromKafkaFranz(record *kgo.Record) *Message {
 	}
 }
 
+var (
+	consumerPaused = metrics.Default.Gauge("etelgo_consumer_paused")
+	consumerPauses = metrics.Default.Counter("etelgo_consumer_pauses_total")
+	oversized      = metrics.Default.Counter("etelgo_consumer_oversized_messages_total")
+	consumed       = metrics.Default.Counter("etelgo_consumed_records_total")
+)
+
+// errTopicRegex rejects the consumers reading a list of topics, the ones matching topic_regex being only known to the consumer group
+var errTopicRegex = errors.New("topic_regex is only supported by the consumer group, list the topics with topic or topics instead")
+
+// batchCodecs names the Kafka batch compression codecs, indexed by the compression type of the record attributes
+var batchCodecs = []string{"none", "gzip", "snappy", "lz4", "zstd"}
+
+// codecRecords counts the consumed records by batch compression codec, to follow the codecs mix of the topics
+var codecRecords = func() []*metrics.Counter {
+	counters := make([]*metrics.Counter, len(batchCodecs))
+	for i, codec := range batchCodecs {
+		counters[i] = metrics.Default.Counter("etelgo_consumed_records_by_codec_total", "codec", codec)
+	}
+	return counters
+}()
+
+// countCodec counts the record by its batch compression codec, an unknown codec being counted as "unknown"
+func countCodec(record *kgo.Record) {
+	if codec := int(record.Attrs.CompressionType()); codec < len(codecRecords) {
+		codecRecords[codec].Inc()
+		return
+	}
+	metrics.Default.Counter("etelgo_consumed_records_by_codec_total", "codec", "unknown").Inc()
+}
+
 type KafkaConsumer struct {
-	client   *kgo.Client
-	logger   *slog.Logger
-	messages chan *Message
-	errors   chan error
+	client     *kgo.Client
+	logger     *slog.Logger
+	messages   chan *Message
+	errors     chan error
+	topics     []string
+	topicRegex *regexp.Regexp // Subscribed topics pattern, topics being empty, see InputConfig.Topic_regex
+	partitions []int
+
+	readCommitted bool // Fetching only committed records, see isolationLevel
+
+	deserializer    Deserializer
+	keyDeserializer Deserializer         // nil with the string key format
+	decompress      decompressor         // nil when the payloads are not compressed
+	maxMessageBytes int                  // Largest value decoded, checked before and after decompression
+	rawValues       bool                 // The values are not deserialized, see ForwardRawValues
+	unwrapper       *deadLetterUnwrapper // nil unless consuming a dead letter topic, see UnwrapDeadLetters
+
+	connectRetries int
+	connectBackoff time.Duration
+
+	pollTimeout       time.Duration                     // Longest wait of a poll, see pollMessages
+	emptyFetchBackoff time.Duration                     // Pause after a poll without records
+	pollFetches       func(context.Context) kgo.Fetches // client.PollFetches when nil, replaced in tests
+
+	replay      *replayState     // Only set for a replay consumer, see NewKafkaReplayConsumer
+	offsets     *offsetTracker   // Completed records, not set for a replay consumer
+	checkpoint  *checkpointState // Only set when consuming without consumer group, see InputConfig.Checkpoint_file
+	closing     *atomic.Bool     // Set by Close, to skip the commit of the final revoke without commit_on_shutdown. Only set for a group member
+	closeCommit *atomic.Bool     // Whether the final revoke commits the offsets, see SkipCloseCommit. Only set for a group member
+	rebalance   *rebalanceHook   // Notified of the partitions assigned and revoked, see OnRebalance. Only set for a group member
+
+	pauseMu sync.Mutex
+	paused  bool
 	// Potentially other fields for configuration, state, etc.
 }
 
 func NewKafkaConsumer(cfg *config.InputConfig, logger *slog.Logger) (*KafkaConsumer, error) {
-	logger.Info("Creating new Kafka consumer", " brokers", cfg.Brokers, "topic", cfg.Topic, "group", cfg.ConsumerGroup)
+	topics := cfg.AllTopics()
+	logger.Info("Creating new Kafka consumer", " brokers", cfg.Brokers, "topics", topics, "group", cfg.ConsumerGroup)
 
-	kgoOpts := []kgo.Opt{
-		kgo.SeedBrokers(cfg.Brokers...),
-		kgo.ConsumerGroup(cfg.ConsumerGroup),
-		kgo.ConsumeTopics(cfg.Topic),
+	// With topic_regex, the consumer group subscribes to every topic matching it, including the ones created later
+	var topicRegex *regexp.Regexp
+	subscription := []kgo.Opt{kgo.ConsumeTopics(topics...)}
+	if cfg.Topic_regex != nil {
+		var err error
+		if topicRegex, err = regexp.Compile(*cfg.Topic_regex); err != nil {
+			return nil, fmt.Errorf("invalid topic_regex: %w", err)
+		}
+		subscription = []kgo.Opt{kgo.ConsumeTopics(*cfg.Topic_regex), kgo.ConsumeRegex()}
+		logger.Info("Subscribing to the topics matching the regex", "topic_regex", *cfg.Topic_regex)
+	}
+
+	// Only the offsets of the completed records are committed, see MarkDone
+	offsets := newOffsetTracker()
+	kgoOpts, err := clientOpts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// With a checkpoint file the partitions are consumed directly, they are only known once listed, see Seek
+	var checkpoint *checkpointState
+	var closing, closeCommit *atomic.Bool
+	var rebalance *rebalanceHook
+	if cfg.Checkpoint_file != nil {
+		interval, err := time.ParseDuration(*cfg.Checkpoint_interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint_interval: %w", err)
+		}
+		checkpoint = newCheckpointState(*cfg.Checkpoint_file, interval, *cfg.Offset_reset)
+	} else {
+		// Leaving the group on Close revokes every partition, committing the offsets like a shutdown does
+		closing = new(atomic.Bool)
+		closeCommit = new(atomic.Bool)
+		closeCommit.Store(*cfg.Commit_on_shutdown)
+		rebalance = &rebalanceHook{}
+		kgoOpts = append(kgoOpts, subscription...)
+		kgoOpts = append(kgoOpts,
+			kgo.ConsumerGroup(cfg.ConsumerGroup),
+			kgo.AutoCommitMarks(),
+			kgo.OnPartitionsAssigned(func(_ context.Context, _ *kgo.Client, assigned map[string][]int32) {
+				rebalance.notify(assigned, nil)
+			}),
+			kgo.OnPartitionsRevoked(func(ctx context.Context, cl *kgo.Client, revoked map[string][]int32) {
+				defer rebalance.notify(nil, revoked)
+				if closing.Load() && !closeCommit.Load() {
+					offsets.forget(revoked)
+					return
+				}
+				if err := cl.CommitMarkedOffsets(ctx); err != nil {
+					logger.Error("failed to commit offsets of revoked partitions", "error", err)
+				}
+				offsets.forget(revoked)
+			}),
+			kgo.OnPartitionsLost(func(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+				offsets.forget(lost)
+				rebalance.notify(nil, lost)
+			}),
+		)
 	}
 
 	client, err := kgo.NewClient(kgoOpts...)
@@ -54,21 +180,209 @@ func NewKafkaConsumer(cfg *config.InputConfig, logger *slog.Logger) (*KafkaConsu
 		return nil, err
 	}
 
+	connectBackoff, err := time.ParseDuration(*cfg.Connect_backoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connect_backoff: %w", err)
+	}
+
+	pollTimeout, emptyFetchBackoff, err := pollingDurations(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	decompress, err := newDecompressor(*cfg.Payload_compression)
+	if err != nil {
+		return nil, err
+	}
+
 	return &KafkaConsumer{
-		client:   client,
-		logger:   logger,
-		messages: make(chan *Message),
-		errors:   make(chan error),
+		client:     client,
+		logger:     logger,
+		messages:   make(chan *Message),
+		errors:     make(chan error),
+		topics:     topics,
+		topicRegex: topicRegex,
+		partitions: cfg.Partitions,
+
+		readCommitted: *cfg.Isolation_level == "read_committed",
+
+		deserializer:    deserializerFor(cfg),
+		keyDeserializer: keyDeserializerFor(cfg),
+		decompress:      decompress,
+		maxMessageBytes: *cfg.Max_message_bytes,
+
+		connectRetries: *cfg.Connect_retries,
+		connectBackoff: connectBackoff,
+
+		pollTimeout:       pollTimeout,
+		emptyFetchBackoff: emptyFetchBackoff,
+
+		offsets:     offsets,
+		checkpoint:  checkpoint,
+		closing:     closing,
+		closeCommit: closeCommit,
+		rebalance:   rebalance,
 	}, nil
 }
 
+// pollingDurations parses the poll_timeout and empty_fetch_backoff of a validated InputConfig
+func pollingDurations(cfg *config.InputConfig) (time.Duration, time.Duration, error) {
+	pollTimeout, err := time.ParseDuration(*cfg.Poll_timeout)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid poll_timeout: %w", err)
+	}
+	emptyFetchBackoff, err := time.ParseDuration(*cfg.Empty_fetch_backoff)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid empty_fetch_backoff: %w", err)
+	}
+	return pollTimeout, emptyFetchBackoff, nil
+}
+
+// metadataMinAge is the franz-go default minimum age of the metadata before a refresh triggered by an error
+const metadataMinAge = 5 * time.Second
+
+// clientOpts returns the franz-go options common to the consumers of a validated InputConfig
+func clientOpts(cfg *config.InputConfig) ([]kgo.Opt, error) {
+	metadataMaxAge, err := time.ParseDuration(*cfg.Metadata_max_age)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata_max_age: %w", err)
+	}
+
+	return []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.FetchIsolationLevel(isolationLevel(cfg)),
+		kgo.MetadataMaxAge(metadataMaxAge),
+		// The minimum age between two refreshes can't exceed the maximum one
+		kgo.MetadataMinAge(min(metadataMaxAge, metadataMinAge)),
+	}, nil
+}
+
+// isolationLevel maps the isolation_level of a validated InputConfig to the franz-go fetch option.
+// read_committed skips the records of aborted transactions and stops at the last stable offset,
+// as required to consume topics written by transactional (exactly once) producers.
+func isolationLevel(cfg *config.InputConfig) kgo.IsolationLevel {
+	if *cfg.Isolation_level == "read_committed" {
+		return kgo.ReadCommitted()
+	}
+	return kgo.ReadUncommitted()
+}
+
+// Connect checks the brokers are reachable, retrying with an exponential backoff and jitter.
+// franz-go connects lazily, so without this a broker restart at startup would only surface as fetch errors.
+func (kc *KafkaConsumer) Connect(ctx context.Context) error {
+	kc.logger.Info("Connecting to Kafka brokers")
+
+	err := retryWithBackoff(ctx, kc.connectRetries, kc.connectBackoff, kc.logger, kc.client.Ping)
+	if err != nil {
+		kc.logger.Error("failed to connect to Kafka brokers", "error", err)
+		return fmt.Errorf("failed to connect to Kafka brokers: %w", err)
+	}
+	return nil
+}
+
+// CheckTopic verifies through the admin client that every input topic exists
+// and that every configured partition is part of them, or that a topic matches the topic regex.
+// It is meant to be called once at startup to fail fast instead of polling an empty topic forever.
+func (kc *KafkaConsumer) CheckTopic(ctx context.Context) error {
+	if kc.topicRegex != nil {
+		kc.logger.Debug("Checking input topics matching the regex", "topic_regex", kc.topicRegex.String())
+		details, err := kadm.NewClient(kc.client).ListTopics(ctx)
+		if err != nil {
+			kc.logger.Error("failed to list topics", "error", err)
+			return fmt.Errorf("failed to list topics: %w", err)
+		}
+		matched, err := matchTopicRegex(kc.topicRegex, details)
+		if err != nil {
+			return err
+		}
+		kc.logger.Info("Input topics matching the regex", "topic_regex", kc.topicRegex.String(), "topics", matched)
+		return nil
+	}
+
+	kc.logger.Debug("Checking input topics existence", "topics", kc.topics)
+
+	details, err := kadm.NewClient(kc.client).ListTopics(ctx, kc.topics...)
+	if err != nil {
+		kc.logger.Error("failed to list topics", "error", err)
+		return fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	for _, topic := range kc.topics {
+		if err := checkTopicDetails(topic, kc.partitions, details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchTopicRegex returns the sorted topics of details matching regex, internal topics excepted like franz-go does,
+// failing when there is none. It holds the regex logic of CheckTopic, testable without a broker.
+func matchTopicRegex(regex *regexp.Regexp, details kadm.TopicDetails) ([]string, error) {
+	var matched []string
+	for _, topic := range details.Sorted() {
+		if topic.IsInternal || topic.Err != nil || !regex.MatchString(topic.Topic) {
+			continue
+		}
+		matched = append(matched, topic.Topic)
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no input topic matches topic_regex %q", regex.String())
+	}
+	return matched, nil
+}
+
+// checkTopicDetails holds the logic of CheckTopic, separated from the admin call to be testable without a broker.
+func checkTopicDetails(topic string, partitions []int, details kadm.TopicDetails) error {
+	detail, ok := details[topic]
+	if !ok {
+		return fmt.Errorf("input topic %q does not exist", topic)
+	}
+	if detail.Err != nil {
+		return fmt.Errorf("input topic %q is not available: %w", topic, detail.Err)
+	}
+
+	for _, p := range partitions {
+		if _, ok := detail.Partitions[int32(p)]; !ok {
+			return fmt.Errorf("partition %d does not exist in topic %q (%d partitions)", p, topic, len(detail.Partitions))
+		}
+	}
+
+	return nil
+}
+
+// Seek positions a replay consumer at the start of its window, and a checkpointed consumer at its stored offsets.
+// It is a no-op for a consumer group member, the group holding its offsets.
+func (kc *KafkaConsumer) Seek(ctx context.Context) error {
+	switch {
+	case kc.replay != nil:
+		return kc.startReplay(ctx)
+	case kc.checkpoint != nil:
+		return kc.startFromCheckpoint(ctx)
+	}
+	return nil
+}
+
+// Done is closed once a replay consumer delivered every record of its window.
+// It is never closed for a live consumer.
+func (kc *KafkaConsumer) Done() <-chan struct{} {
+	if kc.replay == nil {
+		return nil
+	}
+	return kc.replay.done
+}
+
 func (kc *KafkaConsumer) Start(ctx context.Context) {
 	kc.logger.Info("Starting Kafka consumer")
 
 	go kc.pollMessages(ctx)
+	if kc.checkpoint != nil {
+		go kc.saveCheckpoints(ctx)
+	}
 }
 
-// Poll messages from Kafka and send them to the messages channel, multiple select patterns to handle context cancellation
+// Poll messages from Kafka and send them to the messages channel, multiple select patterns to handle context cancellation.
+// Each poll waits at most pollTimeout, and a poll without records is followed by emptyFetchBackoff,
+// so that an idle topic or a failing broker doesn't make the loop spin.
 func (kc *KafkaConsumer) pollMessages(ctx context.Context) {
 	for {
 		select {
@@ -76,7 +390,9 @@ func (kc *KafkaConsumer) pollMessages(ctx context.Context) {
 			kc.logger.Info("Kafka consumer context done, stopping polling")
 			return
 		default:
-			fetches := kc.client.PollFetches(ctx)
+			pollStart := time.Now()
+			fetches := kc.poll(ctx)
+			observeFetch(fetches, time.Since(pollStart))
 
 			errs := fetches.Errors()
 			if len(errs) > 0 {
@@ -90,30 +406,263 @@ func (kc *KafkaConsumer) pollMessages(ctx context.Context) {
 				}
 			}
 
-			fetches.EachRecord(func(record *kgo.Record) {
-				msg := FromKafkaFranz(record)
+			fetches.EachPartition(updateLag)
 
-				deserializer := NewDeserializer("json") // For now, hardcoded to JSON
-				valueFields, err := deserializer.Deserialize(msg.Value)
-				if err != nil {
-					kc.logger.Error("failed to deserialize message value", "error", err)
-					select {
-					case kc.errors <- err:
-					case <-ctx.Done():
-						return
-					}
-				} else {
-					msg.ValueFields = valueFields
+			records := 0
+			fetches.EachRecord(func(record *kgo.Record) {
+				records++
+				deliver, finished := true, false
+				if kc.replay != nil {
+					deliver, finished = kc.replay.track(record)
 				}
+				if deliver {
+					kc.deliver(ctx, record)
+				}
+				if finished {
+					kc.finishPartition(record.Topic, record.Partition)
+				}
+			})
 
+			if records == 0 && kc.emptyFetchBackoff > 0 {
 				select {
-				case kc.messages <- msg:
+				case <-time.After(kc.emptyFetchBackoff):
 				case <-ctx.Done():
-					return
 				}
-			})
+			}
+		}
+	}
+}
+
+// poll fetches the next records, waiting at most pollTimeout. The timeout of an idle poll is not an error,
+// the fetches are then empty.
+func (kc *KafkaConsumer) poll(ctx context.Context) kgo.Fetches {
+	pollFetches := kc.pollFetches
+	if pollFetches == nil {
+		pollFetches = kc.client.PollFetches
+	}
+	if kc.pollTimeout <= 0 {
+		return pollFetches(ctx)
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, kc.pollTimeout)
+	defer cancel()
+	fetches := pollFetches(pollCtx)
+	if ctx.Err() == nil && fetches.NumRecords() == 0 && errors.Is(fetches.Err0(), context.DeadlineExceeded) {
+		return nil
+	}
+	return fetches
+}
+
+var (
+	// fetchLatencyBuckets are the upper bounds in seconds of the fetch latency, up to the longest max_wait_time in use
+	fetchLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// fetchRecordsBuckets are the upper bounds of the records per fetch
+	fetchRecordsBuckets = []float64{1, 10, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+)
+
+// observeFetch records the latency and the number of records of a poll by topic, to tune min_bytes, max_bytes and
+// max_wait_time: a poll returning few records long after it started waits on min_bytes or max_wait_time, one always
+// returning many records quickly is capped by max_bytes. A poll without records, idle or timed out, isn't observed.
+func observeFetch(fetches kgo.Fetches, latency time.Duration) {
+	records := make(map[string]int)
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if len(p.Records) > 0 {
+			records[p.Topic] += len(p.Records)
+		}
+	})
+	for topic, n := range records {
+		metrics.Default.Histogram("etelgo_consumer_fetch_latency_seconds", fetchLatencyBuckets, "topic", topic).Observe(latency.Seconds())
+		metrics.Default.Histogram("etelgo_consumer_fetch_records", fetchRecordsBuckets, "topic", topic).Observe(float64(n))
+	}
+}
+
+// updateLag sets the lag of the partition after its last fetched record: the records left to fetch up to the high watermark
+func updateLag(p kgo.FetchTopicPartition) {
+	if len(p.Records) == 0 {
+		return
+	}
+	last := p.Records[len(p.Records)-1]
+	metrics.Default.Gauge("etelgo_consumer_lag", "topic", p.Topic, "partition", strconv.Itoa(int(p.Partition))).Set(p.HighWatermark - last.Offset - 1)
+}
+
+// deliver decompresses and deserializes the record, then sends it to the messages channel.
+// A record failing to decode, or larger than max_message_bytes, is delivered with its Err set and no fields,
+// the workers applying the error policy to it.
+// The record is tracked before being sent, so that a record not handed over
+// on shutdown holds back the commit.
+func (kc *KafkaConsumer) deliver(ctx context.Context, record *kgo.Record) {
+	msg := FromKafkaFranz(record)
+	consumed.Inc()
+	countCodec(record)
+	if kc.offsets != nil {
+		kc.offsets.deliver(record.Topic, record.Partition, record.Offset, record.LeaderEpoch)
+	}
+
+	var err error
+	if kc.unwrapper != nil {
+		err = kc.unwrapper.unwrap(msg)
+	}
+	if err == nil {
+		err = kc.decode(msg)
+	}
+	if err != nil {
+		msg.Err = err
+	}
+
+	select {
+	case kc.messages <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// decode decompresses and deserializes the message value (unless forwarded raw), then deserializes the key unless its format is string.
+// The size is checked before decompressing then on the decompressed value, the limit applying to what is decoded.
+// A panicking decompressor or deserializer is recovered and reported as an error of the message,
+// so that one bad record can't take down the consumer.
+func (kc *KafkaConsumer) decode(msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			kc.logger.Error("panic while decoding message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("message %s/%d@%d: decoder panic: %v", msg.Topic, msg.Partition, msg.Offset, r)
+		}
+	}()
+
+	if err := kc.checkSize(msg); err != nil {
+		return err
+	}
+
+	if kc.decompress != nil {
+		value, err := kc.decompress(msg.Value)
+		if err != nil {
+			return fmt.Errorf("failed to decompress message value: %w", err)
+		}
+		msg.Value = value
+		if err := kc.checkSize(msg); err != nil {
+			return err
+		}
+	}
+
+	if !kc.rawValues {
+		valueFields, err := kc.deserializer.Deserialize(msg.Value)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize message value: %w", err)
+		}
+		msg.ValueFields = valueFields
+	}
+
+	// A record without key has no KeyFields
+	if kc.keyDeserializer != nil && len(msg.Key) > 0 {
+		keyFields, err := kc.keyDeserializer.Deserialize(msg.Key)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize message key: %w", err)
 		}
+		msg.KeyFields = keyFields
+	}
+	return nil
+}
+
+// ForwardRawValues stops deserializing the message values, delivered with their (decompressed) Value only,
+// for the pipelines never reading ValueFields: the output then produces the value as is. It must be called before Start.
+func (kc *KafkaConsumer) ForwardRawValues() {
+	kc.rawValues = true
+}
+
+// checkSize rejects a message value larger than max_message_bytes
+func (kc *KafkaConsumer) checkSize(msg *Message) error {
+	if kc.maxMessageBytes <= 0 || len(msg.Value) <= kc.maxMessageBytes {
+		return nil
+	}
+	oversized.Inc()
+	return fmt.Errorf("message %s/%d@%d: value of %d bytes exceeds max_message_bytes (%d)", msg.Topic, msg.Partition, msg.Offset, len(msg.Value), kc.maxMessageBytes)
+}
+
+// finishPartition stops fetching a replayed partition, and signals the end of the replay after the last one
+func (kc *KafkaConsumer) finishPartition(topic string, partition int32) {
+	kc.client.RemoveConsumePartitions(map[string][]int32{topic: {partition}})
+	kc.logger.Debug("Partition replayed", "topic", topic, "partition", partition)
+	if kc.replay.finish(topic, partition) {
+		kc.logger.Info("Replay window fully consumed")
+		close(kc.replay.done)
+	}
+}
+
+// Pause stops fetching the input topics, the records already fetched are still delivered.
+// It is used as backpressure while the output can't keep up. With topic_regex the topics paused are the ones
+// matched so far, a topic matched while paused being fetched until the next pause.
+func (kc *KafkaConsumer) Pause() {
+	kc.pauseMu.Lock()
+	defer kc.pauseMu.Unlock()
+	if kc.paused {
+		return
+	}
+
+	kc.client.PauseFetchTopics(kc.consumedTopics()...)
+	kc.paused = true
+	consumerPaused.Set(1)
+	consumerPauses.Inc()
+	kc.logger.Warn("Input fetching paused")
+}
+
+// Resume restarts fetching the input topics paused by Pause
+func (kc *KafkaConsumer) Resume() {
+	kc.pauseMu.Lock()
+	defer kc.pauseMu.Unlock()
+	if !kc.paused {
+		return
+	}
+
+	kc.client.ResumeFetchTopics(kc.client.PauseFetchTopics()...)
+	kc.paused = false
+	consumerPaused.Set(0)
+	kc.logger.Info("Input fetching resumed")
+}
+
+// consumedTopics returns the topics fetched by the consumer, with topic_regex the topics matching it so far
+func (kc *KafkaConsumer) consumedTopics() []string {
+	if kc.topicRegex != nil {
+		return kc.client.GetConsumeTopics()
+	}
+	return kc.topics
+}
+
+// rebalanceHook forwards the partitions assigned and revoked by the consumer group to the function set by OnRebalance.
+// The group callbacks are run by the client, possibly before the function is set.
+type rebalanceHook struct {
+	mu sync.Mutex
+	fn func(assigned, revoked map[string][]int32)
+}
+
+func (h *rebalanceHook) notify(assigned, revoked map[string][]int32) {
+	h.mu.Lock()
+	fn := h.fn
+	h.mu.Unlock()
+	if fn != nil {
+		fn(clonePartitions(assigned), clonePartitions(revoked))
+	}
+}
+
+// clonePartitions copies the partitions of a group callback, owned by the client
+func clonePartitions(partitions map[string][]int32) map[string][]int32 {
+	if partitions == nil {
+		return nil
+	}
+	cloned := make(map[string][]int32, len(partitions))
+	for topic, ps := range partitions {
+		cloned[topic] = slices.Clone(ps)
+	}
+	return cloned
+}
+
+// OnRebalance sets the function called with the partitions assigned, or revoked (lost included), by each rebalance
+// of the consumer group. It is called synchronously by the client during the rebalance, so it must return quickly.
+// It is a no-op for the consumers outside of a consumer group (replay, checkpoint, peek), whose partitions don't move.
+func (kc *KafkaConsumer) OnRebalance(fn func(assigned, revoked map[string][]int32)) {
+	if kc.rebalance == nil {
+		return
 	}
+	kc.rebalance.mu.Lock()
+	defer kc.rebalance.mu.Unlock()
+	kc.rebalance.fn = fn
 }
 
 func (kc *KafkaConsumer) Messages() <-chan *Message {
@@ -124,7 +673,53 @@ func (kc *KafkaConsumer) Errors() <-chan error {
 	return kc.errors
 }
 
+// MarkDone marks the message as completed. Its offset becomes committable once every previous record
+// of the partition is completed too, whatever the completion order of the workers.
+// The committable offsets are committed (or written to the checkpoint file) periodically,
+// on partitions revocation and by Commit.
+func (kc *KafkaConsumer) MarkDone(msg *Message) {
+	if kc.offsets == nil {
+		return
+	}
+	next, epoch, ok := kc.offsets.complete(msg.Topic, msg.Partition, msg.Offset)
+	if !ok {
+		return
+	}
+	if kc.checkpoint != nil {
+		kc.checkpoint.set(msg.Topic, msg.Partition, next)
+		return
+	}
+	kc.client.MarkCommitOffsets(map[string]map[int32]kgo.EpochOffset{
+		msg.Topic: {msg.Partition: {Epoch: epoch, Offset: next}},
+	})
+}
+
+// Commit commits the offsets of the records completed so far, see MarkDone.
+// A checkpointed consumer writes them to its checkpoint file instead.
+// A replay consumer never commits, it is not part of the consumer group.
+func (kc *KafkaConsumer) Commit(ctx context.Context) error {
+	if kc.replay != nil {
+		return nil
+	}
+	if kc.checkpoint != nil {
+		return kc.checkpoint.save()
+	}
+	return kc.client.CommitMarkedOffsets(ctx)
+}
+
+// SkipCloseCommit prevents Close from committing the offsets when leaving the consumer group,
+// for a shutdown that did not complete: the records left are consumed again from the last commit.
+func (kc *KafkaConsumer) SkipCloseCommit() {
+	if kc.closeCommit != nil {
+		kc.closeCommit.Store(false)
+	}
+}
+
 func (kc *KafkaConsumer) Close() error {
-	// Wrapper autour de kc.client.Close()
-	panic("unimplemented")
+	kc.logger.Info("Closing Kafka consumer")
+	if kc.closing != nil {
+		kc.closing.Store(true)
+	}
+	kc.client.Close()
+	return nil
 }
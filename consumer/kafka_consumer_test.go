@@ -1,5 +1,24 @@
 package consumer
 
+import (
+	"context"
+	"errors"
+	"etelgo/metrics"
+	"io"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
 // func TestStart(t *testing.T) {
 // 	ctx := context.Background()
 // 	kc := &KafkaConsumer{
@@ -13,3 +32,349 @@ package consumer
 // 		t.Errorf("Start() error = %v, wantErr = nil", err)
 // 	}
 // }
+
+func TestCheckTopicDetails(t *testing.T) {
+	details := kadm.TopicDetails{
+		"orders": kadm.TopicDetail{
+			Topic: "orders",
+			Partitions: kadm.PartitionDetails{
+				0: {Topic: "orders", Partition: 0},
+				1: {Topic: "orders", Partition: 1},
+			},
+		},
+		"missing": kadm.TopicDetail{
+			Topic: "missing",
+			Err:   kerr.UnknownTopicOrPartition,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		topic      string
+		partitions []int
+		wantErr    bool
+	}{
+		{"Existing topic", "orders", nil, false},
+		{"Existing topic with valid partitions", "orders", []int{0, 1}, false},
+		{"Existing topic with invalid partition", "orders", []int{0, 3}, true},
+		{"Topic in error", "missing", nil, true},
+		{"Topic not returned", "unknown", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTopicDetails(tt.topic, tt.partitions, details)
+			if tt.wantErr && err == nil {
+				t.Errorf("checkTopicDetails() error = nil, wantErr = true")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkTopicDetails() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestMatchTopicRegex(t *testing.T) {
+	details := kadm.TopicDetails{
+		"orders-eu":          kadm.TopicDetail{Topic: "orders-eu"},
+		"orders-us":          kadm.TopicDetail{Topic: "orders-us"},
+		"payments":           kadm.TopicDetail{Topic: "payments"},
+		"orders-deleted":     kadm.TopicDetail{Topic: "orders-deleted", Err: kerr.UnknownTopicOrPartition},
+		"__consumer_offsets": kadm.TopicDetail{Topic: "__consumer_offsets", IsInternal: true},
+	}
+
+	tests := []struct {
+		name    string
+		regex   string
+		want    []string
+		wantErr bool
+	}{
+		{"Matching topics sorted", "^orders-.*", []string{"orders-eu", "orders-us"}, false},
+		{"Unanchored match", "pay", []string{"payments"}, false},
+		{"Internal topics skipped", "consumer_offsets", nil, true},
+		{"No matching topic", "^invoices-", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchTopicRegex(regexp.MustCompile(tt.regex), details)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("matchTopicRegex() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchTopicRegex() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchTopicRegex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliverMaxMessageBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      []byte
+		decompress decompressor
+		wantErr    bool
+	}{
+		{"Within limit", []byte(`{"id": 1}`), nil, false},
+		{"Oversized", []byte(`{"id": 1, "name": "too large"}`), nil, true},
+		{"Oversized once decompressed", []byte(`{}`), func([]byte) ([]byte, error) {
+			return []byte(`{"id": 1, "name": "too large"}`), nil
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := &KafkaConsumer{
+				logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+				messages:        make(chan *Message, 1),
+				errors:          make(chan error, 1),
+				deserializer:    &JSONDeserializer{},
+				decompress:      tt.decompress,
+				maxMessageBytes: 16,
+			}
+
+			kc.deliver(context.Background(), &kgo.Record{Topic: "orders", Value: tt.value})
+
+			if len(kc.messages) != 1 {
+				t.Fatal("deliver() didn't send the message")
+			}
+			msg := <-kc.messages
+			if tt.wantErr {
+				if msg.Err == nil || !strings.Contains(msg.Err.Error(), "exceeds max_message_bytes") {
+					t.Errorf("deliver() message error = %v, want max_message_bytes error", msg.Err)
+				}
+				if msg.ValueFields != nil {
+					t.Error("deliver() decoded an oversized message")
+				}
+				return
+			}
+			if msg.Err != nil {
+				t.Errorf("deliver() message error = %v", msg.Err)
+			}
+		})
+	}
+}
+
+func TestDeliverKeyFormat(t *testing.T) {
+	tests := []struct {
+		name            string
+		keyDeserializer Deserializer
+		key             []byte
+		wantKeyFields   map[string]interface{}
+		wantErr         bool
+	}{
+		{"String key", nil, []byte(`{"id": 1}`), nil, false},
+		{"JSON key", &JSONDeserializer{}, []byte(`{"id": 1}`), map[string]interface{}{"id": int64(1)}, false},
+		{"No key", &JSONDeserializer{}, nil, nil, false},
+		{"Invalid JSON key", &JSONDeserializer{}, []byte("user-1"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := &KafkaConsumer{
+				logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+				messages:        make(chan *Message, 1),
+				errors:          make(chan error, 1),
+				deserializer:    &JSONDeserializer{},
+				keyDeserializer: tt.keyDeserializer,
+			}
+
+			kc.deliver(context.Background(), &kgo.Record{Topic: "orders", Key: tt.key, Value: []byte(`{}`)})
+
+			msg := <-kc.messages
+			if tt.wantErr {
+				if msg.Err == nil || !strings.Contains(msg.Err.Error(), "failed to deserialize message key") {
+					t.Errorf("deliver() message error = %v, want key error", msg.Err)
+				}
+				return
+			}
+			if msg.Err != nil {
+				t.Fatalf("deliver() message error = %v", msg.Err)
+			}
+			if !reflect.DeepEqual(msg.KeyFields, tt.wantKeyFields) {
+				t.Errorf("KeyFields = %v, want %v", msg.KeyFields, tt.wantKeyFields)
+			}
+			if string(msg.Key) != string(tt.key) {
+				t.Errorf("Key = %q, want the raw key %q", msg.Key, tt.key)
+			}
+		})
+	}
+}
+
+type panickingDeserializer struct{}
+
+func (panickingDeserializer) Deserialize([]byte) (map[string]interface{}, error) {
+	panic("corrupted payload")
+}
+
+func TestDeliverRecoversDecoderPanic(t *testing.T) {
+	kc := &KafkaConsumer{
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		messages:     make(chan *Message, 1),
+		errors:       make(chan error, 1),
+		deserializer: panickingDeserializer{},
+	}
+
+	kc.deliver(context.Background(), &kgo.Record{Topic: "orders", Partition: 2, Offset: 42, Value: []byte(`{}`)})
+
+	msg := <-kc.messages
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "orders/2@42: decoder panic: corrupted payload") {
+		t.Errorf("deliver() message error = %v, want decoder panic", msg.Err)
+	}
+}
+
+func TestDeliverRawValues(t *testing.T) {
+	kc := &KafkaConsumer{
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		messages:     make(chan *Message, 1),
+		errors:       make(chan error, 1),
+		deserializer: panickingDeserializer{},
+	}
+	kc.ForwardRawValues()
+
+	kc.deliver(context.Background(), &kgo.Record{Topic: "orders", Value: []byte(`{"id":1}`)})
+
+	msg := <-kc.messages
+	if msg.Err != nil {
+		t.Fatalf("deliver() message error = %v", msg.Err)
+	}
+	if msg.ValueFields != nil || string(msg.Value) != `{"id":1}` {
+		t.Errorf("deliver() = %v, %q, want the raw value only", msg.ValueFields, msg.Value)
+	}
+}
+
+func TestCountCodec(t *testing.T) {
+	before := codecRecords[0].Value()
+	countCodec(&kgo.Record{})
+	if got := codecRecords[0].Value() - before; got != 1 {
+		t.Errorf("expected an uncompressed record counted as none, got %d", got)
+	}
+}
+
+func TestObserveFetch(t *testing.T) {
+	latency := metrics.Default.Histogram("etelgo_consumer_fetch_latency_seconds", fetchLatencyBuckets, "topic", "fetch-test")
+	records := metrics.Default.Histogram("etelgo_consumer_fetch_records", fetchRecordsBuckets, "topic", "fetch-test")
+
+	fetches := kgo.Fetches{{Topics: []kgo.FetchTopic{{
+		Topic: "fetch-test",
+		Partitions: []kgo.FetchPartition{
+			{Partition: 0, Records: []*kgo.Record{{}, {}}},
+			{Partition: 1, Records: []*kgo.Record{{}}},
+		},
+	}}}}
+	observeFetch(fetches, 20*time.Millisecond)
+	observeFetch(nil, time.Second) // Idle poll
+
+	if latency.Count() != 1 || latency.Sum() != 0.02 {
+		t.Errorf("fetch latency count %d sum %g, want one fetch of 0.02s", latency.Count(), latency.Sum())
+	}
+	if records.Count() != 1 || records.Sum() != 3 {
+		t.Errorf("fetch records count %d sum %g, want one fetch of 3 records", records.Count(), records.Sum())
+	}
+}
+
+func TestPollMessagesIdle(t *testing.T) {
+	var polls atomic.Int64
+	kc := &KafkaConsumer{
+		logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+		messages:          make(chan *Message),
+		errors:            make(chan error),
+		emptyFetchBackoff: 20 * time.Millisecond,
+		pollFetches: func(context.Context) kgo.Fetches {
+			polls.Add(1)
+			return nil // Idle topic
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	kc.pollMessages(ctx)
+
+	// Spinning would poll millions of times, the backoff allows about one poll every 20ms
+	if got := polls.Load(); got > 15 {
+		t.Errorf("idle consumer polled %d times in 200ms, expected at most 15", got)
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	kc := &KafkaConsumer{
+		pollTimeout: 10 * time.Millisecond,
+		pollFetches: func(ctx context.Context) kgo.Fetches {
+			<-ctx.Done() // No record to return
+			return kgo.NewErrFetch(ctx.Err())
+		},
+	}
+
+	start := time.Now()
+	fetches := kc.poll(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("poll() blocked %s, expected to return after the poll timeout", elapsed)
+	}
+	if len(fetches.Errors()) != 0 {
+		t.Errorf("poll() errors = %v, want an idle poll without error", fetches.Errors())
+	}
+
+	// The cancellation of the consumer is still reported
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := kc.poll(ctx).Err0(); !errors.Is(err, context.Canceled) {
+		t.Errorf("poll() error = %v, want context canceled", err)
+	}
+}
+
+func TestOnRebalance(t *testing.T) {
+	kc := &KafkaConsumer{rebalance: &rebalanceHook{}}
+
+	// Rebalance before the function is set
+	kc.rebalance.notify(map[string][]int32{"orders": {0}}, nil)
+
+	var gotAssigned, gotRevoked map[string][]int32
+	kc.OnRebalance(func(assigned, revoked map[string][]int32) {
+		gotAssigned, gotRevoked = assigned, revoked
+	})
+	revoked := map[string][]int32{"orders": {1, 2}}
+	kc.rebalance.notify(nil, revoked)
+
+	if gotAssigned != nil || !reflect.DeepEqual(gotRevoked, revoked) {
+		t.Errorf("OnRebalance() got assigned %v, revoked %v, want revoked %v", gotAssigned, gotRevoked, revoked)
+	}
+	revoked["orders"][0] = 5
+	if gotRevoked["orders"][0] != 1 {
+		t.Error("expected the partitions to be copied from the client ones")
+	}
+
+	// Outside of a consumer group, there is no rebalance
+	(&KafkaConsumer{}).OnRebalance(func(map[string][]int32, map[string][]int32) {})
+}
+
+func TestPauseTopicRegex(t *testing.T) {
+	// The client stands for a consumer group having matched two topics, which only it knows of
+	client, err := kgo.NewClient(kgo.SeedBrokers("127.0.0.1:1"), kgo.ConsumeTopics("orders-eu", "orders-us"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+	kc := &KafkaConsumer{
+		client:     client,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		topicRegex: regexp.MustCompile(`^orders-.*`),
+	}
+
+	kc.Pause()
+	paused := client.PauseFetchTopics()
+	sort.Strings(paused)
+	if want := []string{"orders-eu", "orders-us"}; !reflect.DeepEqual(paused, want) {
+		t.Errorf("paused topics = %v, want %v", paused, want)
+	}
+
+	kc.Resume()
+	if paused := client.PauseFetchTopics(); len(paused) != 0 {
+		t.Errorf("paused topics after Resume = %v, want none", paused)
+	}
+}
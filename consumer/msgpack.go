@@ -0,0 +1,49 @@
+package consumer
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackDeserializer decodes a MessagePack map, integers being decoded as int64 (uint64 above the int64 range)
+// and floats as float64, whatever their encoded size.
+type MsgpackDeserializer struct{}
+
+func (d *MsgpackDeserializer) Deserialize(data []byte) (map[string]interface{}, error) {
+	reader := bytes.NewReader(data)
+	decoder := msgpack.NewDecoder(reader)
+	decoder.UseLooseInterfaceDecoding(true)
+
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	if reader.Len() > 0 {
+		return nil, errors.New("invalid MessagePack: unexpected data after top-level value")
+	}
+	normalizeUints(result)
+	return result, nil
+}
+
+// normalizeUints replaces recursively the uint64 values fitting in an int64, positive integers being
+// decoded as uint64 when encoded as msgpack uint (e.g. by the compact encoding of our serializer)
+func normalizeUints(value interface{}) interface{} {
+	switch v := value.(type) {
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = normalizeUints(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeUints(val)
+		}
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package consumer
+
+import "sync"
+
+// offsetTracker computes the offsets safe to commit when the records of a partition complete out of order,
+// as they do with several workers. The commit point of a partition only moves past contiguous completed records:
+// completing offset 5 before offset 3 keeps the commit point before 3, until 3 completes too.
+// It never moves backwards, so a late completion can't commit a lower offset than a previous one.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type partitionOffsets struct {
+	pending   []pendingOffset // Records delivered and not yet committable, in offset order
+	done      map[int64]bool  // Completed records among pending
+	committed int64           // Highest commit point returned, the next offset to consume
+}
+
+type pendingOffset struct {
+	offset int64
+	epoch  int32 // Leader epoch of the record, committed along with the offset
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[topicPartition]*partitionOffsets)}
+}
+
+// deliver registers a record handed over to the workers, records of a partition must be delivered in offset order
+func (t *offsetTracker) deliver(topic string, partition int32, offset int64, epoch int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := topicPartition{topic, partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool), committed: -1}
+		t.partitions[key] = p
+	}
+	p.pending = append(p.pending, pendingOffset{offset: offset, epoch: epoch})
+}
+
+// complete registers a completed record. It returns the new commit point of the partition (offset and epoch)
+// when the completion makes it move forward, ok is false otherwise.
+func (t *offsetTracker) complete(topic string, partition int32, offset int64) (next int64, epoch int32, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, found := t.partitions[topicPartition{topic, partition}]
+	if !found {
+		return 0, 0, false // Partition revoked since the delivery
+	}
+	p.done[offset] = true
+
+	advanced := false
+	for len(p.pending) > 0 && p.done[p.pending[0].offset] {
+		head := p.pending[0]
+		delete(p.done, head.offset)
+		p.pending = p.pending[1:]
+		next, epoch, advanced = head.offset+1, head.epoch, true
+	}
+	if !advanced || next <= p.committed {
+		return 0, 0, false
+	}
+	p.committed = next
+	return next, epoch, true
+}
+
+// forget drops the state of partitions no longer assigned, their records being consumed again from the last commit
+func (t *offsetTracker) forget(partitions map[string][]int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for topic, ps := range partitions {
+		for _, partition := range ps {
+			delete(t.partitions, topicPartition{topic, partition})
+		}
+	}
+}
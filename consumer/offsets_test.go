@@ -0,0 +1,58 @@
+package consumer
+
+import "testing"
+
+func TestOffsetTracker_OutOfOrderCompletion(t *testing.T) {
+	tracker := newOffsetTracker()
+	for offset := int64(3); offset <= 5; offset++ {
+		tracker.deliver("orders", 0, offset, 1)
+	}
+
+	// Offset 5 completes first, committing it would skip 3 and 4
+	if next, _, ok := tracker.complete("orders", 0, 5); ok {
+		t.Fatalf("expected no commit point while 3 and 4 are pending, got %d", next)
+	}
+
+	next, epoch, ok := tracker.complete("orders", 0, 3)
+	if !ok || next != 4 || epoch != 1 {
+		t.Fatalf("expected commit point 4 (epoch 1), got %d (epoch %d), %v", next, epoch, ok)
+	}
+
+	next, _, ok = tracker.complete("orders", 0, 4)
+	if !ok || next != 6 {
+		t.Fatalf("expected commit point 6 once the range is contiguous, got %d, %v", next, ok)
+	}
+}
+
+func TestOffsetTracker_NeverRegresses(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.deliver("orders", 0, 10, 0)
+	if next, _, ok := tracker.complete("orders", 0, 10); !ok || next != 11 {
+		t.Fatalf("expected commit point 11, got %d, %v", next, ok)
+	}
+
+	// A completion of an offset already committed must not move the commit point back
+	tracker.deliver("orders", 0, 7, 0)
+	if next, _, ok := tracker.complete("orders", 0, 7); ok {
+		t.Errorf("expected no commit point lower than 11, got %d", next)
+	}
+}
+
+func TestOffsetTracker_Partitions(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.deliver("orders", 0, 1, 0)
+	tracker.deliver("orders", 1, 1, 0)
+	tracker.deliver("payments", 0, 1, 0)
+
+	if _, _, ok := tracker.complete("orders", 1, 1); !ok {
+		t.Errorf("expected partitions to be tracked independently")
+	}
+
+	tracker.forget(map[string][]int32{"orders": {0}})
+	if _, _, ok := tracker.complete("orders", 0, 1); ok {
+		t.Errorf("expected no commit point for a revoked partition")
+	}
+	if _, _, ok := tracker.complete("payments", 0, 1); !ok {
+		t.Errorf("expected the other partitions to be kept")
+	}
+}
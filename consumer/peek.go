@@ -0,0 +1,128 @@
+package consumer
+
+import (
+	"etelgo/config"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// NewKafkaPeekConsumer creates a consumer sampling the input topics from their earliest or latest offsets,
+// restricted to one partition when partition is not negative (the configured partitions otherwise).
+// Like a replay consumer, it doesn't join the consumer group and never commits.
+func NewKafkaPeekConsumer(cfg *config.InputConfig, from string, partition int, logger *slog.Logger) (*KafkaConsumer, error) {
+	if cfg.Topic_regex != nil {
+		return nil, errTopicRegex
+	}
+	topics := cfg.AllTopics()
+	logger.Info("Creating new Kafka peek consumer", "brokers", cfg.Brokers, "topics", topics, "from", from)
+
+	start := kgo.NewOffset().AtStart()
+	if from == "latest" {
+		start = kgo.NewOffset().AtEnd()
+	}
+
+	partitions := cfg.Partitions
+	if partition >= 0 {
+		partitions = []int{partition}
+	}
+
+	kgoOpts, err := clientOpts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) > 0 {
+		consume := make(map[string]map[int32]kgo.Offset)
+		for _, topic := range topics {
+			consume[topic] = make(map[int32]kgo.Offset)
+			for _, p := range partitions {
+				consume[topic][int32(p)] = start
+			}
+		}
+		kgoOpts = append(kgoOpts, kgo.ConsumePartitions(consume))
+	} else {
+		kgoOpts = append(kgoOpts, kgo.ConsumeTopics(topics...), kgo.ConsumeResetOffset(start))
+	}
+
+	client, err := kgo.NewClient(kgoOpts...)
+	if err != nil {
+		logger.Error("failed to create Kafka client", "error", err)
+		return nil, err
+	}
+
+	connectBackoff, err := time.ParseDuration(*cfg.Connect_backoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connect_backoff: %w", err)
+	}
+
+	pollTimeout, emptyFetchBackoff, err := pollingDurations(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	decompress, err := newDecompressor(*cfg.Payload_compression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaConsumer{
+		client:     client,
+		logger:     logger,
+		messages:   make(chan *Message),
+		errors:     make(chan error),
+		topics:     topics,
+		partitions: partitions,
+
+		readCommitted: *cfg.Isolation_level == "read_committed",
+
+		deserializer:    deserializerFor(cfg),
+		keyDeserializer: keyDeserializerFor(cfg),
+		decompress:      decompress,
+		maxMessageBytes: *cfg.Max_message_bytes,
+
+		connectRetries: *cfg.Connect_retries,
+		connectBackoff: connectBackoff,
+
+		pollTimeout:       pollTimeout,
+		emptyFetchBackoff: emptyFetchBackoff,
+	}, nil
+}
+
+// PeekRecord is the printed form of a consumed message, its value as decoded by the input format.
+// A message failing to decode has its error and raw value instead.
+type PeekRecord struct {
+	Topic     string                 `json:"topic"`
+	Partition int32                  `json:"partition"`
+	Offset    int64                  `json:"offset"`
+	Timestamp time.Time              `json:"timestamp"`
+	Key       interface{}            `json:"key,omitempty"` // Decoded fields, or the raw key with the string key format
+	Headers   map[string]string      `json:"headers,omitempty"`
+	Value     map[string]interface{} `json:"value"`
+	Raw       string                 `json:"raw,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// NewPeekRecord builds the printed form of the message
+func NewPeekRecord(msg *Message) PeekRecord {
+	record := PeekRecord{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp,
+		Headers:   msg.Headers,
+		Value:     msg.ValueFields,
+	}
+	switch {
+	case msg.KeyFields != nil:
+		record.Key = msg.KeyFields
+	case len(msg.Key) > 0:
+		record.Key = string(msg.Key)
+	}
+	if msg.Err != nil {
+		record.Error = msg.Err.Error()
+		record.Raw = string(msg.Value)
+	}
+	return record
+}
@@ -0,0 +1,48 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPeekRecord(t *testing.T) {
+	timestamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		msg  *Message
+		want string
+	}{
+		{
+			name: "Decoded message",
+			msg: &Message{Topic: "orders", Partition: 1, Offset: 7, Timestamp: timestamp, Key: []byte("k1"),
+				Headers: map[string]string{}, ValueFields: map[string]interface{}{"id": 1}},
+			want: `{"topic":"orders","partition":1,"offset":7,"timestamp":"2026-01-02T03:04:05Z","key":"k1","value":{"id":1}}`,
+		},
+		{
+			name: "Decoded key",
+			msg: &Message{Topic: "orders", Timestamp: timestamp, Key: []byte(`{"id":1}`),
+				KeyFields: map[string]interface{}{"id": 1}, ValueFields: map[string]interface{}{}},
+			want: `{"topic":"orders","partition":0,"offset":0,"timestamp":"2026-01-02T03:04:05Z","key":{"id":1},"value":{}}`,
+		},
+		{
+			name: "Decoding error",
+			msg:  &Message{Topic: "orders", Timestamp: timestamp, Value: []byte("not json"), Err: errors.New("failed to deserialize message value")},
+			want: `{"topic":"orders","partition":0,"offset":0,"timestamp":"2026-01-02T03:04:05Z","value":null,"raw":"not json","error":"failed to deserialize message value"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewPeekRecord(tt.msg))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("NewPeekRecord() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
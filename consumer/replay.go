@@ -0,0 +1,177 @@
+package consumer
+
+import (
+	"context"
+	"etelgo/config"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ReplayWindow bounds a replay of historical data by record timestamps
+type ReplayWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+// replayState tracks the partitions still being replayed, the consumer is done once every partition
+// reached either the end offset listed at startup or a record newer than the window.
+// Record timestamps are not strictly ordered within a partition, so the first record after To ends its partition.
+type replayState struct {
+	window    ReplayWindow
+	remaining map[string]map[int32]int64 // End offset (exclusive) of each partition still replaying
+	done      chan struct{}
+}
+
+// NewKafkaReplayConsumer creates a consumer reading the input topics between the window bounds.
+// It doesn't join the consumer group and never commits, so a replay doesn't move the live pipeline offsets.
+func NewKafkaReplayConsumer(cfg *config.InputConfig, window ReplayWindow, logger *slog.Logger) (*KafkaConsumer, error) {
+	if cfg.Topic_regex != nil {
+		return nil, errTopicRegex
+	}
+	topics := cfg.AllTopics()
+	logger.Info("Creating new Kafka replay consumer", "brokers", cfg.Brokers, "topics", topics, "from", window.From, "to", window.To)
+
+	kgoOpts, err := clientOpts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partitions to consume are only known once the offsets are listed, see startReplay
+	client, err := kgo.NewClient(kgoOpts...)
+	if err != nil {
+		logger.Error("failed to create Kafka client", "error", err)
+		return nil, err
+	}
+
+	connectBackoff, err := time.ParseDuration(*cfg.Connect_backoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connect_backoff: %w", err)
+	}
+
+	pollTimeout, emptyFetchBackoff, err := pollingDurations(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	decompress, err := newDecompressor(*cfg.Payload_compression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaConsumer{
+		client:     client,
+		logger:     logger,
+		messages:   make(chan *Message),
+		errors:     make(chan error),
+		topics:     topics,
+		partitions: cfg.Partitions,
+
+		readCommitted: *cfg.Isolation_level == "read_committed",
+
+		deserializer:    deserializerFor(cfg),
+		keyDeserializer: keyDeserializerFor(cfg),
+		decompress:      decompress,
+		maxMessageBytes: *cfg.Max_message_bytes,
+
+		connectRetries: *cfg.Connect_retries,
+		connectBackoff: connectBackoff,
+
+		pollTimeout:       pollTimeout,
+		emptyFetchBackoff: emptyFetchBackoff,
+
+		replay: &replayState{window: window, done: make(chan struct{})},
+	}, nil
+}
+
+// startReplay seeks every partition to the first record at or after the window start
+func (kc *KafkaConsumer) startReplay(ctx context.Context) error {
+	admin := kadm.NewClient(kc.client)
+
+	starts, err := admin.ListOffsetsAfterMilli(ctx, kc.replay.window.From.UnixMilli(), kc.topics...)
+	if err != nil {
+		return fmt.Errorf("failed to list offsets after %s: %w", kc.replay.window.From, err)
+	}
+	// Reading committed records, the last stable offset is the end: the records after it can't be fetched yet
+	listEnds := admin.ListEndOffsets
+	if kc.readCommitted {
+		listEnds = admin.ListCommittedOffsets
+	}
+	ends, err := listEnds(ctx, kc.topics...)
+	if err != nil {
+		return fmt.Errorf("failed to list end offsets: %w", err)
+	}
+
+	consume, remaining := planReplay(starts, ends, kc.partitions)
+	kc.replay.remaining = remaining
+	if len(remaining) == 0 {
+		kc.logger.Info("No record to replay in the window")
+		close(kc.replay.done)
+		return nil
+	}
+
+	kc.logger.Info("Starting replay", "partitions", countPartitions(remaining))
+	kc.client.AddConsumePartitions(consume)
+	return nil
+}
+
+// planReplay computes the start offset of each partition holding records in the window, and its end offset.
+// When partitions are configured, the other partitions are ignored.
+func planReplay(starts, ends kadm.ListedOffsets, partitions []int) (map[string]map[int32]kgo.Offset, map[string]map[int32]int64) {
+	allowed := make(map[int32]bool)
+	for _, p := range partitions {
+		allowed[int32(p)] = true
+	}
+
+	consume := make(map[string]map[int32]kgo.Offset)
+	remaining := make(map[string]map[int32]int64)
+	starts.Each(func(start kadm.ListedOffset) {
+		if start.Err != nil || start.Offset < 0 || (len(allowed) > 0 && !allowed[start.Partition]) {
+			return
+		}
+		end, ok := ends.Lookup(start.Topic, start.Partition)
+		if !ok || end.Err != nil || start.Offset >= end.Offset {
+			return
+		}
+
+		if consume[start.Topic] == nil {
+			consume[start.Topic] = make(map[int32]kgo.Offset)
+			remaining[start.Topic] = make(map[int32]int64)
+		}
+		consume[start.Topic][start.Partition] = kgo.NewOffset().At(start.Offset)
+		remaining[start.Topic][start.Partition] = end.Offset
+	})
+	return consume, remaining
+}
+
+// track reports whether the record is part of the window, and whether its partition is finished
+func (rs *replayState) track(record *kgo.Record) (deliver bool, finished bool) {
+	end, ok := rs.remaining[record.Topic][record.Partition]
+	if !ok {
+		return false, false
+	}
+	if record.Timestamp.After(rs.window.To) {
+		return false, true
+	}
+	return true, record.Offset+1 >= end
+}
+
+// finish marks the partition as replayed, it returns true once every partition is
+func (rs *replayState) finish(topic string, partition int32) bool {
+	delete(rs.remaining[topic], partition)
+	if len(rs.remaining[topic]) == 0 {
+		delete(rs.remaining, topic)
+	}
+	return len(rs.remaining) == 0
+}
+
+func countPartitions(partitions map[string]map[int32]int64) int {
+	count := 0
+	for _, ps := range partitions {
+		count += len(ps)
+	}
+	return count
+}
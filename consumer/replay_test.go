@@ -0,0 +1,79 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestPlanReplay(t *testing.T) {
+	starts := kadm.ListedOffsets{
+		"orders": {
+			0: {Topic: "orders", Partition: 0, Offset: 10},
+			1: {Topic: "orders", Partition: 1, Offset: -1}, // No record after the window start
+			2: {Topic: "orders", Partition: 2, Offset: 5},
+			3: {Topic: "orders", Partition: 3, Err: kerr.NotLeaderForPartition},
+		},
+	}
+	ends := kadm.ListedOffsets{
+		"orders": {
+			0: {Topic: "orders", Partition: 0, Offset: 20},
+			1: {Topic: "orders", Partition: 1, Offset: 8},
+			2: {Topic: "orders", Partition: 2, Offset: 5}, // Already at the end
+			3: {Topic: "orders", Partition: 3, Offset: 3},
+		},
+	}
+
+	consume, remaining := planReplay(starts, ends, nil)
+	if len(consume["orders"]) != 1 || countPartitions(remaining) != 1 {
+		t.Fatalf("planReplay() = %v, %v, want only partition 0", consume, remaining)
+	}
+	if remaining["orders"][0] != 20 {
+		t.Errorf("planReplay() end offset = %d, want 20", remaining["orders"][0])
+	}
+
+	_, remaining = planReplay(starts, ends, []int{2})
+	if len(remaining) != 0 {
+		t.Errorf("planReplay() with partitions = %v, want nothing to replay", remaining)
+	}
+}
+
+func TestReplayStateTrack(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	rs := &replayState{
+		window:    ReplayWindow{From: to.Add(-24 * time.Hour), To: to},
+		remaining: map[string]map[int32]int64{"orders": {0: 20, 1: 8}},
+		done:      make(chan struct{}),
+	}
+
+	tests := []struct {
+		name         string
+		record       *kgo.Record
+		wantDeliver  bool
+		wantFinished bool
+	}{
+		{"Record in the window", &kgo.Record{Topic: "orders", Partition: 0, Offset: 10, Timestamp: to.Add(-time.Hour)}, true, false},
+		{"Last record of the partition", &kgo.Record{Topic: "orders", Partition: 0, Offset: 19, Timestamp: to.Add(-time.Hour)}, true, true},
+		{"Record after the window", &kgo.Record{Topic: "orders", Partition: 1, Offset: 3, Timestamp: to.Add(time.Second)}, false, true},
+		{"Partition not replayed", &kgo.Record{Topic: "orders", Partition: 2, Offset: 0, Timestamp: to.Add(-time.Hour)}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deliver, finished := rs.track(tt.record)
+			if deliver != tt.wantDeliver || finished != tt.wantFinished {
+				t.Errorf("track() = %v, %v, want %v, %v", deliver, finished, tt.wantDeliver, tt.wantFinished)
+			}
+		})
+	}
+
+	if rs.finish("orders", 0) {
+		t.Errorf("finish() = true with partition 1 still replaying")
+	}
+	if !rs.finish("orders", 1) {
+		t.Errorf("finish() = false once every partition is replayed")
+	}
+}
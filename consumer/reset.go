@@ -0,0 +1,149 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"etelgo/config"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// OffsetReset targets the offsets a consumer group is reset to: the earliest or latest offset of each partition,
+// or the first record at or after Timestamp when To is empty.
+type OffsetReset struct {
+	To        string // "earliest" or "latest"
+	Timestamp time.Time
+}
+
+// OffsetChange is the committed offset of a partition before and after a reset, -1 when the group had none
+type OffsetChange struct {
+	Topic     string
+	Partition int32
+	Before    int64
+	After     int64
+}
+
+// ResetOffsets sets the committed offsets of the consumer group on the input topics to the target, and returns the changes
+// ordered by topic and partition. When partitions are configured, the other partitions are left untouched.
+// It refuses to reset a group with active members, which would overwrite the new offsets with their own commits.
+// With dryRun the changes are computed but not committed.
+func ResetOffsets(ctx context.Context, cfg *config.InputConfig, target OffsetReset, dryRun bool, logger *slog.Logger) ([]OffsetChange, error) {
+	if cfg.Checkpoint_file != nil {
+		return nil, errors.New("the offsets are stored in the checkpoint file, not in the consumer group")
+	}
+	if cfg.Topic_regex != nil {
+		return nil, errTopicRegex
+	}
+
+	client, err := kgo.NewClient(kgo.SeedBrokers(cfg.Brokers...))
+	if err != nil {
+		logger.Error("failed to create Kafka client", "error", err)
+		return nil, err
+	}
+	defer client.Close()
+	admin := kadm.NewClient(client)
+
+	groups, err := admin.DescribeGroups(ctx, cfg.ConsumerGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer group %q: %w", cfg.ConsumerGroup, err)
+	}
+	if err := checkGroupInactive(groups[cfg.ConsumerGroup]); err != nil {
+		return nil, err
+	}
+
+	topics := cfg.AllTopics()
+	var targets kadm.ListedOffsets
+	switch target.To {
+	case "earliest":
+		targets, err = admin.ListStartOffsets(ctx, topics...)
+	case "latest":
+		// Reading committed records, the last stable offset is the end: the records after it can't be fetched yet
+		if *cfg.Isolation_level == "read_committed" {
+			targets, err = admin.ListCommittedOffsets(ctx, topics...)
+		} else {
+			targets, err = admin.ListEndOffsets(ctx, topics...)
+		}
+	default:
+		targets, err = admin.ListOffsetsAfterMilli(ctx, target.Timestamp.UnixMilli(), topics...)
+	}
+	if err == nil {
+		err = targets.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target offsets: %w", err)
+	}
+
+	committed, err := admin.FetchOffsets(ctx, cfg.ConsumerGroup)
+	if err == nil {
+		err = committed.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the offsets of consumer group %q: %w", cfg.ConsumerGroup, err)
+	}
+
+	changes, offsets := planReset(targets, committed, cfg.Partitions)
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	logger.Info("Resetting consumer group offsets", "group", cfg.ConsumerGroup, "partitions", len(changes))
+	responses, err := admin.CommitOffsets(ctx, cfg.ConsumerGroup, offsets)
+	if err == nil {
+		err = responses.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit the offsets of consumer group %q: %w", cfg.ConsumerGroup, err)
+	}
+	return changes, nil
+}
+
+// checkGroupInactive returns an error unless the group is empty or doesn't exist yet
+func checkGroupInactive(group kadm.DescribedGroup) error {
+	if group.Err != nil {
+		return fmt.Errorf("failed to describe consumer group %q: %w", group.Group, group.Err)
+	}
+	if len(group.Members) > 0 {
+		return fmt.Errorf("consumer group %q has %d active members, stop them before resetting its offsets", group.Group, len(group.Members))
+	}
+	if group.State != "" && group.State != "Empty" && group.State != "Dead" {
+		return fmt.Errorf("consumer group %q is %s, stop its members before resetting its offsets", group.Group, group.State)
+	}
+	return nil
+}
+
+// planReset computes the change of each partition from its committed offset to the target one, and the offsets to commit.
+// When partitions are configured, the other partitions are ignored.
+func planReset(targets kadm.ListedOffsets, committed kadm.OffsetResponses, partitions []int) ([]OffsetChange, kadm.Offsets) {
+	allowed := make(map[int32]bool)
+	for _, p := range partitions {
+		allowed[int32(p)] = true
+	}
+
+	var changes []OffsetChange
+	offsets := make(kadm.Offsets)
+	targets.Each(func(target kadm.ListedOffset) {
+		if target.Err != nil || target.Offset < 0 || (len(allowed) > 0 && !allowed[target.Partition]) {
+			return
+		}
+
+		before := int64(-1)
+		if current, ok := committed.Lookup(target.Topic, target.Partition); ok && current.Err == nil {
+			before = current.At
+		}
+		changes = append(changes, OffsetChange{Topic: target.Topic, Partition: target.Partition, Before: before, After: target.Offset})
+		offsets.Add(kadm.Offset{Topic: target.Topic, Partition: target.Partition, At: target.Offset, LeaderEpoch: -1})
+	})
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Topic != changes[j].Topic {
+			return changes[i].Topic < changes[j].Topic
+		}
+		return changes[i].Partition < changes[j].Partition
+	})
+	return changes, offsets
+}
@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestPlanReset(t *testing.T) {
+	targets := kadm.ListedOffsets{
+		"orders": {
+			1: {Topic: "orders", Partition: 1, Offset: 0},
+			0: {Topic: "orders", Partition: 0, Offset: 0},
+			2: {Topic: "orders", Partition: 2, Err: kerr.NotLeaderForPartition},
+		},
+	}
+	committed := kadm.OffsetResponses{
+		"orders": {
+			0: {Offset: kadm.Offset{Topic: "orders", Partition: 0, At: 42}},
+		},
+	}
+
+	changes, offsets := planReset(targets, committed, nil)
+	want := []OffsetChange{
+		{Topic: "orders", Partition: 0, Before: 42, After: 0},
+		{Topic: "orders", Partition: 1, Before: -1, After: 0}, // Never committed
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("planReset() = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("planReset() change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if len(offsets["orders"]) != 2 || offsets["orders"][1].At != 0 {
+		t.Errorf("planReset() offsets = %v, want partitions 0 and 1 at 0", offsets)
+	}
+
+	changes, _ = planReset(targets, committed, []int{1})
+	if len(changes) != 1 || changes[0].Partition != 1 {
+		t.Errorf("planReset() with partitions = %v, want only partition 1", changes)
+	}
+}
+
+func TestCheckGroupInactive(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   kadm.DescribedGroup
+		wantErr string
+	}{
+		{"Empty group", kadm.DescribedGroup{Group: "etl", State: "Empty"}, ""},
+		{"Unknown group", kadm.DescribedGroup{Group: "etl", State: "Dead"}, ""},
+		{"Active members", kadm.DescribedGroup{Group: "etl", State: "Stable", Members: make([]kadm.DescribedGroupMember, 2)}, "has 2 active members"},
+		{"Rebalancing", kadm.DescribedGroup{Group: "etl", State: "PreparingRebalance"}, "is PreparingRebalance"},
+		{"Describe error", kadm.DescribedGroup{Group: "etl", Err: kerr.CoordinatorNotAvailable}, "failed to describe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkGroupInactive(tt.group)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkGroupInactive() error = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkGroupInactive() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
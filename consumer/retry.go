@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// maxBackoff caps the exponential growth of the delay between two attempts
+const maxBackoff = 30 * time.Second
+
+// backoffDelay returns the delay before the given retry attempt (starting at 0):
+// exponential growth from initial, capped at maxBackoff, with a random jitter in [delay/2, delay]
+// so that several instances restarted together don't hammer the brokers in sync.
+func backoffDelay(attempt int, initial time.Duration) time.Duration {
+	delay := initial
+	for i := 0; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// retryWithBackoff calls op until it succeeds, up to maxRetries retries after the first attempt.
+// It gives up early if the context is cancelled while waiting.
+func retryWithBackoff(ctx context.Context, maxRetries int, initial time.Duration, logger *slog.Logger, op func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = op(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		delay := backoffDelay(attempt, initial)
+		logger.Warn("Attempt failed, retrying", "attempt", attempt+1, "max_retries", maxRetries, "retry_in", delay, "error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("retry interrupted: %w", ctx.Err())
+		}
+	}
+}
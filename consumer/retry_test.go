@@ -0,0 +1,70 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestBackoffDelay(t *testing.T) {
+	initial := 100 * time.Millisecond
+
+	for attempt := 0; attempt < 12; attempt++ {
+		delay := backoffDelay(attempt, initial)
+		if delay > maxBackoff {
+			t.Errorf("backoffDelay(%d) = %v, should be capped at %v", attempt, delay, maxBackoff)
+		}
+		if delay < initial/2 {
+			t.Errorf("backoffDelay(%d) = %v, should be at least %v", attempt, delay, initial/2)
+		}
+	}
+}
+
+func TestRetryWithBackoff_SucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := retryWithBackoff(context.Background(), 3, time.Millisecond, testLogger, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("retryWithBackoff() unexpected error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryWithBackoff_GivesUp(t *testing.T) {
+	calls := 0
+	errBroker := errors.New("broker unavailable")
+	err := retryWithBackoff(context.Background(), 2, time.Millisecond, testLogger, func(ctx context.Context) error {
+		calls++
+		return errBroker
+	})
+	if !errors.Is(err, errBroker) {
+		t.Errorf("retryWithBackoff() error = %v, want wrapped %v", err, errBroker)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls (1 attempt + 2 retries), got %d", calls)
+	}
+}
+
+func TestRetryWithBackoff_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := retryWithBackoff(ctx, 5, time.Hour, testLogger, func(ctx context.Context) error {
+		return errors.New("broker unavailable")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("retryWithBackoff() error = %v, want context.Canceled", err)
+	}
+}
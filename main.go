@@ -1,38 +1,134 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"etelgo/pipelines"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 )
 
 const Version = "1.0.0"
 
+// Build metadata, set at build time through the linker:
+// go build -ldflags "-X main.Commit=$(git rev-parse --short HEAD) -X main.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Commit    string
+	BuildDate string
+)
+
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	command := os.Args[1]
+// run dispatches the CLI arguments (program name excluded) to the matching command and returns the exit code.
+func run(args []string) int {
+	command, err := parseCommand(args)
+	if err != nil {
+		fmt.Println(err)
+		printUsage()
+		return 1
+	}
 
 	switch command {
 	case "run":
-		runCommand()
+		return runCommand(args[1:])
+	case "replay":
+		return replayCommand(args[1:])
+	case "reprocess":
+		return reprocessCommand(args[1:])
+	case "reset-offsets":
+		return resetOffsetsCommand(args[1:])
+	case "describe-topic":
+		return describeTopicCommand(args[1:])
+	case "peek":
+		return peekCommand(args[1:])
 	case "validate":
-		validateCommand()
-	case "version":
-		fmt.Println(Version)
+		return validateCommand(args[1:])
+	case "test":
+		return testCommand(args[1:])
+	case "config":
+		return configCommand(args[1:])
+	case "schema":
+		return schemaCommand(args[1:])
+	case "version", "--version", "-version", "-v":
+		printVersion()
 	case "help":
 		printUsage()
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
-		os.Exit(1)
+		return 1
+	}
+
+	return 0
+}
+
+// flagExitCode returns the exit code of a command whose flags failed to parse, the usage being already printed:
+// 0 when it was requested with -h, 2 for an invalid flag, as flag.ExitOnError does.
+func flagExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
 	}
+	return 2
+}
 
+// printVersion prints the bare version on the first line, followed by the build information
+func printVersion() {
+	fmt.Println(Version)
+	fmt.Println(buildInfo())
 }
 
-// Logger function to create a new logger based on log level
-func newLogger(logLevel string) *slog.Logger {
+// buildInfo describes the binary build: commit, build date, Go version and platform.
+// The commit falls back on the VCS revision stamped by the Go toolchain, then on "dev" (e.g. go run).
+func buildInfo() string {
+	commit := Commit
+	if commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				if setting.Key == "vcs.revision" {
+					commit = setting.Value
+				}
+			}
+		}
+	}
+	if commit == "" {
+		commit = "dev"
+	}
+
+	buildDate := BuildDate
+	if buildDate == "" {
+		buildDate = "unknown"
+	}
+
+	return fmt.Sprintf("commit: %s, built: %s, go: %s, platform: %s/%s", commit, buildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// parseCommand returns the subcommand from the CLI arguments (program name excluded)
+func parseCommand(args []string) (string, error) {
+	if len(args) < 1 {
+		return "", errors.New("missing command")
+	}
+	return args[0], nil
+}
+
+// Logger function to create a new logger based on log level, writing to w
+func newLogger(logLevel string, w io.Writer) *slog.Logger {
 	logLevelMap := map[string]slog.Level{
 		"debug": slog.LevelDebug,
 		"info":  slog.LevelInfo,
@@ -45,57 +141,763 @@ func newLogger(logLevel string) *slog.Logger {
 		fmt.Printf("Unknown log level: %s, defaulting to info\n", logLevel)
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
 	slog.SetDefault(logger)
 	return logger
 }
 
 // runCommand stats the pipeline based on the provided configuration with the flags.
-func runCommand() {
-	fs := flag.NewFlagSet("run", flag.ExitOnError)
+// It is a thin wrapper around pipelines.RunWithOptions, turning the signals into the cancellation of its context.
+func runCommand(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
 
-	configFile := fs.String("config", "config.yml", "Configuration file path")
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
 	logLevel := fs.String("loglevel", "info", "Log level (debug, info, warn, error)")
-	dryRun := fs.Bool("dry-run", false, "Run without writing to output (validation only)")
+	dryRun := fs.Bool("dry-run", false, "Process messages without writing to output nor committing offsets, then print a report of the processors effects")
+	maxMessages := fs.Int("max-messages", 0, "Stop once this many messages are consumed (0 means no limit)")
+	idleTimeout := fs.Duration("idle-timeout", 0, "Stop once no message is consumed for this duration, e.g. when the topic is drained (0 means no limit)")
+	skipTopicCheck := fs.Bool("skip-topic-check", false, "Skip the input topic existence check at startup")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "Maximum duration of the graceful drain on shutdown (0 waits indefinitely)")
+	strict := fs.Bool("strict", false, "Fail on unknown config fields and suspicious processors chains instead of ignoring or warning")
+	failFast := fs.Bool("fail-fast", false, "Stop at the first configuration error instead of reporting them all")
 
-	fs.Parse(os.Args[2:])
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+	if *maxMessages < 0 {
+		fmt.Println("-max-messages must be positive or 0")
+		return 2
+	}
+	if *idleTimeout < 0 {
+		fmt.Println("-idle-timeout must be positive or 0")
+		return 2
+	}
 
-	logger := newLogger(*logLevel)
+	logger := newLogger(*logLevel, os.Stdout)
 
-	config, err := config.LoadConfig(*configFile, logger)
+	loadOpts := config.LoadOptions{Strict: *strict, FailFast: *failFast}
+	cfg, err := config.LoadConfigWithOptions(*configFile, logger, loadOpts)
 	if err != nil {
-		logger.Error("failed to load config", "error", err)
-		os.Exit(1)
+		logErrors(logger, "failed to load config", err)
+		return 1
 	}
 
 	logger.Info("Starting pipeline",
-		"topic_in", config.Input.Topic,
-		"topic_out", config.Output.Topic,
+		"topics_in", cfg.Input.AllTopics(),
+		"topic_out", cfg.Output.Topic,
+		"dry_run", *dryRun,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go watchMetricsSnapshot(ctx, metrics.Default, logger)
+
+	reloads := make(chan *config.Config)
+	go watchReload(ctx, *configFile, loadOpts, reloads, logger)
+
+	opts := pipelines.RunOptions{
+		DryRun:          *dryRun,
+		SkipTopicCheck:  *skipTopicCheck,
+		MaxMessages:     *maxMessages,
+		IdleTimeout:     *idleTimeout,
+		ShutdownTimeout: *shutdownTimeout,
+		Report:          os.Stdout,
+		Reload:          reloads,
+	}
+	if err := pipelines.RunWithOptions(ctx, cfg, logger, opts); err != nil {
+		logger.Error("pipeline failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// replayCommand replays the records of the input topics produced within a time window, then exits.
+// It reuses the run pipeline, but reads outside of the consumer group and never commits offsets.
+func replayCommand(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "info", "Log level (debug, info, warn, error)")
+	from := fs.String("from", "", "Start of the replay window, RFC3339 timestamp (required)")
+	to := fs.String("to", "", "End of the replay window, RFC3339 timestamp (default now)")
+	dryRun := fs.Bool("dry-run", false, "Replay without writing to output, then print a report of the processors effects")
+	maxMessages := fs.Int("max-messages", 0, "Stop once this many messages are consumed (0 means no limit)")
+	skipTopicCheck := fs.Bool("skip-topic-check", false, "Skip the input topic existence check at startup")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "Maximum duration of the graceful drain on shutdown (0 waits indefinitely)")
+	strict := fs.Bool("strict", false, "Fail on unknown config fields and suspicious processors chains instead of ignoring or warning")
+	failFast := fs.Bool("fail-fast", false, "Stop at the first configuration error instead of reporting them all")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	if *maxMessages < 0 {
+		fmt.Println("-max-messages must be positive or 0")
+		return 2
+	}
+
+	window, err := parseReplayWindow(*from, *to, time.Now())
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stdout)
+
+	cfg, err := config.LoadConfigWithOptions(*configFile, logger, config.LoadOptions{Strict: *strict, FailFast: *failFast})
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	if !hasProcessor(cfg.Processors, config.ProcessorTypeTimestampReplay) {
+		logger.Warn("No timestamp_replay processor configured, records are replayed with their original timestamps")
+	}
+
+	logger.Info("Starting replay",
+		"topics_in", cfg.Input.AllTopics(),
+		"topic_out", cfg.Output.Topic,
+		"from", window.From,
+		"to", window.To,
 		"dry_run", *dryRun,
 	)
 
-	// Suite de la logique à implémenter et à appeler dans le run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go watchMetricsSnapshot(ctx, metrics.Default, logger)
+
+	opts := pipelines.RunOptions{
+		DryRun:          *dryRun,
+		SkipTopicCheck:  *skipTopicCheck,
+		MaxMessages:     *maxMessages,
+		ShutdownTimeout: *shutdownTimeout,
+		Report:          os.Stdout,
+	}
+	if err := pipelines.Replay(ctx, cfg, window, logger, opts); err != nil {
+		logger.Error("replay failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// parseReplayWindow parses the -from and -to flags, an empty -to meaning now
+func parseReplayWindow(from string, to string, now time.Time) (consumer.ReplayWindow, error) {
+	var window consumer.ReplayWindow
+	if from == "" {
+		return window, errors.New("missing -from flag")
+	}
+
+	var err error
+	window.From, err = time.Parse(time.RFC3339, from)
+	if err != nil {
+		return window, fmt.Errorf("invalid -from timestamp: %w", err)
+	}
+
+	window.To = now
+	if to != "" {
+		window.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return window, fmt.Errorf("invalid -to timestamp: %w", err)
+		}
+	}
+
+	if !window.From.Before(window.To) {
+		return window, fmt.Errorf("-from (%s) must be before -to (%s)", window.From.Format(time.RFC3339), window.To.Format(time.RFC3339))
+	}
+	return window, nil
+}
+
+// reprocessCommand consumes the dead letter topic and sends the original records through the pipeline again.
+// The records failing again go back to the dead letter topic, the dead letter records handled being committed.
+func reprocessCommand(args []string) int {
+	fs := flag.NewFlagSet("reprocess", flag.ContinueOnError)
+
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "info", "Log level (debug, info, warn, error)")
+	group := fs.String("group", "", "Consumer group of the dead letter topic (default the input consumer group suffixed with -reprocess)")
+	skipProcessors := fs.Bool("skip-processors", false, "Produce the original records to the output directly, without the processors")
+	dryRun := fs.Bool("dry-run", false, "Reprocess without writing to output nor committing offsets, then print a report of the processors effects")
+	maxMessages := fs.Int("max-messages", 0, "Stop once this many dead letter records are consumed (0 means no limit)")
+	idleTimeout := fs.Duration("idle-timeout", 0, "Stop once no dead letter record is consumed for this duration (0 means no limit)")
+	skipTopicCheck := fs.Bool("skip-topic-check", false, "Skip the dead letter topic existence check at startup")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "Maximum duration of the graceful drain on shutdown (0 waits indefinitely)")
+	strict := fs.Bool("strict", false, "Fail on unknown config fields and suspicious processors chains instead of ignoring or warning")
+	failFast := fs.Bool("fail-fast", false, "Stop at the first configuration error instead of reporting them all")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+	if *maxMessages < 0 {
+		fmt.Println("-max-messages must be positive or 0")
+		return 2
+	}
+	if *idleTimeout < 0 {
+		fmt.Println("-idle-timeout must be positive or 0")
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stdout)
+
+	cfg, err := config.LoadConfigWithOptions(*configFile, logger, config.LoadOptions{Strict: *strict, FailFast: *failFast})
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	if *group == "" {
+		*group = cfg.Input.ConsumerGroup + "-reprocess"
+	}
+	if *skipProcessors {
+		cfg.Processors = nil
+	}
+
+	logger.Info("Starting reprocessing",
+		"dlq_topic", cfg.Errors.Dlq_topic,
+		"group", *group,
+		"topic_out", cfg.Output.Topic,
+		"skip_processors", *skipProcessors,
+		"dry_run", *dryRun,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go watchMetricsSnapshot(ctx, metrics.Default, logger)
+
+	opts := pipelines.RunOptions{
+		DryRun:          *dryRun,
+		SkipTopicCheck:  *skipTopicCheck,
+		MaxMessages:     *maxMessages,
+		IdleTimeout:     *idleTimeout,
+		ShutdownTimeout: *shutdownTimeout,
+		Report:          os.Stdout,
+	}
+	if err := pipelines.Reprocess(ctx, cfg, *group, logger, opts); err != nil {
+		logger.Error("reprocessing failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// resetOffsetsCommand moves the committed offsets of the consumer group on the input topics, then prints them before and after.
+// It refuses to run while the group has active members, the pipeline must be stopped first.
+func resetOffsetsCommand(args []string) int {
+	fs := flag.NewFlagSet("reset-offsets", flag.ContinueOnError)
+
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "info", "Log level (debug, info, warn, error)")
+	to := fs.String("to", "", "Reset to the earliest or latest offset of each partition (earliest, latest)")
+	toTimestamp := fs.String("to-timestamp", "", "Reset to the first record at or after this RFC3339 timestamp")
+	dryRun := fs.Bool("dry-run", false, "Print the offsets changes without committing them")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	target, err := parseOffsetReset(*to, *toTimestamp)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stdout)
+
+	cfg, err := config.LoadConfig(*configFile, logger)
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	changes, err := consumer.ResetOffsets(ctx, &cfg.Input, target, *dryRun, logger)
+	if err != nil {
+		logger.Error("failed to reset offsets", "error", err)
+		return 1
+	}
+	writeOffsetChanges(os.Stdout, cfg.Input.ConsumerGroup, changes, *dryRun)
+	return 0
+}
+
+// parseOffsetReset parses the -to and -to-timestamp flags, exactly one of them being required
+func parseOffsetReset(to string, toTimestamp string) (consumer.OffsetReset, error) {
+	var target consumer.OffsetReset
+	switch {
+	case to == "" && toTimestamp == "":
+		return target, errors.New("missing -to or -to-timestamp flag")
+	case to != "" && toTimestamp != "":
+		return target, errors.New("-to and -to-timestamp are mutually exclusive")
+	case to != "":
+		if to != "earliest" && to != "latest" {
+			return target, fmt.Errorf("invalid -to %q, must be earliest or latest", to)
+		}
+		target.To = to
+		return target, nil
+	}
+
+	var err error
+	target.Timestamp, err = time.Parse(time.RFC3339, toTimestamp)
+	if err != nil {
+		return target, fmt.Errorf("invalid -to-timestamp: %w", err)
+	}
+	return target, nil
+}
+
+// writeOffsetChanges prints the offset of each partition before and after the reset, "-" standing for no committed offset
+func writeOffsetChanges(w io.Writer, group string, changes []consumer.OffsetChange, dryRun bool) {
+	if dryRun {
+		fmt.Fprintf(w, "Dry run, offsets of consumer group %s not committed:\n", group)
+	} else {
+		fmt.Fprintf(w, "Offsets of consumer group %s reset:\n", group)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "  no partition to reset")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TOPIC\tPARTITION\tBEFORE\tAFTER")
+	for _, c := range changes {
+		fmt.Fprintf(tw, "  %s\t%d\t%s\t%d\n", c.Topic, c.Partition, formatOffset(c.Before), c.After)
+	}
+	tw.Flush()
+}
+
+// describeTopicCommand prints the partitions of the input topics (or of -topic) with their replicas, watermarks,
+// and the committed offsets and lag of the consumer group.
+func describeTopicCommand(args []string) int {
+	fs := flag.NewFlagSet("describe-topic", flag.ContinueOnError)
+
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "warn", "Log level (debug, info, warn, error)")
+	topic := fs.String("topic", "", "Topic to describe (default the input topics)")
+	output := fs.String("output", "text", "Output format (text, json)")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Printf("Unknown output format: %s\n", *output)
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stderr)
+
+	cfg, err := config.LoadConfig(*configFile, logger)
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	topics := cfg.Input.AllTopics()
+	if *topic != "" {
+		topics = []string{*topic}
+	}
+	if len(topics) == 0 {
+		fmt.Println("The input subscribes to topic_regex, -topic is required")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	descriptions, err := consumer.DescribeTopics(ctx, &cfg.Input, topics, logger)
+	if err != nil {
+		logger.Error("failed to describe topics", "error", err)
+		return 1
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(descriptions); err != nil {
+			logger.Error("failed to encode topics description", "error", err)
+			return 1
+		}
+		return 0
+	}
+	writeTopicDescriptions(os.Stdout, descriptions)
+	return 0
+}
+
+// writeTopicDescriptions prints a table of partitions per topic, "-" standing for an unknown watermark or no committed offset
+func writeTopicDescriptions(w io.Writer, descriptions []consumer.TopicDescription) {
+	for i, d := range descriptions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Topic %s: %d partitions", d.Topic, len(d.Partitions))
+		if d.Group != "" {
+			fmt.Fprintf(w, ", consumer group %s", d.Group)
+		}
+		fmt.Fprintln(w)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := "  PARTITION\tLEADER\tREPLICAS\tISR\tLOW\tHIGH"
+		if d.Group != "" {
+			header += "\tCOMMITTED\tLAG"
+		}
+		fmt.Fprintln(tw, header)
+		for _, p := range d.Partitions {
+			fmt.Fprintf(tw, "  %d\t%d\t%s\t%s\t%s\t%s", p.Partition, p.Leader, formatBrokers(p.Replicas), formatBrokers(p.ISR),
+				formatOffset(p.Low), formatOffset(p.High))
+			if d.Group != "" {
+				fmt.Fprintf(tw, "\t%s\t%s", formatOptionalOffset(p.Committed), formatOptionalOffset(p.Lag))
+			}
+			fmt.Fprintln(tw)
+		}
+		tw.Flush()
+	}
+}
+
+// formatBrokers joins the broker ids with commas
+func formatBrokers(brokers []int32) string {
+	ids := make([]string, len(brokers))
+	for i, b := range brokers {
+		ids[i] = strconv.Itoa(int(b))
+	}
+	return strings.Join(ids, ",")
+}
+
+// formatOffset prints a negative (unknown) offset as "-"
+func formatOffset(offset int64) string {
+	if offset < 0 {
+		return "-"
+	}
+	return strconv.FormatInt(offset, 10)
+}
+
+func formatOptionalOffset(offset *int64) string {
+	if offset == nil {
+		return "-"
+	}
+	return formatOffset(*offset)
+}
+
+// peekCommand prints the first records consumed from the input topics as decoded by the input format, then exits.
+// No processor is run and no offset is committed, the consumer group being left untouched.
+func peekCommand(args []string) int {
+	fs := flag.NewFlagSet("peek", flag.ContinueOnError)
+
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "warn", "Log level (debug, info, warn, error)")
+	count := fs.Int("n", 10, "Number of records to print")
+	from := fs.String("from", "earliest", "Where to start reading each partition (earliest, latest)")
+	partition := fs.Int("partition", -1, "Partition to read (default the configured partitions, or all)")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+	if *count <= 0 {
+		fmt.Println("-n must be positive")
+		return 2
+	}
+	if *from != "earliest" && *from != "latest" {
+		fmt.Printf("invalid -from %q, must be earliest or latest\n", *from)
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stderr)
+
+	cfg, err := config.LoadConfig(*configFile, logger)
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	kc, err := consumer.NewKafkaPeekConsumer(&cfg.Input, *from, *partition, logger)
+	if err != nil {
+		logger.Error("failed to create consumer", "error", err)
+		return 1
+	}
+	defer kc.Close()
+
+	if err := kc.Connect(ctx); err != nil {
+		return 1
+	}
+	if err := kc.CheckTopic(ctx); err != nil {
+		logger.Error("input topic check failed", "error", err)
+		return 1
+	}
+	kc.Start(ctx)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	for printed := 0; printed < *count; {
+		select {
+		case <-ctx.Done():
+			return 0
+		case err := <-kc.Errors():
+			logger.Warn("failed to fetch records", "error", err)
+		case msg := <-kc.Messages():
+			if err := encoder.Encode(consumer.NewPeekRecord(msg)); err != nil {
+				logger.Error("failed to encode record", "error", err)
+				return 1
+			}
+			printed++
+		}
+	}
+	return 0
+}
+
+// hasProcessor reports whether a processor of the given type is configured
+func hasProcessor(processors []config.ProcessorConfig, processorType string) bool {
+	for _, p := range processors {
+		if p.Type == processorType {
+			return true
+		}
+	}
+	return false
+}
+
+// testCommand runs the fixture messages through the configured processors, and compares the outcome with the golden file.
+// With -update the golden file is (re)written instead. Logs are written to stderr, the diff to stdout.
+func testCommand(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "warn", "Log level (debug, info, warn, error)")
+	input := fs.String("input", "", "Fixture file of input messages, JSON lines (required)")
+	golden := fs.String("golden", "", "Golden file of expected output records, JSON lines (required)")
+	update := fs.Bool("update", false, "Regenerate the golden file from the current output")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+	if *input == "" || *golden == "" {
+		fmt.Println("missing -input or -golden flag")
+		return 2
+	}
+
+	logger := newLogger(*logLevel, os.Stderr)
+
+	cfg, err := config.LoadConfig(*configFile, logger)
+	if err != nil {
+		logErrors(logger, "failed to load config", err)
+		return 1
+	}
+
+	runner, err := pipelines.NewFixtureRunner(cfg, logger)
+	if err != nil {
+		logger.Error("failed to create pipeline", "error", err)
+		return 1
+	}
+
+	fixture, err := os.Open(*input)
+	if err != nil {
+		logger.Error("failed to open fixture file", "error", err)
+		return 1
+	}
+	defer fixture.Close()
+
+	var got strings.Builder
+	if err := runner.Run(context.Background(), fixture, &got); err != nil {
+		logger.Error("failed to run fixture", "error", err)
+		return 1
+	}
+
+	if *update {
+		if err := os.WriteFile(*golden, []byte(got.String()), 0o644); err != nil {
+			logger.Error("failed to write golden file", "error", err)
+			return 1
+		}
+		fmt.Printf("golden file %s updated\n", *golden)
+		return 0
+	}
+
+	want, err := os.ReadFile(*golden)
+	if err != nil {
+		logger.Error("failed to read golden file, run with -update to create it", "error", err)
+		return 1
+	}
+
+	diff := diffLines(string(want), got.String())
+	if len(diff) > 0 {
+		fmt.Printf("FAIL: output differs from %s\n", *golden)
+		for _, d := range diff {
+			fmt.Println(d)
+		}
+		return 1
+	}
+	fmt.Printf("PASS: output matches %s\n", *golden)
+	return 0
+}
+
+// diffLines compares the golden and actual outputs line by line, "-" lines are expected and "+" lines actual
+func diffLines(want string, got string) []string {
+	wantLines := strings.Split(strings.TrimRight(want, "\n"), "\n")
+	gotLines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+
+	var diff []string
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w == g {
+			continue
+		}
+		diff = append(diff, fmt.Sprintf("line %d:", i+1))
+		if w != "" {
+			diff = append(diff, "  - "+w)
+		}
+		if g != "" {
+			diff = append(diff, "  + "+g)
+		}
+	}
+	return diff
+}
+
+// validationResult is the machine-readable output of the validate command
+type validationResult struct {
+	Valid         bool     `json:"valid"`
+	Error         string   `json:"error,omitempty"`
+	Errors        []string `json:"errors,omitempty"` // Every validation error, Error joining them
+	InputTopics   []string `json:"input_topics,omitempty"`
+	InputBrokers  int      `json:"input_brokers"`
+	OutputTopic   string   `json:"output_topic,omitempty"`
+	OutputBrokers int      `json:"output_brokers"`
+	Processors    int      `json:"processors"`
+	Warnings      []string `json:"warnings"`
 }
 
 // validateCommand checks the configuration file to insure it's valid
-func validateCommand() {
-	fs := flag.NewFlagSet("validate", flag.ExitOnError)
-	configFile := fs.String("config", "config.yml", "Configuration file path")
+func validateCommand(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
 	logLevel := fs.String("loglevel", "info", "Log level (debug, info, warn, error)")
+	output := fs.String("output", "text", "Output format (text, json)")
+	strict := fs.Bool("strict", false, "Fail on unknown config fields and suspicious processors chains instead of ignoring or warning")
+	failFast := fs.Bool("fail-fast", false, "Stop at the first configuration error instead of reporting them all")
 
-	fs.Parse(os.Args[2:])
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
 
-	logger := newLogger(*logLevel)
+	opts := config.LoadOptions{Strict: *strict, FailFast: *failFast}
+	if *output == "json" {
+		return validateJSON(*configFile, *logLevel, opts)
+	}
+	if *output != "text" {
+		fmt.Printf("Unknown output format: %s\n", *output)
+		return 1
+	}
+
+	logger := newLogger(*logLevel, os.Stdout)
 
-	config, err := config.LoadConfig(*configFile, logger)
+	config, err := config.LoadConfigWithOptions(*configFile, logger, opts)
 	if err != nil {
-		logger.Error("validation failed", "error", err)
-		os.Exit(1)
+		logErrors(logger, "validation failed", err)
+		return 1
 	}
 
 	logger.Info("configuration is valid")
-	logger.Info("Input", "topic", config.Input.Topic, "brokers", len(config.Input.Brokers))
+	logger.Info("Input", "topics", config.Input.AllTopics(), "brokers", len(config.Input.Brokers))
 	logger.Info("Output", "topic", config.Output.Topic, "brokers", len(config.Output.Brokers))
+	return 0
+}
+
+// validateJSON prints the validation result as JSON on stdout, logs are written to stderr.
+// It returns the exit code of the command.
+func validateJSON(configFile string, logLevel string, opts config.LoadOptions) int {
+	collector := newWarningCollector(newLogger(logLevel, os.Stderr).Handler())
+	logger := slog.New(collector)
+
+	result := validationResult{Valid: true}
+	cfg, err := config.LoadConfigWithOptions(configFile, logger, opts)
+	if err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+		result.Errors = splitErrors(err)
+	} else {
+		result.InputTopics = cfg.Input.AllTopics()
+		result.InputBrokers = len(cfg.Input.Brokers)
+		result.OutputTopic = cfg.Output.Topic
+		result.OutputBrokers = len(cfg.Output.Brokers)
+		result.Processors = len(cfg.Processors)
+	}
+	result.Warnings = collector.Warnings()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		logger.Error("failed to encode validation result", "error", err)
+		return 1
+	}
+
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
+
+// logErrors logs each error joined in err on its own line
+func logErrors(logger *slog.Logger, msg string, err error) {
+	for _, e := range splitErrors(err) {
+		logger.Error(msg, "error", e)
+	}
+}
+
+// splitErrors returns the messages of the errors joined in err (see errors.Join), or its own message
+func splitErrors(err error) []string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	var messages []string
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, splitErrors(e)...)
+	}
+	return messages
+}
+
+// configCommand prints the effective configuration, once validated and with all defaults applied.
+// Logs are written to stderr so that stdout only holds the YAML document.
+func configCommand(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yml", "Configuration file path or http(s) URL")
+	logLevel := fs.String("loglevel", "warn", "Log level (debug, info, warn, error)")
+
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	logger := newLogger(*logLevel, os.Stderr)
+
+	cfg, err := config.LoadConfig(*configFile, logger)
+	if err != nil {
+		logErrors(logger, "validation failed", err)
+		return 1
+	}
+
+	content, err := config.MarshalRedacted(cfg)
+	if err != nil {
+		logger.Error("failed to print config", "error", err)
+		return 1
+	}
+	fmt.Print(string(content))
+	return 0
+}
+
+// schemaCommand prints the JSON Schema of the configuration file, for the editors to validate and autocomplete it
+func schemaCommand(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	content, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to print schema:", err)
+		return 1
+	}
+	fmt.Println(string(content))
+	return 0
 }
 
 // printUsage displays the usage information for the CLI application.
@@ -107,23 +909,115 @@ Usage:
 
 Commands:
   run       Start the Kafka pipeline
+  replay    Replay the input records of a time window through the pipeline, then exit
+  reprocess Send the original records of the dead letter topic through the pipeline again
+  peek      Print the first records of the input topics as decoded JSON, without processing nor committing
+  describe-topic
+            Print the partitions of the input topics: replicas, watermarks, committed offsets and lag
+  reset-offsets
+            Reset the consumer group offsets on the input topics (the group must have no active member)
   validate  Validate the configuration file
-  version   Show version information
+  test      Run a fixture of messages through the processors and compare with a golden file
+  config    Print the effective configuration (defaults applied, secrets redacted)
+  schema    Print the JSON Schema of the configuration file, for editor validation and autocompletion
+  version   Show version information (also available as --version or -v)
   help      Show this help message
 
 Global flags:
   -config string
-        Configuration file path (default "config.yml")
+        Configuration file path or http(s) URL, fetched without authentication (default "config.yml")
   -loglevel string
         Log level: debug, info, warn, error (default "info")
 
+Validate-specific flags (-strict and -fail-fast also apply to run, replay and reprocess):
+  -output string
+        Output format: text, json (default "text"), also for describe-topic
+  -strict
+        Fail on unknown configuration fields (e.g. broker instead of brokers), reported with their line,
+        and on suspicious processors chains (e.g. a field written twice), instead of ignoring or warning
+  -fail-fast
+        Stop at the first configuration error instead of reporting the errors of every section and processor
+
+Test-specific flags:
+  -input string
+        Fixture file of input messages, JSON lines (required)
+  -golden string
+        Golden file of expected output records, JSON lines (required)
+  -update
+        Regenerate the golden file from the current output
+
 Run-specific flags:
   -dry-run
-        Run without writing to output (validation only)
+        Process messages without writing to output nor committing offsets, then print a report of each
+        processor effects: messages passed, modified, dropped, and the fields added, removed or changed.
+        The run joins the consumer group, prefer a dedicated group or replay -dry-run on a live pipeline
+  -max-messages int
+        Stop once this many messages are consumed, e.g. to dry run a bounded sample (0 means no limit)
+  -idle-timeout duration
+        Stop once no message is consumed for this duration and the last ones are produced, then drain and
+        exit 0, e.g. for a cron or Kubernetes Job processing a topic until it is drained (0 means no limit)
+  -skip-topic-check
+        Skip the input topic existence check (topic expected to be created later)
+  -shutdown-timeout duration
+        Maximum duration of the graceful drain on shutdown, 0 waits indefinitely (default 30s)
+
+Replay-specific flags (also accepts -dry-run, -max-messages, -skip-topic-check and -shutdown-timeout):
+  -from string
+        Start of the replay window, RFC3339 timestamp (required)
+  -to string
+        End of the replay window, RFC3339 timestamp (default now)
+
+Reprocess-specific flags (also accepts -dry-run, -max-messages, -idle-timeout, -skip-topic-check and -shutdown-timeout).
+The records failing again are sent back to the dead letter topic, whatever the errors policy:
+  -group string
+        Consumer group of the dead letter topic, a dead letter record being reprocessed once committed
+        (default the input consumer group suffixed with -reprocess)
+  -skip-processors
+        Produce the original records to the output directly, without the processors
+
+Peek-specific flags:
+  -n int
+        Number of records to print before exiting (default 10)
+  -from string
+        Where to start reading each partition: earliest, latest (default "earliest")
+  -partition int
+        Partition to read (default the configured partitions, or all)
+
+Describe-topic-specific flags (also accepts -output):
+  -topic string
+        Topic to describe (default the input topics)
+
+Reset-offsets-specific flags (-to or -to-timestamp required):
+  -to string
+        Reset to the earliest or latest offset of each partition: earliest, latest
+  -to-timestamp string
+        Reset to the first record at or after this RFC3339 timestamp
+  -dry-run
+        Print the offsets before and after without committing them
+
+Signals (run, replay and reprocess):
+  SIGINT, SIGTERM  Graceful shutdown
+  SIGUSR1          Log a snapshot of every metric (consumed, produced, dropped, errors, per processor, lag)
+  SIGHUP           Reload the processors from the config file (run only). In-flight messages finish on the
+                   previous processors, a change outside of the processors section is rejected until a restart
 
 Examples:
   etelgo run -config config.yml
   etelgo run -config config.yml -loglevel debug
   etelgo run -config config.yml -dry-run -metrics-interval 10s
-  etelgo validate -config config.yml`)
+  etelgo run -config config.yml -idle-timeout 2m
+  etelgo replay -config config.yml -from 2026-01-01T00:00:00Z -dry-run -max-messages 1000
+  etelgo replay -config config.yml -from 2026-01-01T00:00:00Z -to 2026-01-02T00:00:00Z
+  etelgo reprocess -config config.yml -dry-run -max-messages 100
+  etelgo reprocess -config config.yml -skip-processors -idle-timeout 30s
+  etelgo peek -config config.yml -n 5 -from latest -partition 0
+  etelgo describe-topic -config config.yml -output json
+  etelgo reset-offsets -config config.yml -to-timestamp 2026-01-01T00:00:00Z -dry-run
+  etelgo reset-offsets -config config.yml -to latest
+  etelgo validate -config config.yml
+  etelgo validate -config config.yml -output json
+  etelgo validate -config config.yml -strict
+  etelgo test -config config.yml -input testdata/in.jsonl -golden testdata/out.golden.jsonl
+  etelgo config -config config.yml
+  etelgo schema > etelgo.schema.json`)
 }
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"No arguments", []string{}, "", true},
+		{"Nil arguments", nil, "", true},
+		{"Command only", []string{"run"}, "run", false},
+		{"Command with flags", []string{"validate", "-config", "config.yml"}, "validate", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommand(tt.args)
+			if tt.wantErr && err == nil {
+				t.Errorf("parseCommand() error = nil, wantErr = true")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("parseCommand() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"No arguments", []string{}, 1},
+		{"Unknown command", []string{"unknown"}, 1},
+		{"Help", []string{"help"}, 0},
+		{"Version", []string{"version"}, 0},
+		{"Version long flag", []string{"--version"}, 0},
+		{"Version short flag", []string{"-v"}, 0},
+		{"Validate missing config file", []string{"validate", "-config", "does-not-exist.yml", "-loglevel", "error"}, 1},
+		{"Validate unknown flag", []string{"validate", "-unknown"}, 2},
+		{"Validate help", []string{"validate", "-h"}, 0},
+		{"Validate unknown output format", []string{"validate", "-output", "xml"}, 1},
+		{"Run help", []string{"run", "-help"}, 0},
+		{"Run invalid flag value", []string{"run", "-max-messages", "many"}, 2},
+		{"Config help", []string{"config", "-h"}, 0},
+		{"Replay missing from", []string{"replay", "-config", "does-not-exist.yml"}, 2},
+		{"Replay missing config file", []string{"replay", "-config", "does-not-exist.yml", "-from", "2026-01-01T00:00:00Z", "-loglevel", "error"}, 1},
+		{"Config missing config file", []string{"config", "-config", "does-not-exist.yml", "-loglevel", "error"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args); got != tt.wantCode {
+				t.Errorf("run(%v) = %d, want %d", tt.args, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParseReplayWindow(t *testing.T) {
+	now := time.Date(2026, 1, 23, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantTo  time.Time
+		wantErr bool
+	}{
+		{"Bounded window", "2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"Open window ends now", "2026-01-01T00:00:00Z", "", now, false},
+		{"Missing from", "", "2026-01-02T00:00:00Z", time.Time{}, true},
+		{"Invalid from", "yesterday", "", time.Time{}, true},
+		{"Invalid to", "2026-01-01T00:00:00Z", "2026-01-02", time.Time{}, true},
+		{"From after to", "2026-01-02T00:00:00Z", "2026-01-01T00:00:00Z", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := parseReplayWindow(tt.from, tt.to, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseReplayWindow() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReplayWindow() unexpected error = %v", err)
+			}
+			if !window.To.Equal(tt.wantTo) {
+				t.Errorf("parseReplayWindow() To = %v, want %v", window.To, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestParseOffsetReset(t *testing.T) {
+	tests := []struct {
+		name          string
+		to            string
+		toTimestamp   string
+		wantTo        string
+		wantTimestamp time.Time
+		wantErr       bool
+	}{
+		{"Earliest", "earliest", "", "earliest", time.Time{}, false},
+		{"Latest", "latest", "", "latest", time.Time{}, false},
+		{"Timestamp", "", "2026-01-01T00:00:00Z", "", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"Missing target", "", "", "", time.Time{}, true},
+		{"Both targets", "earliest", "2026-01-01T00:00:00Z", "", time.Time{}, true},
+		{"Unknown target", "oldest", "", "", time.Time{}, true},
+		{"Invalid timestamp", "", "2026-01-01", "", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := parseOffsetReset(tt.to, tt.toTimestamp)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseOffsetReset() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOffsetReset() unexpected error = %v", err)
+			}
+			if target.To != tt.wantTo || !target.Timestamp.Equal(tt.wantTimestamp) {
+				t.Errorf("parseOffsetReset() = %+v, want to %q at %v", target, tt.wantTo, tt.wantTimestamp)
+			}
+		})
+	}
+}
+
+func TestWriteOffsetChanges(t *testing.T) {
+	var out strings.Builder
+	writeOffsetChanges(&out, "etl", []consumer.OffsetChange{
+		{Topic: "orders", Partition: 0, Before: 42, After: 0},
+		{Topic: "orders", Partition: 1, Before: -1, After: 0},
+	}, false)
+
+	want := "Offsets of consumer group etl reset:\n" +
+		"  TOPIC   PARTITION  BEFORE  AFTER\n" +
+		"  orders  0          42      0\n" +
+		"  orders  1          -       0\n"
+	if out.String() != want {
+		t.Errorf("writeOffsetChanges() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteTopicDescriptions(t *testing.T) {
+	committed, lag := int64(90), int64(10)
+	var out strings.Builder
+	writeTopicDescriptions(&out, []consumer.TopicDescription{{
+		Topic: "orders",
+		Group: "etl",
+		Partitions: []consumer.PartitionDescription{
+			{Partition: 0, Leader: 1, Replicas: []int32{1, 2}, ISR: []int32{1, 2}, Low: 5, High: 100, Committed: &committed, Lag: &lag},
+			{Partition: 1, Leader: 2, Replicas: []int32{2, 3}, ISR: []int32{2}, Low: -1, High: 30},
+		},
+	}})
+
+	want := "Topic orders: 2 partitions, consumer group etl\n" +
+		"  PARTITION  LEADER  REPLICAS  ISR  LOW  HIGH  COMMITTED  LAG\n" +
+		"  0          1       1,2       1,2  5    100   90         10\n" +
+		"  1          2       2,3       2    -    30    -          -\n"
+	if out.String() != want {
+		t.Errorf("writeTopicDescriptions() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	info := buildInfo()
+	if !strings.Contains(info, runtime.Version()) {
+		t.Errorf("buildInfo() = %q, should contain the Go version %s", info, runtime.Version())
+	}
+	if !strings.Contains(info, "commit: dev") || !strings.Contains(info, "built: unknown") {
+		t.Errorf("buildInfo() = %q, should fall back on dev/unknown when unset", info)
+	}
+
+	Commit, BuildDate = "abc1234", "2026-01-23T10:00:00Z"
+	defer func() { Commit, BuildDate = "", "" }()
+
+	info = buildInfo()
+	if !strings.Contains(info, "commit: abc1234") || !strings.Contains(info, "built: 2026-01-23T10:00:00Z") {
+		t.Errorf("buildInfo() = %q, should report the ldflags values", info)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff []string
+	}{
+		{"Identical", "a\nb\n", "a\nb\n", nil},
+		{"Both empty", "", "", nil},
+		{"Changed line", "a\nb\n", "a\nc\n", []string{"line 2:", "  - b", "  + c"}},
+		{"Missing line", "a\nb\n", "a\n", []string{"line 2:", "  - b"}},
+		{"Extra line", "a\n", "a\nb\n", []string{"line 2:", "  + b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffLines(tt.want, tt.got)
+			if strings.Join(diff, "\n") != strings.Join(tt.diff, "\n") {
+				t.Errorf("diffLines() = %q, want %q", diff, tt.diff)
+			}
+		})
+	}
+}
+
+func TestLogMetricsSnapshot(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("etelgo_produced_records_total").Add(3)
+	registry.Gauge("etelgo_consumer_lag", "topic", "orders", "partition", "0").Set(12)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// Safe to repeat, each call logs the current values
+	logMetricsSnapshot(registry, logger)
+	registry.Counter("etelgo_produced_records_total").Inc()
+	logMetricsSnapshot(registry, logger)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{`msg="Metrics snapshot"`, "etelgo_produced_records_total=3", `"etelgo_consumer_lag{topic=\"orders\",partition=\"0\"}"=12`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("first snapshot = %s, want it to contain %s", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], "etelgo_produced_records_total=4") {
+		t.Errorf("second snapshot = %s, want the updated counter", lines[1])
+	}
+	if strings.Index(lines[0], "etelgo_consumer_lag") > strings.Index(lines[0], "etelgo_produced_records_total") {
+		t.Errorf("snapshot = %s, want metrics sorted by name", lines[0])
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	err := errors.Join(errors.New("input: topic required"), errors.Join(errors.New("output: bad format"), errors.New("processor 0: bad")))
+	got := splitErrors(err)
+	want := []string{"input: topic required", "output: bad format", "processor 0: bad"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitErrors() = %q, want %q", got, want)
+	}
+
+	if got := splitErrors(errors.New("single")); len(got) != 1 || got[0] != "single" {
+		t.Errorf("splitErrors(single) = %q", got)
+	}
+}
@@ -1 +1,51 @@
 package main
+
+import (
+	"context"
+	"etelgo/metrics"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+)
+
+// watchMetricsSnapshot logs every metric each time a snapshot signal (SIGUSR1, see snapshotSignals) is received,
+// until ctx is done. It gives the metrics of machines where the monitoring port isn't exposed, e.g. `kill -USR1 <pid>`.
+// Signals received while a snapshot is logged are coalesced into the next one. The shutdown signals are not handled here.
+func watchMetricsSnapshot(ctx context.Context, registry *metrics.Registry, logger *slog.Logger) {
+	if len(snapshotSignals) == 0 {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, snapshotSignals...)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			logMetricsSnapshot(registry, logger)
+		}
+	}
+}
+
+// logMetricsSnapshot logs the current value of every metric in a single record, sorted by name and labels
+func logMetricsSnapshot(registry *metrics.Registry, logger *slog.Logger) {
+	snapshot := registry.Snapshot()
+
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]any, 0, len(keys))
+	for _, key := range keys {
+		// Unlabelled metrics are keyed by their name alone, as in the Prometheus format
+		attrs = append(attrs, slog.Int64(strings.TrimSuffix(key, "{}"), snapshot[key]))
+	}
+	logger.Info("Metrics snapshot", attrs...)
+}
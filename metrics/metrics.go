@@ -0,0 +1,265 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Minimal metrics registry exposed in the Prometheus text format.
+// Every package registers its metrics in the Default registry, which is served by the monitoring endpoint.
+
+type Counter struct {
+	value atomic.Int64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n int64) {
+	c.value.Add(n)
+}
+
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+type Gauge struct {
+	value atomic.Int64
+}
+
+func (g *Gauge) Set(v int64) {
+	g.value.Store(v)
+}
+
+func (g *Gauge) Add(n int64) {
+	g.value.Add(n)
+}
+
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+// Histogram counts the observed values in buckets of increasing upper bounds, with their count and sum
+type Histogram struct {
+	bounds []float64
+	counts []atomic.Int64 // By bucket, not cumulative, the last one counting the values above the highest bound
+	count  atomic.Int64
+	sum    atomic.Uint64 // Bits of the float64 sum
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+func (h *Histogram) Sum() float64 {
+	return math.Float64frombits(h.sum.Load())
+}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+type metric struct {
+	name      string
+	labels    string // Rendered labels, e.g. `topic="orders"`
+	kind      string
+	value     func() int64 // Counters and gauges
+	histogram *Histogram   // Histograms
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[string]*Counter
+	gauges     map[string]*Gauge
+	histograms map[string]*Histogram
+	metrics    map[string]*metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*Counter),
+		gauges:     make(map[string]*Gauge),
+		histograms: make(map[string]*Histogram),
+		metrics:    make(map[string]*metric),
+	}
+}
+
+// Default is the registry used by the whole application
+var Default = NewRegistry()
+
+// renderLabels formats key/value pairs as Prometheus labels, keeping the given order
+func renderLabels(labels []string) string {
+	if len(labels)%2 != 0 {
+		panic("metrics: labels must be key/value pairs")
+	}
+	parts := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func metricKey(name, labels string) string {
+	return name + "{" + labels + "}"
+}
+
+// Counter returns the counter with the given name and label pairs, creating it on first use.
+func (r *Registry) Counter(name string, labels ...string) *Counter {
+	rendered := renderLabels(labels)
+	key := metricKey(name, rendered)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.counters[key]; ok {
+		return c
+	}
+	c := &Counter{}
+	r.counters[key] = c
+	r.metrics[key] = &metric{name: name, labels: rendered, kind: typeCounter, value: c.Value}
+	return c
+}
+
+// Gauge returns the gauge with the given name and label pairs, creating it on first use.
+func (r *Registry) Gauge(name string, labels ...string) *Gauge {
+	rendered := renderLabels(labels)
+	key := metricKey(name, rendered)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if g, ok := r.gauges[key]; ok {
+		return g
+	}
+	g := &Gauge{}
+	r.gauges[key] = g
+	r.metrics[key] = &metric{name: name, labels: rendered, kind: typeGauge, value: g.Value}
+	return g
+}
+
+// Histogram returns the histogram with the given name, bucket upper bounds (sorted) and label pairs, creating it on first use.
+// The bounds of an existing histogram are kept.
+func (r *Registry) Histogram(name string, bounds []float64, labels ...string) *Histogram {
+	rendered := renderLabels(labels)
+	key := metricKey(name, rendered)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.histograms[key]; ok {
+		return h
+	}
+	h := &Histogram{bounds: bounds, counts: make([]atomic.Int64, len(bounds)+1)}
+	r.histograms[key] = h
+	r.metrics[key] = &metric{name: name, labels: rendered, kind: typeHistogram, histogram: h}
+	return h
+}
+
+// Snapshot returns the current value of every metric, keyed by name and labels (e.g. `name{topic="orders"}`).
+// A histogram is reported by its count of observations, keyed as `name_count{...}`.
+func (r *Registry) Snapshot() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]int64, len(r.metrics))
+	for key, m := range r.metrics {
+		if m.histogram != nil {
+			snapshot[metricKey(m.name+"_count", m.labels)] = m.histogram.Count()
+			continue
+		}
+		snapshot[key] = m.value()
+	}
+	return snapshot
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition format, sorted by name.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	metrics := make([]*metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].name != metrics[j].name {
+			return metrics[i].name < metrics[j].name
+		}
+		return metrics[i].labels < metrics[j].labels
+	})
+
+	lastName := ""
+	for _, m := range metrics {
+		if m.name != lastName {
+			if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind); err != nil {
+				return err
+			}
+			lastName = m.name
+		}
+		if m.histogram != nil {
+			if err := writeHistogram(w, m); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", seriesName(m.name, m.labels), m.value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHistogram writes the cumulative buckets of the histogram, then its sum and count
+func writeHistogram(w io.Writer, m *metric) error {
+	h := m.histogram
+	labelsWith := func(le string) string {
+		if m.labels == "" {
+			return fmt.Sprintf("le=%q", le)
+		}
+		return m.labels + fmt.Sprintf(",le=%q", le)
+	}
+
+	var cumulative int64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		le := strconv.FormatFloat(bound, 'g', -1, 64)
+		if _, err := fmt.Fprintf(w, "%s %d\n", seriesName(m.name+"_bucket", labelsWith(le)), cumulative); err != nil {
+			return err
+		}
+	}
+	cumulative += h.counts[len(h.bounds)].Load()
+	if _, err := fmt.Fprintf(w, "%s %d\n", seriesName(m.name+"_bucket", labelsWith("+Inf")), cumulative); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s %s\n", seriesName(m.name+"_sum", m.labels), strconv.FormatFloat(h.Sum(), 'g', -1, 64)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %d\n", seriesName(m.name+"_count", m.labels), cumulative)
+	return err
+}
+
+// seriesName is the name of a series in the Prometheus format, its labels between braces unless there is none
+func seriesName(name, labels string) string {
+	if labels == "" {
+		return name
+	}
+	return name + "{" + labels + "}"
+}
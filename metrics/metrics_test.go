@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_CounterAndGauge(t *testing.T) {
+	registry := NewRegistry()
+
+	registry.Counter("etelgo_messages_total", "topic", "orders").Inc()
+	registry.Counter("etelgo_messages_total", "topic", "orders").Add(2)
+	registry.Counter("etelgo_messages_total", "topic", "payments").Inc()
+	registry.Gauge("etelgo_state").Set(2)
+
+	if got := registry.Counter("etelgo_messages_total", "topic", "orders").Value(); got != 3 {
+		t.Errorf("expected counter value 3, got %d", got)
+	}
+
+	snapshot := registry.Snapshot()
+	if snapshot[`etelgo_messages_total{topic="payments"}`] != 1 {
+		t.Errorf("unexpected snapshot: %v", snapshot)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error = %v", err)
+	}
+	want := `# TYPE etelgo_messages_total counter
+etelgo_messages_total{topic="orders"} 3
+etelgo_messages_total{topic="payments"} 1
+# TYPE etelgo_state gauge
+etelgo_state 2
+`
+	if buf.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	registry := NewRegistry()
+
+	h := registry.Histogram("etelgo_fetch_records", []float64{1, 10}, "topic", "orders")
+	for _, v := range []float64{1, 5, 10, 50} {
+		h.Observe(v)
+	}
+	if h.Count() != 4 || h.Sum() != 66 {
+		t.Errorf("expected count 4 and sum 66, got %d and %g", h.Count(), h.Sum())
+	}
+	if registry.Histogram("etelgo_fetch_records", []float64{1, 10}, "topic", "orders") != h {
+		t.Error("expected the existing histogram to be returned")
+	}
+
+	if got := registry.Snapshot()[`etelgo_fetch_records_count{topic="orders"}`]; got != 4 {
+		t.Errorf("expected the snapshot to hold the count, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error = %v", err)
+	}
+	want := `# TYPE etelgo_fetch_records histogram
+etelgo_fetch_records_bucket{topic="orders",le="1"} 1
+etelgo_fetch_records_bucket{topic="orders",le="10"} 3
+etelgo_fetch_records_bucket{topic="orders",le="+Inf"} 4
+etelgo_fetch_records_sum{topic="orders"} 66
+etelgo_fetch_records_count{topic="orders"} 4
+`
+	if buf.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderLabels_OddPairsPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "key/value") {
+			t.Errorf("expected panic on odd label pairs, got %v", r)
+		}
+	}()
+	renderLabels([]string{"topic"})
+}
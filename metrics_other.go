@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// snapshotSignals is empty where SIGUSR1 doesn't exist, the snapshot is then only available through the metrics endpoint
+var snapshotSignals []os.Signal
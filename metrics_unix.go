@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// snapshotSignals trigger a metrics snapshot in the logs, see watchMetricsSnapshot
+var snapshotSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,99 @@
+package outputs
+
+import (
+	"sync"
+	"time"
+)
+
+type BreakerState int64
+
+const (
+	BreakerClosed   BreakerState = iota // Records are produced normally
+	BreakerOpen                         // Too many consecutive failures, records are buffered
+	BreakerHalfOpen                     // Cooldown elapsed, a single probe decides whether to close or reopen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker opens after a number of consecutive produce failures, so that a real outage
+// is surfaced instead of being masked by endless retries. A threshold of 0 disables it.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+
+	now           func() time.Time
+	onStateChange func(BreakerState)
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration, onStateChange func(BreakerState)) *CircuitBreaker {
+	if onStateChange == nil {
+		onStateChange = func(BreakerState) {}
+	}
+	return &CircuitBreaker{
+		threshold:     threshold,
+		cooldown:      cooldown,
+		now:           time.Now,
+		onStateChange: onStateChange,
+	}
+}
+
+// setState must be called with the lock held
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if state == BreakerOpen {
+		b.openedAt = b.now()
+	}
+	b.onStateChange(state)
+}
+
+// State returns the current state, moving from open to half-open once the cooldown has elapsed.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.setState(BreakerHalfOpen)
+	}
+	return b.state
+}
+
+// RecordSuccess resets the consecutive failures and closes a half-open breaker.
+// Successes reported while open come from records sent before the opening and are ignored.
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		return
+	}
+	b.failures = 0
+	b.setState(BreakerClosed)
+}
+
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.threshold > 0 && b.failures >= b.threshold) {
+		b.setState(BreakerOpen)
+	}
+}
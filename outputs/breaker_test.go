@@ -0,0 +1,83 @@
+package outputs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 23, 10, 0, 0, 0, time.UTC)
+	var transitions []BreakerState
+
+	breaker := NewCircuitBreaker(3, time.Minute, func(s BreakerState) { transitions = append(transitions, s) })
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	breaker.RecordFailure()
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed below threshold, got %s", breaker.State())
+	}
+
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+	breaker.RecordFailure()
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("a success should reset the consecutive failures, got %s", breaker.State())
+	}
+
+	breaker.RecordFailure()
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("expected open after 3 consecutive failures, got %s", breaker.State())
+	}
+
+	now = now.Add(30 * time.Second)
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("expected open during cooldown, got %s", breaker.State())
+	}
+
+	now = now.Add(30 * time.Second)
+	if breaker.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open after cooldown, got %s", breaker.State())
+	}
+
+	breaker.RecordFailure()
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("a failed probe should reopen, got %s", breaker.State())
+	}
+
+	now = now.Add(time.Minute)
+	breaker.State()
+	breaker.RecordSuccess()
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("a successful probe should close, got %s", breaker.State())
+	}
+
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transitions[%d] = %s, want %s", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Minute, nil)
+	for i := 0; i < 100; i++ {
+		breaker.RecordFailure()
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("a zero threshold should never open, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_LateSuccessWhileOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Hour, nil)
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	if breaker.State() != BreakerOpen {
+		t.Errorf("a success reported while open should be ignored, got %s", breaker.State())
+	}
+}
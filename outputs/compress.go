@@ -0,0 +1,45 @@
+package outputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressor applies the message level compression of a payload, on top of the Kafka batch compression.
+type compressor func(data []byte) ([]byte, error)
+
+// newCompressor returns the compressor of the codec, nil when the payloads are not compressed
+func newCompressor(codec string) (compressor, error) {
+	switch codec {
+	case "", "none":
+		return nil, nil
+	case "gzip":
+		return gzipPayload, nil
+	case "zstd":
+		// A single encoder is safe for concurrent use through EncodeAll
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		return func(data []byte) ([]byte, error) {
+			return encoder.EncodeAll(data, nil), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload compression: %s", codec)
+	}
+}
+
+func gzipPayload(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
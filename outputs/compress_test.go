@@ -0,0 +1,51 @@
+package outputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressor(t *testing.T) {
+	payload := []byte(`{"id": 1}`)
+
+	gunzip := func(data []byte) ([]byte, error) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(reader)
+	}
+	decoder, _ := zstd.NewReader(nil)
+	unzstd := func(data []byte) ([]byte, error) {
+		return decoder.DecodeAll(data, nil)
+	}
+
+	tests := []struct {
+		codec      string
+		decompress func([]byte) ([]byte, error)
+	}{
+		{"gzip", gunzip},
+		{"zstd", unzstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codec, func(t *testing.T) {
+			compress, err := newCompressor(tt.codec)
+			if err != nil {
+				t.Fatalf("newCompressor() unexpected error = %v", err)
+			}
+			compressed, err := compress(payload)
+			if err != nil {
+				t.Fatalf("compress() unexpected error = %v", err)
+			}
+			got, err := tt.decompress(compressed)
+			if err != nil || !bytes.Equal(got, payload) {
+				t.Errorf("round trip = %q, %v, want %q", got, err, payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package outputs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+)
+
+// CSVSerializer encodes the fields as a single CSV data row, optionally preceded by a header row.
+// Columns are written in the configured order, or in alphabetical order when none is configured.
+// Fields missing from the message are written empty, fields not part of the configured columns are dropped.
+type CSVSerializer struct {
+	Delimiter rune
+	Header    bool
+	Columns   []string
+}
+
+func (s *CSVSerializer) Serialize(fields map[string]interface{}) ([]byte, error) {
+	columns := s.Columns
+	if len(columns) == 0 {
+		columns = make([]string, 0, len(fields))
+		for key := range fields {
+			columns = append(columns, key)
+		}
+		sort.Strings(columns)
+	}
+
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		if val, ok := fields[column]; ok && val != nil {
+			row[i] = fmt.Sprint(val)
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = s.Delimiter
+	if s.Header {
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Write(row); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	// One message per row, the trailing newline is not needed
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,28 @@
+package outputs
+
+import "testing"
+
+func TestCSVSerializer(t *testing.T) {
+	fields := map[string]interface{}{"name": "Doe, John", "id": int64(1), "extra": true}
+
+	tests := []struct {
+		name       string
+		serializer *CSVSerializer
+		want       string
+	}{
+		{"Alphabetical order with header", &CSVSerializer{Delimiter: ',', Header: true}, "extra,id,name\ntrue,1,\"Doe, John\""},
+		{"Configured columns", &CSVSerializer{Delimiter: ';', Columns: []string{"id", "name", "missing"}}, "1;Doe, John;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.serializer.Serialize(fields)
+			if err != nil {
+				t.Fatalf("Serialize() unexpected error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Serialize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
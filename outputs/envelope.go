@@ -0,0 +1,69 @@
+package outputs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/processors"
+	"time"
+)
+
+// deadLetterEnvelope wraps the original record of a failed message into a JSON object describing the failure,
+// holding the fields of a validated DLQEnvelopeConfig. The key and value are base64 encoded unless raw,
+// raw payloads being written as strings (the invalid UTF-8 sequences are then replaced).
+type deadLetterEnvelope struct {
+	fields map[string]bool
+	raw    bool
+	now    func() time.Time
+}
+
+func newDeadLetterEnvelope(cfg *config.DLQEnvelopeConfig) *deadLetterEnvelope {
+	e := &deadLetterEnvelope{
+		fields: make(map[string]bool, len(cfg.Fields)),
+		raw:    *cfg.Payload_encoding == "raw",
+		now:    time.Now,
+	}
+	for _, field := range cfg.Fields {
+		e.fields[field] = true
+	}
+	return e
+}
+
+// encode returns the envelope of the message failing with cause. A missing key or value is null.
+func (e *deadLetterEnvelope) encode(msg *consumer.Message, cause error) ([]byte, error) {
+	envelope := make(map[string]interface{}, len(e.fields))
+	set := func(field string, value interface{}) {
+		if e.fields[field] {
+			envelope[field] = value
+		}
+	}
+
+	set("key", e.payload(msg.Key))
+	set("value", e.payload(msg.Value))
+	set("topic", msg.Topic)
+	set("partition", msg.Partition)
+	set("offset", msg.Offset)
+	set("error", cause.Error())
+	set("timestamp", e.now().UTC().Format(time.RFC3339Nano))
+	if msg.Headers != nil {
+		set("headers", msg.Headers)
+	}
+	var processorErr *processors.ProcessorError
+	if errors.As(cause, &processorErr) {
+		set("processor", processorErr.Processor)
+	}
+
+	return json.Marshal(envelope)
+}
+
+func (e *deadLetterEnvelope) payload(data []byte) interface{} {
+	switch {
+	case data == nil:
+		return nil
+	case e.raw:
+		return string(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,71 @@
+package outputs
+
+import (
+	"encoding/json"
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/processors"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterEnvelope(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	msg := &consumer.Message{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    42,
+		Value:     []byte(`{"id":1}`),
+		Headers:   map[string]string{"trace-id": "abc"},
+	}
+	cause := &processors.ProcessorError{Processor: "schema", Err: errors.New("field id: expected string")}
+
+	tests := []struct {
+		name     string
+		encoding string
+		fields   []string
+		cause    error
+		want     string
+	}{
+		{
+			name:     "Default fields, base64",
+			encoding: "base64",
+			fields:   []string{"key", "value", "topic", "partition", "offset", "processor", "error", "timestamp"},
+			cause:    cause,
+			want:     `{"error":"processor schema: field id: expected string","key":null,"offset":42,"partition":2,"processor":"schema","timestamp":"2026-01-02T03:04:05Z","topic":"orders","value":"eyJpZCI6MX0="}`,
+		},
+		{
+			name:     "Raw value with headers",
+			encoding: "raw",
+			fields:   []string{"value", "headers", "error"},
+			cause:    cause,
+			want:     `{"error":"processor schema: field id: expected string","headers":{"trace-id":"abc"},"value":"{\"id\":1}"}`,
+		},
+		{
+			name:     "Not a processor failure",
+			encoding: "raw",
+			fields:   []string{"processor", "error"},
+			cause:    errors.New("failed to deserialize message value"),
+			want:     `{"error":"failed to deserialize message value"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope := newDeadLetterEnvelope(&config.DLQEnvelopeConfig{Fields: tt.fields, Payload_encoding: &tt.encoding})
+			envelope.now = now
+
+			got, err := envelope.encode(msg, tt.cause)
+			if err != nil {
+				t.Fatalf("encode() unexpected error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("encode() = %s, want %s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("encode() = %s, want valid JSON", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package outputs
+
+import (
+	"etelgo/config"
+	"etelgo/consumer"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// outputHeaders renders the headers stamped on the produced records (OutputConfig.Headers, validated),
+// the values without variable being rendered once
+type outputHeaders struct {
+	static    map[string]string
+	templates map[string]string
+}
+
+// newOutputHeaders returns nil without headers
+func newOutputHeaders(headers map[string]string) *outputHeaders {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := &outputHeaders{static: make(map[string]string), templates: make(map[string]string)}
+	for name, value := range headers {
+		if config.HeaderTemplateVariable.MatchString(value) {
+			h.templates[name] = value
+		} else {
+			h.static[name] = value
+		}
+	}
+	return h
+}
+
+// apply returns the headers of the message with the output headers, the message headers being left untouched
+func (h *outputHeaders) apply(msg *consumer.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers)+len(h.static)+len(h.templates))
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+	for name, value := range h.static {
+		headers[name] = value
+	}
+	for name, template := range h.templates {
+		headers[name] = config.HeaderTemplateVariable.ReplaceAllStringFunc(template, func(match string) string {
+			return variableValue(msg, match[2:len(match)-1])
+		})
+	}
+	return headers
+}
+
+// variableValue returns the value of a validated header variable, see config.HeaderTemplateVariable
+func variableValue(msg *consumer.Message, variable string) string {
+	switch variable {
+	case "topic":
+		return msg.Topic
+	case "partition":
+		return strconv.Itoa(int(msg.Partition))
+	case "offset":
+		return strconv.FormatInt(msg.Offset, 10)
+	case "key":
+		return string(msg.Key)
+	}
+
+	path, _ := strings.CutPrefix(variable, config.HeaderValuePrefix)
+	var val interface{} = msg.ValueFields
+	for _, part := range strings.Split(path, ".") {
+		fields, ok := val.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		val = fields[part]
+	}
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprint(val)
+}
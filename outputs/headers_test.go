@@ -0,0 +1,54 @@
+package outputs
+
+import (
+	"etelgo/consumer"
+	"reflect"
+	"testing"
+)
+
+func TestOutputHeaders(t *testing.T) {
+	headers := newOutputHeaders(map[string]string{
+		"pipeline": "etelgo",
+		"source":   "${topic}/${partition}@${offset}",
+		"tenant":   "${value.tenant.id}",
+		"missing":  "[${value.region}]",
+		"trace":    "key-${key}",
+	})
+	msg := &consumer.Message{
+		Topic:       "orders",
+		Partition:   2,
+		Offset:      42,
+		Key:         []byte("k1"),
+		Headers:     map[string]string{"trace": "abc", "kept": "yes"},
+		ValueFields: map[string]interface{}{"tenant": map[string]interface{}{"id": int64(7)}},
+	}
+
+	kp := &KafkaProducer{serializer: &JSONSerializer{}, headers: headers}
+	record, err := kp.ToKafkaFranz(msg, "out")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]string, len(record.Headers))
+	for _, h := range record.Headers {
+		got[h.Key] = string(h.Value)
+	}
+	want := map[string]string{
+		"pipeline": "etelgo",
+		"source":   "orders/2@42",
+		"tenant":   "7",
+		"missing":  "[]",
+		"trace":    "key-k1",
+		"kept":     "yes",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("record headers = %v, want %v", got, want)
+	}
+	if msg.Headers["trace"] != "abc" || len(msg.Headers) != 2 {
+		t.Errorf("message headers modified: %v", msg.Headers)
+	}
+
+	if newOutputHeaders(nil) != nil {
+		t.Errorf("newOutputHeaders(nil) != nil, want no output headers")
+	}
+}
@@ -0,0 +1,483 @@
+package outputs
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Same adapter pattern as the consumer: our Message is converted into a franz-go kgo.Record here,
+// the rest of the application only deals with the Producer interface.
+
+var compressionCodecs = map[string]kgo.CompressionCodec{
+	"none":   kgo.NoCompression(),
+	"gzip":   kgo.GzipCompression(),
+	"snappy": kgo.SnappyCompression(),
+	"lz4":    kgo.Lz4Compression(),
+	"zstd":   kgo.ZstdCompression(),
+}
+
+var (
+	producedRecords = metrics.Default.Counter("etelgo_produced_records_total")
+	produceFailures = metrics.Default.Counter("etelgo_produce_failures_total")
+	breakerState    = metrics.Default.Gauge("etelgo_producer_circuit_state")
+	breakerOpenings = metrics.Default.Counter("etelgo_producer_circuit_opened_total")
+	bufferedRecords = metrics.Default.Gauge("etelgo_producer_buffered_records")
+	deadLetters     = metrics.Default.Counter("etelgo_dlq_records_total")
+)
+
+// Delivery is the outcome of a record produced asynchronously, once acknowledged by the output or failed after the retries
+type Delivery struct {
+	Message    *consumer.Message // Message the record was built from
+	Topic      string
+	Partition  int32 // Partition and Offset of the record, only set when delivered
+	Offset     int64
+	DeadLetter bool  // The record was sent to the dead letter topic, see DeadLetter
+	Err        error // Delivery failure, nil when delivered
+}
+
+// pendingRecord is a record waiting to be produced, with the message it was built from for its delivery report
+type pendingRecord struct {
+	record     *kgo.Record
+	msg        *consumer.Message
+	deadLetter bool
+}
+
+// probeInterval is how often the breaker state is checked to probe the output once the cooldown elapsed
+const probeInterval = time.Second
+
+type KafkaProducer struct {
+	client     *kgo.Client
+	logger     *slog.Logger
+	router     *TopicRouter
+	serializer Serializer
+	compress   compressor // nil when the payloads are not compressed
+
+	keySerializer    Serializer     // nil with the string key format, the raw key being sent
+	messageTimestamp bool           // The records carry the message Timestamp, the produce time otherwise
+	headers          *outputHeaders // Stamped on the records, nil without output headers
+
+	// While the circuit breaker is not closed, records wait in the buffer.
+	// A full buffer blocks Send, which applies backpressure up to the consumer.
+	breaker *CircuitBreaker
+	buffer  chan *pendingRecord
+	probe   *pendingRecord              // Record kept for the next probe after a failed one, only used by recoverLoop
+	held    atomic.Int64                // Records in the buffer or kept as probe, not yet handed to the client
+	ping    func(context.Context) error // client.Ping when nil, probing the output while nothing is buffered. Replaced in tests
+
+	envelope   *deadLetterEnvelope // Wraps the dead letter records, nil to produce them as consumed, see UseDeadLetterEnvelope
+	onDelivery func(Delivery)      // Reported the outcome of every record, see OnDelivery
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// NewKafkaProducer creates a producer from a validated OutputConfig (defaults must already be applied).
+//
+// When topic routing is enabled, destination topics are not known at startup.
+// If auto_create_topic is true the client is allowed to create them on first produce,
+// which also requires auto.create.topics.enable on the broker side.
+// Otherwise producing to a missing topic fails after the configured retries, and the record is reported as an error.
+func NewKafkaProducer(cfg *config.OutputConfig, logger *slog.Logger) (*KafkaProducer, error) {
+	logger.Info("Creating new Kafka producer", "brokers", cfg.Brokers, "topic", cfg.Topic)
+
+	retryBackoff, err := time.ParseDuration(*cfg.Retry_backoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry_backoff: %w", err)
+	}
+	requestTimeout, err := time.ParseDuration(*cfg.Request_timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request_timeout: %w", err)
+	}
+	breakerCooldown, err := time.ParseDuration(*cfg.Breaker_cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("invalid breaker_cooldown: %w", err)
+	}
+	linger, err := time.ParseDuration(*cfg.Linger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid linger: %w", err)
+	}
+
+	compress, err := newCompressor(*cfg.Payload_compression)
+	if err != nil {
+		return nil, err
+	}
+
+	kgoOpts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(cfg.Topic),
+		kgo.ProducerBatchCompression(compressionCodecs[*cfg.Compression]),
+		kgo.MaxBufferedRecords(*cfg.Batch_size),
+		kgo.ProducerLinger(linger),
+		kgo.ProduceRequestTimeout(requestTimeout),
+		kgo.RetryBackoffFn(func(int) time.Duration { return retryBackoff }),
+		kgo.RecordRetries(*cfg.Max_retries),
+	}
+	if *cfg.Auto_create_topic {
+		kgoOpts = append(kgoOpts, kgo.AllowAutoTopicCreation())
+	}
+	if len(cfg.Partitions) > 0 {
+		kgoOpts = append(kgoOpts, kgo.RecordPartitioner(newRestrictedPartitioner(cfg.Partitions)))
+	}
+
+	client, err := kgo.NewClient(kgoOpts...)
+	if err != nil {
+		logger.Error("failed to create Kafka client", "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	kp := &KafkaProducer{
+		client:     client,
+		logger:     logger,
+		router:     NewTopicRouter(cfg),
+		serializer: serializerFor(cfg),
+		compress:   compress,
+
+		keySerializer:    keySerializerFor(cfg),
+		messageTimestamp: *cfg.Timestamp_source == config.TimestampSourceMessage,
+		headers:          newOutputHeaders(cfg.Headers),
+		buffer:           make(chan *pendingRecord, *cfg.Breaker_buffer_size),
+		ctx:              ctx,
+		cancel:           cancel,
+		done:             make(chan struct{}),
+	}
+	kp.breaker = NewCircuitBreaker(*cfg.Breaker_failure_threshold, breakerCooldown, kp.onBreakerStateChange)
+
+	go kp.recoverLoop()
+
+	return kp, nil
+}
+
+func (kp *KafkaProducer) onBreakerStateChange(state BreakerState) {
+	breakerState.Set(int64(state))
+	switch state {
+	case BreakerOpen:
+		breakerOpenings.Inc()
+		kp.logger.Warn("Producer circuit breaker opened, buffering records", "buffer_size", cap(kp.buffer))
+	case BreakerHalfOpen:
+		kp.logger.Info("Producer circuit breaker half-open, probing output")
+	case BreakerClosed:
+		kp.logger.Info("Producer circuit breaker closed, resuming")
+	}
+}
+
+// ToKafkaFranz converts a Message into a franz-go record for the given topic.
+// The deserialized ValueFields take precedence over the raw Value when they are set,
+// and so do the KeyFields over the raw Key unless the key format is string.
+// Headers are carried over, including the ones edited by the processors and the trace context, the output headers
+// (OutputConfig.Headers) being stamped over them.
+// The record carries the message Timestamp with the message timestamp source, the client setting the produce time
+// otherwise (or when the message has none).
+func (kp *KafkaProducer) ToKafkaFranz(msg *consumer.Message, topic string) (*kgo.Record, error) {
+	value := msg.Value
+	if msg.ValueFields != nil {
+		serialized, err := kp.serializer.Serialize(msg.ValueFields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize message value: %w", err)
+		}
+		value = serialized
+	}
+	if kp.compress != nil {
+		compressed, err := kp.compress(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress message value: %w", err)
+		}
+		value = compressed
+	}
+
+	key := msg.Key
+	if kp.keySerializer != nil && msg.KeyFields != nil {
+		serialized, err := kp.keySerializer.Serialize(msg.KeyFields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize message key: %w", err)
+		}
+		key = serialized
+	}
+
+	headers := msg.Headers
+	if kp.headers != nil {
+		headers = kp.headers.apply(msg)
+	}
+
+	record := &kgo.Record{
+		Key:     key,
+		Value:   value,
+		Topic:   topic,
+		Headers: recordHeaders(headers),
+	}
+	if kp.messageTimestamp {
+		record.Timestamp = msg.Timestamp
+	}
+	return record, nil
+}
+
+// recordHeaders converts the message headers, sorted by key so that the records are deterministic
+func recordHeaders(headers map[string]string) []kgo.RecordHeader {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]kgo.RecordHeader, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, kgo.RecordHeader{Key: key, Value: []byte(headers[key])})
+	}
+	return out
+}
+
+// Send produces the message asynchronously, delivery errors are reported through the logs and the circuit breaker.
+// It blocks when the producer buffer is full, applying backpressure on the workers.
+func (kp *KafkaProducer) Send(ctx context.Context, msg *consumer.Message) error {
+	topic := kp.router.Route(msg)
+
+	record, err := kp.ToKafkaFranz(msg, topic)
+	if err != nil {
+		kp.logger.Error("failed to build record", "error", err)
+		return err
+	}
+
+	return kp.produce(ctx, &pendingRecord{record: record, msg: msg})
+}
+
+// Headers added to the dead letter records, locating the source record and the failure
+const (
+	HeaderDLQError     = "etelgo-error"
+	HeaderDLQTopic     = "etelgo-source-topic"
+	HeaderDLQPartition = "etelgo-source-partition"
+	HeaderDLQOffset    = "etelgo-source-offset"
+)
+
+// DeadLetter produces the raw key and value of the message to the dead letter topic, as consumed,
+// with the cause and the source position as headers so that the record can be inspected and replayed.
+// With an envelope the value is the envelope of the original record instead, see UseDeadLetterEnvelope.
+// It goes through the circuit breaker like Send.
+func (kp *KafkaProducer) DeadLetter(ctx context.Context, msg *consumer.Message, topic string, cause error) error {
+	headers := make(map[string]string, len(msg.Headers)+4)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderDLQError] = cause.Error()
+	headers[HeaderDLQTopic] = msg.Topic
+	headers[HeaderDLQPartition] = strconv.Itoa(int(msg.Partition))
+	headers[HeaderDLQOffset] = strconv.FormatInt(msg.Offset, 10)
+
+	value := msg.Value
+	if kp.envelope != nil {
+		var err error
+		if value, err = kp.envelope.encode(msg, cause); err != nil {
+			return fmt.Errorf("failed to encode the dead letter envelope: %w", err)
+		}
+	}
+
+	record := &kgo.Record{
+		Key:     msg.Key,
+		Value:   value,
+		Topic:   topic,
+		Headers: recordHeaders(headers),
+		Context: deadLetterContext(),
+	}
+	if err := kp.produce(ctx, &pendingRecord{record: record, msg: msg, deadLetter: true}); err != nil {
+		return err
+	}
+	deadLetters.Inc()
+	return nil
+}
+
+// UseDeadLetterEnvelope wraps the dead letter records into the JSON envelope described by cfg (validated),
+// nil producing them as consumed. It must be called before the first record is sent.
+func (kp *KafkaProducer) UseDeadLetterEnvelope(cfg *config.DLQEnvelopeConfig) {
+	kp.envelope = nil
+	if cfg != nil {
+		kp.envelope = newDeadLetterEnvelope(cfg)
+	}
+}
+
+// OnDelivery sets the function reported the outcome of every record sent by Send and DeadLetter, e.g. to send
+// the messages failing to be delivered to the dead letter topic. It is called from the client goroutines,
+// so it must return quickly, and it must be set before the first record is sent.
+func (kp *KafkaProducer) OnDelivery(fn func(Delivery)) {
+	kp.onDelivery = fn
+}
+
+// produce sends the record asynchronously, or buffers it while the circuit breaker is not closed
+func (kp *KafkaProducer) produce(ctx context.Context, pending *pendingRecord) error {
+	if kp.breaker.State() != BreakerClosed {
+		return kp.bufferRecord(ctx, pending)
+	}
+
+	kp.client.Produce(ctx, pending.record, func(r *kgo.Record, err error) { kp.delivered(pending, err) })
+	return nil
+}
+
+// delivered counts the outcome of the record, by topic, and reports it to the delivery function.
+// A failed record is reported once the client retries are exhausted, the circuit breaker counting the failure.
+func (kp *KafkaProducer) delivered(pending *pendingRecord, err error) {
+	r := pending.record
+	delivery := Delivery{Message: pending.msg, Topic: r.Topic, DeadLetter: pending.deadLetter, Err: err}
+	if err != nil {
+		produceFailures.Inc()
+		metrics.Default.Counter("etelgo_delivery_failures_total", "topic", r.Topic).Inc()
+		kp.breaker.RecordFailure()
+		kp.logger.Error("failed to produce record", "topic", r.Topic, "error", err)
+	} else {
+		producedRecords.Inc()
+		metrics.Default.Counter("etelgo_delivered_records_total", "topic", r.Topic).Inc()
+		kp.breaker.RecordSuccess()
+		delivery.Partition, delivery.Offset = r.Partition, r.Offset
+	}
+
+	if kp.onDelivery != nil {
+		kp.onDelivery(delivery)
+	}
+}
+
+func (kp *KafkaProducer) bufferRecord(ctx context.Context, pending *pendingRecord) error {
+	select {
+	case kp.buffer <- pending:
+		bufferedRecords.Add(1)
+		kp.held.Add(1)
+		return nil
+	default:
+	}
+
+	kp.logger.Warn("Producer buffer full, applying backpressure", "buffer_size", cap(kp.buffer))
+	select {
+	case kp.buffer <- pending:
+		bufferedRecords.Add(1)
+		kp.held.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recoverLoop probes the output with a single synchronous produce once the breaker is half-open.
+// On success the breaker closes and the buffered records are sent, on failure it reopens for another cooldown.
+func (kp *KafkaProducer) recoverLoop() {
+	defer close(kp.done)
+
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-kp.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if kp.breaker.State() != BreakerHalfOpen {
+			continue
+		}
+
+		if kp.probe == nil {
+			select {
+			case kp.probe = <-kp.buffer:
+				bufferedRecords.Add(-1)
+			default:
+				kp.pingOutput()
+				continue
+			}
+		}
+
+		// A failed probe is kept for the next one, its delivery is only reported once it succeeds
+		if err := kp.client.ProduceSync(kp.ctx, kp.probe.record).FirstErr(); err != nil {
+			produceFailures.Inc()
+			kp.breaker.RecordFailure()
+			kp.logger.Warn("Producer probe failed", "topic", kp.probe.record.Topic, "error", err)
+			continue
+		}
+		kp.delivered(kp.probe, nil)
+		kp.probe = nil
+		kp.held.Add(-1)
+		kp.drainBuffer()
+	}
+}
+
+// pingOutput probes the brokers of a half-open breaker with nothing buffered, so that an idle producer
+// closes its breaker too instead of waiting for a record to probe with
+func (kp *KafkaProducer) pingOutput() {
+	ping := kp.ping
+	if ping == nil {
+		ping = kp.client.Ping
+	}
+	if err := ping(kp.ctx); err != nil {
+		kp.breaker.RecordFailure()
+		kp.logger.Warn("Producer probe failed", "error", err)
+		return
+	}
+	kp.breaker.RecordSuccess()
+	kp.drainBuffer() // Records buffered during the ping
+}
+
+// drainBuffer sends asynchronously every record buffered while the breaker was open
+func (kp *KafkaProducer) drainBuffer() {
+	for {
+		select {
+		case pending := <-kp.buffer:
+			bufferedRecords.Add(-1)
+			kp.client.Produce(context.Background(), pending.record, func(r *kgo.Record, err error) { kp.delivered(pending, err) })
+			kp.held.Add(-1)
+		default:
+			return
+		}
+	}
+}
+
+// Ready reports an error while the circuit breaker is not closed
+func (kp *KafkaProducer) Ready() error {
+	if state := kp.breaker.State(); state != BreakerClosed {
+		return fmt.Errorf("producer circuit breaker is %s", state)
+	}
+	return nil
+}
+
+// Flush waits for every buffered record to be delivered, including the ones held by the circuit breaker until
+// it recovers. It returns as soon as no record is held, whatever the breaker state: a breaker left half-open
+// by an idle producer must not hold the shutdown back.
+func (kp *KafkaProducer) Flush(ctx context.Context) error {
+	for kp.held.Load() > 0 {
+		select {
+		case <-time.After(probeInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return kp.client.Flush(ctx)
+}
+
+func (kp *KafkaProducer) Pending() int {
+	return int(kp.client.BufferedProduceRecords()) + int(kp.held.Load())
+}
+
+func (kp *KafkaProducer) Close() error {
+	kp.logger.Info("Closing Kafka producer")
+	kp.cancel()
+	<-kp.done
+
+	if pending := len(kp.buffer); pending > 0 || kp.probe != nil {
+		kp.logger.Warn("Dropping records buffered while the circuit breaker was open", "count", pending+boolToInt(kp.probe != nil))
+	}
+
+	// Records are flushed during the graceful drain, the remaining ones are failed by the client
+	kp.client.Close()
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
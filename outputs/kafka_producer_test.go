@@ -0,0 +1,165 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestToKafkaFranz_Timestamp(t *testing.T) {
+	replayed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		messageTimestamp bool
+		timestamp        time.Time
+		want             time.Time
+	}{
+		{"Message timestamp", true, replayed, replayed},
+		{"Message without timestamp", true, time.Time{}, time.Time{}}, // Set to the produce time by the client
+		{"Produce time", false, replayed, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kp := &KafkaProducer{messageTimestamp: tt.messageTimestamp}
+			record, err := kp.ToKafkaFranz(&consumer.Message{Value: []byte("v"), Timestamp: tt.timestamp}, "orders")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !record.Timestamp.Equal(tt.want) {
+				t.Errorf("record timestamp = %v, want %v", record.Timestamp, tt.want)
+			}
+		})
+	}
+}
+
+func TestToKafkaFranz_TraceContext(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	msg := &consumer.Message{
+		Value: []byte("v"),
+		Headers: map[string]string{
+			consumer.HeaderTraceparent: traceparent,
+			consumer.HeaderTracestate:  "congo=t61rcWkgMzE",
+		},
+	}
+
+	record, err := (&KafkaProducer{}).ToKafkaFranz(msg, "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, h := range record.Headers {
+		got[h.Key] = string(h.Value)
+	}
+	if got[consumer.HeaderTraceparent] != traceparent || got[consumer.HeaderTracestate] != "congo=t61rcWkgMzE" {
+		t.Errorf("record headers = %v, want the trace context propagated unchanged", got)
+	}
+}
+
+func TestDelivered(t *testing.T) {
+	var deliveries []Delivery
+	kp := &KafkaProducer{
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		breaker:    NewCircuitBreaker(5, time.Second, func(BreakerState) {}),
+		onDelivery: func(d Delivery) { deliveries = append(deliveries, d) },
+	}
+	msg := &consumer.Message{Topic: "orders", Offset: 7}
+	failures := metrics.Default.Counter("etelgo_delivery_failures_total", "topic", "orders-out")
+	delivered := metrics.Default.Counter("etelgo_delivered_records_total", "topic", "orders-out")
+	failuresBefore, deliveredBefore := failures.Value(), delivered.Value()
+
+	cause := errors.New("UNKNOWN_TOPIC_OR_PARTITION")
+	kp.delivered(&pendingRecord{record: &kgo.Record{Topic: "orders-out"}, msg: msg}, cause)
+	kp.delivered(&pendingRecord{record: &kgo.Record{Topic: "orders-out", Partition: 2, Offset: 42}, msg: msg}, nil)
+
+	if got := failures.Value() - failuresBefore; got != 1 {
+		t.Errorf("expected 1 delivery failure counted for the topic, got %d", got)
+	}
+	if got := delivered.Value() - deliveredBefore; got != 1 {
+		t.Errorf("expected 1 delivered record counted for the topic, got %d", got)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries reported, got %d", len(deliveries))
+	}
+	if d := deliveries[0]; d.Message != msg || d.Topic != "orders-out" || !errors.Is(d.Err, cause) {
+		t.Errorf("failed delivery = %+v, want the message and its error", d)
+	}
+	if d := deliveries[1]; d.Err != nil || d.Partition != 2 || d.Offset != 42 {
+		t.Errorf("delivery = %+v, want partition 2 offset 42", d)
+	}
+}
+
+func TestPingOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		pingErr error
+		want    BreakerState
+	}{
+		{"Brokers reachable", nil, BreakerClosed},
+		{"Brokers unreachable", errors.New("unable to dial"), BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 23, 10, 0, 0, 0, time.UTC)
+			breaker := NewCircuitBreaker(1, time.Minute, nil)
+			breaker.now = func() time.Time { return now }
+			breaker.RecordFailure()
+			now = now.Add(time.Minute)
+
+			kp := &KafkaProducer{
+				logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+				breaker: breaker,
+				buffer:  make(chan *pendingRecord, 1),
+				ping:    func(context.Context) error { return tt.pingErr },
+				ctx:     context.Background(),
+			}
+			if state := breaker.State(); state != BreakerHalfOpen {
+				t.Fatalf("breaker state = %s, want half-open", state)
+			}
+
+			// Nothing is buffered to probe with, the idle producer must not stay half-open
+			kp.pingOutput()
+			if state := breaker.State(); state != tt.want {
+				t.Errorf("breaker state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlushHalfOpenIdle(t *testing.T) {
+	client, err := kgo.NewClient(kgo.SeedBrokers("127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	now := time.Date(2026, 1, 23, 10, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(1, time.Minute, nil)
+	breaker.now = func() time.Time { return now }
+	breaker.RecordFailure()
+	now = now.Add(time.Minute)
+	kp := &KafkaProducer{client: client, breaker: breaker, buffer: make(chan *pendingRecord, 1)}
+
+	// A shutdown while half-open with nothing buffered must not wait for the breaker to close
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := kp.Flush(ctx); err != nil {
+		t.Errorf("Flush() error = %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Flush() took %s, want an immediate return", elapsed)
+	}
+	if state := breaker.State(); state != BreakerHalfOpen {
+		t.Errorf("breaker state = %s, want still half-open", state)
+	}
+}
@@ -0,0 +1,20 @@
+package outputs
+
+import (
+	"bytes"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackSerializer encodes the fields as a MessagePack map, integers using their most compact encoding
+type MsgpackSerializer struct{}
+
+func (s *MsgpackSerializer) Serialize(fields map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
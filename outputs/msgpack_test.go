@@ -0,0 +1,47 @@
+package outputs
+
+import (
+	"etelgo/consumer"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	fields := map[string]interface{}{
+		"small":    int64(7),
+		"negative": int64(-300),
+		"large":    int64(math.MaxInt64),
+		"price":    9.99,
+		"name":     "sku-42",
+		"active":   true,
+		"nested": map[string]interface{}{
+			"count": int64(2),
+			"tags":  []interface{}{"a", "b"},
+		},
+	}
+
+	data, err := (&MsgpackSerializer{}).Serialize(fields)
+	if err != nil {
+		t.Fatalf("Serialize() unexpected error = %v", err)
+	}
+
+	got, err := (&consumer.MsgpackDeserializer{}).Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(got, fields) {
+		t.Errorf("round trip = %#v, want %#v", got, fields)
+	}
+}
+
+func TestMsgpackDeserializeInvalid(t *testing.T) {
+	data, _ := (&MsgpackSerializer{}).Serialize(map[string]interface{}{"id": int64(1)})
+
+	if _, err := (&consumer.MsgpackDeserializer{}).Deserialize(data[:len(data)-1]); err == nil {
+		t.Errorf("Deserialize() of a truncated payload error = nil, wantErr = true")
+	}
+	if _, err := (&consumer.MsgpackDeserializer{}).Deserialize(append(data, 0x01)); err == nil {
+		t.Errorf("Deserialize() with trailing data error = nil, wantErr = true")
+	}
+}
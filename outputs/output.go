@@ -1 +1,143 @@
 package outputs
+
+import (
+	"context"
+	"encoding/json"
+	"etelgo/config"
+	"etelgo/consumer"
+	"math"
+)
+
+type Producer interface {
+	Send(ctx context.Context, msg *consumer.Message) error
+
+	// DeadLetter sends the original record of a message that failed, unprocessed, to the dead letter topic,
+	// or its envelope describing the failure (see config.DLQEnvelopeConfig)
+	DeadLetter(ctx context.Context, msg *consumer.Message, topic string, cause error) error
+
+	Flush(ctx context.Context) error
+
+	Ready() error
+
+	// Pending returns the number of messages sent but not yet acknowledged by the output
+	Pending() int
+
+	// OnDelivery sets the function reported the outcome of every message sent, once acknowledged or failed
+	OnDelivery(fn func(Delivery))
+
+	Close() error
+}
+
+type Serializer interface {
+	Serialize(fields map[string]interface{}) ([]byte, error)
+}
+
+// JSONSerializer encodes the fields as a JSON object. JSON has no NaN nor Inf: NonFinite chooses how the non-finite
+// floats are encoded, see OutputConfig.Non_finite_floats. The default, config.NonFiniteError, fails the message.
+type JSONSerializer struct {
+	NonFinite string
+}
+
+func (s *JSONSerializer) Serialize(fields map[string]interface{}) ([]byte, error) {
+	if s.NonFinite == config.NonFiniteNull || s.NonFinite == config.NonFiniteDropField {
+		if cleaned, changed := s.cleanObject(fields); changed {
+			fields = cleaned
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// cleanObject returns a copy of the object without its non-finite floats, nested values included,
+// or the object itself when it has none (changed false): the message fields are never modified.
+func (s *JSONSerializer) cleanObject(fields map[string]interface{}) (map[string]interface{}, bool) {
+	var cleaned map[string]interface{}
+	for key, val := range fields {
+		newVal, changed, drop := s.cleanValue(val)
+		if !changed {
+			continue
+		}
+		if cleaned == nil {
+			cleaned = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				cleaned[k] = v
+			}
+		}
+		if drop {
+			delete(cleaned, key)
+		} else {
+			cleaned[key] = newVal
+		}
+	}
+	return cleaned, cleaned != nil
+}
+
+// cleanValue replaces the non-finite floats of the value, drop telling the field is to be removed
+func (s *JSONSerializer) cleanValue(val interface{}) (newVal interface{}, changed bool, drop bool) {
+	switch v := val.(type) {
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return val, false, false
+		}
+		return nil, true, s.NonFinite == config.NonFiniteDropField
+	case float32:
+		return s.cleanValue(float64(v))
+	case map[string]interface{}:
+		cleaned, changed := s.cleanObject(v)
+		return cleaned, changed, false
+	case []interface{}:
+		var cleaned []interface{}
+		for i, item := range v {
+			newItem, changed, _ := s.cleanValue(item)
+			if !changed {
+				continue
+			}
+			if cleaned == nil {
+				cleaned = append([]interface{}(nil), v...)
+			}
+			// Removing an element would shift the next ones, it is encoded as null instead
+			cleaned[i] = newItem
+		}
+		return cleaned, cleaned != nil, false
+	}
+	return val, false, false
+}
+
+// serializerFor creates the serializer of the output messages from a validated OutputConfig
+func serializerFor(cfg *config.OutputConfig) Serializer {
+	if cfg.Format == string(config.FormatCSV) {
+		return &CSVSerializer{
+			Delimiter: []rune(*cfg.Csv.Delimiter)[0],
+			Header:    *cfg.Csv.Header,
+			Columns:   cfg.Csv.Columns,
+		}
+	}
+	if cfg.Format == string(config.FormatMsgpack) {
+		return &MsgpackSerializer{}
+	}
+	// For now, every other format is encoded as JSON
+	return &JSONSerializer{NonFinite: *cfg.Non_finite_floats}
+}
+
+// keySerializerFor creates the serializer of the output record keys from a validated OutputConfig,
+// nil with the string key format
+func keySerializerFor(cfg *config.OutputConfig) Serializer {
+	if *cfg.Key_format == string(config.FormatString) {
+		return nil
+	}
+	return NewSerializer(*cfg.Key_format)
+}
+
+func NewSerializer(format string) Serializer {
+	switch format {
+	case "json":
+		return &JSONSerializer{}
+	case "msgpack":
+		return &MsgpackSerializer{}
+	// case "avro":
+	//	return &AvroSerializer{}
+	// case "protobuf":
+	//	return &ProtobufSerializer{}
+	default:
+		return &JSONSerializer{}
+	}
+}
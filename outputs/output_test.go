@@ -0,0 +1,51 @@
+package outputs
+
+import (
+	"etelgo/config"
+	"math"
+	"testing"
+)
+
+func TestJSONSerializer_NonFinite(t *testing.T) {
+	newFields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":     int64(1),
+			"ratio":  math.NaN(),
+			"stats":  map[string]interface{}{"max": math.Inf(1), "min": 0.5},
+			"values": []interface{}{1.5, math.Inf(-1)},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		nonFinite string
+		want      string
+		wantErr   bool
+	}{
+		{"Error", config.NonFiniteError, "", true},
+		{"Null", config.NonFiniteNull, `{"id":1,"ratio":null,"stats":{"max":null,"min":0.5},"values":[1.5,null]}`, false},
+		{"Drop field", config.NonFiniteDropField, `{"id":1,"stats":{"min":0.5},"values":[1.5,null]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := newFields()
+			got, err := (&JSONSerializer{NonFinite: tt.nonFinite}).Serialize(fields)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Serialize() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Serialize() unexpected error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Serialize() = %s, want %s", got, tt.want)
+			}
+			if !math.IsNaN(fields["ratio"].(float64)) || !math.IsInf(fields["stats"].(map[string]interface{})["max"].(float64), 1) {
+				t.Error("Serialize() modified the message fields")
+			}
+		})
+	}
+}
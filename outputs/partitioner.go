@@ -0,0 +1,85 @@
+package outputs
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// deadLetterKey marks the context of the dead letter records, which the restricted partitioner leaves to the default one
+type deadLetterKey struct{}
+
+func isDeadLetter(r *kgo.Record) bool {
+	return r.Context != nil && r.Context.Value(deadLetterKey{}) != nil
+}
+
+// restrictedPartitioner writes the records to the output partitions only (OutputConfig.Partitions): a keyed record
+// goes to the partition of the list its key hashes to (murmur2, as the Kafka default partitioner), keeping the
+// records of a key together, and the records without key are spread round-robin over the list.
+// A listed partition the topic doesn't have is not replaced by another one: the record fails, and is handled
+// like any undelivered record (logged, or sent to the dead letter topic with the dlq policy).
+// The dead letter records are not restricted, the default partitioning applies to them.
+type restrictedPartitioner struct {
+	partitions []int32
+}
+
+func newRestrictedPartitioner(partitions []int) *restrictedPartitioner {
+	p := &restrictedPartitioner{partitions: make([]int32, len(partitions))}
+	for i, partition := range partitions {
+		p.partitions[i] = int32(partition)
+	}
+	return p
+}
+
+func (p *restrictedPartitioner) ForTopic(topic string) kgo.TopicPartitioner {
+	return &restrictedTopicPartitioner{
+		partitions: p.partitions,
+		keys:       kgo.StickyKeyPartitioner(nil).ForTopic(topic),
+		fallback:   kgo.StickyKeyPartitioner(nil).ForTopic(topic),
+	}
+}
+
+// restrictedTopicPartitioner is used by a single record at a time, see kgo.Partitioner
+type restrictedTopicPartitioner struct {
+	partitions []int32
+	next       int                  // Round-robin position of the records without key
+	keys       kgo.TopicPartitioner // Hashes the keys over the listed partitions
+	fallback   kgo.TopicPartitioner // Partitions the dead letter records
+}
+
+// RequiresConsistency is true for the restricted records, so that the partition index is the partition number
+// (the available partitions only being indexed otherwise)
+func (p *restrictedTopicPartitioner) RequiresConsistency(r *kgo.Record) bool {
+	if isDeadLetter(r) {
+		return p.fallback.RequiresConsistency(r)
+	}
+	return true
+}
+
+func (p *restrictedTopicPartitioner) Partition(r *kgo.Record, n int) int {
+	if isDeadLetter(r) {
+		return p.fallback.Partition(r, n)
+	}
+
+	var i int
+	if r.Key != nil {
+		i = p.keys.Partition(r, len(p.partitions))
+	} else {
+		i = p.next % len(p.partitions)
+		p.next++
+	}
+	// Out of [0, n) when the topic lacks the partition, failing the record
+	return int(p.partitions[i])
+}
+
+// OnNewBatch lets the default partitioner of the dead letter records move to another partition, see kgo.StickyKeyPartitioner
+func (p *restrictedTopicPartitioner) OnNewBatch() {
+	if onNewBatch, ok := p.fallback.(kgo.TopicPartitionerOnNewBatch); ok {
+		onNewBatch.OnNewBatch()
+	}
+}
+
+// deadLetterContext marks a dead letter record, see restrictedPartitioner
+func deadLetterContext() context.Context {
+	return context.WithValue(context.Background(), deadLetterKey{}, true)
+}
@@ -0,0 +1,55 @@
+package outputs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestRestrictedPartitioner(t *testing.T) {
+	p := newRestrictedPartitioner([]int{1, 3, 4}).ForTopic("orders")
+	allowed := map[int]bool{1: true, 3: true, 4: true}
+
+	// The records of a key stay on one of the listed partitions
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		record := &kgo.Record{Key: []byte(key)}
+		if !p.RequiresConsistency(record) {
+			t.Fatal("RequiresConsistency() = false, want the partition index to be the partition number")
+		}
+		first := p.Partition(record, 6)
+		if !allowed[first] {
+			t.Errorf("key %q partition = %d, want one of the listed partitions", key, first)
+		}
+		if again := p.Partition(&kgo.Record{Key: []byte(key)}, 6); again != first {
+			t.Errorf("key %q partition = %d then %d, want the same partition", key, first, again)
+		}
+	}
+
+	// The records without key are spread over the listed partitions
+	var got []int
+	for i := 0; i < 4; i++ {
+		got = append(got, p.Partition(&kgo.Record{}, 6))
+	}
+	if want := []int{1, 3, 4, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("partitions without key = %v, want %v", got, want)
+	}
+
+	// A listed partition missing from the topic is out of range, kgo failing the record
+	for i := 0; i < 3; i++ {
+		if partition := p.Partition(&kgo.Record{}, 4); partition == 4 {
+			return
+		}
+	}
+	t.Error("expected the missing partition 4 to be chosen out of range")
+}
+
+func TestRestrictedPartitioner_DeadLetter(t *testing.T) {
+	p := newRestrictedPartitioner([]int{5}).ForTopic("orders-dlq")
+	for i := 0; i < 10; i++ {
+		record := &kgo.Record{Key: []byte{byte(i)}, Context: deadLetterContext()}
+		if partition := p.Partition(record, 3); partition < 0 || partition >= 3 {
+			t.Fatalf("dead letter partition = %d, want the default partitioning over the 3 partitions", partition)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package outputs
+
+import (
+	"etelgo/config"
+	"etelgo/consumer"
+)
+
+// TopicRouter chooses the destination topic of each message.
+// Without topic_field every message goes to the default topic (OutputConfig.Topic).
+// With topic_field the field value is used as the topic name, or looked up in topic_map when one is provided.
+// The default topic is the fallback when the field is missing, not a string, or not present in topic_map.
+type TopicRouter struct {
+	defaultTopic string
+	field        string
+	mapping      map[string]string
+}
+
+func NewTopicRouter(cfg *config.OutputConfig) *TopicRouter {
+	router := &TopicRouter{
+		defaultTopic: cfg.Topic,
+		mapping:      cfg.Topic_map,
+	}
+	if cfg.Topic_field != nil {
+		router.field = *cfg.Topic_field
+	}
+	return router
+}
+
+// Route returns the topic the message must be produced to.
+func (r *TopicRouter) Route(msg *consumer.Message) string {
+	if r.field == "" {
+		return r.defaultTopic
+	}
+
+	val, ok := msg.ValueFields[r.field]
+	if !ok {
+		return r.defaultTopic
+	}
+	strVal, ok := val.(string)
+	if !ok || strVal == "" {
+		return r.defaultTopic
+	}
+
+	if len(r.mapping) == 0 {
+		return strVal
+	}
+	if topic, ok := r.mapping[strVal]; ok {
+		return topic
+	}
+	return r.defaultTopic
+}
@@ -0,0 +1,65 @@
+package outputs
+
+import (
+	"etelgo/config"
+	"etelgo/consumer"
+	"testing"
+)
+
+func TestTopicRouter_Route(t *testing.T) {
+	field := "event_type"
+
+	tests := []struct {
+		name   string
+		cfg    config.OutputConfig
+		fields map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "No routing uses default topic",
+			cfg:    config.OutputConfig{Topic: "default"},
+			fields: map[string]interface{}{"event_type": "order"},
+			want:   "default",
+		},
+		{
+			name:   "Field value used as topic",
+			cfg:    config.OutputConfig{Topic: "default", Topic_field: &field},
+			fields: map[string]interface{}{"event_type": "orders"},
+			want:   "orders",
+		},
+		{
+			name:   "Mapped value",
+			cfg:    config.OutputConfig{Topic: "default", Topic_field: &field, Topic_map: map[string]string{"order": "orders", "payment": "payments"}},
+			fields: map[string]interface{}{"event_type": "payment"},
+			want:   "payments",
+		},
+		{
+			name:   "Unmapped value falls back to default",
+			cfg:    config.OutputConfig{Topic: "default", Topic_field: &field, Topic_map: map[string]string{"order": "orders"}},
+			fields: map[string]interface{}{"event_type": "refund"},
+			want:   "default",
+		},
+		{
+			name:   "Missing field falls back to default",
+			cfg:    config.OutputConfig{Topic: "default", Topic_field: &field},
+			fields: map[string]interface{}{"other": "orders"},
+			want:   "default",
+		},
+		{
+			name:   "Non string field falls back to default",
+			cfg:    config.OutputConfig{Topic: "default", Topic_field: &field},
+			fields: map[string]interface{}{"event_type": 42},
+			want:   "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewTopicRouter(&tt.cfg)
+			got := router.Route(&consumer.Message{ValueFields: tt.fields})
+			if got != tt.want {
+				t.Errorf("Route() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
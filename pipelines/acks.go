@@ -0,0 +1,136 @@
+package pipelines
+
+import (
+	"etelgo/consumer"
+	"sync"
+)
+
+// ackTracker marks a message done once every record sent for it is acknowledged by the output: its results,
+// and its dead letter record with the dlq policy. Marking it once the records are only sent would commit offsets
+// whose records can still fail to be delivered, breaking the at-least-once delivery.
+// A record not delivered is settled by the error policy (see onDelivery), a message held back from the commit
+// stopping the pipeline: it is consumed again after a restart.
+// A nil tracker, in dry run, tracks nothing.
+type ackTracker struct {
+	mu      sync.Mutex
+	records map[recordKey]*trackedRecord
+	done    func(*consumer.Message) // Called once a message is settled, consumer.KafkaConsumer.MarkDone
+}
+
+// recordKey identifies a record sent and not yet acknowledged. The results of a message are often the message itself,
+// its dead letter record is then told apart by deadLetter.
+type recordKey struct {
+	msg        *consumer.Message
+	deadLetter bool
+}
+
+type trackedRecord struct {
+	settlement *settlement
+	count      int // Records sent for the same message and not yet acknowledged
+}
+
+// settlement holds the state of a source message until it is marked done
+type settlement struct {
+	msg     *consumer.Message
+	pending int  // Records not yet acknowledged, plus one while the message is still being handled
+	failed  bool // A record was not delivered, the message is held back from the commit
+}
+
+func newAckTracker(done func(*consumer.Message)) *ackTracker {
+	return &ackTracker{records: make(map[recordKey]*trackedRecord), done: done}
+}
+
+// begin starts the settlement of a message, which is held until end is called
+func (t *ackTracker) begin(msg *consumer.Message) *settlement {
+	if t == nil {
+		return nil
+	}
+	return &settlement{msg: msg, pending: 1}
+}
+
+// track registers a record of the settlement, before it is sent
+func (t *ackTracker) track(s *settlement, msg *consumer.Message, deadLetter bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s.pending++
+	key := recordKey{msg, deadLetter}
+	if r, ok := t.records[key]; ok {
+		r.count++
+		return
+	}
+	t.records[key] = &trackedRecord{settlement: s, count: 1}
+}
+
+// untrack unregisters a record which could not be sent, the message being settled by the error policy
+func (t *ackTracker) untrack(msg *consumer.Message, deadLetter bool) {
+	t.settle(recordKey{msg, deadLetter}, false)
+}
+
+// redirect replaces the undelivered record of a message by its dead letter record, tracked in its place
+func (t *ackTracker) redirect(msg *consumer.Message) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := recordKey{msg, false}
+	r, ok := t.records[key]
+	if !ok {
+		return
+	}
+	if r.count--; r.count == 0 {
+		delete(t.records, key)
+	}
+	dlqKey := recordKey{msg, true}
+	if dlq, ok := t.records[dlqKey]; ok {
+		dlq.count++
+		return
+	}
+	t.records[dlqKey] = &trackedRecord{settlement: r.settlement, count: 1}
+}
+
+// end ends the handling of the message, which is marked done once its records are acknowledged, unless ok is false
+func (t *ackTracker) end(s *settlement, ok bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.release(s, !ok)
+	t.mu.Unlock()
+}
+
+// acknowledge settles the record of a delivery report, failed being set when it was not delivered.
+// The records not tracked, e.g. the messages flushed by the stateful processors, are ignored.
+func (t *ackTracker) acknowledge(msg *consumer.Message, deadLetter bool, failed bool) {
+	t.settle(recordKey{msg, deadLetter}, failed)
+}
+
+func (t *ackTracker) settle(key recordKey, failed bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.records[key]
+	if !ok {
+		return
+	}
+	if r.count--; r.count == 0 {
+		delete(t.records, key)
+	}
+	t.release(r.settlement, failed)
+}
+
+// release drops a pending record of the settlement, marking the message done when it was the last one
+func (t *ackTracker) release(s *settlement, failed bool) {
+	s.failed = s.failed || failed
+	if s.pending--; s.pending == 0 && !s.failed {
+		t.done(s.msg)
+	}
+}
@@ -0,0 +1,136 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/outputs"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// deliveringProducer reports the delivery of every record as it is sent, failed with err (dlqErr for the dead letters)
+type deliveringProducer struct {
+	recordingProducer
+	err        error
+	dlqErr     error
+	onDelivery func(outputs.Delivery)
+}
+
+func (p *deliveringProducer) OnDelivery(fn func(outputs.Delivery)) { p.onDelivery = fn }
+
+func (p *deliveringProducer) Send(_ context.Context, msg *consumer.Message) error {
+	p.onDelivery(outputs.Delivery{Message: msg, Topic: "orders-out", Err: p.err})
+	return nil
+}
+
+func (p *deliveringProducer) DeadLetter(_ context.Context, msg *consumer.Message, topic string, _ error) error {
+	p.onDelivery(outputs.Delivery{Message: msg, Topic: topic, DeadLetter: true, Err: p.dlqErr})
+	return nil
+}
+
+func TestHandle_MarkDoneOnDelivery(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cause := errors.New("UNKNOWN_TOPIC_OR_PARTITION")
+
+	tests := []struct {
+		name        string
+		policy      string
+		err         error
+		dlqErr      error
+		wantDone    bool
+		wantFailure bool
+	}{
+		{"Delivered", config.ErrorPolicyFail, nil, nil, true, false},
+		{"Delivery failure skipped", config.ErrorPolicySkip, cause, nil, true, false},
+		{"Delivery failure dropped", config.ErrorPolicyDrop, cause, nil, true, false},
+		{"Delivery failure sent to the dead letter topic", config.ErrorPolicyDLQ, cause, nil, true, false},
+		{"Delivery failure of the dead letter", config.ErrorPolicyDLQ, cause, cause, false, true},
+		{"Delivery failure with fail", config.ErrorPolicyFail, cause, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := NewPipeline(nil, logger)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			producer := &deliveringProducer{err: tt.err, dlqErr: tt.dlqErr}
+			marked := make(chan *consumer.Message, 1)
+			o := &Orchestrator{
+				config:   &config.Config{Errors: config.ErrorsConfig{Policy: tt.policy, Dlq_topic: "orders-dlq"}},
+				producer: producer,
+				logger:   logger,
+				acks:     newAckTracker(func(msg *consumer.Message) { marked <- msg }),
+				failed:   make(chan error, 1),
+			}
+			o.pipeline.Store(pipeline)
+			producer.OnDelivery(func(d outputs.Delivery) { o.onDelivery(context.Background(), d) })
+
+			msg := &consumer.Message{Topic: "orders", Partition: 1, Offset: 7, ValueFields: map[string]interface{}{"id": 1}}
+			o.handle(msg, context.Background(), context.Background())
+
+			select {
+			case got := <-marked:
+				if !tt.wantDone {
+					t.Errorf("message %s/%d@%d marked done, want it held back from the commit", got.Topic, got.Partition, got.Offset)
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.wantDone {
+					t.Error("expected the message marked done")
+				}
+			}
+			select {
+			case err := <-o.failed:
+				if !tt.wantFailure {
+					t.Errorf("unexpected failure: %v", err)
+				} else if !errors.Is(err, cause) {
+					t.Errorf("failure = %v, want the delivery error", err)
+				}
+			default:
+				if tt.wantFailure {
+					t.Error("expected the pipeline to fail rather than holding the message back silently")
+				}
+			}
+		})
+	}
+}
+
+func TestAckTracker(t *testing.T) {
+	var marked []*consumer.Message
+	tracker := newAckTracker(func(msg *consumer.Message) { marked = append(marked, msg) })
+	msg := &consumer.Message{Topic: "orders", Offset: 3}
+	first, second := &consumer.Message{}, &consumer.Message{}
+
+	s := tracker.begin(msg)
+	tracker.track(s, first, false)
+	tracker.track(s, second, false)
+	tracker.acknowledge(first, false, false)
+	tracker.end(s, true)
+	if len(marked) != 0 {
+		t.Fatal("message marked done before all its records are delivered")
+	}
+	tracker.acknowledge(second, false, false)
+	if len(marked) != 1 || marked[0] != msg {
+		t.Fatalf("marked = %v, want the message marked done once its records are delivered", marked)
+	}
+	if len(tracker.records) != 0 {
+		t.Errorf("expected no record left tracked, got %d", len(tracker.records))
+	}
+
+	// A message without result is marked done as soon as it is handled
+	dropped := &consumer.Message{Topic: "orders", Offset: 4}
+	tracker.end(tracker.begin(dropped), true)
+	if len(marked) != 2 || marked[1] != dropped {
+		t.Errorf("marked = %v, want the dropped message marked done", marked)
+	}
+
+	// A message settled by the fail policy is never marked done
+	tracker.end(tracker.begin(&consumer.Message{Topic: "orders", Offset: 5}), false)
+	if len(marked) != 2 {
+		t.Errorf("marked = %v, want the failed message held back", marked)
+	}
+}
@@ -0,0 +1,110 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/consumer"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Worker affinity strategies, see InputConfig.Worker_affinity.
+// Both route every record of a partition to the same worker, which processes them one at a time:
+// the records of a partition reach the output in offset order, and the state a processor keeps per key
+// (e.g. the enrich cache) stays local to a worker. A slow message delays the next ones of its partition and
+// of the other partitions of its worker. With fewer workers than partitions each worker serves several partitions,
+// with more workers than partitions the extra workers stay idle.
+const (
+	AffinityHash   = "hash"   // Partition modulo workers: even spread, but most partitions move when the workers count changes
+	AffinitySticky = "sticky" // Rendezvous hashing: only the partitions of added or removed workers move when the count changes
+)
+
+// affinity returns the worker, in [0, workers), processing the records of the partition
+type affinity func(topic string, partition int32, workers int) int
+
+func newAffinity(strategy string) affinity {
+	if strategy == AffinitySticky {
+		return stickyAffinity
+	}
+	return hashAffinity
+}
+
+// hashAffinity spreads consecutive partitions over consecutive workers, the topic shifting the start
+// so that the partition 0 of every topic does not land on the same worker
+func hashAffinity(topic string, partition int32, workers int) int {
+	h := fnv.New32a()
+	h.Write([]byte(topic))
+	return int((uint64(h.Sum32()) + uint64(partition)) % uint64(workers))
+}
+
+// stickyAffinity picks the worker with the highest score for the partition (highest random weight hashing).
+// A worker keeps its partitions as long as it exists: adding a worker only takes over the partitions it now scores
+// the highest on, removing one only moves its own partitions.
+func stickyAffinity(topic string, partition int32, workers int) int {
+	best, bestScore := 0, uint64(0)
+	for w := 0; w < workers; w++ {
+		h := fnv.New64a()
+		h.Write([]byte(topic))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(int(partition))))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(w)))
+		if score := h.Sum64(); w == 0 || score > bestScore {
+			best, bestScore = w, score
+		}
+	}
+	return best
+}
+
+// dispatch routes the consumed messages to the queue of the worker owning their partition, until ctx is done.
+// In ordered mode the messages are registered in the reorder buffer first, every worker sharing the same queue.
+// It stops by itself once maxMessages are dispatched, closing limitReached, or once no message was consumed
+// for idleTimeout, closing idleReached. The idle timer is only restarted while messages are still processed
+// or pending in the output, e.g. when the consumer is paused by the backpressure.
+func (o *Orchestrator) dispatch(ctx context.Context, queues []chan *consumer.Message) {
+	route := newAffinity(*o.config.Input.Worker_affinity)
+	dispatched := 0
+
+	var idleTimer *time.Timer
+	var idle <-chan time.Time
+	if o.idleTimeout > 0 {
+		idleTimer = time.NewTimer(o.idleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+
+	for {
+		select {
+		case msg := <-o.consumer.Messages():
+			if idleTimer != nil {
+				idleTimer.Reset(o.idleTimeout)
+			}
+			if o.reorder != nil && !o.reorder.add(ctx, msg) {
+				return
+			}
+			queue := queues[route(msg.Topic, msg.Partition, len(queues))]
+			select {
+			case queue <- msg:
+			case <-ctx.Done():
+				if o.reorder != nil {
+					o.reorder.remove(msg)
+				}
+				return // The message is not marked done, it is consumed again on restart
+			}
+			if dispatched++; dispatched == o.maxMessages {
+				close(o.limitReached)
+				return
+			}
+		case <-idle:
+			if o.inFlight.Load() > 0 || o.producer.Pending() > 0 {
+				idleTimer.Reset(o.idleTimeout)
+				continue
+			}
+			close(o.idleReached)
+			return
+		case <-ctx.Done():
+			o.logger.Info("dispatcher context done, stopping")
+			return
+		}
+	}
+}
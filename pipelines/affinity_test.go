@@ -0,0 +1,98 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAffinityRange(t *testing.T) {
+	for _, strategy := range []string{AffinityHash, AffinitySticky} {
+		route := newAffinity(strategy)
+		for _, workers := range []int{1, 3, 8} {
+			for partition := int32(0); partition < 32; partition++ {
+				w := route("orders", partition, workers)
+				if w < 0 || w >= workers {
+					t.Fatalf("%s: partition %d routed to worker %d of %d", strategy, partition, w, workers)
+				}
+				if again := route("orders", partition, workers); again != w {
+					t.Fatalf("%s: partition %d routed to %d then %d", strategy, partition, w, again)
+				}
+			}
+		}
+	}
+}
+
+func TestHashAffinitySpread(t *testing.T) {
+	// Consecutive partitions land on distinct workers
+	counts := make(map[int]int)
+	for partition := int32(0); partition < 8; partition++ {
+		counts[hashAffinity("orders", partition, 4)]++
+	}
+	for w := 0; w < 4; w++ {
+		if counts[w] != 2 {
+			t.Errorf("worker %d got %d partitions, want 2 (%v)", w, counts[w], counts)
+		}
+	}
+}
+
+func TestStickyAffinityScaling(t *testing.T) {
+	const partitions = 64
+	moved := 0
+	for partition := int32(0); partition < partitions; partition++ {
+		before := stickyAffinity("orders", partition, 4)
+		after := stickyAffinity("orders", partition, 5)
+		if before != after {
+			moved++
+			// A partition only moves to the added worker
+			if after != 4 {
+				t.Errorf("partition %d moved from worker %d to existing worker %d", partition, before, after)
+			}
+		}
+	}
+	if moved == 0 || moved > partitions/2 {
+		t.Errorf("%d of %d partitions moved when adding a fifth worker, want about a fifth", moved, partitions)
+	}
+}
+
+func TestDispatchIdleTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	input := config.InputConfig{Brokers: []string{"localhost:9092"}, Topic: "orders", Format: "json"}
+	if err := input.Validate(logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cons, err := consumer.NewKafkaPeekConsumer(&input, "earliest", -1, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cons.Close()
+
+	o := &Orchestrator{
+		config:      &config.Config{Input: input},
+		consumer:    cons,
+		producer:    discardProducer{},
+		logger:      logger,
+		idleTimeout: 20 * time.Millisecond,
+		idleReached: make(chan struct{}),
+	}
+
+	// A message still processed restarts the idle timer
+	o.inFlight.Add(1)
+	go o.dispatch(context.Background(), []chan *consumer.Message{make(chan *consumer.Message)})
+	select {
+	case <-o.idleReached:
+		t.Fatal("expected the idle timeout to wait for the message in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	o.inFlight.Add(-1)
+	select {
+	case <-o.idleReached:
+	case <-time.After(time.Second):
+		t.Fatal("expected the idle timeout to stop the dispatch")
+	}
+}
@@ -0,0 +1,59 @@
+package pipelines
+
+import (
+	"context"
+	"time"
+)
+
+// backpressureInterval is how often the records pending in the producer are compared with the watermarks
+const backpressureInterval = 100 * time.Millisecond
+
+// watermarks decides when the input must be paused, with an hysteresis between the two thresholds
+// so that the fetching does not flap around a single value.
+type watermarks struct {
+	high   int
+	low    int
+	paused bool
+}
+
+// update returns whether the input must be paused with the given number of pending records
+func (w *watermarks) update(pending int) bool {
+	if !w.paused && pending > w.high {
+		w.paused = true
+	} else if w.paused && pending < w.low {
+		w.paused = false
+	}
+	return w.paused
+}
+
+// regulate pauses the consumer fetching while the producer can't keep up, until ctx is done
+func (o *Orchestrator) regulate(ctx context.Context) {
+	w := &watermarks{
+		high: *o.config.Output.Backpressure_high_watermark,
+		low:  *o.config.Output.Backpressure_low_watermark,
+	}
+
+	ticker := time.NewTicker(backpressureInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			wasPaused := w.paused
+			pending := o.producer.Pending()
+			if paused := w.update(pending); paused != wasPaused {
+				if paused {
+					o.logger.Warn("Output backpressure, pausing the input", "pending", pending, "high_watermark", w.high)
+					o.consumer.Pause()
+					o.emit(Event{Type: EventPaused, Pending: pending})
+				} else {
+					o.logger.Info("Output caught up, resuming the input", "pending", pending, "low_watermark", w.low)
+					o.consumer.Resume()
+					o.emit(Event{Type: EventResumed, Pending: pending})
+				}
+			}
+		}
+	}
+}
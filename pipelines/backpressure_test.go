@@ -0,0 +1,26 @@
+package pipelines
+
+import "testing"
+
+func TestWatermarks(t *testing.T) {
+	w := &watermarks{high: 100, low: 50}
+
+	steps := []struct {
+		pending int
+		want    bool
+	}{
+		{10, false},
+		{100, false}, // At the high watermark, not above
+		{101, true},
+		{80, true}, // Between the watermarks the state is kept
+		{50, true},
+		{49, false},
+		{80, false},
+	}
+
+	for _, step := range steps {
+		if got := w.update(step.pending); got != step.want {
+			t.Errorf("update(%d) = %v, want %v", step.pending, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"etelgo/processors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync/atomic"
+)
+
+var droppedRecords = metrics.Default.Counter("etelgo_dropped_records_total")
+
+// Pipeline chains the configured processors, applied on each message by ascending priority then config order.
+// The processors selecting an input topic only apply to its messages, see config.ProcessorChains.
+type Pipeline struct {
+	processors []processors.Processor
+	counters   []processorCounters // Counters of each processor, in the processors order
+	indexes    []int               // Config index of each processor, in the processors order
+	topics     []string            // Topic of the chain of each processor, "" for the default chain
+	chains     map[string][]int    // Positions in processors of the chain of each topic, see config.ProcessorChains
+	report     *Report             // Effects of each processor, only collected in dry run, see EnableReport
+	logger     *slog.Logger
+
+	// Totals of this pipeline, see Stats
+	processed atomic.Int64
+	dropped   atomic.Int64
+	errors    atomic.Int64
+
+	inFlight atomic.Int64 // Messages being processed by the orchestrator workers, see Orchestrator.acquirePipeline
+}
+
+// processorCounters counts the messages entering a processor, those it drops, those it emits
+// (one per kept message, several for a one-to-many processor) and those it fails on, labelled by the config index
+// of the processor since several may share a type. The metrics are shared by the pipelines of the process,
+// totals only count the messages of the pipeline holding them.
+type processorCounters struct {
+	processed *metrics.Counter
+	dropped   *metrics.Counter
+	emitted   *metrics.Counter
+	errors    *metrics.Counter
+	totals    *processorTotals
+}
+
+type processorTotals struct {
+	processed atomic.Int64
+	dropped   atomic.Int64
+	emitted   atomic.Int64
+	errors    atomic.Int64
+}
+
+func newProcessorCounters(index int, processorType string) processorCounters {
+	labels := []string{"index", strconv.Itoa(index), "processor", processorType}
+	return processorCounters{
+		processed: metrics.Default.Counter("etelgo_processor_messages_total", labels...),
+		dropped:   metrics.Default.Counter("etelgo_processor_dropped_total", labels...),
+		emitted:   metrics.Default.Counter("etelgo_processor_emitted_total", labels...),
+		errors:    metrics.Default.Counter("etelgo_processor_errors_total", labels...),
+		totals:    &processorTotals{},
+	}
+}
+
+func (c processorCounters) countProcessed() {
+	c.processed.Inc()
+	c.totals.processed.Add(1)
+}
+
+func (c processorCounters) countDropped() {
+	c.dropped.Inc()
+	c.totals.dropped.Add(1)
+}
+
+func (c processorCounters) countEmitted(n int) {
+	c.emitted.Add(int64(n))
+	c.totals.emitted.Add(int64(n))
+}
+
+func (c processorCounters) countError() {
+	c.errors.Inc()
+	c.totals.errors.Add(1)
+}
+
+func NewPipeline(cfgs []config.ProcessorConfig, logger *slog.Logger) (*Pipeline, error) {
+	pipeline := &Pipeline{
+		logger: logger,
+	}
+
+	// Sorting indexes keeps the config position in the errors
+	for _, i := range config.ProcessorOrder(cfgs) {
+		cfg := cfgs[i]
+		processor, err := processors.NewProcessor(processors.ProcessorConfig{
+			Type:   cfg.Type,
+			Config: cfg.Config,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("processor %d (%s): %w", i, cfg.Type, err)
+		}
+		pipeline.processors = append(pipeline.processors, processor)
+		pipeline.counters = append(pipeline.counters, newProcessorCounters(i, cfg.Type))
+		pipeline.indexes = append(pipeline.indexes, i)
+		pipeline.topics = append(pipeline.topics, cfg.Topic)
+	}
+
+	// The processors being built in execution order, the chains are mapped from config indexes to positions
+	positions := make(map[int]int, len(pipeline.indexes))
+	for position, i := range pipeline.indexes {
+		positions[i] = position
+	}
+	pipeline.chains = make(map[string][]int)
+	for topic, chain := range config.ProcessorChains(cfgs) {
+		for _, i := range chain {
+			pipeline.chains[topic] = append(pipeline.chains[topic], positions[i])
+		}
+	}
+
+	return pipeline, nil
+}
+
+// chain returns the positions of the processors applied to the messages of topic: the processors selecting it,
+// or the default chain when there are none
+func (p *Pipeline) chain(topic string) []int {
+	if chain, ok := p.chains[topic]; ok {
+		return chain
+	}
+	return p.chains[""]
+}
+
+// Process applies the processors of the chain of the message topic on the message, returning the resulting messages:
+// none when one of the processors dropped it, several after a one-to-many processor (see processors.MultiProcessor).
+// ctx interrupts the processors waiting before returning the message.
+func (p *Pipeline) Process(ctx context.Context, msg *consumer.Message) ([]*consumer.Message, error) {
+	p.processed.Add(1)
+	out, err := p.processFrom(ctx, p.chain(msg.Topic), []*consumer.Message{msg})
+	switch {
+	case err != nil:
+		p.errors.Add(1)
+	case len(out) == 0:
+		p.dropped.Add(1)
+	}
+	return out, err
+}
+
+// Flush collects the messages emitted on their own by the stateful processors (see processors.Flusher),
+// each being run through the processors following its emitter in its chain. final flushes everything pending, on shutdown.
+// A flushed message failing in a later processor is logged and skipped, there is no source message to report it on.
+func (p *Pipeline) Flush(ctx context.Context, final bool) []*consumer.Message {
+	var out []*consumer.Message
+	for i, processor := range p.processors {
+		flusher, ok := processor.(processors.Flusher)
+		if !ok {
+			continue
+		}
+		chain := p.chains[p.topics[i]]
+		next := chain[slices.Index(chain, i)+1:]
+		for _, msg := range flusher.Flush(final) {
+			p.counters[i].countEmitted(1)
+			result, err := p.processFrom(ctx, next, []*consumer.Message{msg})
+			if err != nil {
+				processErrors.Inc()
+				p.errors.Add(1)
+				p.logger.Error("error processing flushed message", "processor", processor.Name(), "error", err)
+				continue
+			}
+			out = append(out, result...)
+		}
+	}
+	return out
+}
+
+// processFrom applies the processors of chain, given by position, on the messages
+func (p *Pipeline) processFrom(ctx context.Context, chain []int, msgs []*consumer.Message) ([]*consumer.Message, error) {
+	for _, i := range chain {
+		processor := p.processors[i]
+		var next []*consumer.Message
+		for _, m := range msgs {
+			p.counters[i].countProcessed()
+			var before snapshot
+			if p.report != nil {
+				before = takeSnapshot(m)
+			}
+			out, err := apply(ctx, processor, m)
+			if p.report != nil {
+				p.report.record(i, before, out, err)
+			}
+			if err != nil {
+				p.counters[i].countError()
+				return nil, &processors.ProcessorError{Processor: processor.Name(), Err: err}
+			}
+			if len(out) == 0 {
+				p.counters[i].countDropped()
+				droppedRecords.Inc()
+				p.logger.Debug("message dropped", "processor", processor.Name(), "topic", m.Topic, "partition", m.Partition, "offset", m.Offset)
+				continue
+			}
+			p.counters[i].countEmitted(len(out))
+			next = append(next, out...)
+		}
+		if len(next) == 0 {
+			return nil, nil
+		}
+		msgs = next
+	}
+	return msgs, nil
+}
+
+// apply runs the processor on the message, a nil message from Process meaning no message
+func apply(ctx context.Context, processor processors.Processor, msg *consumer.Message) ([]*consumer.Message, error) {
+	if multi, ok := processor.(processors.MultiProcessor); ok {
+		return multi.ProcessMulti(ctx, msg)
+	}
+	out, err := processor.Process(ctx, msg)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return []*consumer.Message{out}, nil
+}
+
+// Stats is a snapshot of the counters of a pipeline, see Pipeline.Stats
+type Stats struct {
+	Processed  int64            // Messages run through the pipeline
+	Dropped    int64            // Messages resulting in no message, dropped by one of the processors
+	Errors     int64            // Messages failing in one of the processors, flushed messages included
+	Processors []ProcessorStats // In the processors order
+}
+
+// ProcessorStats counts the messages of a processor
+type ProcessorStats struct {
+	Index     int    // Position of the processor in the config
+	Type      string // Processor type
+	Processed int64  // Messages entering the processor
+	Dropped   int64  // Messages resulting in no message
+	Emitted   int64  // Messages resulting from the processor, several per message for a one-to-many processor
+	Errors    int64  // Messages the processor failed on
+}
+
+// Stats returns a snapshot of the counters of the pipeline, for applications embedding it to expose them their own way.
+// Unlike the etelgo_processor_* metrics, shared by the pipelines of the process, they only count this pipeline.
+// It is safe to call concurrently with the processing, and only allocates the processors slice.
+// The counters are read one at a time: a snapshot taken while processing may be off by the messages in flight.
+func (p *Pipeline) Stats() Stats {
+	stats := Stats{
+		Processed:  p.processed.Load(),
+		Dropped:    p.dropped.Load(),
+		Errors:     p.errors.Load(),
+		Processors: make([]ProcessorStats, len(p.processors)),
+	}
+	for i, processor := range p.processors {
+		totals := p.counters[i].totals
+		stats.Processors[i] = ProcessorStats{
+			Index:     p.indexes[i],
+			Type:      processor.Name(),
+			Processed: totals.processed.Load(),
+			Dropped:   totals.dropped.Load(),
+			Emitted:   totals.emitted.Load(),
+			Errors:    totals.errors.Load(),
+		}
+	}
+	return stats
+}
+
+// addTotals adds the message counts of the pipeline to the stats, leaving the processors ones
+func (s *Stats) addTotals(p *Pipeline) {
+	s.Processed += p.processed.Load()
+	s.Dropped += p.dropped.Load()
+	s.Errors += p.errors.Load()
+}
+
+// Stats returns a snapshot of the counters of the processors since the start, across the reloads: the message counts
+// add up the pipelines replaced by Reload and the current one, the Processors being the ones of the current chain.
+func (o *Orchestrator) Stats() Stats {
+	o.retiredMu.Lock()
+	defer o.retiredMu.Unlock()
+
+	stats := o.pipeline.Load().Stats()
+	stats.Processed += o.retiredTotals.Processed
+	stats.Dropped += o.retiredTotals.Dropped
+	stats.Errors += o.retiredTotals.Errors
+	for _, pipeline := range o.retired {
+		stats.addTotals(pipeline)
+	}
+	return stats
+}
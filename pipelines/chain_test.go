@@ -0,0 +1,173 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewPipeline_Priority(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	priority := func(p int) *int { return &p }
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "passthrough"},
+		{Type: "copy", Config: map[string]interface{}{"source_field": "a", "target_field": "b"}},
+		{Type: "sample", Priority: priority(-1), Config: map[string]interface{}{"every": 1}},
+		{Type: "route", Priority: priority(5), Config: map[string]interface{}{"rules": []interface{}{
+			map[string]interface{}{"label": "all", "default": true},
+		}}},
+	}
+
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"sample", "passthrough", "copy", "route"}
+	if len(pipeline.processors) != len(want) {
+		t.Fatalf("expected %d processors, got %d", len(want), len(pipeline.processors))
+	}
+	for i, processor := range pipeline.processors {
+		if processor.Name() != want[i] {
+			t.Errorf("processor %d: expected %s, got %s", i, want[i], processor.Name())
+		}
+	}
+}
+
+func TestPipeline_ProcessOneToMany(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "explode", Config: map[string]interface{}{"field_name": "scores"}},
+		{Type: "drop", Config: map[string]interface{}{"field_name": "_key", "filter_criteria": "art"}},
+		{Type: "copy", Config: map[string]interface{}{"source_field": "_value", "target_field": "score"}},
+	}
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := &consumer.Message{ValueFields: map[string]interface{}{
+		"scores": map[string]interface{}{"art": 15.0, "math": 12.0, "music": 9.0},
+	}}
+	out, err := pipeline.Process(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]float64{"math": 12, "music": 9}
+	if len(out) != len(want) {
+		t.Fatalf("expected %d messages, got %d", len(want), len(out))
+	}
+	for _, m := range out {
+		key := m.ValueFields["_key"].(string)
+		if m.ValueFields["score"] != want[key] {
+			t.Errorf("%s: score = %v, want %v", key, m.ValueFields["score"], want[key])
+		}
+	}
+}
+
+func TestPipeline_TopicChains(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "enrich", Topic: "orders", Config: map[string]interface{}{"added_field_name": "chain", "added_field_value": "orders"}},
+		{Type: "enrich", Config: map[string]interface{}{"added_field_name": "chain", "added_field_value": "default"}},
+		{Type: "copy", Topic: "orders", Config: map[string]interface{}{"source_field": "chain", "target_field": "copied"}},
+	}
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		topic  string
+		chain  string
+		copied interface{}
+	}{
+		{"orders", "orders", "orders"},
+		{"payments", "default", nil},
+	}
+	for _, tt := range tests {
+		msg := &consumer.Message{Topic: tt.topic, ValueFields: map[string]interface{}{}}
+		out, err := pipeline.Process(context.Background(), msg)
+		if err != nil || len(out) != 1 {
+			t.Fatalf("%s: Process() = %v, %v, want one message", tt.topic, out, err)
+		}
+		if out[0].ValueFields["chain"] != tt.chain || out[0].ValueFields["copied"] != tt.copied {
+			t.Errorf("%s: expected the %s chain, got %v", tt.topic, tt.chain, out[0].ValueFields)
+		}
+	}
+}
+
+func TestPipeline_Flush(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "aggregate", Config: map[string]interface{}{"group_by": "user", "functions": []interface{}{"count"}, "window": "1h"}},
+		{Type: "copy", Config: map[string]interface{}{"source_field": "count", "target_field": "total"}},
+	}
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, user := range []string{"alice", "bob", "alice"} {
+		msg := &consumer.Message{Timestamp: at, ValueFields: map[string]interface{}{"user": user}}
+		if out, err := pipeline.Process(context.Background(), msg); err != nil || len(out) != 0 {
+			t.Fatalf("Process() = %v, %v, want the message aggregated", out, err)
+		}
+	}
+
+	out := pipeline.Flush(context.Background(), true)
+	if len(out) != 2 {
+		t.Fatalf("expected 2 flushed messages, got %d", len(out))
+	}
+	if out[0].ValueFields["user"] != "alice" || out[0].ValueFields["total"] != int64(2) {
+		t.Errorf("expected the flushed message to run through the next processors, got %v", out[0].ValueFields)
+	}
+}
+
+func TestPipeline_Stats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "explode", Config: map[string]interface{}{"field_name": "scores"}},
+		{Type: "drop", Config: map[string]interface{}{"field_name": "_key", "filter_criteria": "art"}},
+	}
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, scores := range []interface{}{
+		map[string]interface{}{"art": 15.0, "math": 12.0, "music": 9.0}, // 2 messages out of 3 kept
+		"not a map",                        // Error
+		map[string]interface{}{"art": 1.0}, // Every exploded message dropped
+	} {
+		pipeline.Process(context.Background(), &consumer.Message{ValueFields: map[string]interface{}{"scores": scores}})
+	}
+
+	stats := pipeline.Stats()
+	if stats.Processed != 3 || stats.Dropped != 1 || stats.Errors != 1 {
+		t.Errorf("unexpected pipeline stats: %+v", stats)
+	}
+	want := []ProcessorStats{
+		{Index: 0, Type: "explode", Processed: 3, Dropped: 0, Emitted: 4, Errors: 1},
+		{Index: 1, Type: "drop", Processed: 4, Dropped: 2, Emitted: 2, Errors: 0},
+	}
+	if len(stats.Processors) != len(want) {
+		t.Fatalf("expected %d processors, got %d", len(want), len(stats.Processors))
+	}
+	for i := range want {
+		if stats.Processors[i] != want[i] {
+			t.Errorf("processor %d: got %+v, want %+v", i, stats.Processors[i], want[i])
+		}
+	}
+}
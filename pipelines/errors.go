@@ -0,0 +1,118 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"etelgo/outputs"
+	"fmt"
+	"runtime/debug"
+)
+
+var (
+	decodeErrors = metrics.Default.Counter("etelgo_errors_total", "stage", "decode")
+	lostRecords  = metrics.Default.Counter("etelgo_lost_records_total") // Records not delivered, skipped or dropped by the error policy
+)
+
+// process runs the message through the pipeline, a message that failed to decode being returned as an error.
+// A panicking processor is recovered and reported as an error of the message, so that one bad record
+// can't take down the worker.
+func (o *Orchestrator) process(msg *consumer.Message, ctx context.Context) (out []*consumer.Message, err error) {
+	if msg.Err != nil {
+		decodeErrors.Inc()
+		return nil, msg.Err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			o.logger.Error("panic while processing message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "panic", r, "stack", string(debug.Stack()))
+			out, err = nil, fmt.Errorf("message %s/%d@%d: processing panic: %v", msg.Topic, msg.Partition, msg.Offset, r)
+		}
+		if err != nil {
+			processErrors.Inc()
+		}
+	}()
+	return o.ProcessMessages(msg, ctx)
+}
+
+// handleError applies the error policy to a message that failed to decode or to process.
+// It returns whether the message is done, a message not done being held back from the commit:
+// with the fail policy, or when it couldn't be sent to the dead letter topic. The dead letter record is tracked
+// by the settlement of the message, which is only marked done once it is delivered.
+func (o *Orchestrator) handleError(ctx context.Context, settlement *settlement, msg *consumer.Message, err error) bool {
+	switch o.config.Errors.Policy {
+	case config.ErrorPolicyDrop:
+		o.logger.Debug("error processing message, dropped", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
+	case config.ErrorPolicyDLQ:
+		o.acks.track(settlement, msg, true)
+		if dlqErr := o.producer.DeadLetter(ctx, msg, o.config.Errors.Dlq_topic, err); dlqErr != nil {
+			o.acks.untrack(msg, true)
+			o.logger.Error("failed to send message to the dead letter topic", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "dlq_topic", o.config.Errors.Dlq_topic, "error", dlqErr)
+			return false
+		}
+		o.logger.Warn("error processing message, sent to the dead letter topic", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "dlq_topic", o.config.Errors.Dlq_topic, "error", err)
+	case config.ErrorPolicyFail:
+		o.logger.Error("error processing message, stopping the pipeline", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
+		o.fail(fmt.Errorf("message %s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err))
+		return false
+	default:
+		o.logger.Error("error processing message, skipped", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
+	}
+	return true
+}
+
+// onDelivery reports the delivery outcome to the deliveries callback and acknowledges the record, see ackTracker.
+// A record failing to be delivered once the producer retries are exhausted is settled by the error policy:
+//   - skip and drop : the loss is logged and counted, the message being marked done like a failed processing
+//   - dlq : the message is sent to the dead letter topic, and marked done once the dead letter record is delivered
+//   - fail : the pipeline stops
+//
+// A dead letter record failing to be sent or delivered stops the pipeline too, its message being held back
+// from the commit rather than pinning the commit point of the partition while the consumption goes on.
+// It runs in the producer goroutines, which the dead letter send can't block (the breaker buffer being likely full):
+// it is done aside.
+func (o *Orchestrator) onDelivery(ctx context.Context, d outputs.Delivery) {
+	if o.deliveries != nil {
+		o.deliveries(d)
+	}
+	if d.Err == nil || d.Message == nil {
+		o.acks.acknowledge(d.Message, d.DeadLetter, false)
+		return
+	}
+
+	msg := d.Message
+	switch {
+	case d.DeadLetter:
+		o.acks.acknowledge(msg, true, true)
+		o.logger.Error("dead letter record not delivered, stopping the pipeline", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "dlq_topic", d.Topic, "error", d.Err)
+		o.fail(fmt.Errorf("message %s/%d@%d: dead letter record not delivered: %w", msg.Topic, msg.Partition, msg.Offset, d.Err))
+	case o.config.Errors.Policy == config.ErrorPolicyFail:
+		o.acks.acknowledge(msg, false, true)
+		o.logger.Error("message not delivered, stopping the pipeline", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "output_topic", d.Topic, "error", d.Err)
+		o.fail(fmt.Errorf("message %s/%d@%d: not delivered: %w", msg.Topic, msg.Partition, msg.Offset, d.Err))
+	case o.config.Errors.Policy == config.ErrorPolicyDLQ:
+		o.acks.redirect(msg)
+		go func() {
+			if err := o.producer.DeadLetter(ctx, msg, o.config.Errors.Dlq_topic, d.Err); err != nil {
+				o.acks.acknowledge(msg, true, true)
+				o.logger.Error("failed to send undelivered message to the dead letter topic, stopping the pipeline", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "dlq_topic", o.config.Errors.Dlq_topic, "error", err)
+				o.fail(fmt.Errorf("message %s/%d@%d: not delivered nor sent to the dead letter topic: %w", msg.Topic, msg.Partition, msg.Offset, err))
+				return
+			}
+			o.logger.Warn("message not delivered, sent to the dead letter topic", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "dlq_topic", o.config.Errors.Dlq_topic, "error", d.Err)
+		}()
+	default:
+		lostRecords.Inc()
+		o.acks.acknowledge(msg, false, false)
+		o.logger.Error("message not delivered, skipped", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "output_topic", d.Topic, "error", d.Err)
+	}
+}
+
+// fail reports the error stopping the pipeline to Run, only the first one being kept
+func (o *Orchestrator) fail(err error) {
+	select {
+	case o.failed <- err:
+	default:
+	}
+}
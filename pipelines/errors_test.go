@@ -0,0 +1,191 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/outputs"
+	"etelgo/processors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingProducer records the messages sent to the dead letter topic
+type recordingProducer struct {
+	deadLetters []*consumer.Message
+	err         error
+}
+
+func (p *recordingProducer) Send(context.Context, *consumer.Message) error { return nil }
+func (p *recordingProducer) Flush(context.Context) error                   { return nil }
+func (p *recordingProducer) Ready() error                                  { return nil }
+func (p *recordingProducer) Pending() int                                  { return 0 }
+func (p *recordingProducer) OnDelivery(func(outputs.Delivery))             {}
+func (p *recordingProducer) Close() error                                  { return nil }
+
+func (p *recordingProducer) DeadLetter(_ context.Context, msg *consumer.Message, _ string, _ error) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.deadLetters = append(p.deadLetters, msg)
+	return nil
+}
+
+type panickingProcessor struct{}
+
+func (panickingProcessor) Process(context.Context, *consumer.Message) (*consumer.Message, error) {
+	panic("nil map")
+}
+
+func (panickingProcessor) Name() string { return "panicking" }
+
+func TestHandleError(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	cause := errors.New("failed to deserialize message value")
+
+	tests := []struct {
+		name        string
+		policy      string
+		producerErr error
+		wantDone    bool
+		wantDLQ     int
+		wantFailure bool
+	}{
+		{"Skip", config.ErrorPolicySkip, nil, true, 0, false},
+		{"Drop", config.ErrorPolicyDrop, nil, true, 0, false},
+		{"DLQ", config.ErrorPolicyDLQ, nil, true, 1, false},
+		{"DLQ unavailable", config.ErrorPolicyDLQ, errors.New("buffer full"), false, 0, false},
+		{"Fail", config.ErrorPolicyFail, nil, false, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &recordingProducer{err: tt.producerErr}
+			o := &Orchestrator{
+				config:   &config.Config{Errors: config.ErrorsConfig{Policy: tt.policy, Dlq_topic: "orders-dlq"}},
+				producer: producer,
+				logger:   logger,
+				failed:   make(chan error, 1),
+			}
+			msg := &consumer.Message{Topic: "orders", Partition: 1, Offset: 7}
+
+			if done := o.handleError(context.Background(), nil, msg, cause); done != tt.wantDone {
+				t.Errorf("handleError() = %v, want %v", done, tt.wantDone)
+			}
+			if len(producer.deadLetters) != tt.wantDLQ {
+				t.Errorf("expected %d dead letters, got %d", tt.wantDLQ, len(producer.deadLetters))
+			}
+			select {
+			case err := <-o.failed:
+				if !tt.wantFailure {
+					t.Errorf("unexpected failure: %v", err)
+				} else if !errors.Is(err, cause) || !strings.Contains(err.Error(), "orders/1@7") {
+					t.Errorf("failure = %v, want the cause and the message position", err)
+				}
+			default:
+				if tt.wantFailure {
+					t.Error("expected the pipeline to fail")
+				}
+			}
+		})
+	}
+}
+
+func TestProcessRecoversPanic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	o := &Orchestrator{
+		config:   &config.Config{},
+		producer: &recordingProducer{},
+		logger:   logger,
+	}
+	o.pipeline.Store(&Pipeline{
+		processors: []processors.Processor{panickingProcessor{}},
+		counters:   []processorCounters{newProcessorCounters(0, "panicking")},
+		topics:     []string{""},
+		chains:     map[string][]int{"": {0}},
+		logger:     logger,
+	})
+	msg := &consumer.Message{Topic: "orders", Partition: 3, Offset: 12, ValueFields: map[string]interface{}{}}
+
+	_, err := o.process(msg, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "orders/3@12: processing panic: nil map") {
+		t.Errorf("process() error = %v, want processing panic", err)
+	}
+}
+
+func TestProcessDecodeError(t *testing.T) {
+	o := &Orchestrator{config: &config.Config{}, logger: slog.New(slog.NewTextHandler(os.Stderr, nil))}
+	cause := errors.New("failed to decompress message value")
+
+	if _, err := o.process(&consumer.Message{Err: cause}, context.Background()); !errors.Is(err, cause) {
+		t.Errorf("process() error = %v, want %v", err, cause)
+	}
+}
+
+func TestOnDelivery(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	msg := &consumer.Message{Topic: "orders", Partition: 1, Offset: 7}
+	cause := errors.New("UNKNOWN_TOPIC_OR_PARTITION")
+
+	tests := []struct {
+		name     string
+		policy   string
+		delivery outputs.Delivery
+		wantDLQ  int
+	}{
+		{"Delivery failure with dlq", config.ErrorPolicyDLQ, outputs.Delivery{Message: msg, Err: cause}, 1},
+		{"Delivered", config.ErrorPolicyDLQ, outputs.Delivery{Message: msg}, 0},
+		{"Dead letter failure", config.ErrorPolicyDLQ, outputs.Delivery{Message: msg, DeadLetter: true, Err: cause}, 0},
+		{"Delivery failure with skip", config.ErrorPolicySkip, outputs.Delivery{Message: msg, Err: cause}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &dlqSignalProducer{sent: make(chan *consumer.Message, 1)}
+			var reported []outputs.Delivery
+			o := &Orchestrator{
+				config:     &config.Config{Errors: config.ErrorsConfig{Policy: tt.policy, Dlq_topic: "orders-dlq"}},
+				producer:   producer,
+				logger:     logger,
+				deliveries: func(d outputs.Delivery) { reported = append(reported, d) },
+			}
+
+			o.onDelivery(context.Background(), tt.delivery)
+
+			if len(reported) != 1 {
+				t.Errorf("expected the delivery reported to the callback, got %d", len(reported))
+			}
+			if tt.wantDLQ == 0 {
+				select {
+				case <-producer.sent:
+					t.Error("expected no dead letter")
+				case <-time.After(20 * time.Millisecond):
+				}
+				return
+			}
+			select {
+			case got := <-producer.sent:
+				if got != msg {
+					t.Errorf("dead letter = %v, want the undelivered message", got)
+				}
+			case <-time.After(time.Second):
+				t.Error("expected the undelivered message sent to the dead letter topic")
+			}
+		})
+	}
+}
+
+// dlqSignalProducer signals the messages sent to the dead letter topic, which onDelivery does asynchronously
+type dlqSignalProducer struct {
+	recordingProducer
+	sent chan *consumer.Message
+}
+
+func (p *dlqSignalProducer) DeadLetter(_ context.Context, msg *consumer.Message, _ string, _ error) error {
+	p.sent <- msg
+	return nil
+}
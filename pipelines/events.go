@@ -0,0 +1,36 @@
+package pipelines
+
+import "time"
+
+// EventType names a step of the pipeline lifecycle, see Event
+type EventType string
+
+const (
+	EventStarted    EventType = "started"    // The input is consumed and the workers are running
+	EventRebalanced EventType = "rebalanced" // The consumer group assigned or revoked partitions
+	EventPaused     EventType = "paused"     // The input fetching is paused by the output backpressure
+	EventResumed    EventType = "resumed"    // The output caught up, the input fetching resumed
+	EventDraining   EventType = "draining"   // The consumption stopped, the messages in flight are drained
+	EventStopped    EventType = "stopped"    // Run is returning, with Err when the pipeline failed
+)
+
+// Event is a lifecycle change of a running pipeline, reported to RunOptions.Events for host applications
+// to react to it, e.g. an orchestrator tracking readiness. It comes in addition to the logs.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	Assigned map[string][]int32 // Partitions assigned by topic, EventRebalanced only
+	Revoked  map[string][]int32 // Partitions revoked or lost by topic, EventRebalanced only
+	Pending  int                // Records pending in the output, EventPaused and EventResumed only
+	Err      error              // Error stopping the pipeline, EventStopped only
+}
+
+// emit reports the event to the events callback, if any, timestamping it
+func (o *Orchestrator) emit(event Event) {
+	if o.events == nil {
+		return
+	}
+	event.Time = time.Now()
+	o.events(event)
+}
@@ -0,0 +1,23 @@
+package pipelines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmit(t *testing.T) {
+	// Without callback, the events are skipped
+	(&Orchestrator{}).emit(Event{Type: EventStarted})
+
+	var events []Event
+	o := &Orchestrator{events: func(e Event) { events = append(events, e) }}
+	before := time.Now()
+	o.emit(Event{Type: EventRebalanced, Assigned: map[string][]int32{"orders": {0, 1}}})
+
+	if len(events) != 1 || events[0].Type != EventRebalanced || len(events[0].Assigned["orders"]) != 2 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[0].Time.Before(before) {
+		t.Errorf("event time = %v, want the emit time", events[0].Time)
+	}
+}
@@ -0,0 +1,149 @@
+package pipelines
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/outputs"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Fixtures and golden files are JSON lines files, so that pipeline configs can be regression tested without Kafka.
+// Each fixture line is an input message, whose value is always written as JSON whatever the input format.
+// Each golden line is an outcome of a fixture message : an output record, or the processing error.
+// Dropped messages have no golden line, and a message exploded by a one-to-many processor has one per output record.
+// The messages emitted by stateful processors (e.g. aggregate) are written after the line closing their window,
+// or at the end of the fixture.
+
+// FixtureMessage is one line of a fixture file
+type FixtureMessage struct {
+	Topic     string            `json:"topic,omitempty"`
+	Partition int32             `json:"partition,omitempty"`
+	Offset    int64             `json:"offset,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     json.RawMessage   `json:"value"`
+}
+
+// GoldenRecord is one line of a golden file
+type GoldenRecord struct {
+	Line      int                    `json:"line"`            // Line of the fixture message it results from or that flushed it, 0 when flushed at the end
+	Topic     string                 `json:"topic,omitempty"` // Destination topic, per the output routing
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+	Key       string                 `json:"key,omitempty"`
+	Headers   map[string]string      `json:"headers,omitempty"`
+	Value     map[string]interface{} `json:"value,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// FixtureRunner runs fixture messages through the configured processors
+type FixtureRunner struct {
+	pipeline     *Pipeline
+	router       *outputs.TopicRouter
+	deserializer consumer.Deserializer
+}
+
+func NewFixtureRunner(cfg *config.Config, logger *slog.Logger) (*FixtureRunner, error) {
+	pipeline, err := NewPipeline(cfg.Processors, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	floatNumbers := cfg.Input.Json_numbers != nil && *cfg.Input.Json_numbers == "float64"
+	return &FixtureRunner{
+		pipeline:     pipeline,
+		router:       outputs.NewTopicRouter(&cfg.Output),
+		deserializer: &consumer.JSONDeserializer{FloatNumbers: floatNumbers},
+	}, nil
+}
+
+// Run reads the fixture messages from r and writes the golden records to w.
+// An invalid fixture line stops the run, while a processing error is recorded as the outcome of the message.
+func (fr *FixtureRunner) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var fixture FixtureMessage
+		if err := json.Unmarshal(scanner.Bytes(), &fixture); err != nil {
+			return fmt.Errorf("fixture line %d: %w", line, err)
+		}
+		valueFields, err := fr.deserializer.Deserialize(fixture.Value)
+		if err != nil {
+			return fmt.Errorf("fixture line %d: invalid value: %w", line, err)
+		}
+
+		msg := &consumer.Message{
+			Key:         []byte(fixture.Key),
+			Value:       fixture.Value,
+			Topic:       fixture.Topic,
+			Partition:   fixture.Partition,
+			Offset:      fixture.Offset,
+			Timestamp:   fixture.Timestamp,
+			Headers:     fixture.Headers,
+			ValueFields: valueFields,
+		}
+
+		out, err := fr.pipeline.Process(ctx, msg)
+		if err != nil {
+			if err := encoder.Encode(GoldenRecord{Line: line, Error: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, m := range out {
+			if err := encoder.Encode(goldenRecord(line, fr.router, m)); err != nil {
+				return err
+			}
+		}
+
+		// Windows closed by this message are emitted right away, as the flush timer would
+		for _, m := range fr.pipeline.Flush(ctx, false) {
+			if err := encoder.Encode(goldenRecord(line, fr.router, m)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// The messages still held by the stateful processors are emitted at the end of the fixture, without line
+	for _, m := range fr.pipeline.Flush(ctx, true) {
+		if err := encoder.Encode(goldenRecord(0, fr.router, m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goldenRecord builds the golden record of an output message resulting from the fixture line
+func goldenRecord(line int, router *outputs.TopicRouter, m *consumer.Message) GoldenRecord {
+	record := GoldenRecord{
+		Line:    line,
+		Topic:   router.Route(m),
+		Key:     string(m.Key),
+		Headers: m.Headers,
+		Value:   m.ValueFields,
+	}
+	if !m.Timestamp.IsZero() {
+		record.Timestamp = &m.Timestamp
+	}
+	return record
+}
@@ -0,0 +1,51 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFixtureRunner_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	topicField := "_route"
+	cfg := &config.Config{
+		Processors: []config.ProcessorConfig{
+			{Type: "route", Config: map[string]interface{}{"rules": []interface{}{
+				map[string]interface{}{"label": "errors", "when": map[string]interface{}{"field": "level", "value": "error"}},
+			}}},
+			{Type: "copy", Config: map[string]interface{}{"source_field": "user", "target_field": "user.name"}},
+		},
+		Output: config.OutputConfig{Topic: "out", Topic_field: &topicField},
+	}
+
+	runner, err := NewFixtureRunner(cfg, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fixture := `{"key":"k1","value":{"level":"error","count":9007199254740993}}
+
+{"value":{"level":"info","user":"john"}}
+{"timestamp":"2026-01-01T00:00:00Z","headers":{"h":"v"},"value":{"level":"info"}}
+`
+	var got strings.Builder
+	if err := runner.Run(context.Background(), strings.NewReader(fixture), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"line":1,"topic":"errors","key":"k1","value":{"_route":"errors","count":9007199254740993,"level":"error"}}
+{"line":3,"error":"processor copy: field \"user\" is not an object"}
+{"line":4,"topic":"out","timestamp":"2026-01-01T00:00:00Z","headers":{"h":"v"},"value":{"level":"info"}}
+`
+	if got.String() != want {
+		t.Errorf("unexpected golden output:\n%s\nwant:\n%s", got.String(), want)
+	}
+
+	if err := runner.Run(context.Background(), strings.NewReader("{not json}\n"), &got); err == nil {
+		t.Errorf("expected an error on an invalid fixture line")
+	}
+}
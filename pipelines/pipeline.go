@@ -1,11 +1,22 @@
-package main
+package pipelines
 
 import (
 	"context"
 	"etelgo/config"
 	"etelgo/consumer"
+	"etelgo/metrics"
+	"etelgo/outputs"
+	"fmt"
+	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	consumeErrors = metrics.Default.Counter("etelgo_errors_total", "stage", "consume")
+	processErrors = metrics.Default.Counter("etelgo_errors_total", "stage", "process")
 )
 
 // Need to add how to handle different type of consumer
@@ -13,75 +24,313 @@ import (
 type Orchestrator struct {
 	config   *config.Config
 	consumer *consumer.KafkaConsumer
+	pipeline atomic.Pointer[Pipeline] // Swapped by Reload, see acquirePipeline
+	producer outputs.Producer
 	logger   *slog.Logger
+	inFlight atomic.Int64   // Messages currently processed by the workers
+	failed   chan error     // First error stopping the pipeline, with the fail error policy
+	reorder  *reorderBuffer // Releases the messages in offset order, nil unless the output is ordered
+	acks     *ackTracker    // Marks the messages done once their records are delivered, nil in dry run
+	report   *Report        // Effects of the processors, only set in dry run: nothing is produced nor committed
+
+	rawValues bool // The values are forwarded without decoding, see forwardsRawValues
+
+	maxMessages  int           // Messages dispatched before stopping, 0 means no limit
+	limitReached chan struct{} // Closed once maxMessages messages are dispatched
+	idleTimeout  time.Duration // Time without message before stopping, 0 means no limit
+	idleReached  chan struct{} // Closed once no message was consumed for idleTimeout
+
+	retiredMu     sync.Mutex
+	retired       []*Pipeline // Pipelines replaced by Reload, flushed once their last message is processed
+	retiredTotals Stats       // Message counts of the retired pipelines flushed and forgotten, see Orchestrator.Stats
+
+	events     func(Event)            // Lifecycle events callback, see RunOptions.Events
+	deliveries func(outputs.Delivery) // Delivery reports callback, see RunOptions.Deliveries
 	//metrics to be added to enable telemetry and observability
 }
 
-func NewOrchestrator(configPath string, logger *slog.Logger) (*Orchestrator, error) {
-	cfg, err := config.LoadConfig(configPath, logger)
+// RunOptions holds the runtime options provided through the CLI flags
+type RunOptions struct {
+	DryRun         bool // Process the messages without writing to output nor committing offsets, see Report
+	SkipTopicCheck bool // Skip the input topic existence check at startup
+	MaxMessages    int  // Stop once this many messages are consumed, 0 means no limit
+
+	// Stop once no message was consumed for this duration and none is still processed or pending in the output,
+	// e.g. for a job processing a topic until it is drained. 0 means no limit.
+	IdleTimeout time.Duration
+
+	// Receives the dry run report once the run is over, nil to skip it (see Orchestrator.Report)
+	Report io.Writer
+
+	// Receives the reloaded configurations whose processors replace the running ones, see Orchestrator.Reload
+	Reload <-chan *config.Config
+
+	// Called with each lifecycle event of the run, nil to skip them. It is called synchronously
+	// (from the consumer group rebalance for EventRebalanced), so it must return quickly.
+	Events func(Event)
+
+	// Called with the delivery outcome of every record produced, including the dead letter ones, nil to skip them.
+	// It is called from the producer goroutines, so it must return quickly and be safe for concurrent use.
+	Deliveries func(outputs.Delivery)
+
+	// Maximum duration of the graceful drain on shutdown (in-flight messages, producer flush, offsets commit).
+	// Once elapsed the remaining messages are dropped, 0 means waiting indefinitely.
+	ShutdownTimeout time.Duration
+}
+
+func NewOrchestrator(cfg *config.Config, logger *slog.Logger) (*Orchestrator, error) {
+	cons, err := consumer.NewKafkaConsumer(&cfg.Input, logger)
 	if err != nil {
-		logger.Error("error loading config")
+		logger.Error("error creating a new Kafka Consumer")
 		return nil, err
 	}
 
-	cons, err := consumer.NewKafkaConsumer(&cfg.Input, logger)
+	return newOrchestrator(cfg, cons, logger)
+}
+
+// NewReplayOrchestrator creates an orchestrator replaying the input topics within the window.
+// The run stops by itself once the window is consumed, and the consumer group offsets are left untouched.
+func NewReplayOrchestrator(cfg *config.Config, window consumer.ReplayWindow, logger *slog.Logger) (*Orchestrator, error) {
+	cons, err := consumer.NewKafkaReplayConsumer(&cfg.Input, window, logger)
 	if err != nil {
-		logger.Error("error creating a new Kafka Consumer")
+		logger.Error("error creating a new Kafka replay Consumer")
+		return nil, err
+	}
+
+	return newOrchestrator(cfg, cons, logger)
+}
+
+func newOrchestrator(cfg *config.Config, cons *consumer.KafkaConsumer, logger *slog.Logger) (*Orchestrator, error) {
+	pipeline, err := NewPipeline(cfg.Processors, logger)
+	if err != nil {
+		logger.Error("error creating the processors pipeline")
+		return nil, err
+	}
+
+	prod, err := outputs.NewKafkaProducer(&cfg.Output, logger)
+	if err != nil {
+		logger.Error("error creating a new Kafka Producer")
 		return nil, err
 	}
+	prod.UseDeadLetterEnvelope(cfg.Errors.Dlq_envelope)
 
-	return &Orchestrator{
-		cfg,
-		cons,
-		logger,
-	}, nil
+	var reorder *reorderBuffer
+	if *cfg.Output.Ordered {
+		reorder = newReorderBuffer(*cfg.Output.Ordered_buffer_size)
+	}
+
+	o := &Orchestrator{
+		config:   cfg,
+		consumer: cons,
+		producer: prod,
+		logger:   logger,
+		failed:   make(chan error, 1),
+		reorder:  reorder,
+	}
+	o.pipeline.Store(pipeline)
+	return o, nil
 }
 
-func (o *Orchestrator) Run(ctx context.Context, dryRun bool) error {
+func (o *Orchestrator) Run(ctx context.Context, opts RunOptions) (err error) {
 	o.logger.Info("Running Orchestrator")
+	o.events = opts.Events
+	defer func() { o.emit(Event{Type: EventStopped, Err: err}) }()
 
-	if dryRun {
-		o.logger.Info("Dry run mode - exiting")
-		return nil
+	if err := o.consumer.Connect(ctx); err != nil {
+		return err
+	}
+
+	if opts.SkipTopicCheck {
+		o.logger.Warn("Input topic check skipped")
+	} else if err := o.consumer.CheckTopic(ctx); err != nil {
+		o.logger.Error("input topic check failed", "error", err)
+		return err
+	}
+
+	if err := o.consumer.Seek(ctx); err != nil {
+		o.logger.Error("failed to seek input topics", "error", err)
+		return err
+	}
+
+	if opts.DryRun {
+		o.logger.Info("Dry run mode - messages are processed without writing to output nor committing offsets")
+		o.producer.Close()
+		o.producer = discardProducer{}
+		o.report = o.pipeline.Load().EnableReport()
+		if opts.Report != nil {
+			defer o.report.Write(opts.Report)
+		}
 	}
 
+	// The dry run report needs the decoded fields
+	if o.report == nil && forwardsRawValues(o.config) {
+		o.logger.Info("No processor reads the payloads, forwarding the raw values without decoding them")
+		o.rawValues = true
+		o.consumer.ForwardRawValues()
+	}
+
+	o.maxMessages = opts.MaxMessages
+	o.limitReached = make(chan struct{})
+	o.idleTimeout = opts.IdleTimeout
+	o.idleReached = make(chan struct{})
+
+	if me := o.config.Monitoring.Metrics_export; me.Enabled {
+		go metrics.Serve(ctx, fmt.Sprintf(":%d", me.Port), metrics.Default, o.Ready, o.logger)
+	}
+
+	// consumeCtx is also cancelled once a replay is complete, triggering the same drain as a shutdown
+	consumeCtx, stopConsuming := context.WithCancel(ctx)
+	defer stopConsuming()
+
 	//start consumer
-	o.consumer.Start(ctx)
+	o.consumer.OnRebalance(func(assigned, revoked map[string][]int32) {
+		o.emit(Event{Type: EventRebalanced, Assigned: assigned, Revoked: revoked})
+	})
+	o.consumer.Start(consumeCtx)
 	defer o.consumer.Close()
+	defer o.producer.Close()
+
+	// Messages in flight are processed with a context outliving ctx, so that the shutdown lets them reach the output.
+	// It is only cancelled when the shutdown timeout is reached.
+	processCtx, cancelProcess := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProcess()
+
+	if o.report == nil {
+		o.acks = newAckTracker(o.consumer.MarkDone)
+	}
+	o.deliveries = opts.Deliveries
+	o.producer.OnDelivery(func(d outputs.Delivery) { o.onDelivery(processCtx, d) })
 
 	//Messages loop
 	var wg sync.WaitGroup
 	workerCount := o.config.Input.Workers
-	o.logger.Info("Starting workers", "count", workerCount)
-
+	// Each worker has its own queue, fed with the messages of its partitions.
+	// In ordered mode the workers share a single queue instead, the reorder buffer restoring the offset order.
+	queues := make([]chan *consumer.Message, workerCount)
+	var shared chan *consumer.Message
+	if o.reorder != nil {
+		o.logger.Info("Starting workers", "count", workerCount, "ordered", true, "ordered_buffer_size", cap(o.reorder.slots))
+		shared = make(chan *consumer.Message)
+	} else {
+		o.logger.Info("Starting workers", "count", workerCount, "affinity", *o.config.Input.Worker_affinity)
+	}
 	for i := 0; i < workerCount; i++ {
+		queues[i] = shared
+		if queues[i] == nil {
+			queues[i] = make(chan *consumer.Message)
+		}
 		wg.Add(1)
-		go o.worker(ctx, i, &wg)
+		go o.worker(consumeCtx, processCtx, i, queues[i], &wg)
+	}
+	go o.dispatch(consumeCtx, queues)
+
+	//Metrics and Errors handling
+	go o.HandleErrors(consumeCtx)
+	go o.regulate(consumeCtx)
+	go o.flushLoop(consumeCtx, processCtx)
+	if opts.Reload != nil {
+		go o.watchReloads(consumeCtx, opts.Reload)
 	}
+	o.emit(Event{Type: EventStarted})
 
-	//Apply processors
+	var failure error
+	select {
+	case <-ctx.Done():
+	case <-o.consumer.Done():
+		o.logger.Info("Replay complete")
+	case <-o.limitReached:
+		o.logger.Info("Maximum messages consumed", "max_messages", o.maxMessages)
+	case <-o.idleReached:
+		o.logger.Info("No message consumed within the idle timeout", "idle_timeout", o.idleTimeout)
+	case failure = <-o.failed:
+	}
+	stopConsuming()
+	o.emit(Event{Type: EventDraining})
+	if err := o.drain(&wg, cancelProcess, opts.ShutdownTimeout); err != nil {
+		return err
+	}
+	return failure
+}
 
-	//Send to output
+// drain waits for the workers to finish their current message, flushes the producer and commits the offsets,
+// all bounded by the shutdown timeout. Offsets are not committed if the drain did not complete, not even by the
+// consumer leaving its group on Close: the dropped messages will then be consumed again on restart. Without commit_on_shutdown the drained messages
+// are not committed either, the restart resuming from the last periodic commit.
+func (o *Orchestrator) drain(wg *sync.WaitGroup, cancelProcess context.CancelFunc, timeout time.Duration) error {
+	o.logger.Info("Draining pipeline", "timeout", timeout)
 
-	//Metrics and Errors handling
-	go o.HandleErrors(ctx)
+	drainCtx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(drainCtx, timeout)
+		defer cancel()
+	}
 
-	wg.Wait()
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-drainCtx.Done():
+		dropped := o.inFlight.Load() + int64(o.producer.Pending())
+		cancelProcess()
+		o.logger.Warn("Shutdown timeout reached while waiting for workers, dropping messages", "dropped", dropped)
+		o.consumer.SkipCloseCommit()
+		return nil
+	}
+
+	// The stateful processors emit what they still hold, before the producer flush
+	o.flush(drainCtx, true)
+
+	if err := o.producer.Flush(drainCtx); err != nil {
+		o.logger.Warn("Shutdown timeout reached while flushing producer, dropping messages", "dropped", o.producer.Pending(), "error", err)
+		o.consumer.SkipCloseCommit()
+		return nil
+	}
 
+	if o.report != nil {
+		o.logger.Info("Pipeline drained, offsets not committed in dry run")
+		return nil
+	}
+	if !*o.config.Input.Commit_on_shutdown {
+		o.logger.Info("Pipeline drained, offsets not committed on shutdown (commit_on_shutdown disabled)")
+		return nil
+	}
+
+	if err := o.consumer.Commit(drainCtx); err != nil {
+		o.logger.Error("failed to commit offsets on shutdown", "error", err)
+		return err
+	}
+
+	o.logger.Info("Pipeline drained")
 	return nil
 }
 
-func (o *Orchestrator) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
+// Report returns the effects of the processors collected by a dry run, nil otherwise
+func (o *Orchestrator) Report() *Report {
+	return o.report
+}
+
+// Ready reports whether the pipeline is able to deliver messages
+func (o *Orchestrator) Ready() error {
+	return o.producer.Ready()
+}
+
+// worker processes the messages of its queue, see dispatch.
+// It stops receiving messages once ctx is done, the current message being processed with processCtx
+func (o *Orchestrator) worker(ctx context.Context, processCtx context.Context, id int, queue <-chan *consumer.Message, wg *sync.WaitGroup) {
 	defer wg.Done()
 	o.logger.Info("Starting worker", "id", id)
 
 	for {
 		select {
-		case msg := <-o.consumer.Messages():
-			err := o.ProcessMessages(msg, ctx)
-			if err != nil {
-				o.logger.Error("error processing message", "error", err)
-			}
+		case msg := <-queue:
+			o.inFlight.Add(1)
+			o.handle(msg, ctx, processCtx)
+			o.inFlight.Add(-1)
 		case <-ctx.Done():
 			o.logger.Info("worker context done, stopping", "id", id)
 			return
@@ -89,10 +338,74 @@ func (o *Orchestrator) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
 
 	}
 }
+
+// handle processes the message, then sends the results to the output and settles the message: marked done
+// once its records are delivered (see ackTracker), or handed to the error policy. The processing stops waiting once ctx is done, the send is bounded by processCtx.
+// In ordered mode the send and settlement wait for the previous messages of the partition, see reorderBuffer.
+func (o *Orchestrator) handle(msg *consumer.Message, ctx context.Context, processCtx context.Context) {
+	if o.report != nil {
+		o.report.consume(msg)
+	}
+	out, err := o.process(msg, ctx)
+	release := func() {
+		settlement := o.acks.begin(msg)
+		if err == nil {
+			if err = o.send(processCtx, settlement, out); err != nil {
+				processErrors.Inc()
+			}
+		}
+
+		// A message dropped by the shutdown timeout stays uncommitted, to be consumed again on restart
+		done := processCtx.Err() == nil
+		if err != nil && done {
+			done = o.handleError(processCtx, settlement, msg, err)
+		}
+		o.acks.end(settlement, done)
+	}
+
+	if o.reorder != nil {
+		o.reorder.complete(msg, release)
+		return
+	}
+	release()
+}
+
+// flushInterval is how often the stateful processors are asked for the messages they emit on their own
+const flushInterval = time.Second
+
+// flushLoop sends the messages emitted by the stateful processors until ctx is done, see Pipeline.Flush
+func (o *Orchestrator) flushLoop(ctx context.Context, processCtx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.flush(processCtx, false)
+		}
+	}
+}
+
+// flush sends the messages emitted by the stateful processors to the output, all the pending ones when final is set.
+// The pipelines replaced by Reload are flushed entirely, once they have no message in flight.
+func (o *Orchestrator) flush(ctx context.Context, final bool) {
+	out := o.pipeline.Load().Flush(ctx, final)
+	out = append(out, o.flushRetired(ctx)...)
+	for _, msg := range out {
+		if err := o.producer.Send(ctx, msg); err != nil {
+			processErrors.Inc()
+			o.logger.Error("failed to send flushed message", "error", err)
+		}
+	}
+}
+
 func (o *Orchestrator) HandleErrors(ctx context.Context) {
 	for {
 		select {
 		case err := <-o.consumer.Errors():
+			consumeErrors.Inc()
 			o.logger.Error("received error from consumer", "error", err)
 			// o.handleErrorByType(err)
 		case <-ctx.Done():
@@ -105,8 +418,23 @@ func (o *Orchestrator) HandleErrors(ctx context.Context) {
 func (o *Orchestrator) handleErrorByType(err error) {
 }
 
-func (o *Orchestrator) ProcessMessages(msg *consumer.Message, ctx context.Context) error {
-	o.logger.Info("Starting message processing")
+// ProcessMessages applies the processors pipeline on the message, returning the messages to send to the output.
+// Processors waiting before returning (e.g. pace) stop waiting once ctx is done.
+func (o *Orchestrator) ProcessMessages(msg *consumer.Message, ctx context.Context) ([]*consumer.Message, error) {
+	o.logger.Debug("Starting message processing", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	pipeline := o.acquirePipeline()
+	defer pipeline.inFlight.Add(-1)
+	return pipeline.Process(ctx, msg)
+}
 
+// send produces the resulting messages of a processed message
+func (o *Orchestrator) send(ctx context.Context, settlement *settlement, out []*consumer.Message) error {
+	for _, m := range out {
+		o.acks.track(settlement, m, false)
+		if err := o.producer.Send(ctx, m); err != nil {
+			o.acks.untrack(m, false)
+			return err
+		}
+	}
 	return nil
 }
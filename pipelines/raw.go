@@ -0,0 +1,42 @@
+package pipelines
+
+import (
+	"etelgo/config"
+	"etelgo/processors"
+	"strings"
+)
+
+// forwardsRawValues tells whether the message values can be forwarded without being decoded then encoded again,
+// e.g. to mirror a topic: no processor reads them, the output encodes them in the input format and doesn't route
+// on their fields nor stamp them in the headers. The csv and auto formats are always decoded, the columns or the format
+// of each message possibly differing on output.
+func forwardsRawValues(cfg *config.Config) bool {
+	if !passthroughOnly(cfg.Processors) || cfg.Output.Topic_field != nil || headersReadValues(cfg.Output.Headers) {
+		return false
+	}
+	switch config.Format(cfg.Input.Format) {
+	case config.FormatCSV, config.FormatAuto:
+		return false
+	}
+	return cfg.Input.Format == cfg.Output.Format
+}
+
+// passthroughOnly tells whether the processors, if any, leave the payloads untouched
+func passthroughOnly(cfgs []config.ProcessorConfig) bool {
+	for _, cfg := range cfgs {
+		if cfg.Type != processors.ProcessorTypePassthrough {
+			return false
+		}
+	}
+	return true
+}
+
+// headersReadValues tells whether an output header references a value field, see config.HeaderTemplateVariable
+func headersReadValues(headers map[string]string) bool {
+	for _, template := range headers {
+		if strings.Contains(template, "${"+config.HeaderValuePrefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/outputs"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestForwardsRawValues(t *testing.T) {
+	passthrough := []config.ProcessorConfig{{Type: "passthrough"}}
+	copyField := []config.ProcessorConfig{{Type: "copy", Config: map[string]interface{}{"source_field": "id", "target_field": "order_id"}}}
+	topicField := "topic"
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want bool
+	}{
+		{"No processor", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "json"}}, true},
+		{"Passthrough", config.Config{Input: config.InputConfig{Format: "msgpack"}, Output: config.OutputConfig{Format: "msgpack"}, Processors: passthrough}, true},
+		{"Processor reading the value", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "json"}, Processors: copyField}, false},
+		{"Format converted", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "msgpack"}}, false},
+		{"CSV format", config.Config{Input: config.InputConfig{Format: "csv"}, Output: config.OutputConfig{Format: "csv"}}, false},
+		{"Topic routing", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "json", Topic_field: &topicField}}, false},
+		{"Headers from the metadata", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "json", Headers: map[string]string{"source": "${topic}"}}}, true},
+		{"Headers from a value field", config.Config{Input: config.InputConfig{Format: "json"}, Output: config.OutputConfig{Format: "json", Headers: map[string]string{"tenant": "${value.tenant.id}"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forwardsRawValues(&tt.cfg); got != tt.want {
+				t.Errorf("forwardsRawValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrchestratorReload_RawValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	current := &config.Config{Input: config.InputConfig{Topics: []string{"orders"}, Format: "json"}}
+	pipeline, err := NewPipeline(nil, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o := &Orchestrator{config: current, logger: logger, rawValues: true}
+	o.pipeline.Store(pipeline)
+
+	next := &config.Config{
+		Input:      current.Input,
+		Processors: []config.ProcessorConfig{{Type: "copy", Config: map[string]interface{}{"source_field": "id", "target_field": "order_id"}}},
+	}
+	if err := o.Reload(next); err == nil || !strings.Contains(err.Error(), "forwarded without being decoded") {
+		t.Errorf("Reload() error = %v, want the raw forwarding rejection", err)
+	}
+
+	next.Processors = []config.ProcessorConfig{{Type: "passthrough"}}
+	if err := o.Reload(next); err != nil {
+		t.Errorf("Reload() error = %v, want a passthrough accepted", err)
+	}
+}
+
+// BenchmarkNoopPipeline compares a message going through a passthrough pipeline decoded then encoded again,
+// to the same message forwarded raw (see forwardsRawValues)
+func BenchmarkNoopPipeline(b *testing.B) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pipeline, err := NewPipeline([]config.ProcessorConfig{{Type: "passthrough"}}, logger)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	value := []byte(`{"id":"a1b2c3","user":{"name":"alice","email":"alice@example.com","tags":["new","vip"]},"amount":129.99,"items":[{"sku":"X1","qty":2},{"sku":"Y7","qty":1}],"created_at":"2026-01-02T03:04:05Z"}`)
+	deserializer := &consumer.JSONDeserializer{}
+	serializer := &outputs.JSONSerializer{}
+	ctx := context.Background()
+
+	b.Run("decode_encode", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(value)))
+		for i := 0; i < b.N; i++ {
+			fields, err := deserializer.Deserialize(value)
+			if err != nil {
+				b.Fatal(err)
+			}
+			out, err := pipeline.Process(ctx, &consumer.Message{Value: value, ValueFields: fields})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := serializer.Serialize(out[0].ValueFields); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("raw_forward", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(value)))
+		for i := 0; i < b.N; i++ {
+			if _, err := pipeline.Process(ctx, &consumer.Message{Value: value}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	configReloads        = metrics.Default.Counter("etelgo_config_reloads_total", "result", "success")
+	configReloadFailures = metrics.Default.Counter("etelgo_config_reloads_total", "result", "failure")
+)
+
+// Reload replaces the processors chain by the one of cfg, a configuration validated like the running one.
+// The messages in flight finish on the previous chain, the next ones go through the new chain.
+// Only the processors can change: a configuration changing any other section is rejected, the change requiring a restart,
+// and so are processors reading the values while they are forwarded raw (see forwardsRawValues).
+// The stateful processors of the previous chain (e.g. aggregate) emit what they hold once its last message is processed.
+func (o *Orchestrator) Reload(cfg *config.Config) error {
+	if o.report != nil {
+		return errors.New("the processors can't be reloaded in dry run")
+	}
+	if sections := changedSections(o.config, cfg); len(sections) > 0 {
+		return fmt.Errorf("only the processors can be reloaded, restart to apply the changes of %s", strings.Join(sections, ", "))
+	}
+	if o.rawValues && !passthroughOnly(cfg.Processors) {
+		return errors.New("the values are forwarded without being decoded, restart to apply processors reading them")
+	}
+
+	pipeline, err := NewPipeline(cfg.Processors, o.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create the processors pipeline: %w", err)
+	}
+
+	// Swapped under the lock, so that Stats counts the previous pipeline once
+	o.retiredMu.Lock()
+	o.retired = append(o.retired, o.pipeline.Swap(pipeline))
+	o.retiredMu.Unlock()
+
+	o.logger.Info("Processors reloaded", "processors", len(pipeline.processors))
+	return nil
+}
+
+// watchReloads applies the configurations received until ctx is done, a rejected one leaving the running chain in place
+func (o *Orchestrator) watchReloads(ctx context.Context, reloads <-chan *config.Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfg := <-reloads:
+			if err := o.Reload(cfg); err != nil {
+				configReloadFailures.Inc()
+				o.logger.Error("configuration reload rejected", "error", err)
+				continue
+			}
+			configReloads.Inc()
+		}
+	}
+}
+
+// changedSections returns the sections of the configuration other than the processors differing in next
+func changedSections(current *config.Config, next *config.Config) []string {
+	var sections []string
+	if !reflect.DeepEqual(current.Input, next.Input) {
+		sections = append(sections, "input")
+	}
+	if !reflect.DeepEqual(current.Output, next.Output) {
+		sections = append(sections, "output")
+	}
+	if !reflect.DeepEqual(current.Monitoring, next.Monitoring) {
+		sections = append(sections, "monitoring")
+	}
+	if !reflect.DeepEqual(current.Errors, next.Errors) {
+		sections = append(sections, "errors")
+	}
+	return sections
+}
+
+// acquirePipeline returns the current pipeline, counting the message about to be processed in its inFlight.
+// The pipeline is loaded again after counting, so that a pipeline seen without message in flight once replaced
+// can't get a new one.
+func (o *Orchestrator) acquirePipeline() *Pipeline {
+	for {
+		pipeline := o.pipeline.Load()
+		pipeline.inFlight.Add(1)
+		if o.pipeline.Load() == pipeline {
+			return pipeline
+		}
+		pipeline.inFlight.Add(-1)
+	}
+}
+
+// flushRetired collects everything held by the replaced pipelines without message in flight, which are then forgotten
+func (o *Orchestrator) flushRetired(ctx context.Context) []*consumer.Message {
+	o.retiredMu.Lock()
+	defer o.retiredMu.Unlock()
+
+	var out []*consumer.Message
+	kept := o.retired[:0]
+	for _, pipeline := range o.retired {
+		if pipeline.inFlight.Load() > 0 {
+			kept = append(kept, pipeline)
+			continue
+		}
+		out = append(out, pipeline.Flush(ctx, true)...)
+		o.retiredTotals.addTotals(pipeline)
+	}
+	o.retired = kept
+	return out
+}
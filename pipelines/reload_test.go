@@ -0,0 +1,157 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOrchestratorReload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	current := &config.Config{
+		Input:      config.InputConfig{Topics: []string{"orders"}, ConsumerGroup: "etl"},
+		Processors: []config.ProcessorConfig{{Type: "passthrough"}},
+	}
+	pipeline, err := NewPipeline(current.Processors, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o := &Orchestrator{config: current, logger: logger}
+	o.pipeline.Store(pipeline)
+
+	// A message in flight keeps the previous pipeline until it is processed
+	inFlight := o.acquirePipeline()
+
+	next := &config.Config{
+		Input: config.InputConfig{Topics: []string{"orders"}, ConsumerGroup: "etl"},
+		Processors: []config.ProcessorConfig{
+			{Type: "copy", Config: map[string]interface{}{"source_field": "id", "target_field": "order_id"}},
+		},
+	}
+	if err := o.Reload(next); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	out, err := o.ProcessMessages(&consumer.Message{ValueFields: map[string]interface{}{"id": "1"}}, context.Background())
+	if err != nil || len(out) != 1 || out[0].ValueFields["order_id"] != "1" {
+		t.Errorf("ProcessMessages() = %v, %v, want the reloaded processors applied", out, err)
+	}
+
+	o.flushRetired(context.Background())
+	if len(o.retired) != 1 {
+		t.Errorf("expected the previous pipeline to be kept while a message is in flight, got %d retired", len(o.retired))
+	}
+	inFlight.inFlight.Add(-1)
+	o.flushRetired(context.Background())
+	if len(o.retired) != 0 {
+		t.Errorf("expected the previous pipeline to be forgotten once flushed, got %d retired", len(o.retired))
+	}
+}
+
+func TestOrchestratorStats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	current := &config.Config{
+		Input: config.InputConfig{Topics: []string{"orders"}, ConsumerGroup: "etl"},
+		Processors: []config.ProcessorConfig{
+			{Type: "field_exists", Config: map[string]interface{}{"field_name": "id"}},
+		},
+	}
+	pipeline, err := NewPipeline(current.Processors, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o := &Orchestrator{config: current, logger: logger}
+	o.pipeline.Store(pipeline)
+
+	process := func(fields map[string]interface{}) {
+		if _, err := o.ProcessMessages(&consumer.Message{ValueFields: fields}, context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	process(map[string]interface{}{"id": "1"})
+	process(map[string]interface{}{"name": "no id"})
+
+	next := &config.Config{
+		Input: config.InputConfig{Topics: []string{"orders"}, ConsumerGroup: "etl"},
+		Processors: []config.ProcessorConfig{
+			{Type: "copy", Config: map[string]interface{}{"source_field": "id", "target_field": "order_id"}},
+		},
+	}
+	if err := o.Reload(next); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	process(map[string]interface{}{"id": "2"})
+
+	check := func(when string) {
+		stats := o.Stats()
+		if stats.Processed != 3 || stats.Dropped != 1 || stats.Errors != 0 {
+			t.Errorf("%s: Stats() = %+v, want 3 processed and 1 dropped across the reload", when, stats)
+		}
+		if len(stats.Processors) != 1 || stats.Processors[0].Type != "copy" || stats.Processors[0].Processed != 1 {
+			t.Errorf("%s: Processors = %+v, want the counts of the current chain", when, stats.Processors)
+		}
+	}
+	check("before the previous pipeline is flushed")
+	o.flushRetired(context.Background())
+	if len(o.retired) != 0 {
+		t.Fatalf("expected the previous pipeline to be forgotten once flushed, got %d retired", len(o.retired))
+	}
+	check("once the previous pipeline is forgotten")
+}
+
+func TestOrchestratorReload_Rejected(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	current := &config.Config{
+		Input:      config.InputConfig{Topics: []string{"orders"}, ConsumerGroup: "etl"},
+		Output:     config.OutputConfig{Topic: "orders-out"},
+		Processors: []config.ProcessorConfig{{Type: "passthrough"}},
+	}
+	pipeline, err := NewPipeline(current.Processors, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		next    *config.Config
+		wantErr string
+	}{
+		{
+			name: "Topic changed",
+			next: &config.Config{
+				Input:      config.InputConfig{Topics: []string{"payments"}, ConsumerGroup: "etl"},
+				Output:     config.OutputConfig{Topic: "orders-out"},
+				Processors: current.Processors,
+			},
+			wantErr: "restart to apply the changes of input",
+		},
+		{
+			name: "Invalid processor",
+			next: &config.Config{
+				Input:      current.Input,
+				Output:     current.Output,
+				Processors: []config.ProcessorConfig{{Type: "unknown"}},
+			},
+			wantErr: "failed to create the processors pipeline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Orchestrator{config: current, logger: logger}
+			o.pipeline.Store(pipeline)
+
+			err := o.Reload(tt.next)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Reload() error = %v, want %q", err, tt.wantErr)
+			}
+			if o.pipeline.Load() != pipeline || len(o.retired) != 0 {
+				t.Error("expected the running pipeline to be kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"sync"
+)
+
+var reorderBuffered = metrics.Default.Gauge("etelgo_reorder_buffered_records")
+
+// reorderBuffer releases the messages of each partition in offset order, whatever the order the workers complete them in,
+// for the ordered output mode. A message completed before the previous ones of its partition waits in the buffer,
+// its release (send and commit) running once they are all released: a slow message holds back its whole partition.
+// The buffer holds at most size messages, dispatched and not yet released, the dispatch waiting for a free slot
+// when it is full. A message stuck at the head of a partition can then stall the input (head-of-line blocking).
+type reorderBuffer struct {
+	slots      chan struct{}
+	mu         sync.Mutex
+	partitions map[topicPartition]*reorderQueue
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// reorderQueue holds the messages of a partition, in dispatch order
+type reorderQueue struct {
+	mu        sync.Mutex
+	pending   []*reorderEntry
+	releasing bool // A goroutine is running the releases, the others only record their completion
+}
+
+type reorderEntry struct {
+	msg     *consumer.Message
+	release func() // Set once the message is completed
+}
+
+func newReorderBuffer(size int) *reorderBuffer {
+	return &reorderBuffer{
+		slots:      make(chan struct{}, size),
+		partitions: make(map[topicPartition]*reorderQueue),
+	}
+}
+
+func (b *reorderBuffer) queue(msg *consumer.Message) *reorderQueue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := topicPartition{msg.Topic, msg.Partition}
+	q, ok := b.partitions[key]
+	if !ok {
+		q = &reorderQueue{}
+		b.partitions[key] = q
+	}
+	return q
+}
+
+// add registers the message before its dispatch, the messages of a partition must be added in offset order.
+// It waits for a free slot, and returns false when ctx is done first.
+func (b *reorderBuffer) add(ctx context.Context, msg *consumer.Message) bool {
+	select {
+	case b.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	reorderBuffered.Add(1)
+
+	q := b.queue(msg)
+	q.mu.Lock()
+	q.pending = append(q.pending, &reorderEntry{msg: msg})
+	q.mu.Unlock()
+	return true
+}
+
+// remove unregisters the last message added to its partition, whose dispatch was cancelled
+func (b *reorderBuffer) remove(msg *consumer.Message) {
+	q := b.queue(msg)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n := len(q.pending); n > 0 && q.pending[n-1].msg == msg {
+		q.pending = q.pending[:n-1]
+		<-b.slots
+		reorderBuffered.Add(-1)
+	}
+}
+
+// complete records the release of a processed message, then runs the releases now in order.
+// The releases of a partition run one at a time, by the goroutine completing the head of the partition.
+func (b *reorderBuffer) complete(msg *consumer.Message, release func()) {
+	q := b.queue(msg)
+	q.mu.Lock()
+	for _, entry := range q.pending {
+		if entry.msg == msg {
+			entry.release = release
+			break
+		}
+	}
+	if q.releasing {
+		q.mu.Unlock()
+		return
+	}
+
+	q.releasing = true
+	for len(q.pending) > 0 && q.pending[0].release != nil {
+		head := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		head.release()
+		<-b.slots
+		reorderBuffered.Add(-1)
+
+		q.mu.Lock()
+	}
+	q.releasing = false
+	q.mu.Unlock()
+}
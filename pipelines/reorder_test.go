@@ -0,0 +1,104 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/consumer"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReorderBuffer_OutOfOrderCompletion(t *testing.T) {
+	buffer := newReorderBuffer(10)
+	msgs := make([]*consumer.Message, 5)
+	for i := range msgs {
+		msgs[i] = &consumer.Message{Topic: "orders", Partition: 0, Offset: int64(i)}
+		if !buffer.add(context.Background(), msgs[i]) {
+			t.Fatal("add() failed with free slots")
+		}
+	}
+
+	var released []int64
+	for _, i := range []int{3, 1, 0, 4, 2} {
+		msg := msgs[i]
+		buffer.complete(msg, func() { released = append(released, msg.Offset) })
+	}
+
+	for i, offset := range released {
+		if offset != int64(i) {
+			t.Fatalf("released out of order: %v", released)
+		}
+	}
+	if len(released) != len(msgs) {
+		t.Fatalf("expected %d releases, got %v", len(msgs), released)
+	}
+	if len(buffer.slots) != 0 {
+		t.Errorf("expected every slot to be freed, %d still used", len(buffer.slots))
+	}
+}
+
+func TestReorderBuffer_ConcurrentWorkers(t *testing.T) {
+	const perPartition = 200
+	buffer := newReorderBuffer(16)
+	queue := make(chan *consumer.Message)
+
+	var mu sync.Mutex
+	released := make(map[int32][]int64)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range queue {
+				time.Sleep(time.Duration(rand.IntN(200)) * time.Microsecond) // Workers complete out of order
+				buffer.complete(msg, func() {
+					mu.Lock()
+					released[msg.Partition] = append(released[msg.Partition], msg.Offset)
+					mu.Unlock()
+				})
+			}
+		}()
+	}
+
+	for offset := int64(0); offset < perPartition; offset++ {
+		for partition := int32(0); partition < 3; partition++ {
+			msg := &consumer.Message{Topic: "orders", Partition: partition, Offset: offset}
+			buffer.add(context.Background(), msg)
+			queue <- msg
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for partition, offsets := range released {
+		if len(offsets) != perPartition {
+			t.Errorf("partition %d: expected %d releases, got %d", partition, perPartition, len(offsets))
+		}
+		for i, offset := range offsets {
+			if offset != int64(i) {
+				t.Fatalf("partition %d: released out of order at %d: got offset %d", partition, i, offset)
+			}
+		}
+	}
+}
+
+func TestReorderBuffer_Bounded(t *testing.T) {
+	buffer := newReorderBuffer(2)
+	head := &consumer.Message{Topic: "orders", Offset: 0}
+	buffer.add(context.Background(), head)
+	buffer.add(context.Background(), &consumer.Message{Topic: "orders", Offset: 1})
+
+	// The head is not completed, the full buffer blocks the next dispatch
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if buffer.add(ctx, &consumer.Message{Topic: "orders", Offset: 2}) {
+		t.Fatal("add() succeeded on a full buffer")
+	}
+
+	buffer.complete(head, func() {})
+	if !buffer.add(context.Background(), &consumer.Message{Topic: "orders", Offset: 2}) {
+		t.Error("add() failed after the head release")
+	}
+}
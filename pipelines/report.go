@@ -0,0 +1,202 @@
+package pipelines
+
+import (
+	"bytes"
+	"context"
+	"etelgo/consumer"
+	"etelgo/outputs"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Report summarizes the effects of each processor on the messages of a dry run: how many it passed through unchanged,
+// modified or dropped, and which fields it added, removed or changed, as dot-paths (arrays being compared as a whole).
+// It is safe for concurrent use by the workers.
+type Report struct {
+	mu           sync.Mutex
+	consumed     int64 // Messages consumed, including those that failed to decode
+	decodeErrors int64
+	processors   []*processorReport // In the processors order
+}
+
+type processorReport struct {
+	index    int // Config index of the processor
+	name     string
+	in       int64
+	passed   int64
+	modified int64
+	dropped  int64
+	errors   int64
+	added    map[string]int64 // Messages per field path
+	removed  map[string]int64
+	changed  map[string]int64
+}
+
+// EnableReport starts collecting the effects of the processors on the messages, at the cost of copying
+// the fields of each message before every processor. It is meant for dry runs.
+func (p *Pipeline) EnableReport() *Report {
+	report := &Report{}
+	for i, processor := range p.processors {
+		report.processors = append(report.processors, &processorReport{
+			index:   p.indexes[i],
+			name:    processor.Name(),
+			added:   make(map[string]int64),
+			removed: make(map[string]int64),
+			changed: make(map[string]int64),
+		})
+	}
+	p.report = report
+	return report
+}
+
+// snapshot holds the key and the flattened fields of a message before a processor, see takeSnapshot
+type snapshot struct {
+	key    []byte
+	fields map[string]interface{}
+}
+
+// takeSnapshot copies the key and fields of the message, processors modifying the message in place
+func takeSnapshot(msg *consumer.Message) snapshot {
+	s := snapshot{key: bytes.Clone(msg.Key), fields: make(map[string]interface{})}
+	flattenFields("", msg.ValueFields, s.fields)
+	return s
+}
+
+// flattenFields sets the leaves of the fields into flat, by dot-path. Arrays and empty objects are leaves, copied.
+func flattenFields(prefix string, fields map[string]interface{}, flat map[string]interface{}) {
+	for name, value := range fields {
+		path := prefix + name
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenFields(path+".", nested, flat)
+			continue
+		}
+		flat[path] = copyValue(value)
+	}
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			copied[name] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// consume counts a message consumed, before processing
+func (r *Report) consume(msg *consumer.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.consumed++
+	if msg.Err != nil {
+		r.decodeErrors++
+	}
+}
+
+// record counts the outcome of the processor at position i on a message, before being the message snapshot taken before it ran.
+// A message is modified when any of the resulting messages differs from it, each one counting its fields differences.
+func (r *Report) record(i int, before snapshot, out []*consumer.Message, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pr := r.processors[i]
+	pr.in++
+	switch {
+	case err != nil:
+		pr.errors++
+		return
+	case len(out) == 0:
+		pr.dropped++
+		return
+	}
+
+	modified := false
+	for _, msg := range out {
+		after := takeSnapshot(msg)
+		if !bytes.Equal(before.key, after.key) {
+			modified = true
+		}
+		for path, value := range after.fields {
+			previous, ok := before.fields[path]
+			switch {
+			case !ok:
+				pr.added[path]++
+			case !reflect.DeepEqual(previous, value):
+				pr.changed[path]++
+			default:
+				continue
+			}
+			modified = true
+		}
+		for path := range before.fields {
+			if _, ok := after.fields[path]; !ok {
+				pr.removed[path]++
+				modified = true
+			}
+		}
+	}
+	if modified {
+		pr.modified++
+	} else {
+		pr.passed++
+	}
+}
+
+// Write prints the report, one block per processor in the processors order
+func (r *Report) Write(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(w, "Dry run report: %d messages consumed, %d failed to decode\n", r.consumed, r.decodeErrors)
+	for _, pr := range r.processors {
+		fmt.Fprintf(w, "  processor %d (%s): %d in, %d passed, %d modified, %d dropped, %d errors\n",
+			pr.index, pr.name, pr.in, pr.passed, pr.modified, pr.dropped, pr.errors)
+		writeFields(w, "added", pr.added)
+		writeFields(w, "removed", pr.removed)
+		writeFields(w, "changed", pr.changed)
+	}
+}
+
+// writeFields prints the field paths by name, with the number of messages for each
+func writeFields(w io.Writer, label string, fields map[string]int64) {
+	if len(fields) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fmt.Fprintf(w, "    %s:", label)
+	for _, path := range paths {
+		fmt.Fprintf(w, " %s (%d)", path, fields[path])
+	}
+	fmt.Fprintln(w)
+}
+
+// discardProducer stands for the output in dry run, every message being dropped
+type discardProducer struct{}
+
+func (discardProducer) Send(context.Context, *consumer.Message) error { return nil }
+func (discardProducer) DeadLetter(context.Context, *consumer.Message, string, error) error {
+	return nil
+}
+func (discardProducer) Flush(context.Context) error       { return nil }
+func (discardProducer) Ready() error                      { return nil }
+func (discardProducer) Pending() int                      { return 0 }
+func (discardProducer) OnDelivery(func(outputs.Delivery)) {}
+func (discardProducer) Close() error                      { return nil }
@@ -0,0 +1,80 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_Report(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cfgs := []config.ProcessorConfig{
+		{Type: "drop", Config: map[string]interface{}{"field_name": "status", "filter_criteria": "test"}},
+		{Type: "passthrough"},
+		{Type: "copy", Config: map[string]interface{}{"source_field": "user.name", "target_field": "name"}},
+		{Type: "project", Config: map[string]interface{}{"exclude": []interface{}{"status"}}},
+	}
+	pipeline, err := NewPipeline(cfgs, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := pipeline.EnableReport()
+
+	for _, status := range []string{"ok", "test", "ok"} {
+		msg := &consumer.Message{Topic: "users", ValueFields: map[string]interface{}{
+			"status": status,
+			"user":   map[string]interface{}{"name": "alice"},
+		}}
+		report.consume(msg)
+		if _, err := pipeline.Process(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var out strings.Builder
+	report.Write(&out)
+	for _, want := range []string{
+		"Dry run report: 3 messages consumed, 0 failed to decode",
+		"processor 0 (drop): 3 in, 2 passed, 0 modified, 1 dropped, 0 errors",
+		"processor 1 (passthrough): 2 in, 2 passed, 0 modified, 0 dropped, 0 errors",
+		"processor 2 (copy): 2 in, 0 passed, 2 modified, 0 dropped, 0 errors\n    added: name (2)\n",
+		"processor 3 (project): 2 in, 0 passed, 2 modified, 0 dropped, 0 errors\n    removed: status (2)\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestReport_ChangedFields(t *testing.T) {
+	report := &Report{processors: []*processorReport{{
+		added:   make(map[string]int64),
+		removed: make(map[string]int64),
+		changed: make(map[string]int64),
+	}}}
+
+	msg := &consumer.Message{ValueFields: map[string]interface{}{
+		"amount": 10.0,
+		"tags":   []interface{}{"a"},
+		"user":   map[string]interface{}{"id": "1", "name": "alice"},
+	}}
+	before := takeSnapshot(msg)
+
+	// Modified in place, as the processors do
+	msg.ValueFields["tags"].([]interface{})[0] = "b"
+	msg.ValueFields["user"].(map[string]interface{})["name"] = "bob"
+	report.record(0, before, []*consumer.Message{msg}, nil)
+
+	pr := report.processors[0]
+	if pr.modified != 1 || pr.changed["tags"] != 1 || pr.changed["user.name"] != 1 || len(pr.changed) != 2 {
+		t.Errorf("unexpected changes: modified %d, changed %v", pr.modified, pr.changed)
+	}
+	if len(pr.added) != 0 || len(pr.removed) != 0 {
+		t.Errorf("unexpected added %v or removed %v", pr.added, pr.removed)
+	}
+}
@@ -0,0 +1,65 @@
+package pipelines
+
+import (
+	"errors"
+	"etelgo/config"
+	"etelgo/consumer"
+	"log/slog"
+)
+
+// NewReprocessOrchestrator creates an orchestrator consuming the dead letter topic (errors.dlq_topic, on the output brokers)
+// with the consumer group group, and processing the original records of the dead letter records through the processors
+// to the output, see consumer.UnwrapDeadLetters. The records failing again are sent back to the dead letter topic,
+// whatever the errors policy, and the offsets of the dead letter records are committed once handled.
+func NewReprocessOrchestrator(cfg *config.Config, group string, logger *slog.Logger) (*Orchestrator, error) {
+	reprocessCfg, err := reprocessConfig(cfg, group)
+	if err != nil {
+		return nil, err
+	}
+
+	cons, err := consumer.NewKafkaConsumer(&reprocessCfg.Input, logger)
+	if err != nil {
+		logger.Error("error creating a new Kafka dead letter Consumer")
+		return nil, err
+	}
+	if err := cons.UnwrapDeadLetters(cfg.Errors.Dlq_envelope); err != nil {
+		cons.Close()
+		return nil, err
+	}
+
+	return newOrchestrator(reprocessCfg, cons, logger)
+}
+
+// reprocessConfig returns the configuration of cfg consuming the dead letter topic instead of the input topics.
+// The dead letter records hold the decompressed values, and are all consumed on the first run of the group.
+func reprocessConfig(cfg *config.Config, group string) (*config.Config, error) {
+	if cfg.Errors.Dlq_topic == "" {
+		return nil, errors.New("no dead letter topic configured (errors.dlq_topic)")
+	}
+	if group == "" {
+		return nil, errors.New("no consumer group to reprocess the dead letter topic with")
+	}
+	// The dead letter records being consumed from the dead letter topic, they would all go through the default chain
+	for _, processor := range cfg.Processors {
+		if processor.Topic != "" {
+			return nil, errors.New("the processors selecting an input topic can't be applied to the dead letter records, skip the processors")
+		}
+	}
+
+	reprocessCfg := *cfg
+	input := cfg.Input
+	input.Brokers = cfg.Output.Brokers
+	input.Topic = cfg.Errors.Dlq_topic
+	input.Topics = nil
+	input.Topic_regex = nil
+	input.Partitions = nil
+	input.ConsumerGroup = group
+	input.Checkpoint_file = nil
+	earliest, none := "earliest", "none"
+	input.Offset_reset = &earliest
+	input.Payload_compression = &none
+	reprocessCfg.Input = input
+
+	reprocessCfg.Errors.Policy = config.ErrorPolicyDLQ
+	return &reprocessCfg, nil
+}
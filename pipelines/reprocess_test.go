@@ -0,0 +1,53 @@
+package pipelines
+
+import (
+	"etelgo/config"
+	"reflect"
+	"testing"
+)
+
+func TestReprocessConfig(t *testing.T) {
+	gzip, checkpoint := "gzip", "offsets.json"
+	cfg := &config.Config{
+		Input: config.InputConfig{
+			Brokers:             []string{"input:9092"},
+			Topic:               "orders",
+			Topics:              []string{"payments"},
+			ConsumerGroup:       "etelgo",
+			Partitions:          []int{0, 1},
+			Payload_compression: &gzip,
+			Checkpoint_file:     &checkpoint,
+		},
+		Output: config.OutputConfig{Brokers: []string{"output:9092"}, Topic: "out"},
+		Errors: config.ErrorsConfig{Policy: config.ErrorPolicySkip, Dlq_topic: "orders-dlq"},
+	}
+
+	got, err := reprocessConfig(cfg, "etelgo-reprocess")
+	if err != nil {
+		t.Fatalf("reprocessConfig() unexpected error = %v", err)
+	}
+	if topics := got.Input.AllTopics(); !reflect.DeepEqual(topics, []string{"orders-dlq"}) {
+		t.Errorf("reprocessConfig() topics = %v, want [orders-dlq]", topics)
+	}
+	if !reflect.DeepEqual(got.Input.Brokers, cfg.Output.Brokers) || got.Input.ConsumerGroup != "etelgo-reprocess" {
+		t.Errorf("reprocessConfig() brokers = %v, group = %s, want the output brokers and etelgo-reprocess", got.Input.Brokers, got.Input.ConsumerGroup)
+	}
+	if got.Input.Partitions != nil || got.Input.Checkpoint_file != nil || *got.Input.Payload_compression != "none" || *got.Input.Offset_reset != "earliest" {
+		t.Errorf("reprocessConfig() input = %+v, want every partition from the earliest offset, without checkpoint nor compression", got.Input)
+	}
+	if got.Errors.Policy != config.ErrorPolicyDLQ {
+		t.Errorf("reprocessConfig() policy = %s, want dlq", got.Errors.Policy)
+	}
+	if cfg.Input.Topic != "orders" || cfg.Errors.Policy != config.ErrorPolicySkip {
+		t.Errorf("reprocessConfig() modified the source configuration")
+	}
+
+	if _, err := reprocessConfig(&config.Config{}, "etelgo-reprocess"); err == nil {
+		t.Errorf("reprocessConfig() without dlq_topic error = nil, want an error")
+	}
+
+	cfg.Processors = []config.ProcessorConfig{{Type: "passthrough", Topic: "orders"}}
+	if _, err := reprocessConfig(cfg, "etelgo-reprocess"); err == nil {
+		t.Errorf("reprocessConfig() with a per-topic processor error = nil, want an error")
+	}
+}
@@ -0,0 +1,48 @@
+package pipelines
+
+import (
+	"context"
+	"etelgo/config"
+	"etelgo/consumer"
+	"log/slog"
+)
+
+// Run runs the pipeline of the configuration until ctx is done, for applications embedding it as a library.
+// The configuration must be validated: loaded through config.LoadConfig, or checked with Config.Validate.
+//
+// The shutdown is driven by ctx only: no signal is handled and the process never exits. Once ctx is cancelled,
+// the consumption stops and the messages in flight are drained to the output and committed, bounded by
+// RunOptions.ShutdownTimeout (see RunWithOptions, Run waiting indefinitely). Run then returns nil.
+// It returns an error when the pipeline can't start, or stops on its own (e.g. the fail errors policy).
+func Run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
+	return RunWithOptions(ctx, cfg, logger, RunOptions{})
+}
+
+// RunWithOptions is Run with the options of the run command
+func RunWithOptions(ctx context.Context, cfg *config.Config, logger *slog.Logger, opts RunOptions) error {
+	orchestrator, err := NewOrchestrator(cfg, logger)
+	if err != nil {
+		return err
+	}
+	return orchestrator.Run(ctx, opts)
+}
+
+// Replay replays the records of the input topics produced within the window, as Run does, returning once
+// the window is consumed or ctx is done. The consumer group offsets are left untouched.
+func Replay(ctx context.Context, cfg *config.Config, window consumer.ReplayWindow, logger *slog.Logger, opts RunOptions) error {
+	orchestrator, err := NewReplayOrchestrator(cfg, window, logger)
+	if err != nil {
+		return err
+	}
+	return orchestrator.Run(ctx, opts)
+}
+
+// Reprocess consumes the dead letter topic and processes the original records again, as Run does, see
+// NewReprocessOrchestrator. It returns once ctx is done or a bound of opts (MaxMessages, IdleTimeout) is reached.
+func Reprocess(ctx context.Context, cfg *config.Config, group string, logger *slog.Logger, opts RunOptions) error {
+	orchestrator, err := NewReprocessOrchestrator(cfg, group, logger)
+	if err != nil {
+		return err
+	}
+	return orchestrator.Run(ctx, opts)
+}
@@ -0,0 +1,299 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"etelgo/consumer"
+	"etelgo/metrics"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Aggregation functions of the aggregate processor
+const (
+	AggregateCount = "count"
+	AggregateSum   = "sum"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateAvg   = "avg"
+)
+
+var ValidAggregateFunctions = map[string]bool{
+	AggregateCount: true,
+	AggregateSum:   true,
+	AggregateMin:   true,
+	AggregateMax:   true,
+	AggregateAvg:   true,
+}
+
+// Fields set on the aggregated messages, next to the group_by field and the functions results
+const (
+	AggregateWindowStartField = "window_start"
+	AggregateWindowEndField   = "window_end"
+
+	defaultAggregateMaxGroups = 10000
+)
+
+var (
+	aggregateLate       = metrics.Default.Counter("etelgo_aggregate_late_total")
+	aggregateOpenGroups = metrics.Default.Gauge("etelgo_aggregate_open_groups")
+)
+
+// Flusher is a stateful processor emitting messages on its own rather than in response to a message.
+// The pipeline calls Flush periodically, and once with final set on shutdown so that nothing stays pending.
+// The emitted messages run through the processors following the flusher.
+type Flusher interface {
+	Processor
+	Flush(final bool) []*consumer.Message
+}
+
+// AggregateProcessor groups the messages by the group_by field over tumbling windows of their Kafka timestamp,
+// and emits one message per window and group once the window is closed, e.g.
+// {"user": "alice", "window_start": "...", "window_end": "...", "count": 3, "sum": 42.5}.
+// The source messages are consumed by the processor: they are committed once aggregated, so the open windows
+// are lost on a crash, while a graceful shutdown emits them as they are.
+//
+// A window closes once the latest timestamp seen, minus allowed_lateness, passes its end,
+// or once the input is idle for a window length. A message whose window is already closed is late:
+// it is dropped and counted in etelgo_aggregate_late_total.
+// The memory holds one accumulator (a few numbers) per open window and group, bounded by max_groups:
+// a message opening a group beyond it is an error, handled by the error policy.
+type AggregateProcessor struct {
+	logger    *slog.Logger
+	groupBy   string
+	aggField  string // Empty when only counting
+	functions []string
+	window    time.Duration
+	lateness  time.Duration
+	maxGroups int
+	now       func() time.Time
+
+	mu          sync.Mutex
+	groups      map[aggregateKey]*aggregate
+	watermark   time.Time // Latest message timestamp seen
+	closedUntil time.Time // End of the last closed window, the messages of earlier windows are late
+	lastSeen    time.Time // Wall clock time of the last message, to close the windows of an idle input
+}
+
+type aggregateKey struct {
+	start int64 // Window start, in Unix nanoseconds
+	group string
+}
+
+// aggregate accumulates the messages of a window and group
+type aggregate struct {
+	group    interface{}
+	topic    string
+	count    int64 // Messages of the window
+	values   int64 // Messages with a numeric agg_field
+	sum      float64
+	min, max float64
+}
+
+func NewAggregateProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &AggregateProcessor{
+		logger:    cfg.logger,
+		maxGroups: defaultAggregateMaxGroups,
+		now:       time.Now,
+		groups:    make(map[aggregateKey]*aggregate),
+	}
+
+	processor.groupBy, _ = cfg.Config["group_by"].(string)
+	if processor.groupBy == "" {
+		return nil, errors.New("missing or invalid 'group_by' parameter")
+	}
+
+	functions, _ := cfg.Config["functions"].([]interface{})
+	if len(functions) == 0 {
+		return nil, errors.New("missing or invalid 'functions' parameter")
+	}
+	numeric := false
+	for _, f := range functions {
+		name, _ := f.(string)
+		if !ValidAggregateFunctions[name] {
+			return nil, fmt.Errorf("invalid aggregate function: %v", f)
+		}
+		numeric = numeric || name != AggregateCount
+		processor.functions = append(processor.functions, name)
+	}
+
+	if val, ok := cfg.Config["agg_field"]; ok {
+		field, ok := val.(string)
+		if !ok || field == "" {
+			return nil, errors.New("invalid 'agg_field' parameter")
+		}
+		processor.aggField = field
+	}
+	if numeric && processor.aggField == "" {
+		return nil, errors.New("'agg_field' is required by the sum, min, max and avg functions")
+	}
+
+	window, _ := cfg.Config["window"].(string)
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid window: %q, must be a positive duration", window)
+	}
+	processor.window = duration
+
+	if val, ok := cfg.Config["allowed_lateness"]; ok {
+		lateness, _ := val.(string)
+		duration, err := time.ParseDuration(lateness)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid allowed_lateness: %q, must be a duration", lateness)
+		}
+		processor.lateness = duration
+	}
+
+	if val, ok := cfg.Config["max_groups"]; ok {
+		size, ok := toFloat(val)
+		if !ok || size < 1 || size != float64(int64(size)) {
+			return nil, errors.New("'max_groups' must be a positive integer")
+		}
+		processor.maxGroups = int(size)
+	}
+
+	return processor, nil
+}
+
+func (p *AggregateProcessor) Name() string {
+	return ProcessorTypeAggregate
+}
+
+// Process adds the message to the accumulator of its window and group, the message itself is consumed
+func (p *AggregateProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	group, _ := getPath(msg.ValueFields, p.groupBy)
+
+	var number float64
+	hasNumber := false
+	if p.aggField != "" {
+		if val, ok := getPath(msg.ValueFields, p.aggField); ok && val != nil {
+			number, hasNumber = toFloat(val)
+			if !hasNumber {
+				return nil, fmt.Errorf("aggregate field %q must be a number, got %T", p.aggField, val)
+			}
+		}
+	}
+
+	start := msg.Timestamp.Truncate(p.window)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastSeen = p.now()
+	if !start.Add(p.window).After(p.closedUntil) {
+		aggregateLate.Inc()
+		p.logger.Debug("late message dropped, its window is closed", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "timestamp", msg.Timestamp)
+		return nil, nil
+	}
+
+	key := aggregateKey{start: start.UnixNano(), group: fmt.Sprint(group)}
+	agg, ok := p.groups[key]
+	if !ok {
+		if len(p.groups) >= p.maxGroups {
+			return nil, fmt.Errorf("aggregate max_groups (%d) reached, group %q not aggregated", p.maxGroups, key.group)
+		}
+		agg = &aggregate{group: group, topic: msg.Topic}
+		p.groups[key] = agg
+		aggregateOpenGroups.Add(1)
+	}
+
+	agg.count++
+	if hasNumber {
+		if agg.values == 0 || number < agg.min {
+			agg.min = number
+		}
+		if agg.values == 0 || number > agg.max {
+			agg.max = number
+		}
+		agg.sum += number
+		agg.values++
+	}
+
+	if msg.Timestamp.After(p.watermark) {
+		p.watermark = msg.Timestamp
+	}
+	return nil, nil
+}
+
+// Flush emits the closed windows, every open window when final is set, ordered by window then group
+func (p *AggregateProcessor) Flush(final bool) []*consumer.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idle := !p.lastSeen.IsZero() && p.now().Sub(p.lastSeen) >= p.window
+	closing := p.watermark.Add(-p.lateness)
+
+	var keys []aggregateKey
+	for key := range p.groups {
+		end := time.Unix(0, key.start).Add(p.window)
+		if final || idle || !end.After(closing) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].start != keys[j].start {
+			return keys[i].start < keys[j].start
+		}
+		return keys[i].group < keys[j].group
+	})
+
+	out := make([]*consumer.Message, 0, len(keys))
+	for _, key := range keys {
+		start := time.Unix(0, key.start).UTC()
+		end := start.Add(p.window)
+		if end.After(p.closedUntil) {
+			p.closedUntil = end
+		}
+		out = append(out, p.emit(start, end, p.groups[key]))
+		delete(p.groups, key)
+	}
+	aggregateOpenGroups.Add(-int64(len(keys)))
+	return out
+}
+
+// emit builds the aggregated message of a window and group
+func (p *AggregateProcessor) emit(start, end time.Time, agg *aggregate) *consumer.Message {
+	fields := map[string]interface{}{
+		AggregateWindowStartField: start.Format(time.RFC3339Nano),
+		AggregateWindowEndField:   end.Format(time.RFC3339Nano),
+	}
+	// The group_by dot-path is valid, writing into a fresh map can't fail
+	_ = setPath(fields, p.groupBy, agg.group)
+
+	for _, function := range p.functions {
+		var result interface{} // Null when no message of the window had a numeric agg_field
+		switch function {
+		case AggregateCount:
+			result = agg.count
+		case AggregateSum:
+			result = agg.sum
+		case AggregateMin:
+			if agg.values > 0 {
+				result = agg.min
+			}
+		case AggregateMax:
+			if agg.values > 0 {
+				result = agg.max
+			}
+		case AggregateAvg:
+			if agg.values > 0 {
+				result = agg.sum / float64(agg.values)
+			}
+		}
+		fields[function] = result
+	}
+
+	msg := &consumer.Message{
+		Topic:       agg.topic,
+		Timestamp:   end,
+		Headers:     map[string]string{},
+		ValueFields: fields,
+	}
+	if agg.group != nil {
+		msg.Key = []byte(fmt.Sprint(agg.group))
+	}
+	return msg
+}
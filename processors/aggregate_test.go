@@ -0,0 +1,159 @@
+package processors
+
+import (
+	"context"
+	"etelgo/consumer"
+	"testing"
+	"time"
+)
+
+func newTestAggregate(t *testing.T, config map[string]interface{}) *AggregateProcessor {
+	t.Helper()
+	processor, err := NewAggregateProcessor(ProcessorConfig{Type: ProcessorTypeAggregate, Config: config, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	return processor.(*AggregateProcessor)
+}
+
+func aggregateMessage(at time.Time, user string, amount interface{}) *consumer.Message {
+	fields := map[string]interface{}{"user": user}
+	if amount != nil {
+		fields["amount"] = amount
+	}
+	return &consumer.Message{Topic: "payments", Timestamp: at, ValueFields: fields}
+}
+
+func TestNewAggregateProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"group_by": "user", "agg_field": "amount", "functions": []interface{}{"count", "sum"}, "window": "1m"}, false},
+		{"Count only without agg_field", map[string]interface{}{"group_by": "user", "functions": []interface{}{"count"}, "window": "1m"}, false},
+		{"Sum without agg_field", map[string]interface{}{"group_by": "user", "functions": []interface{}{"sum"}, "window": "1m"}, true},
+		{"Unknown function", map[string]interface{}{"group_by": "user", "agg_field": "amount", "functions": []interface{}{"median"}, "window": "1m"}, true},
+		{"Invalid window", map[string]interface{}{"group_by": "user", "functions": []interface{}{"count"}, "window": "0s"}, true},
+		{"Invalid max_groups", map[string]interface{}{"group_by": "user", "functions": []interface{}{"count"}, "window": "1m", "max_groups": 0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAggregateProcessor(ProcessorConfig{Type: ProcessorTypeAggregate, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAggregateProcessor_Windows(t *testing.T) {
+	p := newTestAggregate(t, map[string]interface{}{
+		"group_by":  "user",
+		"agg_field": "amount",
+		"functions": []interface{}{"count", "sum", "min", "max", "avg"},
+		"window":    "1m",
+	})
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return base }
+
+	for _, msg := range []*consumer.Message{
+		aggregateMessage(base.Add(5*time.Second), "alice", 10.0),
+		aggregateMessage(base.Add(20*time.Second), "bob", int64(3)),
+		aggregateMessage(base.Add(30*time.Second), "alice", int64(4)),
+		aggregateMessage(base.Add(40*time.Second), "alice", nil), // Counted, without value
+	} {
+		out, err := p.Process(context.Background(), msg)
+		if err != nil || out != nil {
+			t.Fatalf("Process() = %v, %v, want the message consumed", out, err)
+		}
+	}
+
+	if out := p.Flush(false); len(out) != 0 {
+		t.Fatalf("expected the window to stay open, got %d messages", len(out))
+	}
+
+	// A message of the next window closes the first one
+	if _, err := p.Process(context.Background(), aggregateMessage(base.Add(70*time.Second), "bob", 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := p.Flush(false)
+	if len(out) != 2 {
+		t.Fatalf("expected 2 aggregated messages, got %d", len(out))
+	}
+
+	alice := out[0].ValueFields
+	if alice["user"] != "alice" || alice["count"] != int64(3) || alice["sum"] != 14.0 || alice["min"] != 4.0 || alice["max"] != 10.0 || alice["avg"] != 7.0 {
+		t.Errorf("unexpected alice aggregate: %v", alice)
+	}
+	if alice[AggregateWindowStartField] != "2024-05-01T10:00:00Z" || alice[AggregateWindowEndField] != "2024-05-01T10:01:00Z" {
+		t.Errorf("unexpected window: %v - %v", alice[AggregateWindowStartField], alice[AggregateWindowEndField])
+	}
+	if string(out[0].Key) != "alice" || !out[0].Timestamp.Equal(base.Add(time.Minute)) {
+		t.Errorf("unexpected key or timestamp: %s %v", out[0].Key, out[0].Timestamp)
+	}
+	if out[1].ValueFields["user"] != "bob" || out[1].ValueFields["count"] != int64(1) {
+		t.Errorf("unexpected bob aggregate: %v", out[1].ValueFields)
+	}
+
+	// The first window is closed, its late messages are dropped
+	if _, err := p.Process(context.Background(), aggregateMessage(base.Add(50*time.Second), "alice", 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out = p.Flush(true)
+	if len(out) != 1 || out[0].ValueFields["sum"] != 1.0 {
+		t.Fatalf("expected the final flush to emit the open window only, got %v", out)
+	}
+}
+
+func TestAggregateProcessor_IdleAndLateness(t *testing.T) {
+	p := newTestAggregate(t, map[string]interface{}{
+		"group_by":         "user",
+		"functions":        []interface{}{"count"},
+		"window":           "1m",
+		"allowed_lateness": "30s",
+	})
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := base
+	p.now = func() time.Time { return now }
+
+	p.Process(context.Background(), aggregateMessage(base.Add(10*time.Second), "alice", nil))
+	p.Process(context.Background(), aggregateMessage(base.Add(80*time.Second), "alice", nil))
+	if out := p.Flush(false); len(out) != 0 {
+		t.Fatalf("expected the allowed lateness to keep the window open, got %d messages", len(out))
+	}
+
+	// Late by less than allowed_lateness, still aggregated
+	p.Process(context.Background(), aggregateMessage(base.Add(50*time.Second), "alice", nil))
+
+	now = now.Add(time.Minute)
+	out := p.Flush(false)
+	if len(out) != 2 || out[0].ValueFields["count"] != int64(2) {
+		t.Fatalf("expected the idle input to close every window, got %v", out)
+	}
+}
+
+func TestAggregateProcessor_MaxGroups(t *testing.T) {
+	p := newTestAggregate(t, map[string]interface{}{
+		"group_by":   "user",
+		"functions":  []interface{}{"count"},
+		"window":     "1m",
+		"max_groups": 1,
+	})
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, err := p.Process(context.Background(), aggregateMessage(at, "alice", nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.Process(context.Background(), aggregateMessage(at, "alice", nil)); err != nil {
+		t.Fatalf("unexpected error for an existing group: %v", err)
+	}
+	if _, err := p.Process(context.Background(), aggregateMessage(at, "bob", nil)); err == nil {
+		t.Error("expected max_groups error, got nil")
+	}
+}
@@ -0,0 +1,216 @@
+package processors
+
+import (
+	"container/list"
+	"context"
+	"etelgo/metrics"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SharedCache is the lookup cache shared by the lookup processors (see LookupProcessor), so that several stages querying
+// the same external source reuse each other's results instead of repeating the calls.
+// Entries are keyed by (source, key), the source naming the external system (e.g. an URL or a file path).
+// Each processor accesses it through a CacheScope carrying its own TTL and max size:
+// an entry stored by a stage with a long TTL is considered expired by a stage with a shorter one,
+// and each source keeps at most the largest max size requested for it, evicting the least recently used entries.
+var SharedCache = NewCache()
+
+// Processor configuration keys of the cache options
+const (
+	cacheTTLKey     = "cache_ttl"
+	cacheMaxSizeKey = "cache_max_size"
+
+	defaultCacheTTL     = 5 * time.Minute
+	defaultCacheMaxSize = 10000
+)
+
+type Cache struct {
+	mu      sync.Mutex
+	sources map[string]*cacheSource
+}
+
+// cacheSource holds the entries of one source, the most recently used first
+type cacheSource struct {
+	maxSize  int
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]*cacheLoad
+	hits     *metrics.Counter
+	misses   *metrics.Counter
+}
+
+type cacheEntry struct {
+	key      string
+	value    interface{}
+	storedAt time.Time
+}
+
+// cacheLoad is a load in progress, awaited by the concurrent lookups of the same key
+type cacheLoad struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func NewCache() *Cache {
+	return &Cache{sources: make(map[string]*cacheSource)}
+}
+
+// CacheScope is the view of a processor on the cache, restricted to one source
+type CacheScope struct {
+	cache  *Cache
+	source string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// Scope returns the view of a processor on the source, with its TTL and max size.
+func (c *Cache) Scope(source string, ttl time.Duration, maxSize int) *CacheScope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	src, ok := c.sources[source]
+	if !ok {
+		src = &cacheSource{
+			entries:  make(map[string]*list.Element),
+			lru:      list.New(),
+			inflight: make(map[string]*cacheLoad),
+			hits:     metrics.Default.Counter("etelgo_processor_cache_hits_total", "source", source),
+			misses:   metrics.Default.Counter("etelgo_processor_cache_misses_total", "source", source),
+		}
+		c.sources[source] = src
+	}
+	src.maxSize = max(src.maxSize, maxSize)
+
+	return &CacheScope{cache: c, source: source, ttl: ttl, now: time.Now}
+}
+
+// newCacheScope returns the scope of a processor on the shared cache, configured by its cache_ttl and cache_max_size options
+func newCacheScope(cfg ProcessorConfig, source string) (*CacheScope, error) {
+	ttl := defaultCacheTTL
+	if val, ok := cfg.Config[cacheTTLKey]; ok {
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be a duration string", cacheTTLKey)
+		}
+		parsed, err := time.ParseDuration(str)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid '%s': %s", cacheTTLKey, str)
+		}
+		ttl = parsed
+	}
+
+	maxSize := defaultCacheMaxSize
+	if val, ok := cfg.Config[cacheMaxSizeKey]; ok {
+		size, ok := toFloat(val)
+		if !ok || size < 1 || size != float64(int64(size)) {
+			return nil, fmt.Errorf("'%s' must be a positive integer", cacheMaxSizeKey)
+		}
+		maxSize = int(size)
+	}
+
+	return SharedCache.Scope(source, ttl, maxSize), nil
+}
+
+// Get returns the cached value of the key, if stored within the scope TTL
+func (s *CacheScope) Get(key string) (interface{}, bool) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	src := s.cache.sources[s.source]
+	value, ok := s.get(src, key)
+	if ok {
+		src.hits.Inc()
+	} else {
+		src.misses.Inc()
+	}
+	return value, ok
+}
+
+// Set stores the value of the key
+func (s *CacheScope) Set(key string, value interface{}) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	s.set(s.cache.sources[s.source], key, value)
+}
+
+// GetOrLoad returns the cached value of the key, or calls load and stores its result.
+// Concurrent lookups of a key being loaded wait for that load instead of calling load again,
+// so the messages of a batch processed by several workers trigger a single external call per key.
+// Errors are not cached.
+func (s *CacheScope) GetOrLoad(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	s.cache.mu.Lock()
+	src := s.cache.sources[s.source]
+	if value, ok := s.get(src, key); ok {
+		src.hits.Inc()
+		s.cache.mu.Unlock()
+		return value, nil
+	}
+	src.misses.Inc()
+
+	if inflight, ok := src.inflight[key]; ok {
+		s.cache.mu.Unlock()
+		select {
+		case <-inflight.done:
+			return inflight.value, inflight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &cacheLoad{done: make(chan struct{})}
+	src.inflight[key] = call
+	s.cache.mu.Unlock()
+
+	// A panicking load is recovered by the pipeline: the waiting lookups get an error, and the next one loads again
+	stored := false
+	defer func() {
+		s.cache.mu.Lock()
+		delete(src.inflight, key)
+		if stored {
+			s.set(src, key, call.value)
+		} else if call.err == nil {
+			call.err = fmt.Errorf("load of %q panicked", key)
+		}
+		s.cache.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = load(ctx)
+	stored = call.err == nil
+	return call.value, call.err
+}
+
+// get returns the entry of the key when fresh for the scope, the cache lock must be held
+func (s *CacheScope) get(src *cacheSource, key string) (interface{}, bool) {
+	elem, ok := src.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if s.now().Sub(entry.storedAt) >= s.ttl {
+		return nil, false
+	}
+	src.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set stores the entry and evicts the least recently used ones beyond the source max size, the cache lock must be held
+func (s *CacheScope) set(src *cacheSource, key string, value interface{}) {
+	if elem, ok := src.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value, entry.storedAt = value, s.now()
+		src.lru.MoveToFront(elem)
+		return
+	}
+
+	src.entries[key] = src.lru.PushFront(&cacheEntry{key: key, value: value, storedAt: s.now()})
+	for src.lru.Len() > src.maxSize {
+		oldest := src.lru.Back()
+		src.lru.Remove(oldest)
+		delete(src.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
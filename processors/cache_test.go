@@ -0,0 +1,164 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"etelgo/metrics"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheScope_TTL(t *testing.T) {
+	cache := NewCache()
+	now := time.Now()
+	short := cache.Scope("ttl-source", time.Minute, 10)
+	long := cache.Scope("ttl-source", time.Hour, 10)
+	short.now = func() time.Time { return now }
+	long.now = func() time.Time { return now }
+
+	long.Set("user-1", "john")
+	if value, ok := short.Get("user-1"); !ok || value != "john" {
+		t.Fatalf("expected the entry to be shared between scopes, got %v, %v", value, ok)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if _, ok := short.Get("user-1"); ok {
+		t.Errorf("expected the entry to be expired for the short TTL scope")
+	}
+	if _, ok := long.Get("user-1"); !ok {
+		t.Errorf("expected the entry to be fresh for the long TTL scope")
+	}
+
+	hits := metrics.Default.Counter("etelgo_processor_cache_hits_total", "source", "ttl-source").Value()
+	misses := metrics.Default.Counter("etelgo_processor_cache_misses_total", "source", "ttl-source").Value()
+	if hits != 2 || misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d and %d", hits, misses)
+	}
+}
+
+func TestCacheScope_MaxSize(t *testing.T) {
+	cache := NewCache()
+	scope := cache.Scope("lru-source", time.Hour, 2)
+	cache.Scope("other-source", time.Hour, 1)
+
+	scope.Set("a", 1)
+	scope.Set("b", 2)
+	scope.Get("a") // b becomes the least recently used
+	scope.Set("c", 3)
+
+	if _, ok := scope.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := scope.Get(key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+}
+
+func TestCacheScope_GetOrLoad(t *testing.T) {
+	scope := NewCache().Scope("load-source", time.Hour, 10)
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := scope.GetOrLoad(context.Background(), "key", load)
+			if err != nil || value != "value" {
+				t.Errorf("unexpected result: %v, %v", value, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected concurrent lookups to share a single load, got %d calls", calls.Load())
+	}
+
+	failing := func(ctx context.Context) (interface{}, error) { return nil, errors.New("unavailable") }
+	if _, err := scope.GetOrLoad(context.Background(), "other", failing); err == nil {
+		t.Errorf("expected the load error")
+	}
+	if _, ok := scope.Get("other"); ok {
+		t.Errorf("expected errors not to be cached")
+	}
+}
+
+func TestCacheScope_GetOrLoadPanic(t *testing.T) {
+	scope := NewCache().Scope("panic-source", time.Hour, 10)
+
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		scope.GetOrLoad(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+			<-release
+			panic("lookup failed")
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	waited := make(chan error, 1)
+	go func() {
+		_, err := scope.GetOrLoad(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+			return "unexpected", nil
+		})
+		waited <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-waited:
+		if err == nil {
+			t.Errorf("expected the waiting lookup to fail with the panicking load")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup still waiting for the panicking load")
+	}
+
+	value, err := scope.GetOrLoad(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		return "value", nil
+	})
+	if err != nil || value != "value" {
+		t.Errorf("expected the next lookup to load again, got %v, %v", value, err)
+	}
+}
+
+func TestNewCacheScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Defaults", map[string]interface{}{}, false},
+		{"Custom", map[string]interface{}{"cache_ttl": "30s", "cache_max_size": 100}, false},
+		{"Invalid TTL", map[string]interface{}{"cache_ttl": "soon"}, true},
+		{"Negative TTL", map[string]interface{}{"cache_ttl": "-1s"}, true},
+		{"Invalid max size", map[string]interface{}{"cache_max_size": 0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCacheScope(ProcessorConfig{Config: tt.config, logger: testLogger}, "config-source")
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
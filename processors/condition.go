@@ -0,0 +1,147 @@
+package processors
+
+import (
+	"errors"
+	"etelgo/consumer"
+	"fmt"
+	"strings"
+)
+
+// Comparison operators of the conditions evaluated against a message field
+const (
+	OperatorEq       = "eq"
+	OperatorNe       = "ne"
+	OperatorGt       = "gt"
+	OperatorGte      = "gte"
+	OperatorLt       = "lt"
+	OperatorLte      = "lte"
+	OperatorContains = "contains"
+	OperatorExists   = "exists"
+)
+
+var ValidOperators = map[string]bool{
+	OperatorEq:       true,
+	OperatorNe:       true,
+	OperatorGt:       true,
+	OperatorGte:      true,
+	OperatorLt:       true,
+	OperatorLte:      true,
+	OperatorContains: true,
+	OperatorExists:   true,
+}
+
+// condition compares a message field, addressed by a dot-path, with a value.
+// Numbers are compared numerically whatever their type (int64, float64...), strings lexicographically.
+// contains matches a substring of a string field or an element of an array field.
+// A missing field only matches the ne operator.
+type condition struct {
+	field    string
+	operator string
+	value    interface{}
+}
+
+// newCondition parses a condition from its configuration: field, operator (default eq) and value
+func newCondition(cfg map[string]interface{}) (*condition, error) {
+	c := &condition{operator: OperatorEq}
+
+	c.field, _ = cfg["field"].(string)
+	if c.field == "" {
+		return nil, errors.New("missing or invalid condition 'field'")
+	}
+
+	if operator, ok := cfg["operator"]; ok {
+		c.operator, _ = operator.(string)
+		if !ValidOperators[c.operator] {
+			return nil, fmt.Errorf("invalid condition operator: %v", operator)
+		}
+	}
+
+	value, ok := cfg["value"]
+	if !ok && c.operator != OperatorExists {
+		return nil, fmt.Errorf("missing condition 'value' for operator %s", c.operator)
+	}
+	c.value = value
+
+	return c, nil
+}
+
+func (c *condition) match(msg *consumer.Message) bool {
+	val, ok := getPath(msg.ValueFields, c.field)
+	if !ok {
+		return c.operator == OperatorNe
+	}
+
+	switch c.operator {
+	case OperatorExists:
+		return true
+	case OperatorEq:
+		return equal(val, c.value)
+	case OperatorNe:
+		return !equal(val, c.value)
+	case OperatorContains:
+		if items, ok := val.([]interface{}); ok {
+			for _, item := range items {
+				if equal(item, c.value) {
+					return true
+				}
+			}
+			return false
+		}
+		str, ok := val.(string)
+		sub, ok2 := c.value.(string)
+		return ok && ok2 && strings.Contains(str, sub)
+	}
+
+	cmp, ok := compare(val, c.value)
+	if !ok {
+		return false
+	}
+	switch c.operator {
+	case OperatorGt:
+		return cmp > 0
+	case OperatorGte:
+		return cmp >= 0
+	case OperatorLt:
+		return cmp < 0
+	case OperatorLte:
+		return cmp <= 0
+	}
+	return false
+}
+
+// equal compares numbers, strings, booleans and nulls, objects and arrays are never equal
+func equal(a, b interface{}) bool {
+	if cmp, ok := compare(a, b); ok {
+		return cmp == 0
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	x, ok := a.(bool)
+	y, ok2 := b.(bool)
+	return ok && ok2 && x == y
+}
+
+// compare orders two numbers or two strings, ok is false for other types
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	x, ok := a.(string)
+	y, ok2 := b.(string)
+	if !ok || !ok2 {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
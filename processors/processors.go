@@ -1,11 +1,37 @@
 package processors
 
 import (
+	"context"
+	"crypto/md5"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"etelgo/consumer"
+	"etelgo/metrics"
+	"fmt"
+	"hash"
+	"hash/fnv"
 	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"text/template/parse"
 	"time"
+
+	"github.com/nyaruka/phonenumbers"
+	"github.com/ohler55/ojg/jp"
 )
 
 const (
@@ -15,22 +41,71 @@ const (
 	ProcessorTypeEnrich          = "enrich"
 	ProcessorTypeFilter          = "filter"
 	ProcessorTypePassthrough     = "passthrough"
+	ProcessorTypeHeaderField     = "header_field"
+	ProcessorTypeSample          = "sample"
+	ProcessorTypePace            = "pace"
+	ProcessorTypeExtract         = "extract"
+	ProcessorTypeMerge           = "merge"
+	ProcessorTypeCopy            = "copy"
+	ProcessorTypeRoute           = "route"
+	ProcessorTypeFieldExists     = "field_exists"
+	ProcessorTypeMaxAge          = "max_age"
+	ProcessorTypeBucket          = "bucket"
+	ProcessorTypeExplode         = "explode"
+	ProcessorTypeAggregate       = "aggregate"
+	ProcessorTypeProject         = "project"
+	ProcessorTypeEmptyToNull     = "empty_to_null"
+	ProcessorTypeSchema          = "schema"
+	ProcessorTypeRenameKeys      = "rename_keys"
+	ProcessorTypeGenerateID      = "generate_id"
+	ProcessorTypeKeyCase         = "key_case"
+	ProcessorTypeHeadersObject   = "headers_object"
+	ProcessorTypeFieldLimits     = "field_limits"
+	ProcessorTypeCoalesce        = "coalesce"
+	ProcessorTypeDedupAdjacent   = "dedup_adjacent"
+	ProcessorTypeEpochConvert    = "epoch_convert"
+	ProcessorTypeNormalize       = "normalize"
+	ProcessorTypeFingerprint     = "fingerprint"
+	ProcessorTypeTemplate        = "template"
+	ProcessorTypeTap             = "tap"
+	ProcessorTypeRequire         = "require"
+	ProcessorTypeFormatNumber    = "format_number"
+	ProcessorTypeLookup          = "lookup"
+)
+
+// HeaderFieldDirection is the way a header_field processor copies data
+type HeaderFieldDirection string
+
+const (
+	DirectionToHeader HeaderFieldDirection = "to_header" // Field value written into the header
+	DirectionToField  HeaderFieldDirection = "to_field"  // Header value written into the field
+	DirectionDelete   HeaderFieldDirection = "delete"    // Header removed
+
+	DirectionToHeaders HeaderFieldDirection = "to_headers" // headers_object only: object fields written into the headers
 )
 
 type TransformationOperation string
 
 const (
-	OperationUppercase TransformationOperation = "uppercase"
-	OperationLowercase TransformationOperation = "lowercase"
-	OperationAddPrefix TransformationOperation = "add_prefix"
-	OperationAddSuffix TransformationOperation = "add_suffix"
+	OperationUppercase     TransformationOperation = "uppercase"
+	OperationLowercase     TransformationOperation = "lowercase"
+	OperationAddPrefix     TransformationOperation = "add_prefix"
+	OperationAddSuffix     TransformationOperation = "add_suffix"
+	OperationBase64Encode  TransformationOperation = "base64_encode"
+	OperationBase64Decode  TransformationOperation = "base64_decode"
+	OperationJSONParse     TransformationOperation = "json_parse"
+	OperationJSONStringify TransformationOperation = "json_stringify"
 )
 
 var ValidTransformOperations = map[TransformationOperation]bool{
-	OperationUppercase: true,
-	OperationLowercase: true,
-	OperationAddPrefix: true,
-	OperationAddSuffix: true,
+	OperationUppercase:     true,
+	OperationLowercase:     true,
+	OperationAddPrefix:     true,
+	OperationAddSuffix:     true,
+	OperationBase64Encode:  true,
+	OperationBase64Decode:  true,
+	OperationJSONParse:     true,
+	OperationJSONStringify: true,
 }
 
 type ProcessorConfig struct {
@@ -39,11 +114,37 @@ type ProcessorConfig struct {
 	logger *slog.Logger
 }
 
+// Processor transforms a message, returning a nil message without error to drop it.
+// ctx is the pipeline context, done once the shutdown drain times out: processors waiting on a timer,
+// a lookup or any external call must stop waiting when it is done.
 type Processor interface {
-	Process(msg *consumer.Message) (*consumer.Message, error)
+	Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error)
 	Name() string
 }
 
+// ProcessorError is the error of a processor failing on a message, naming the processor by its type,
+// e.g. for the dead letter envelope (see config.DLQEnvelopeConfig)
+type ProcessorError struct {
+	Processor string
+	Err       error
+}
+
+func (e *ProcessorError) Error() string {
+	return fmt.Sprintf("processor %s: %v", e.Processor, e.Err)
+}
+
+func (e *ProcessorError) Unwrap() error {
+	return e.Err
+}
+
+// MultiProcessor is a processor emitting several messages from one (one-to-many), the pipeline calling
+// ProcessMulti instead of Process. Each emitted message runs through the next processors on its own,
+// and returning no message drops the source message.
+type MultiProcessor interface {
+	Processor
+	ProcessMulti(ctx context.Context, msg *consumer.Message) ([]*consumer.Message, error)
+}
+
 // Factory pattern to create processors based on type
 func NewProcessor(cfg ProcessorConfig, logger *slog.Logger) (Processor, error) {
 	cfg.logger = logger
@@ -58,6 +159,66 @@ func NewProcessor(cfg ProcessorConfig, logger *slog.Logger) (Processor, error) {
 		return NewEnrichProcessor(cfg)
 	case ProcessorTypePassthrough:
 		return NewPassthroughProcessor(cfg), nil
+	case ProcessorTypeHeaderField:
+		return NewHeaderFieldProcessor(cfg)
+	case ProcessorTypeSample:
+		return NewSampleProcessor(cfg)
+	case ProcessorTypePace:
+		return NewPaceProcessor(cfg)
+	case ProcessorTypeExtract:
+		return NewExtractProcessor(cfg)
+	case ProcessorTypeMerge:
+		return NewMergeProcessor(cfg)
+	case ProcessorTypeCopy:
+		return NewCopyProcessor(cfg)
+	case ProcessorTypeRoute:
+		return NewRouteProcessor(cfg)
+	case ProcessorTypeFieldExists:
+		return NewFieldExistsProcessor(cfg)
+	case ProcessorTypeMaxAge:
+		return NewMaxAgeProcessor(cfg)
+	case ProcessorTypeBucket:
+		return NewBucketProcessor(cfg)
+	case ProcessorTypeExplode:
+		return NewExplodeProcessor(cfg)
+	case ProcessorTypeAggregate:
+		return NewAggregateProcessor(cfg)
+	case ProcessorTypeProject:
+		return NewProjectProcessor(cfg)
+	case ProcessorTypeEmptyToNull:
+		return NewEmptyToNullProcessor(cfg)
+	case ProcessorTypeSchema:
+		return NewSchemaProcessor(cfg)
+	case ProcessorTypeRenameKeys:
+		return NewRenameKeysProcessor(cfg)
+	case ProcessorTypeGenerateID:
+		return NewIDProcessor(cfg)
+	case ProcessorTypeKeyCase:
+		return NewKeyCaseProcessor(cfg)
+	case ProcessorTypeHeadersObject:
+		return NewHeadersObjectProcessor(cfg)
+	case ProcessorTypeFieldLimits:
+		return NewFieldLimitsProcessor(cfg)
+	case ProcessorTypeCoalesce:
+		return NewCoalesceProcessor(cfg)
+	case ProcessorTypeDedupAdjacent:
+		return NewDedupAdjacentProcessor(cfg)
+	case ProcessorTypeEpochConvert:
+		return NewEpochConvertProcessor(cfg)
+	case ProcessorTypeNormalize:
+		return NewNormalizeProcessor(cfg)
+	case ProcessorTypeFingerprint:
+		return NewFingerprintProcessor(cfg)
+	case ProcessorTypeTemplate:
+		return NewTemplateProcessor(cfg)
+	case ProcessorTypeTap:
+		return NewTapProcessor(cfg)
+	case ProcessorTypeRequire:
+		return NewRequireProcessor(cfg)
+	case ProcessorTypeFormatNumber:
+		return NewFormatNumberProcessor(cfg)
+	case ProcessorTypeLookup:
+		return NewLookupProcessor(cfg)
 	default:
 		logger.Error("unknown processor type", slog.String("type", cfg.Type))
 		return nil, errors.New("unknown processor type: " + cfg.Type)
@@ -114,7 +275,7 @@ func (p *TimestampReplayProcessor) Name() string {
 // Process can replay messages based on the options defined in the processor.
 // This processer basically applies to every message where there is a timestamp field correspond to the field name used in the configuration.
 
-func (p *TimestampReplayProcessor) Process(msg *consumer.Message) (*consumer.Message, error) {
+func (p *TimestampReplayProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
 	p.logger.Info("TimestampReplayProcessor: processing message for timestamp replay")
 	// Dual logic based on the options provided
 
@@ -148,16 +309,24 @@ func (p *TimestampReplayProcessor) Process(msg *consumer.Message) (*consumer.Mes
 }
 
 // DropProcessor drops messages based on certain criteria.
+// filter_criteria is parsed as the value_type (string by default), the field being compared as that type:
+// a number matches whatever its decoded type (int64 or float64), and so does a string holding the same value.
+// Several conditions can be given instead, the message being dropped when all (or any, see match) of them match.
 type DropProcessor struct {
 	filterCriteria string
 	fieldName      string
+	valueType      string
+	criteria       interface{} // filterCriteria parsed as the value type
+	conditions     []*condition
+	matchAny       bool // Dropped when any of the conditions matches, all of them by default
 	logger         *slog.Logger
 }
 
 // NewDropProcessor creates a new DropProcessor with the given configuration.
 func NewDropProcessor(cfg ProcessorConfig) (Processor, error) {
 	processor := &DropProcessor{
-		logger: cfg.logger,
+		logger:    cfg.logger,
+		valueType: "string",
 	}
 
 	criteria, ok := cfg.Config["filter_criteria"]
@@ -175,18 +344,55 @@ func NewDropProcessor(cfg ProcessorConfig) (Processor, error) {
 			processor.fieldName = strVal
 		}
 	}
+
+	if val, ok := cfg.Config["conditions"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, errors.New("'conditions' must be a non empty list")
+		}
+		for i, item := range items {
+			when, _ := item.(map[string]interface{})
+			c, err := newCondition(when)
+			if err != nil {
+				return nil, fmt.Errorf("conditions[%d]: %w", i, err)
+			}
+			processor.conditions = append(processor.conditions, c)
+		}
+
+		switch match := cfg.Config["match"]; match {
+		case nil, "all":
+		case "any":
+			processor.matchAny = true
+		default:
+			return nil, fmt.Errorf("invalid drop match: %v", match)
+		}
+		return processor, nil
+	}
+
+	if valueType, ok := cfg.Config["value_type"].(string); ok {
+		processor.valueType = valueType
+	}
+	parsed, err := parseDropValue(processor.filterCriteria, processor.valueType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter_criteria: %w", err)
+	}
+	processor.criteria = parsed
 	return processor, nil
 
 }
 
-func (p *DropProcessor) Process(msg *consumer.Message) (*consumer.Message, error) {
+func (p *DropProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if len(p.conditions) > 0 {
+		if p.matchConditions(msg) {
+			return nil, nil
+		}
+		return msg, nil
+	}
+
 	if p.fieldName != "" && p.filterCriteria != "" {
 		val, ok := msg.ValueFields[p.fieldName]
-		if ok {
-			strVal, ok := val.(string)
-			if ok && strVal == p.filterCriteria {
-				return nil, nil
-			}
+		if ok && p.matches(val) {
+			return nil, nil
 		}
 	}
 
@@ -194,12 +400,81 @@ func (p *DropProcessor) Process(msg *consumer.Message) (*consumer.Message, error
 
 }
 
+// matchConditions tells whether all the conditions match the message, any of them with matchAny
+func (p *DropProcessor) matchConditions(msg *consumer.Message) bool {
+	for _, c := range p.conditions {
+		if c.match(msg) == p.matchAny {
+			return p.matchAny
+		}
+	}
+	return !p.matchAny
+}
+
+// matches compares the field value to the criteria as the value type, a value not convertible never matching
+func (p *DropProcessor) matches(val interface{}) bool {
+	if p.valueType == "string" {
+		strVal, ok := val.(string)
+		return ok && strVal == p.criteria
+	}
+
+	// Strings (e.g. from the csv format) are parsed as the value type
+	if strVal, ok := val.(string); ok {
+		parsed, err := parseDropValue(strVal, p.valueType)
+		return err == nil && parsed == p.criteria
+	}
+
+	switch criteria := p.criteria.(type) {
+	case int64:
+		switch v := val.(type) {
+		case int:
+			return int64(v) == criteria
+		case int64:
+			return v == criteria
+		case uint64:
+			return v <= math.MaxInt64 && int64(v) == criteria
+		case float64:
+			return v == math.Trunc(v) && v == float64(criteria)
+		}
+	case float64:
+		f, ok := toFloat(val)
+		return ok && f == criteria
+	case bool:
+		b, ok := val.(bool)
+		return ok && b == criteria
+	}
+	return false
+}
+
+// parseDropValue converts the value to the drop value type: string, int (int64), float (float64) or bool
+func parseDropValue(value string, valueType string) (interface{}, error) {
+	switch valueType {
+	case "string":
+		return value, nil
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	}
+	return nil, fmt.Errorf("unknown value_type %q", valueType)
+}
+
 func (p *DropProcessor) Name() string {
 	return ProcessorTypeDrop
 }
 
 // Transform operation types function
 func applyTransformation(value interface{}, operation string, params map[string]interface{}) (interface{}, error) {
+	// json_stringify applies to any value, the other operations only to strings
+	if operation == "json_stringify" {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return value, fmt.Errorf("failed to stringify value: %w", err)
+		}
+		return string(encoded), nil
+	}
+
 	strVal, ok := value.(string)
 	if !ok {
 		return value, nil
@@ -221,17 +496,35 @@ func applyTransformation(value interface{}, operation string, params map[string]
 			return value, errors.New("missing or invalid 'suffix' parameter for add_suffix operation")
 		}
 		return strVal + suffix, nil
+	case "base64_encode":
+		return base64.StdEncoding.EncodeToString([]byte(strVal)), nil
+	case "base64_decode":
+		decoded, err := base64.StdEncoding.DecodeString(strVal)
+		if err != nil {
+			return value, fmt.Errorf("invalid base64 value: %w", err)
+		}
+		return string(decoded), nil
+	case "json_parse":
+		// Objects are decoded as map[string]interface{}, so that the dot-paths of the next processors reach into them
+		parsed, err := consumer.DecodeJSON([]byte(strVal))
+		if err != nil {
+			return value, fmt.Errorf("invalid JSON value: %w", err)
+		}
+		return parsed, nil
 	default:
 		return value, errors.New("unknown transformation operation: " + operation)
 	}
 }
 
 // TransformProcessor modifies message content by modifying mentioned fields' values.
+// A failing transformation fails the message (errors policy), unless keep_original_on_error leaves the field
+// as it was and passes the message on, for best-effort transformations.
 type TransformProcessor struct {
-	logger    *slog.Logger
-	fieldName string
-	operation string
-	params    map[string]interface{}
+	logger              *slog.Logger
+	fieldName           string
+	operation           string
+	params              map[string]interface{}
+	keepOriginalOnError bool
 }
 
 func NewTransformProcessor(cfg ProcessorConfig) (Processor, error) {
@@ -258,7 +551,15 @@ func NewTransformProcessor(cfg ProcessorConfig) (Processor, error) {
 		}
 	}
 
-	processor.params = cfg.Config["params"].(map[string]interface{})
+	// The operations without parameters (e.g. base64_encode) are configured without params
+	processor.params, _ = cfg.Config["params"].(map[string]interface{})
+	if processor.params == nil {
+		processor.params = map[string]interface{}{}
+	}
+
+	if keep, ok := cfg.Config["keep_original_on_error"].(bool); ok {
+		processor.keepOriginalOnError = keep
+	}
 
 	return processor, nil
 }
@@ -267,7 +568,7 @@ func (p *TransformProcessor) Name() string {
 	return ProcessorTypeTransform
 }
 
-func (p *TransformProcessor) Process(msg *consumer.Message) (*consumer.Message, error) {
+func (p *TransformProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
 	if p.fieldName == "" || p.operation == "" {
 		p.logger.Warn("TransformProcessor: missing field_name or operation configuration")
 		return msg, nil
@@ -279,6 +580,10 @@ func (p *TransformProcessor) Process(msg *consumer.Message) (*consumer.Message,
 	}
 
 	newVal, err := applyTransformation(val, p.operation, p.params)
+	if err != nil && p.keepOriginalOnError {
+		p.logger.Warn("TransformProcessor: failed to apply transformation, keeping the original value", "field_name", p.fieldName, "error", err)
+		return msg, nil
+	}
 	if err != nil {
 		p.logger.Error("TransformProcessor: failed to apply transformation", "error", err)
 		return nil, err
@@ -317,7 +622,7 @@ func NewEnrichProcessor(cfg ProcessorConfig) (Processor, error) {
 	return processor, nil
 }
 
-func (p *EnrichProcessor) Process(msg *consumer.Message) (*consumer.Message, error) {
+func (p *EnrichProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
 	if p.addedFieldName == "" || p.addedFieldValue == nil {
 		p.logger.Warn("EnrichProcessor: missing added_field_name or added_field_value configuration")
 		return msg, nil
@@ -342,7 +647,7 @@ func NewPassthroughProcessor(cfg ProcessorConfig) *PassthroughProcessor {
 	}
 }
 
-func (p *PassthroughProcessor) Process(msg *consumer.Message) (*consumer.Message, error) {
+func (p *PassthroughProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
 	p.logger.Info("PassthroughProcessor: passing message through unchanged")
 	return msg, nil
 }
@@ -350,3 +655,2616 @@ func (p *PassthroughProcessor) Process(msg *consumer.Message) (*consumer.Message
 func (p *PassthroughProcessor) Name() string {
 	return ProcessorTypePassthrough
 }
+
+// HeaderFieldProcessor copies data between a message header and a value field, or deletes a header.
+type HeaderFieldProcessor struct {
+	logger    *slog.Logger
+	header    string
+	fieldName string
+	direction HeaderFieldDirection
+}
+
+func NewHeaderFieldProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &HeaderFieldProcessor{
+		logger: cfg.logger,
+	}
+
+	processor.header, _ = cfg.Config["header"].(string)
+	if processor.header == "" {
+		return nil, errors.New("missing or invalid 'header' parameter")
+	}
+
+	direction, _ := cfg.Config["direction"].(string)
+	processor.direction = HeaderFieldDirection(direction)
+	switch processor.direction {
+	case DirectionToHeader, DirectionToField:
+		processor.fieldName, _ = cfg.Config["field_name"].(string)
+		if processor.fieldName == "" {
+			return nil, errors.New("missing or invalid 'field_name' parameter for direction " + direction)
+		}
+	case DirectionDelete:
+	default:
+		return nil, errors.New("invalid header_field direction: " + direction)
+	}
+
+	return processor, nil
+}
+
+func (p *HeaderFieldProcessor) Name() string {
+	return ProcessorTypeHeaderField
+}
+
+// Process leaves the message unchanged when the source header or field is missing
+func (p *HeaderFieldProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	switch p.direction {
+	case DirectionToHeader:
+		val, ok := msg.ValueFields[p.fieldName]
+		if !ok {
+			return msg, nil
+		}
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		msg.Headers[p.header] = fmt.Sprint(val)
+	case DirectionToField:
+		val, ok := msg.Headers[p.header]
+		if !ok {
+			return msg, nil
+		}
+		if msg.ValueFields == nil {
+			msg.ValueFields = make(map[string]interface{})
+		}
+		msg.ValueFields[p.fieldName] = val
+	case DirectionDelete:
+		delete(msg.Headers, p.header)
+	}
+	return msg, nil
+}
+
+// SampleProcessor passes a fraction of the messages and drops the rest, in one of two modes.
+// With rate, sampling is independent per message: each one passes with the probability rate,
+// it is not a deterministic one every N, so the passed count only converges to the rate over many messages.
+// With every, exactly one of every N messages of each partition passes (the first, then the N+1th, ...).
+// Counters are partition-local, so the ratio holds whatever the worker processing each message.
+type SampleProcessor struct {
+	logger *slog.Logger
+	rate   float64
+	every  int64 // 0 when sampling by rate
+
+	mu       sync.Mutex // The workers share the processor, and neither rand.Rand nor the counters are safe for concurrent use
+	rng      *rand.Rand
+	counters map[partitionKey]int64
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// NewSampleProcessor reads either the rate, with the optional seed making the sampling reproducible, or every
+func NewSampleProcessor(cfg ProcessorConfig) (Processor, error) {
+	_, hasRate := cfg.Config["rate"]
+	everyVal, hasEvery := cfg.Config["every"]
+	if hasRate == hasEvery {
+		return nil, errors.New("sample requires either 'rate' or 'every', but not both")
+	}
+
+	if hasEvery {
+		every, ok := toFloat(everyVal)
+		if !ok || every < 1 || every != float64(int64(every)) {
+			return nil, fmt.Errorf("invalid sample every: %v, must be a positive integer", everyVal)
+		}
+		return &SampleProcessor{
+			logger:   cfg.logger,
+			every:    int64(every),
+			counters: make(map[partitionKey]int64),
+		}, nil
+	}
+
+	rate, ok := toFloat(cfg.Config["rate"])
+	if !ok || rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("invalid sample rate: %v, must be a number in [0,1]", cfg.Config["rate"])
+	}
+
+	seed := rand.Uint64()
+	if val, ok := cfg.Config["seed"]; ok {
+		s, ok := toFloat(val)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample seed: %v, must be an integer", val)
+		}
+		seed = uint64(s)
+	}
+
+	return &SampleProcessor{
+		logger: cfg.logger,
+		rate:   rate,
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+	}, nil
+}
+
+func (p *SampleProcessor) Name() string {
+	return ProcessorTypeSample
+}
+
+func (p *SampleProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	p.mu.Lock()
+	var pass bool
+	if p.every > 0 {
+		key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+		pass = p.counters[key]%p.every == 0
+		p.counters[key]++
+	} else {
+		pass = p.rng.Float64() < p.rate
+	}
+	p.mu.Unlock()
+
+	if !pass {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// PaceProcessor delays the messages to reproduce their original timing, scaled by speed (2.0 = twice as fast).
+// The first message sets the reference: every message is released once the wall clock elapsed since the reference
+// matches the gap between its timestamp and the reference one. Unlike sleeping the gap between consecutive messages,
+// the delays don't add up when several workers process messages concurrently.
+// Messages older than the current replay position are released immediately.
+type PaceProcessor struct {
+	logger *slog.Logger
+	speed  float64
+
+	mu        sync.Mutex
+	started   bool
+	reference time.Time // Timestamp of the first message
+	startedAt time.Time // Wall clock when the first message was processed
+}
+
+func NewPaceProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &PaceProcessor{
+		logger: cfg.logger,
+		speed:  1,
+	}
+
+	if val, ok := cfg.Config["speed"]; ok {
+		speed, ok := toFloat(val)
+		if !ok || speed <= 0 {
+			return nil, fmt.Errorf("invalid pace speed: %v, must be a positive number", val)
+		}
+		processor.speed = speed
+	}
+
+	return processor, nil
+}
+
+func (p *PaceProcessor) Name() string {
+	return ProcessorTypePace
+}
+
+// Process waits for the message release time.
+// Once ctx is done the message is returned without waiting, so that a shutdown still delivers it.
+func (p *PaceProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	delay := p.delay(msg.Timestamp, time.Now())
+	if delay <= 0 {
+		return msg, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		p.logger.Debug("PaceProcessor: wait interrupted", "remaining", delay)
+	}
+	return msg, nil
+}
+
+// delay returns how long the message with the given timestamp must wait from now
+func (p *PaceProcessor) delay(timestamp time.Time, now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		p.started = true
+		p.reference = timestamp
+		p.startedAt = now
+		return 0
+	}
+
+	target := time.Duration(float64(timestamp.Sub(p.reference)) / p.speed)
+	return target - now.Sub(p.startedAt)
+}
+
+// ExtractProcessor evaluates a JSONPath expression against the value fields and writes the result into target_field.
+// A singular path (e.g. $.user.name) writes the matched value, a path able to match several values
+// (wildcards, slices, filters, descent, unions, e.g. $.items[*].sku) always writes an array.
+// When nothing matches, the message is left unchanged.
+type ExtractProcessor struct {
+	logger      *slog.Logger
+	path        jp.Expr
+	targetField string
+	singular    bool
+}
+
+func NewExtractProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &ExtractProcessor{
+		logger: cfg.logger,
+	}
+
+	path, _ := cfg.Config["path"].(string)
+	if path == "" {
+		return nil, errors.New("missing or invalid 'path' parameter")
+	}
+	expr, err := jp.ParseString(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	processor.path = expr
+	processor.singular = isSingularPath(expr)
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	return processor, nil
+}
+
+// isSingularPath reports whether the path can match at most one value
+func isSingularPath(expr jp.Expr) bool {
+	for _, frag := range expr {
+		switch frag.(type) {
+		case jp.Root, jp.At, jp.Child, jp.Nth, jp.Bracket:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ExtractProcessor) Name() string {
+	return ProcessorTypeExtract
+}
+
+func (p *ExtractProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	results := p.path.Get(msg.ValueFields)
+	if p.singular {
+		if len(results) == 0 {
+			return msg, nil
+		}
+		msg.ValueFields[p.targetField] = results[0]
+		return msg, nil
+	}
+
+	if results == nil {
+		results = []interface{}{}
+	}
+	msg.ValueFields[p.targetField] = results
+	return msg, nil
+}
+
+// MergeProcessor combines several source fields into target_field, either joined with separator
+// or rendered through template where {field} is replaced by the field value (e.g. "{last}, {first}").
+// With missing "skip" (default) a missing field is left out of the join, with "empty" it is joined as an empty string.
+// In a template, a missing field is always rendered empty.
+type MergeProcessor struct {
+	logger       *slog.Logger
+	sourceFields []string
+	targetField  string
+	separator    string
+	template     string
+	skipMissing  bool
+}
+
+func NewMergeProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &MergeProcessor{
+		logger:      cfg.logger,
+		skipMissing: true,
+	}
+
+	sources, _ := cfg.Config["source_fields"].([]interface{})
+	for _, source := range sources {
+		field, ok := source.(string)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid source field: %v", source)
+		}
+		processor.sourceFields = append(processor.sourceFields, field)
+	}
+	if len(processor.sourceFields) < 2 {
+		return nil, errors.New("merge requires at least two 'source_fields'")
+	}
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	processor.separator, _ = cfg.Config["separator"].(string)
+	processor.template, _ = cfg.Config["template"].(string)
+
+	if missing, ok := cfg.Config["missing"].(string); ok {
+		switch missing {
+		case "skip":
+		case "empty":
+			processor.skipMissing = false
+		default:
+			return nil, errors.New("invalid merge missing value: " + missing)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *MergeProcessor) Name() string {
+	return ProcessorTypeMerge
+}
+
+func (p *MergeProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	if p.template != "" {
+		replacements := make([]string, 0, 2*len(p.sourceFields))
+		for _, field := range p.sourceFields {
+			replacements = append(replacements, "{"+field+"}", p.fieldString(msg, field))
+		}
+		msg.ValueFields[p.targetField] = strings.NewReplacer(replacements...).Replace(p.template)
+		return msg, nil
+	}
+
+	parts := make([]string, 0, len(p.sourceFields))
+	for _, field := range p.sourceFields {
+		if _, ok := msg.ValueFields[field]; !ok && p.skipMissing {
+			continue
+		}
+		parts = append(parts, p.fieldString(msg, field))
+	}
+	msg.ValueFields[p.targetField] = strings.Join(parts, p.separator)
+	return msg, nil
+}
+
+// fieldString formats the field value, a missing or null field being empty
+func (p *MergeProcessor) fieldString(msg *consumer.Message, field string) string {
+	val, ok := msg.ValueFields[field]
+	if !ok || val == nil {
+		return ""
+	}
+	return fmt.Sprint(val)
+}
+
+// CopyProcessor duplicates the value of source_field into target_field, the original being kept.
+// Both fields accept dot-paths into nested objects (e.g. "user.address.city"), missing objects of the target being created.
+// The value is deep copied, so that transforming the copy leaves the original untouched.
+// When the target already exists it is only replaced with overwrite.
+type CopyProcessor struct {
+	logger      *slog.Logger
+	sourceField string
+	targetField string
+	overwrite   bool
+}
+
+func NewCopyProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &CopyProcessor{
+		logger: cfg.logger,
+	}
+
+	processor.sourceField, _ = cfg.Config["source_field"].(string)
+	if processor.sourceField == "" {
+		return nil, errors.New("missing or invalid 'source_field' parameter")
+	}
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+	processor.overwrite, _ = cfg.Config["overwrite"].(bool)
+
+	return processor, nil
+}
+
+func (p *CopyProcessor) Name() string {
+	return ProcessorTypeCopy
+}
+
+func (p *CopyProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.sourceField)
+	if !ok {
+		return msg, nil
+	}
+	if _, exists := getPath(msg.ValueFields, p.targetField); exists && !p.overwrite {
+		return msg, nil
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, deepCopy(val)); err != nil {
+		p.logger.Error("CopyProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// getPath returns the value at the dot-path, e.g. "user.address.city"
+func getPath(fields map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	current := fields
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	val, ok := current[keys[len(keys)-1]]
+	return val, ok
+}
+
+// setPath writes the value at the dot-path, creating the missing intermediate objects.
+// It fails when an intermediate field exists but is not an object.
+func setPath(fields map[string]interface{}, path string, value interface{}) error {
+	if fields == nil {
+		return errors.New("message has no value fields")
+	}
+	keys := strings.Split(path, ".")
+	current := fields
+	for _, key := range keys[:len(keys)-1] {
+		existing, ok := current[key]
+		if !ok {
+			next := make(map[string]interface{})
+			current[key] = next
+			current = next
+			continue
+		}
+		next, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field %q is not an object", key)
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+	return nil
+}
+
+// deletePath removes the field at the dot-path, if present
+func deletePath(fields map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	current := fields
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, keys[len(keys)-1])
+}
+
+// deepCopy copies the nested objects and arrays of a decoded value
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = deepCopy(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = deepCopy(val)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// RouteProcessor tags the message with the label of the first matching rule, written into target_field.
+// Rules are evaluated in order, the default rule (at most one, last) matching any message.
+// Without default rule, a message matching no rule is left untagged.
+// Pointing the output topic_field at target_field gives a declarative demux.
+type RouteProcessor struct {
+	logger       *slog.Logger
+	targetField  string
+	rules        []routeRule
+	defaultLabel *string
+}
+
+type routeRule struct {
+	label string
+	when  *condition
+}
+
+const defaultRouteField = "_route"
+
+func NewRouteProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &RouteProcessor{
+		logger:      cfg.logger,
+		targetField: defaultRouteField,
+	}
+
+	if target, ok := cfg.Config["target_field"].(string); ok && target != "" {
+		processor.targetField = target
+	}
+
+	rules, _ := cfg.Config["rules"].([]interface{})
+	if len(rules) == 0 {
+		return nil, errors.New("route requires at least one rule")
+	}
+	for i, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rule %d: must be an object", i)
+		}
+		label, _ := rule["label"].(string)
+		if label == "" {
+			return nil, fmt.Errorf("rule %d: missing or invalid 'label'", i)
+		}
+
+		if isDefault, _ := rule["default"].(bool); isDefault {
+			if processor.defaultLabel != nil || i != len(rules)-1 {
+				return nil, fmt.Errorf("rule %d: only one default rule is allowed, as the last rule", i)
+			}
+			processor.defaultLabel = &label
+			continue
+		}
+
+		when, ok := rule["when"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rule %d: missing 'when' condition", i)
+		}
+		c, err := newCondition(when)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		processor.rules = append(processor.rules, routeRule{label: label, when: c})
+	}
+
+	return processor, nil
+}
+
+func (p *RouteProcessor) Name() string {
+	return ProcessorTypeRoute
+}
+
+func (p *RouteProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	for _, rule := range p.rules {
+		if rule.when.match(msg) {
+			msg.ValueFields[p.targetField] = rule.label
+			return msg, nil
+		}
+	}
+	if p.defaultLabel != nil {
+		msg.ValueFields[p.targetField] = *p.defaultLabel
+	}
+	return msg, nil
+}
+
+// FieldExistsProcessor keeps or drops messages on the sole presence of a field (dot-path), whatever its value.
+// A field is present when it exists and is not null. The message matches when the field presence equals
+// require_present, then action "keep" forwards only the matching messages and "drop" discards them.
+type FieldExistsProcessor struct {
+	logger         *slog.Logger
+	fieldName      string
+	requirePresent bool
+	drop           bool
+}
+
+const (
+	FieldExistsActionKeep = "keep"
+	FieldExistsActionDrop = "drop"
+)
+
+func NewFieldExistsProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &FieldExistsProcessor{
+		logger:         cfg.logger,
+		requirePresent: true,
+	}
+
+	processor.fieldName, _ = cfg.Config["field_name"].(string)
+	if processor.fieldName == "" {
+		return nil, errors.New("missing or invalid 'field_name' parameter")
+	}
+	if requirePresent, ok := cfg.Config["require_present"].(bool); ok {
+		processor.requirePresent = requirePresent
+	}
+
+	if action, ok := cfg.Config["action"]; ok {
+		switch action {
+		case FieldExistsActionKeep:
+		case FieldExistsActionDrop:
+			processor.drop = true
+		default:
+			return nil, fmt.Errorf("invalid field_exists action: %v", action)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *FieldExistsProcessor) Name() string {
+	return ProcessorTypeFieldExists
+}
+
+func (p *FieldExistsProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	present := ok && val != nil
+
+	if (present == p.requirePresent) == p.drop {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// MaxAgeProcessor drops the messages older than max_age, e.g. to skip an ancient backlog when a consumer restarts far behind.
+// The age is computed from the Kafka timestamp, or from timestamp_field: an RFC3339 string or a Unix epoch number
+// in timestamp_unit. A message without a readable timestamp_field is an error rather than silently kept or dropped.
+type MaxAgeProcessor struct {
+	logger         *slog.Logger
+	maxAge         time.Duration
+	timestampField string        // Empty to use the Kafka timestamp
+	unit           time.Duration // Unit of the numeric timestamps
+	now            func() time.Time
+}
+
+func NewMaxAgeProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &MaxAgeProcessor{
+		logger: cfg.logger,
+		unit:   time.Millisecond,
+		now:    time.Now,
+	}
+
+	maxAge, _ := cfg.Config["max_age"].(string)
+	duration, err := time.ParseDuration(maxAge)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid max_age: %q, must be a positive duration", maxAge)
+	}
+	processor.maxAge = duration
+
+	if val, ok := cfg.Config["timestamp_field"]; ok {
+		field, ok := val.(string)
+		if !ok || field == "" {
+			return nil, errors.New("invalid 'timestamp_field' parameter")
+		}
+		processor.timestampField = field
+	}
+
+	if unit, ok := cfg.Config["timestamp_unit"]; ok {
+		switch unit {
+		case "ms":
+		case "s":
+			processor.unit = time.Second
+		default:
+			return nil, fmt.Errorf("invalid max_age timestamp_unit: %v", unit)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *MaxAgeProcessor) Name() string {
+	return ProcessorTypeMaxAge
+}
+
+func (p *MaxAgeProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	timestamp := msg.Timestamp
+	if p.timestampField != "" {
+		var err error
+		if timestamp, err = p.fieldTime(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	if age := p.now().Sub(timestamp); age > p.maxAge {
+		p.logger.Debug("MaxAgeProcessor: message too old", "age", age, "max_age", p.maxAge)
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// fieldTime reads the message time from timestamp_field
+func (p *MaxAgeProcessor) fieldTime(msg *consumer.Message) (time.Time, error) {
+	val, ok := getPath(msg.ValueFields, p.timestampField)
+	if !ok || val == nil {
+		return time.Time{}, fmt.Errorf("timestamp field %q not found", p.timestampField)
+	}
+
+	if str, ok := val.(string); ok {
+		timestamp, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("timestamp field %q: %w", p.timestampField, err)
+		}
+		return timestamp, nil
+	}
+
+	epoch, ok := toFloat(val)
+	if !ok {
+		return time.Time{}, fmt.Errorf("timestamp field %q must be an RFC3339 string or a number, got %T", p.timestampField, val)
+	}
+	return time.Unix(0, int64(epoch*float64(p.unit))), nil
+}
+
+// BucketProcessor writes into target_field the label of the first bucket whose max is greater or equal to the
+// numeric field, e.g. an age into child/adult/senior. The last bucket is a catch-all for the values above every max.
+// A message without the field is left untouched, a non numeric value is an error.
+type BucketProcessor struct {
+	logger      *slog.Logger
+	fieldName   string
+	targetField string
+	buckets     []bucket // Ascending max, without the catch-all
+	catchAll    string
+}
+
+type bucket struct {
+	max   float64 // Inclusive upper bound
+	label string
+}
+
+func NewBucketProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &BucketProcessor{logger: cfg.logger}
+
+	processor.fieldName, _ = cfg.Config["field_name"].(string)
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.fieldName == "" || processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'field_name' or 'target_field' parameter")
+	}
+
+	buckets, _ := cfg.Config["buckets"].([]interface{})
+	if len(buckets) < 2 {
+		return nil, errors.New("bucket requires at least one bucket and the catch-all")
+	}
+	for i, b := range buckets {
+		entry, _ := b.(map[string]interface{})
+		label, _ := entry["label"].(string)
+		if label == "" {
+			return nil, fmt.Errorf("bucket %d: missing or invalid 'label'", i)
+		}
+		if i == len(buckets)-1 {
+			if _, ok := entry["max"]; ok {
+				return nil, errors.New("the last bucket must be the catch-all, without 'max'")
+			}
+			processor.catchAll = label
+			break
+		}
+
+		threshold, ok := toFloat(entry["max"])
+		if !ok {
+			return nil, fmt.Errorf("bucket %d: missing or invalid 'max'", i)
+		}
+		if i > 0 && threshold <= processor.buckets[i-1].max {
+			return nil, fmt.Errorf("bucket %d: 'max' must be greater than the previous one", i)
+		}
+		processor.buckets = append(processor.buckets, bucket{max: threshold, label: label})
+	}
+
+	return processor, nil
+}
+
+func (p *BucketProcessor) Name() string {
+	return ProcessorTypeBucket
+}
+
+func (p *BucketProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	if !ok || val == nil {
+		return msg, nil
+	}
+
+	number, ok := toFloat(val)
+	if !ok {
+		return nil, fmt.Errorf("bucket field %q must be a number, got %T", p.fieldName, val)
+	}
+
+	label := p.catchAll
+	for _, b := range p.buckets {
+		if number <= b.max {
+			label = b.label
+			break
+		}
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, label); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Fields set by the explode processor on each emitted message
+const (
+	ExplodeKeyField   = "_key"
+	ExplodeValueField = "_value"
+)
+
+// ExplodeProcessor emits one message per entry of a map field, in key order, reshaping wide records into long ones:
+// {"id": 1, "scores": {"math": 12, "art": 15}} becomes {"id": 1, "_key": "art", "_value": 15} and
+// {"id": 1, "_key": "math", "_value": 12}. The exploded field is removed from the emitted messages.
+// Every emitted message keeps the Kafka key, headers and position of the source message, so they all go to the same
+// output partition with a key-based partitioner. A message without the field is left untouched, an empty map drops it,
+// and a value that isn't a map is an error.
+type ExplodeProcessor struct {
+	logger    *slog.Logger
+	fieldName string
+}
+
+func NewExplodeProcessor(cfg ProcessorConfig) (Processor, error) {
+	fieldName, ok := cfg.Config["field_name"].(string)
+	if !ok || fieldName == "" {
+		return nil, errors.New("missing or invalid 'field_name' parameter")
+	}
+	return &ExplodeProcessor{logger: cfg.logger, fieldName: fieldName}, nil
+}
+
+func (p *ExplodeProcessor) Name() string {
+	return ProcessorTypeExplode
+}
+
+// Process can't return several messages, explode only runs through ProcessMulti
+func (p *ExplodeProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	return nil, errors.New("explode emits several messages and requires the one-to-many pipeline (ProcessMulti)")
+}
+
+func (p *ExplodeProcessor) ProcessMulti(ctx context.Context, msg *consumer.Message) ([]*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	if !ok || val == nil {
+		return []*consumer.Message{msg}, nil
+	}
+
+	entries, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("explode field %q must be a map, got %T", p.fieldName, val)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]*consumer.Message, 0, len(keys))
+	for _, key := range keys {
+		fields := deepCopy(msg.ValueFields).(map[string]interface{})
+		deletePath(fields, p.fieldName)
+		fields[ExplodeKeyField] = key
+		fields[ExplodeValueField] = deepCopy(entries[key])
+
+		emitted := *msg
+		emitted.ValueFields = fields
+		emitted.Headers = make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			emitted.Headers[k] = v
+		}
+		out = append(out, &emitted)
+	}
+	return out, nil
+}
+
+// ProjectProcessor reshapes the value fields, either keeping only the include dot-paths or removing the exclude ones.
+// A dot-path selects a whole subtree, e.g. "user.address" keeps or removes every field under it.
+// Paths missing from a message are ignored.
+type ProjectProcessor struct {
+	logger  *slog.Logger
+	include []string
+	exclude []string
+}
+
+func NewProjectProcessor(cfg ProcessorConfig) (Processor, error) {
+	include, hasInclude := cfg.Config["include"]
+	exclude, hasExclude := cfg.Config["exclude"]
+	if hasInclude == hasExclude {
+		return nil, errors.New("exactly one of 'include' or 'exclude' must be set")
+	}
+
+	list := include
+	if hasExclude {
+		list = exclude
+	}
+	items, _ := list.([]interface{})
+	if len(items) == 0 {
+		return nil, errors.New("'include' or 'exclude' must be a non empty list of fields")
+	}
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid project field: %v", item)
+		}
+		paths = append(paths, path)
+	}
+
+	processor := &ProjectProcessor{logger: cfg.logger}
+	if hasInclude {
+		processor.include = paths
+	} else {
+		processor.exclude = paths
+	}
+	return processor, nil
+}
+
+func (p *ProjectProcessor) Name() string {
+	return ProcessorTypeProject
+}
+
+func (p *ProjectProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	if p.include != nil {
+		projected := make(map[string]interface{})
+		for _, path := range p.include {
+			if val, ok := getPath(msg.ValueFields, path); ok {
+				if err := setPath(projected, path, val); err != nil {
+					return nil, err
+				}
+			}
+		}
+		msg.ValueFields = projected
+		return msg, nil
+	}
+
+	for _, path := range p.exclude {
+		deletePath(msg.ValueFields, path)
+	}
+	return msg, nil
+}
+
+// Modes of the empty_to_null processor
+const (
+	EmptyModeNullify = "nullify" // The empty string becomes null
+	EmptyModeRemove  = "remove"  // The field is removed
+)
+
+// EmptyToNullProcessor converts the fields holding an empty string into null, or removes them.
+// Without fields, every string field is converted, nested objects included (arrays are left untouched).
+type EmptyToNullProcessor struct {
+	logger *slog.Logger
+	fields []string // Dot-paths, every field when empty
+	remove bool
+}
+
+func NewEmptyToNullProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &EmptyToNullProcessor{logger: cfg.logger}
+
+	if val, ok := cfg.Config["fields"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, errors.New("'fields' must be a non empty list of fields")
+		}
+		for _, item := range items {
+			path, ok := item.(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("invalid empty_to_null field: %v", item)
+			}
+			processor.fields = append(processor.fields, path)
+		}
+	}
+
+	if mode, ok := cfg.Config["mode"]; ok {
+		switch mode {
+		case EmptyModeNullify:
+		case EmptyModeRemove:
+			processor.remove = true
+		default:
+			return nil, fmt.Errorf("invalid empty_to_null mode: %v", mode)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *EmptyToNullProcessor) Name() string {
+	return ProcessorTypeEmptyToNull
+}
+
+func (p *EmptyToNullProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	if len(p.fields) == 0 {
+		p.clean(msg.ValueFields)
+		return msg, nil
+	}
+
+	for _, path := range p.fields {
+		if val, ok := getPath(msg.ValueFields, path); !ok || val != "" {
+			continue
+		}
+		if p.remove {
+			deletePath(msg.ValueFields, path)
+		} else if err := setPath(msg.ValueFields, path, nil); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// clean converts the empty strings of the object and its nested objects
+func (p *EmptyToNullProcessor) clean(fields map[string]interface{}) {
+	for key, val := range fields {
+		switch v := val.(type) {
+		case string:
+			if v != "" {
+				continue
+			}
+			if p.remove {
+				delete(fields, key)
+			} else {
+				fields[key] = nil
+			}
+		case map[string]interface{}:
+			p.clean(v)
+		}
+	}
+}
+
+// Field types of the schema processor
+const (
+	SchemaTypeString = "string"
+	SchemaTypeInt    = "int"
+	SchemaTypeFloat  = "float"
+	SchemaTypeBool   = "bool"
+	SchemaTypeObject = "object"
+	SchemaTypeArray  = "array"
+)
+
+var validSchemaTypes = map[string]bool{
+	SchemaTypeString: true,
+	SchemaTypeInt:    true,
+	SchemaTypeFloat:  true,
+	SchemaTypeBool:   true,
+	SchemaTypeObject: true,
+	SchemaTypeArray:  true,
+}
+
+// Modes of the schema processor
+const (
+	SchemaModeReject = "reject" // A mismatching field fails the message
+	SchemaModeCoerce = "coerce" // A mismatching field is converted, the message failing when it can't be
+)
+
+// SchemaProcessor checks the type of the schema fields, a lightweight type gate without the weight of JSON Schema.
+// A mismatch is returned as an error, handled by the errors policy. Missing and null fields are accepted,
+// see field_exists to require them. An integral float is an int, and any number a float: with json_numbers
+// float64 every number is decoded as float.
+type SchemaProcessor struct {
+	logger *slog.Logger
+	fields []string          // Dot-paths, sorted for a stable error on several mismatches
+	types  map[string]string // Expected type by dot-path
+	coerce bool
+}
+
+func NewSchemaProcessor(cfg ProcessorConfig) (Processor, error) {
+	schema, ok := cfg.Config["schema"].(map[string]interface{})
+	if !ok || len(schema) == 0 {
+		return nil, errors.New("missing or invalid 'schema' parameter")
+	}
+
+	processor := &SchemaProcessor{logger: cfg.logger, types: make(map[string]string, len(schema))}
+	for field, val := range schema {
+		fieldType, _ := val.(string)
+		if field == "" || !validSchemaTypes[fieldType] {
+			return nil, fmt.Errorf("invalid schema type for field %q: %v", field, val)
+		}
+		processor.fields = append(processor.fields, field)
+		processor.types[field] = fieldType
+	}
+	sort.Strings(processor.fields)
+
+	if mode, ok := cfg.Config["mode"]; ok {
+		switch mode {
+		case SchemaModeReject:
+		case SchemaModeCoerce:
+			processor.coerce = true
+		default:
+			return nil, fmt.Errorf("invalid schema mode: %v", mode)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *SchemaProcessor) Name() string {
+	return ProcessorTypeSchema
+}
+
+func (p *SchemaProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	for _, field := range p.fields {
+		val, ok := getPath(msg.ValueFields, field)
+		if !ok || val == nil || matchesSchemaType(val, p.types[field]) {
+			continue
+		}
+		if !p.coerce {
+			return nil, fmt.Errorf("field %q: expected %s, got %s", field, p.types[field], schemaTypeOf(val))
+		}
+
+		coerced, ok := coerceSchemaType(val, p.types[field])
+		if !ok {
+			return nil, fmt.Errorf("field %q: cannot coerce %s %v to %s", field, schemaTypeOf(val), val, p.types[field])
+		}
+		if err := setPath(msg.ValueFields, field, coerced); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// matchesSchemaType reports whether the decoded value has the schema type
+func matchesSchemaType(val interface{}, fieldType string) bool {
+	switch fieldType {
+	case SchemaTypeString:
+		_, ok := val.(string)
+		return ok
+	case SchemaTypeInt:
+		switch v := val.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return v == math.Trunc(v) && !math.IsInf(v, 0)
+		}
+		return false
+	case SchemaTypeFloat:
+		_, ok := toFloat(val)
+		return ok
+	case SchemaTypeBool:
+		_, ok := val.(bool)
+		return ok
+	case SchemaTypeObject:
+		_, ok := val.(map[string]interface{})
+		return ok
+	case SchemaTypeArray:
+		_, ok := val.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// coerceSchemaType converts a scalar to the schema type: numbers and booleans to string, and strings to numbers
+// or booleans when they parse. Objects and arrays are never coerced.
+func coerceSchemaType(val interface{}, fieldType string) (interface{}, bool) {
+	switch fieldType {
+	case SchemaTypeString:
+		switch v := val.(type) {
+		case bool:
+			return strconv.FormatBool(v), true
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case int, int64, uint64:
+			return fmt.Sprint(v), true
+		}
+	case SchemaTypeInt:
+		switch v := val.(type) {
+		case string:
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			return n, err == nil
+		case float64:
+			// A fractional float is rejected rather than silently truncated
+			return nil, false
+		}
+	case SchemaTypeFloat:
+		if v, ok := val.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+		}
+	case SchemaTypeBool:
+		if v, ok := val.(string); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			return b, err == nil
+		}
+	}
+	return nil, false
+}
+
+// schemaTypeOf names the schema type of a decoded value, for the mismatch errors
+func schemaTypeOf(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return SchemaTypeString
+	case int, int64, uint64:
+		return SchemaTypeInt
+	case float64:
+		if matchesSchemaType(v, SchemaTypeInt) {
+			return SchemaTypeInt
+		}
+		return SchemaTypeFloat
+	case bool:
+		return SchemaTypeBool
+	case map[string]interface{}:
+		return SchemaTypeObject
+	case []interface{}:
+		return SchemaTypeArray
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+// RenameKeysProcessor renames every top-level field key matching pattern, the matches being replaced by replacement
+// (regexp.ReplaceAllString syntax, $1 expanding to the first group). The replaced text can be upper or lower cased,
+// e.g. pattern "_(\w)", replacement "$1" and replacement_case "upper" turn snake_case keys into camelCase.
+// Two keys ending up with the same name are an error, handled by the errors policy.
+type RenameKeysProcessor struct {
+	logger      *slog.Logger
+	pattern     *regexp.Regexp
+	replacement string
+	convertCase func(string) string // nil to keep the replaced text as is
+}
+
+func NewRenameKeysProcessor(cfg ProcessorConfig) (Processor, error) {
+	pattern, _ := cfg.Config["pattern"].(string)
+	if pattern == "" {
+		return nil, errors.New("missing or invalid 'pattern' parameter")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'pattern': %w", err)
+	}
+	replacement, ok := cfg.Config["replacement"].(string)
+	if !ok {
+		return nil, errors.New("missing or invalid 'replacement' parameter")
+	}
+
+	processor := &RenameKeysProcessor{logger: cfg.logger, pattern: re, replacement: replacement}
+	if val, ok := cfg.Config["replacement_case"]; ok {
+		switch val {
+		case "upper":
+			processor.convertCase = strings.ToUpper
+		case "lower":
+			processor.convertCase = strings.ToLower
+		default:
+			return nil, fmt.Errorf("invalid replacement_case: %v", val)
+		}
+	}
+	return processor, nil
+}
+
+func (p *RenameKeysProcessor) Name() string {
+	return ProcessorTypeRenameKeys
+}
+
+func (p *RenameKeysProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	// Sorted keys make the collision error deterministic
+	keys := make([]string, 0, len(msg.ValueFields))
+	for key := range msg.ValueFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	renamed := make(map[string]interface{}, len(msg.ValueFields))
+	sources := make(map[string]string, len(msg.ValueFields))
+	for _, key := range keys {
+		name := p.rename(key)
+		if source, exists := sources[name]; exists {
+			return nil, fmt.Errorf("keys %q and %q are both renamed to %q", source, key, name)
+		}
+		sources[name] = key
+		renamed[name] = msg.ValueFields[key]
+	}
+	msg.ValueFields = renamed
+	return msg, nil
+}
+
+// rename applies the replacement to every match of the key
+func (p *RenameKeysProcessor) rename(key string) string {
+	if p.convertCase == nil {
+		return p.pattern.ReplaceAllString(key, p.replacement)
+	}
+	var renamed []byte
+	last := 0
+	for _, match := range p.pattern.FindAllStringSubmatchIndex(key, -1) {
+		renamed = append(renamed, key[last:match[0]]...)
+		renamed = append(renamed, p.convertCase(string(p.pattern.ExpandString(nil, p.replacement, key, match)))...)
+		last = match[1]
+	}
+	return string(append(renamed, key[last:]...))
+}
+
+// Generators of the generate_id processor
+const (
+	IDGeneratorUUID     = "uuid"     // Random UUID v4, as a string
+	IDGeneratorSequence = "sequence" // Increasing int64 starting at 1, restarting with the process
+)
+
+// IDProcessor writes a generated ID into the target field of the messages lacking one, e.g. for downstream deduplication.
+// The sequence is shared by the workers, each message getting a distinct value, but the order of the values
+// only follows the processing order of the messages, not their offsets.
+type IDProcessor struct {
+	logger      *slog.Logger
+	targetField string
+	sequence    bool
+	overwrite   bool
+	next        atomic.Int64 // Last value of the sequence
+}
+
+func NewIDProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &IDProcessor{logger: cfg.logger}
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	if generator, ok := cfg.Config["generator"]; ok {
+		switch generator {
+		case IDGeneratorUUID:
+		case IDGeneratorSequence:
+			processor.sequence = true
+		default:
+			return nil, fmt.Errorf("invalid generate_id generator: %v", generator)
+		}
+	}
+	processor.overwrite, _ = cfg.Config["overwrite"].(bool)
+
+	return processor, nil
+}
+
+func (p *IDProcessor) Name() string {
+	return ProcessorTypeGenerateID
+}
+
+func (p *IDProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		msg.ValueFields = make(map[string]interface{})
+	}
+	if val, exists := getPath(msg.ValueFields, p.targetField); exists && val != nil && !p.overwrite {
+		return msg, nil
+	}
+
+	var id interface{}
+	if p.sequence {
+		id = p.next.Add(1)
+	} else {
+		uuid, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		id = uuid
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, id); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// newUUID returns a random (version 4) UUID in its canonical form
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// KeyCaseProcessor upper or lower cases the top-level field keys, all of them or the listed ones, e.g. to merge
+// sources with inconsistent naming conventions. The values are left untouched.
+// Two keys ending up with the same name (e.g. "id" and "ID") are an error, handled by the errors policy.
+type KeyCaseProcessor struct {
+	logger      *slog.Logger
+	convertCase func(string) string
+	fields      map[string]bool // Keys converted, nil for every key
+}
+
+func NewKeyCaseProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &KeyCaseProcessor{logger: cfg.logger}
+	switch mode := cfg.Config["mode"]; mode {
+	case "upper":
+		processor.convertCase = strings.ToUpper
+	case "lower":
+		processor.convertCase = strings.ToLower
+	default:
+		return nil, fmt.Errorf("invalid key_case mode: %v", mode)
+	}
+
+	if val, ok := cfg.Config["fields"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, errors.New("'fields' must be a non empty list of keys")
+		}
+		processor.fields = make(map[string]bool, len(items))
+		for _, item := range items {
+			key, ok := item.(string)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid key_case field: %v", item)
+			}
+			processor.fields[key] = true
+		}
+	}
+	return processor, nil
+}
+
+func (p *KeyCaseProcessor) Name() string {
+	return ProcessorTypeKeyCase
+}
+
+func (p *KeyCaseProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	// Sorted keys make the collision error deterministic
+	keys := make([]string, 0, len(msg.ValueFields))
+	for key := range msg.ValueFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	converted := make(map[string]interface{}, len(msg.ValueFields))
+	sources := make(map[string]string, len(msg.ValueFields))
+	for _, key := range keys {
+		name := key
+		if p.fields == nil || p.fields[key] {
+			name = p.convertCase(key)
+		}
+		if source, exists := sources[name]; exists {
+			return nil, fmt.Errorf("keys %q and %q both end up as %q", source, key, name)
+		}
+		sources[name] = key
+		converted[name] = msg.ValueFields[key]
+	}
+	msg.ValueFields = converted
+	return msg, nil
+}
+
+// HeadersObjectProcessor mirrors the message headers into an object field of the value, for the consumers
+// that can't read headers, or writes the fields of such an object back into the headers.
+// to_field replaces the object with the headers (all of them, or the listed ones), leaving the message unchanged
+// without any. to_headers writes each field of the object (the listed ones) into the header of the same name,
+// strings as is and other values JSON encoded; a target field that is not an object is an error.
+type HeadersObjectProcessor struct {
+	logger      *slog.Logger
+	direction   HeaderFieldDirection
+	targetField string
+	headers     map[string]bool // Headers copied, nil for every header
+}
+
+func NewHeadersObjectProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &HeadersObjectProcessor{logger: cfg.logger}
+
+	direction, _ := cfg.Config["direction"].(string)
+	processor.direction = HeaderFieldDirection(direction)
+	if processor.direction != DirectionToField && processor.direction != DirectionToHeaders {
+		return nil, errors.New("invalid headers_object direction: " + direction)
+	}
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	if val, ok := cfg.Config["headers"]; ok {
+		items, ok := val.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, errors.New("'headers' must be a non empty list of header names")
+		}
+		processor.headers = make(map[string]bool, len(items))
+		for _, item := range items {
+			name, ok := item.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid headers_object header: %v", item)
+			}
+			processor.headers[name] = true
+		}
+	}
+	return processor, nil
+}
+
+func (p *HeadersObjectProcessor) Name() string {
+	return ProcessorTypeHeadersObject
+}
+
+func (p *HeadersObjectProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if p.direction == DirectionToField {
+		object := make(map[string]interface{})
+		for name, value := range msg.Headers {
+			if p.headers == nil || p.headers[name] {
+				object[name] = value
+			}
+		}
+		if len(object) == 0 {
+			return msg, nil
+		}
+		if msg.ValueFields == nil {
+			msg.ValueFields = make(map[string]interface{})
+		}
+		if err := setPath(msg.ValueFields, p.targetField, object); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	val, ok := getPath(msg.ValueFields, p.targetField)
+	if !ok || val == nil {
+		return msg, nil
+	}
+	object, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("field %q is not an object: %T", p.targetField, val)
+	}
+	for name, value := range object {
+		if p.headers != nil && !p.headers[name] {
+			continue
+		}
+		header, ok := value.(string)
+		if !ok {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field %q as header: %w", name, err)
+			}
+			header = string(encoded)
+		}
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		msg.Headers[name] = header
+	}
+	return msg, nil
+}
+
+// FieldLimitsProcessor rejects the messages whose fields are nested deeper than max_depth or number more than
+// max_fields, e.g. to protect the stateful processors and the output from pathological payloads.
+// The top-level fields are at depth 1, an object or array value adding a level. The fields are counted at every level,
+// the array elements being counted as levels but not as fields. The payload is walked iteratively, so that
+// an adversarial nesting can't exhaust the stack, and the walk stops at the first limit exceeded.
+// A rejected message is an error, handled by the errors policy.
+type FieldLimitsProcessor struct {
+	logger    *slog.Logger
+	maxDepth  int // 0 means no limit
+	maxFields int // 0 means no limit
+}
+
+func NewFieldLimitsProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &FieldLimitsProcessor{logger: cfg.logger}
+
+	for key, limit := range map[string]*int{"max_depth": &processor.maxDepth, "max_fields": &processor.maxFields} {
+		val, ok := cfg.Config[key]
+		if !ok {
+			continue
+		}
+		n, ok := toFloat(val)
+		if !ok || n < 1 || n != float64(int64(n)) {
+			return nil, fmt.Errorf("'%s' must be a positive integer, got: %v", key, val)
+		}
+		*limit = int(n)
+	}
+	if processor.maxDepth == 0 && processor.maxFields == 0 {
+		return nil, errors.New("field_limits requires 'max_depth' or 'max_fields'")
+	}
+	return processor, nil
+}
+
+func (p *FieldLimitsProcessor) Name() string {
+	return ProcessorTypeFieldLimits
+}
+
+func (p *FieldLimitsProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	type level struct {
+		value interface{}
+		depth int
+	}
+
+	fields := 0
+	stack := []level{{value: msg.ValueFields, depth: 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var children []interface{}
+		switch v := current.value.(type) {
+		case map[string]interface{}:
+			fields += len(v)
+			if p.maxFields > 0 && fields > p.maxFields {
+				return nil, fmt.Errorf("message has more than %d fields", p.maxFields)
+			}
+			for _, child := range v {
+				children = append(children, child)
+			}
+		case []interface{}:
+			children = v
+		}
+
+		if len(children) == 0 {
+			continue
+		}
+		if p.maxDepth > 0 && current.depth >= p.maxDepth {
+			return nil, fmt.Errorf("message is nested deeper than %d levels", p.maxDepth)
+		}
+		for _, child := range children {
+			stack = append(stack, level{value: child, depth: current.depth + 1})
+		}
+	}
+	return msg, nil
+}
+
+// CoalesceProcessor writes into target_field the first of source_fields present with a non-null value, e.g. to read
+// a datum named differently across the versions of a message. The fields accept dot-paths into nested objects.
+// Without any, target_field is set to default when configured, left as is otherwise.
+// The target can be one of the sources, keeping its value when it comes first.
+type CoalesceProcessor struct {
+	logger       *slog.Logger
+	sourceFields []string
+	targetField  string
+	defaultValue interface{}
+	hasDefault   bool
+}
+
+func NewCoalesceProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &CoalesceProcessor{
+		logger: cfg.logger,
+	}
+
+	sources, _ := cfg.Config["source_fields"].([]interface{})
+	for _, source := range sources {
+		field, ok := source.(string)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid source field: %v", source)
+		}
+		processor.sourceFields = append(processor.sourceFields, field)
+	}
+	if len(processor.sourceFields) == 0 {
+		return nil, errors.New("coalesce requires at least one of 'source_fields'")
+	}
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	processor.defaultValue, processor.hasDefault = cfg.Config["default"]
+
+	return processor, nil
+}
+
+func (p *CoalesceProcessor) Name() string {
+	return ProcessorTypeCoalesce
+}
+
+func (p *CoalesceProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	var val interface{}
+	found := false
+	for _, field := range p.sourceFields {
+		if v, ok := getPath(msg.ValueFields, field); ok && v != nil {
+			if field == p.targetField {
+				return msg, nil
+			}
+			val, found = deepCopy(v), true
+			break
+		}
+	}
+	if !found {
+		if !p.hasDefault {
+			return msg, nil
+		}
+		// Copied for each message, the processors down the chain may modify it
+		val = deepCopy(p.defaultValue)
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, val); err != nil {
+		p.logger.Error("CoalesceProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// DedupAdjacentProcessor drops a message identical to the previous one of its partition, e.g. repeated heartbeats
+// or snapshots, without the time window of a full deduplication. Messages are compared by the value of key_field
+// (a dot-path) when set, by their whole value otherwise. A message lacking key_field is never dropped and resets
+// the comparison. Only the last value of each partition is kept, in its JSON form.
+// The previous message is the previous one processed: each partition is processed in order by a single worker,
+// except with the ordered output spreading them over every worker.
+type DedupAdjacentProcessor struct {
+	logger   *slog.Logger
+	keyField string // Whole value when empty
+
+	mu   sync.Mutex
+	last map[partitionKey]string
+}
+
+func NewDedupAdjacentProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &DedupAdjacentProcessor{
+		logger: cfg.logger,
+		last:   make(map[partitionKey]string),
+	}
+
+	if val, ok := cfg.Config["key_field"]; ok {
+		processor.keyField, _ = val.(string)
+		if processor.keyField == "" {
+			return nil, fmt.Errorf("invalid dedup_adjacent key_field: %v", val)
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *DedupAdjacentProcessor) Name() string {
+	return ProcessorTypeDedupAdjacent
+}
+
+func (p *DedupAdjacentProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+
+	var compared interface{} = msg.ValueFields
+	if p.keyField != "" {
+		val, ok := getPath(msg.ValueFields, p.keyField)
+		if !ok {
+			p.mu.Lock()
+			delete(p.last, key)
+			p.mu.Unlock()
+			return msg, nil
+		}
+		compared = val
+	}
+	encoded, err := json.Marshal(compared)
+	if err != nil {
+		return nil, fmt.Errorf("dedup_adjacent: failed to encode the compared value: %w", err)
+	}
+
+	p.mu.Lock()
+	last, seen := p.last[key]
+	p.last[key] = string(encoded)
+	p.mu.Unlock()
+
+	if seen && last == string(encoded) {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// epochUnits are the units of the epoch_convert timestamps, with the RFC3339 layout of their precision
+var epochUnits = map[string]struct {
+	unit   time.Duration
+	layout string
+}{
+	"s":  {time.Second, time.RFC3339},
+	"ms": {time.Millisecond, "2006-01-02T15:04:05.000Z07:00"},
+	"us": {time.Microsecond, "2006-01-02T15:04:05.000000Z07:00"},
+	"ns": {time.Nanosecond, "2006-01-02T15:04:05.000000000Z07:00"},
+}
+
+// The epoch seconds of the RFC3339 timestamps range, from the year 0 to the year 9999
+const (
+	minEpochSeconds = -62167219200
+	maxEpochSeconds = 253402300799
+)
+
+// EpochConvertProcessor converts field_name between a Unix epoch number in unit (s, ms, us or ns) and an RFC3339
+// string, into target_field (field_name itself by default). With to_rfc3339 the string is in UTC with the precision
+// of the unit, e.g. "2026-01-02T03:04:05.678Z" in ms, and with to_epoch the number is an integer, the precision
+// beyond the unit being truncated. A message without the field is left untouched, a value of the wrong type or out
+// of range (years 0 to 9999, and the int64 range of the epoch) is an error.
+type EpochConvertProcessor struct {
+	logger      *slog.Logger
+	fieldName   string
+	targetField string
+	toEpoch     bool
+	unit        time.Duration
+	layout      string
+}
+
+func NewEpochConvertProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &EpochConvertProcessor{
+		logger: cfg.logger,
+	}
+
+	processor.fieldName, _ = cfg.Config["field_name"].(string)
+	if processor.fieldName == "" {
+		return nil, errors.New("missing or invalid 'field_name' parameter")
+	}
+	processor.targetField = processor.fieldName
+	if val, ok := cfg.Config["target_field"]; ok {
+		processor.targetField, _ = val.(string)
+		if processor.targetField == "" {
+			return nil, errors.New("invalid 'target_field' parameter")
+		}
+	}
+
+	switch direction := cfg.Config["direction"]; direction {
+	case "to_rfc3339":
+	case "to_epoch":
+		processor.toEpoch = true
+	default:
+		return nil, fmt.Errorf("invalid epoch_convert direction: %v", direction)
+	}
+
+	unitName := "ms"
+	if val, ok := cfg.Config["unit"]; ok {
+		unitName, _ = val.(string)
+	}
+	unit, ok := epochUnits[unitName]
+	if !ok {
+		return nil, fmt.Errorf("invalid epoch_convert unit: %v", cfg.Config["unit"])
+	}
+	processor.unit, processor.layout = unit.unit, unit.layout
+
+	return processor, nil
+}
+
+func (p *EpochConvertProcessor) Name() string {
+	return ProcessorTypeEpochConvert
+}
+
+func (p *EpochConvertProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	if !ok {
+		return msg, nil
+	}
+
+	var converted interface{}
+	var err error
+	if p.toEpoch {
+		converted, err = p.epoch(val)
+	} else {
+		converted, err = p.rfc3339(val)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("epoch_convert field %q: %w", p.fieldName, err)
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, converted); err != nil {
+		p.logger.Error("EpochConvertProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// rfc3339 formats the epoch number, the integers being converted without going through a float to keep their precision
+func (p *EpochConvertProcessor) rfc3339(val interface{}) (string, error) {
+	perSecond := int64(time.Second / p.unit)
+
+	var seconds, nanos int64
+	switch v := val.(type) {
+	case int, int64, uint64:
+		n, ok := toInt64(v)
+		if !ok {
+			return "", fmt.Errorf("epoch %v out of range", v)
+		}
+		seconds, nanos = n/perSecond, n%perSecond*int64(p.unit)
+	case float64:
+		s := math.Floor(v / float64(perSecond))
+		if math.IsNaN(s) || s < minEpochSeconds || s > maxEpochSeconds {
+			return "", fmt.Errorf("epoch %v out of range", v)
+		}
+		seconds, nanos = int64(s), int64((v-s*float64(perSecond))*float64(p.unit))
+	default:
+		return "", fmt.Errorf("expected an epoch number, got %T", val)
+	}
+
+	if seconds < minEpochSeconds || seconds > maxEpochSeconds {
+		return "", fmt.Errorf("epoch %v out of range", val)
+	}
+	return time.Unix(seconds, nanos).UTC().Format(p.layout), nil
+}
+
+// epoch parses the RFC3339 string into an epoch number in unit
+func (p *EpochConvertProcessor) epoch(val interface{}) (int64, error) {
+	str, ok := val.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected an RFC3339 string, got %T", val)
+	}
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return 0, err
+	}
+
+	perSecond := int64(time.Second / p.unit)
+	seconds := t.Unix()
+	if seconds > math.MaxInt64/perSecond || seconds < math.MinInt64/perSecond+1 {
+		return 0, fmt.Errorf("%s out of the epoch range in %s", str, p.unit)
+	}
+	return seconds*perSecond + int64(t.Nanosecond())/int64(p.unit), nil
+}
+
+// NormalizeProcessor canonicalizes the contact value of field_name in place, by kind:
+//   - email : trimmed and lowercased, then checked to be a bare address (e.g. "Jane.Doe@Example.com " becomes "jane.doe@example.com")
+//   - phone : parsed and checked against the numbering plan of its country with github.com/nyaruka/phonenumbers,
+//     then formatted in E.164, e.g. "0033 6 12-34-56-78" becomes "+33612345678". The international prefix is written
+//     "+" or "00", a national number without it being parsed in the country of default_country_code.
+//
+// An invalid value (or not a string) is an error, or with flag_field is left as is and flag_field is set to true,
+// flag_field being false for the normalized values. A message without the field is left untouched.
+type NormalizeProcessor struct {
+	logger        *slog.Logger
+	fieldName     string
+	phone         bool
+	defaultRegion string // Region of default_country_code, the national numbers being invalid without it
+	flagField     string // Empty when the invalid values are errors
+}
+
+func NewNormalizeProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &NormalizeProcessor{
+		logger: cfg.logger,
+	}
+
+	processor.fieldName, _ = cfg.Config["field_name"].(string)
+	if processor.fieldName == "" {
+		return nil, errors.New("missing or invalid 'field_name' parameter")
+	}
+
+	switch kind := cfg.Config["kind"]; kind {
+	case "email":
+	case "phone":
+		processor.phone = true
+	default:
+		return nil, fmt.Errorf("invalid normalize kind: %v", kind)
+	}
+
+	if val, ok := cfg.Config["default_country_code"]; ok {
+		code, _ := val.(string)
+		if processor.defaultRegion = countryCodeRegion(code); processor.defaultRegion == "" {
+			return nil, fmt.Errorf("invalid 'default_country_code' parameter: %v", val)
+		}
+	}
+
+	if val, ok := cfg.Config["flag_field"]; ok {
+		processor.flagField, _ = val.(string)
+		if processor.flagField == "" {
+			return nil, errors.New("invalid 'flag_field' parameter")
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *NormalizeProcessor) Name() string {
+	return ProcessorTypeNormalize
+}
+
+func (p *NormalizeProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	if !ok {
+		return msg, nil
+	}
+
+	var normalized string
+	var err error
+	str, ok := val.(string)
+	switch {
+	case !ok:
+		err = fmt.Errorf("expected a string, got %T", val)
+	case p.phone:
+		normalized, err = p.normalizePhone(str)
+	default:
+		normalized, err = normalizeEmail(str)
+	}
+
+	if err != nil && p.flagField == "" {
+		return nil, fmt.Errorf("normalize field %q: %w", p.fieldName, err)
+	}
+	if err == nil {
+		if err := setPath(msg.ValueFields, p.fieldName, normalized); err != nil {
+			p.logger.Error("NormalizeProcessor: failed to write field", "field_name", p.fieldName, "error", err)
+			return nil, err
+		}
+	}
+	if p.flagField != "" {
+		if err := setPath(msg.ValueFields, p.flagField, err != nil); err != nil {
+			p.logger.Error("NormalizeProcessor: failed to write flag field", "flag_field", p.flagField, "error", err)
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// normalizeEmail returns the trimmed and lowercased address, which must be bare (no display name nor comment)
+func normalizeEmail(email string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(normalized)
+	if err != nil || addr.Address != normalized {
+		return "", fmt.Errorf("invalid email address %q", email)
+	}
+	return normalized, nil
+}
+
+// normalizePhone returns the number in the E.164 format, see NormalizeProcessor
+func (p *NormalizeProcessor) normalizePhone(phone string) (string, error) {
+	number := strings.TrimSpace(phone)
+	if strings.HasPrefix(number, "00") {
+		number = "+" + number[2:]
+	}
+
+	parsed, err := phonenumbers.Parse(number, p.defaultRegion)
+	if err != nil {
+		return "", fmt.Errorf("invalid phone number %q: %w", phone, err)
+	}
+	if !phonenumbers.IsValidNumber(parsed) {
+		return "", fmt.Errorf("invalid phone number %q", phone)
+	}
+	return phonenumbers.Format(parsed, phonenumbers.E164), nil
+}
+
+// countryCodeRegion returns the main region of a country calling code (e.g. "FR" for "33"), empty when not assigned
+func countryCodeRegion(code string) string {
+	if len(code) < 1 || len(code) > 3 || code[0] == '0' || strings.Trim(code, "0123456789") != "" {
+		return ""
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return ""
+	}
+	if region := phonenumbers.GetRegionCodeForCountryCode(n); region != phonenumbers.UNKNOWN_REGION {
+		return region
+	}
+	return ""
+}
+
+// fingerprintHashes are the algorithms of the fingerprint processor
+var fingerprintHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+	"fnv64a": func() hash.Hash { return fnv.New64a() },
+}
+
+// FingerprintProcessor writes into target_field (default "_fingerprint") the hex hash of the fields, e.g. for a
+// downstream dedup to key on it. The fields (dot-paths) are hashed as a JSON object keyed by path, the keys of
+// every object being sorted, so that the fingerprint doesn't depend on the fields order. A missing field is left out,
+// a null one is not. Without fields the whole value is hashed, but for target_field so that it is stable once set.
+// Integers and floats encode alike when equal (1 and 1.0), a NaN or Inf float is an error.
+type FingerprintProcessor struct {
+	logger      *slog.Logger
+	fields      []string // Every field when empty
+	newHash     func() hash.Hash
+	targetField string
+}
+
+func NewFingerprintProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &FingerprintProcessor{
+		logger:      cfg.logger,
+		targetField: "_fingerprint",
+	}
+
+	if val, ok := cfg.Config["fields"]; ok {
+		fields, _ := val.([]interface{})
+		for _, f := range fields {
+			field, ok := f.(string)
+			if !ok || field == "" {
+				return nil, fmt.Errorf("invalid fingerprint field: %v", f)
+			}
+			processor.fields = append(processor.fields, field)
+		}
+		if len(processor.fields) == 0 {
+			return nil, errors.New("invalid 'fields' parameter, omit it to fingerprint every field")
+		}
+	}
+
+	algorithm := "sha256"
+	if val, ok := cfg.Config["algorithm"]; ok {
+		algorithm, _ = val.(string)
+	}
+	processor.newHash = fingerprintHashes[algorithm]
+	if processor.newHash == nil {
+		return nil, fmt.Errorf("invalid fingerprint algorithm: %v", cfg.Config["algorithm"])
+	}
+
+	if val, ok := cfg.Config["target_field"]; ok {
+		processor.targetField, _ = val.(string)
+		if processor.targetField == "" {
+			return nil, errors.New("invalid 'target_field' parameter")
+		}
+	}
+
+	return processor, nil
+}
+
+func (p *FingerprintProcessor) Name() string {
+	return ProcessorTypeFingerprint
+}
+
+func (p *FingerprintProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	var hashed map[string]interface{}
+	if len(p.fields) == 0 {
+		hashed = msg.ValueFields
+		if _, ok := getPath(hashed, p.targetField); ok {
+			hashed = deepCopy(hashed).(map[string]interface{})
+			deletePath(hashed, p.targetField)
+		}
+	} else {
+		hashed = make(map[string]interface{}, len(p.fields))
+		for _, field := range p.fields {
+			if val, ok := getPath(msg.ValueFields, field); ok {
+				hashed[field] = val
+			}
+		}
+	}
+
+	// encoding/json sorts the keys of the maps
+	encoded, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint: %w", err)
+	}
+	h := p.newHash()
+	h.Write(encoded)
+
+	if err := setPath(msg.ValueFields, p.targetField, hex.EncodeToString(h.Sum(nil))); err != nil {
+		p.logger.Error("FingerprintProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// TemplateProcessor renders the Go text/template template against the value fields into target_field (a string),
+// e.g. "User {{.name}} from {{.country}}". The template is compiled once, a compilation error failing the creation.
+// With missing "empty" (default) a missing or null field renders as an empty string (text/template writes
+// "<no value>" otherwise), with "error" a missing field fails the message, handled by the errors policy.
+type TemplateProcessor struct {
+	logger      *slog.Logger
+	template    *template.Template
+	targetField string
+}
+
+// templateEmptyFunc is appended to the pipelines of the printed actions with missing "empty", see emptyMissingValues
+const templateEmptyFunc = "_missingAsEmpty"
+
+func NewTemplateProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &TemplateProcessor{
+		logger: cfg.logger,
+	}
+
+	text, ok := cfg.Config["template"].(string)
+	if !ok || text == "" {
+		return nil, errors.New("missing or invalid 'template' parameter")
+	}
+
+	processor.targetField, _ = cfg.Config["target_field"].(string)
+	if processor.targetField == "" {
+		return nil, errors.New("missing or invalid 'target_field' parameter")
+	}
+
+	missing := "empty"
+	if val, ok := cfg.Config["missing"]; ok {
+		missing, _ = val.(string)
+	}
+
+	tmpl := template.New(ProcessorTypeTemplate).Funcs(template.FuncMap{templateEmptyFunc: emptyIfNil})
+	switch missing {
+	case "empty":
+	case "error":
+		tmpl = tmpl.Option("missingkey=error")
+	default:
+		return nil, fmt.Errorf("invalid template missing value: %v", cfg.Config["missing"])
+	}
+
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if missing == "empty" {
+		for _, t := range tmpl.Templates() {
+			emptyMissingValues(t.Tree.Root)
+		}
+	}
+	processor.template = tmpl
+
+	return processor, nil
+}
+
+func (p *TemplateProcessor) Name() string {
+	return ProcessorTypeTemplate
+}
+
+func (p *TemplateProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	if msg.ValueFields == nil {
+		return msg, nil
+	}
+
+	var rendered strings.Builder
+	if err := p.template.Execute(&rendered, msg.ValueFields); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+
+	if err := setPath(msg.ValueFields, p.targetField, rendered.String()); err != nil {
+		p.logger.Error("TemplateProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// emptyMissingValues pipes the value of every printed action (e.g. {{.name}}) to templateEmptyFunc, the missing and null
+// values then being printed as empty strings
+func emptyMissingValues(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			emptyMissingValues(child)
+		}
+	case *parse.ActionNode:
+		// An action declaring variables prints nothing
+		if len(n.Pipe.Decl) == 0 {
+			empty := parse.NewIdentifier(templateEmptyFunc).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{empty}})
+		}
+	case *parse.IfNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.RangeNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.WithNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	}
+}
+
+func emptyIfNil(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// TapProcessor passes the messages unchanged, counting them in etelgo_tap_messages_total{name="<name>"} to measure how
+// many reach its position in the chain (e.g. before and after a drop stage). With sample_rate, each message is also
+// logged with the probability sample_rate, its position and fields, to look at what flows at that point.
+type TapProcessor struct {
+	logger     *slog.Logger
+	name       string
+	sampleRate float64
+	messages   *metrics.Counter
+}
+
+func NewTapProcessor(cfg ProcessorConfig) (Processor, error) {
+	name, ok := cfg.Config["name"].(string)
+	if !ok || name == "" {
+		return nil, errors.New("missing or invalid 'name' parameter")
+	}
+
+	var sampleRate float64
+	if val, ok := cfg.Config["sample_rate"]; ok {
+		sampleRate, ok = toFloat(val)
+		if !ok || sampleRate < 0 || sampleRate > 1 {
+			return nil, fmt.Errorf("invalid tap sample_rate: %v, must be a number in [0,1]", val)
+		}
+	}
+
+	return &TapProcessor{
+		logger:     cfg.logger,
+		name:       name,
+		sampleRate: sampleRate,
+		messages:   metrics.Default.Counter("etelgo_tap_messages_total", "name", name),
+	}, nil
+}
+
+func (p *TapProcessor) Name() string {
+	return ProcessorTypeTap
+}
+
+func (p *TapProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	p.messages.Inc()
+	if p.sampleRate > 0 && rand.Float64() < p.sampleRate {
+		p.logger.Info("Tap", "name", p.name, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset,
+			"key", string(msg.Key), "fields", msg.ValueFields)
+	}
+	return msg, nil
+}
+
+// RequireProcessor enforces a data contract: every field (dot-paths) must be present and not null. A message missing
+// any of them fails with the list of the missing fields, and is handled by the errors policy (skipped, or sent to the
+// dead letter topic). With log_missing, the missing fields are also logged with the message position.
+type RequireProcessor struct {
+	logger     *slog.Logger
+	fields     []string
+	logMissing bool
+}
+
+func NewRequireProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &RequireProcessor{logger: cfg.logger}
+
+	fields, _ := cfg.Config["fields"].([]interface{})
+	for _, f := range fields {
+		field, ok := f.(string)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid required field: %v", f)
+		}
+		processor.fields = append(processor.fields, field)
+	}
+	if len(processor.fields) == 0 {
+		return nil, errors.New("missing or invalid 'fields' parameter")
+	}
+
+	if logMissing, ok := cfg.Config["log_missing"].(bool); ok {
+		processor.logMissing = logMissing
+	}
+
+	return processor, nil
+}
+
+func (p *RequireProcessor) Name() string {
+	return ProcessorTypeRequire
+}
+
+func (p *RequireProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	var missing []string
+	for _, field := range p.fields {
+		if val, ok := getPath(msg.ValueFields, field); !ok || val == nil {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) == 0 {
+		return msg, nil
+	}
+
+	if p.logMissing {
+		p.logger.Warn("RequireProcessor: missing required fields", "fields", missing, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	}
+	return nil, fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+}
+
+// FormatNumberProcessor writes the number of field_name as a fixed-decimal string into target_field (field_name by
+// default), for reporting: rounded to precision decimals (default 2), the integer part grouped by thousands_separator
+// (none by default), the decimals following decimal_separator (default ".") and currency_prefix following the sign,
+// e.g. -1234.5 gives "-$1,234.50". The integers are formatted exactly. A missing field leaves the message unchanged,
+// a value that is not a number (numeric strings included), NaN or Inf fails the message, handled by the errors policy.
+type FormatNumberProcessor struct {
+	logger             *slog.Logger
+	fieldName          string
+	targetField        string
+	precision          int
+	thousandsSeparator string
+	decimalSeparator   string
+	currencyPrefix     string
+}
+
+func NewFormatNumberProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &FormatNumberProcessor{
+		logger:           cfg.logger,
+		precision:        2,
+		decimalSeparator: ".",
+	}
+
+	processor.fieldName, _ = cfg.Config["field_name"].(string)
+	if processor.fieldName == "" {
+		return nil, errors.New("missing or invalid 'field_name' parameter")
+	}
+	processor.targetField = processor.fieldName
+	if val, ok := cfg.Config["target_field"]; ok {
+		processor.targetField, _ = val.(string)
+		if processor.targetField == "" {
+			return nil, errors.New("invalid 'target_field' parameter")
+		}
+	}
+
+	if val, ok := cfg.Config["precision"]; ok {
+		precision, ok := toInt64(val)
+		if !ok || precision < 0 || precision > 10 {
+			return nil, fmt.Errorf("invalid format_number precision: %v, must be an integer in [0,10]", val)
+		}
+		processor.precision = int(precision)
+	}
+
+	for key, option := range map[string]*string{
+		"thousands_separator": &processor.thousandsSeparator,
+		"decimal_separator":   &processor.decimalSeparator,
+		"currency_prefix":     &processor.currencyPrefix,
+	} {
+		if val, ok := cfg.Config[key]; ok {
+			str, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid '%s' parameter: %v", key, val)
+			}
+			*option = str
+		}
+	}
+	if processor.decimalSeparator == "" || processor.decimalSeparator == processor.thousandsSeparator {
+		return nil, errors.New("invalid 'decimal_separator' parameter, it must be non empty and differ from the thousands_separator")
+	}
+
+	return processor, nil
+}
+
+func (p *FormatNumberProcessor) Name() string {
+	return ProcessorTypeFormatNumber
+}
+
+func (p *FormatNumberProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.fieldName)
+	if !ok {
+		return msg, nil
+	}
+
+	formatted, err := p.format(val)
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %w", p.fieldName, err)
+	}
+	if err := setPath(msg.ValueFields, p.targetField, formatted); err != nil {
+		p.logger.Error("FormatNumberProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// format returns the number formatted, see FormatNumberProcessor
+func (p *FormatNumberProcessor) format(val interface{}) (string, error) {
+	var digits string
+	switch n := val.(type) {
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", fmt.Errorf("cannot format %v", n)
+		}
+		digits = strconv.FormatFloat(n, 'f', p.precision, 64)
+	case int, int64, uint64:
+		digits = fmt.Sprint(n)
+		if p.precision > 0 {
+			digits += "." + strings.Repeat("0", p.precision)
+		}
+	default:
+		return "", fmt.Errorf("not a number: %v (%T)", val, val)
+	}
+
+	digits, negative := strings.CutPrefix(digits, "-")
+	integer, decimals, _ := strings.Cut(digits, ".")
+	// A negative number rounded to zero loses its sign
+	negative = negative && strings.Trim(digits, "0.") != ""
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteString(p.currencyPrefix)
+	b.WriteString(groupThousands(integer, p.thousandsSeparator))
+	if decimals != "" {
+		b.WriteString(p.decimalSeparator)
+		b.WriteString(decimals)
+	}
+	return b.String(), nil
+}
+
+// groupThousands inserts the separator every three digits from the right of the integer digits
+func groupThousands(digits string, separator string) string {
+	if separator == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		b.WriteString(separator)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// LookupProcessor enriches the messages from a JSON file holding an object of the values by key: the value of
+// key_field is looked up in file, as a string (e.g. 42 looks up "42"), and the value found is written into target_field.
+// The lookups go through the shared cache (see SharedCache) with the file path as source, so that the stages reading
+// the same file reuse each other's results. The file is read again on a miss, its changes being picked up once the
+// entries expire (cache_ttl, default 5m), and the source keeps at most cache_max_size entries (default 10000).
+// A message without key_field, or whose key is absent from the file, is left unchanged. A file that can't be read
+// fails the message, handled by the errors policy.
+type LookupProcessor struct {
+	logger      *slog.Logger
+	keyField    string
+	targetField string
+	file        string
+	cache       *CacheScope
+	readFile    func(name string) ([]byte, error) // os.ReadFile when nil, replaced in tests
+}
+
+func NewLookupProcessor(cfg ProcessorConfig) (Processor, error) {
+	processor := &LookupProcessor{logger: cfg.logger}
+
+	for key, option := range map[string]*string{
+		"key_field":    &processor.keyField,
+		"target_field": &processor.targetField,
+		"file":         &processor.file,
+	} {
+		*option, _ = cfg.Config[key].(string)
+		if *option == "" {
+			return nil, fmt.Errorf("missing or invalid '%s' parameter", key)
+		}
+	}
+
+	cache, err := newCacheScope(cfg, processor.file)
+	if err != nil {
+		return nil, err
+	}
+	processor.cache = cache
+
+	return processor, nil
+}
+
+func (p *LookupProcessor) Name() string {
+	return ProcessorTypeLookup
+}
+
+func (p *LookupProcessor) Process(ctx context.Context, msg *consumer.Message) (*consumer.Message, error) {
+	val, ok := getPath(msg.ValueFields, p.keyField)
+	if !ok || val == nil {
+		return msg, nil
+	}
+
+	key := fmt.Sprint(val)
+	found, err := p.cache.GetOrLoad(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return p.load(key)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lookup of %q in %s: %w", key, p.file, err)
+	}
+	if found == nil {
+		return msg, nil
+	}
+
+	// The cached value is shared by the messages, each one gets its own copy
+	if err := setPath(msg.ValueFields, p.targetField, deepCopy(found)); err != nil {
+		p.logger.Error("LookupProcessor: failed to write target field", "target_field", p.targetField, "error", err)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// load reads the value of the key from the file, nil when absent (cached as well, so that the unknown keys
+// don't read the file again)
+func (p *LookupProcessor) load(key string) (interface{}, error) {
+	readFile := p.readFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	data, err := readFile(p.file)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := consumer.DecodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the file must hold a JSON object of the values by key")
+	}
+	return values[key], nil
+}
+
+// toInt64 converts the integers decoded from the payload, false when out of the int64 range
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	}
+	return 0, false
+}
+
+// toFloat converts the numbers decoded from the YAML configuration
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
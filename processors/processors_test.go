@@ -1,9 +1,20 @@
 package processors
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"etelgo/consumer"
+	"etelgo/metrics"
 	"io"
 	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -152,7 +163,7 @@ func TestTimestampReplayProcessor_WithTargetTimestamp(t *testing.T) {
 	msg := createTestMessage()
 	originalTimestamp := msg.Timestamp
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -181,7 +192,7 @@ func TestTimestampReplayProcessor_WithInvalidTargetTimestamp(t *testing.T) {
 	processor, _ := NewTimestampReplayProcessor(cfg)
 	msg := createTestMessage()
 
-	_, err := processor.Process(msg)
+	_, err := processor.Process(context.Background(), msg)
 	if err == nil {
 		t.Errorf("expected error for invalid timestamp format, got nil")
 	}
@@ -201,7 +212,7 @@ func TestTimestampReplayProcessor_WithOffsetSeconds(t *testing.T) {
 	msg := createTestMessage()
 	originalTimestamp := msg.Timestamp
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -226,7 +237,7 @@ func TestTimestampReplayProcessor_WithOffsetMinutes(t *testing.T) {
 	msg := createTestMessage()
 	originalTimestamp := msg.Timestamp
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -251,7 +262,7 @@ func TestTimestampReplayProcessor_WithOffsetHours(t *testing.T) {
 	msg := createTestMessage()
 	originalTimestamp := msg.Timestamp
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -275,7 +286,7 @@ func TestTimestampReplayProcessor_WithInvalidUnit(t *testing.T) {
 	processor, _ := NewTimestampReplayProcessor(cfg)
 	msg := createTestMessage()
 
-	_, err := processor.Process(msg)
+	_, err := processor.Process(context.Background(), msg)
 	if err == nil {
 		t.Errorf("expected error for invalid time unit, got nil")
 	}
@@ -295,7 +306,7 @@ func TestTimestampReplayProcessor_NegativeOffset(t *testing.T) {
 	msg := createTestMessage()
 	originalTimestamp := msg.Timestamp
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -332,7 +343,7 @@ func TestDropProcessor_NoConfiguration(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["test_field"] = "test_value"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -355,7 +366,7 @@ func TestDropProcessor_DropMessage(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["status"] = "inactive"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -378,7 +389,7 @@ func TestDropProcessor_KeepMessage_CriteriaNotMatched(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["status"] = "active"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -401,7 +412,7 @@ func TestDropProcessor_FieldDoesNotExist(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["status"] = "active"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -422,9 +433,9 @@ func TestDropProcessor_FieldValueNotString(t *testing.T) {
 
 	processor, _ := NewDropProcessor(cfg)
 	msg := createTestMessage()
-	msg.ValueFields["count"] = 100 // int, not string
+	msg.ValueFields["count"] = 100 // int, not string: kept without value_type
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -433,6 +444,100 @@ func TestDropProcessor_FieldValueNotString(t *testing.T) {
 	}
 }
 
+func TestDropProcessor_ValueType(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueType string
+		criteria  string
+		value     interface{}
+		wantDrop  bool
+	}{
+		{"Int matches int64", "int", "100", int64(100), true},
+		{"Int matches whole float64", "int", "100", float64(100), true},
+		{"Int skips fractional float64", "int", "100", 100.5, false},
+		{"Int matches numeric string", "int", "100", "100", true},
+		{"Int skips other value", "int", "100", int64(101), false},
+		{"Float matches float64", "float", "19.99", 19.99, true},
+		{"Float matches int64", "float", "20", int64(20), true},
+		{"Float skips bool", "float", "1", true, false},
+		{"Bool matches bool", "bool", "true", true, true},
+		{"Bool matches string", "bool", "false", "false", true},
+		{"Bool skips other value", "bool", "true", false, false},
+		{"String skips number", "string", "100", int64(100), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewDropProcessor(ProcessorConfig{
+				Type:   ProcessorTypeDrop,
+				Config: map[string]interface{}{"field_name": "count", "filter_criteria": tt.criteria, "value_type": tt.valueType},
+				logger: testLogger,
+			})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields["count"] = tt.value
+
+			result, err := processor.Process(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error processing message: %v", err)
+			}
+			if (result == nil) != tt.wantDrop {
+				t.Errorf("Process() dropped = %v, want %v", result == nil, tt.wantDrop)
+			}
+		})
+	}
+
+	if _, err := NewDropProcessor(ProcessorConfig{Type: ProcessorTypeDrop, Config: map[string]interface{}{"field_name": "count", "filter_criteria": "abc", "value_type": "int"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for a criteria not matching the value type")
+	}
+}
+
+func TestDropProcessor_Conditions(t *testing.T) {
+	conditions := []interface{}{
+		map[string]interface{}{"field": "status", "value": "inactive"},
+		map[string]interface{}{"field": "user.region", "operator": "eq", "value": "eu"},
+	}
+
+	tests := []struct {
+		name     string
+		match    interface{}
+		fields   map[string]interface{}
+		wantDrop bool
+	}{
+		{"All matching", nil, map[string]interface{}{"status": "inactive", "user": map[string]interface{}{"region": "eu"}}, true},
+		{"All with one not matching", "all", map[string]interface{}{"status": "inactive", "user": map[string]interface{}{"region": "us"}}, false},
+		{"Any with one matching", "any", map[string]interface{}{"status": "active", "user": map[string]interface{}{"region": "eu"}}, true},
+		{"Any with none matching", "any", map[string]interface{}{"status": "active"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"conditions": conditions}
+			if tt.match != nil {
+				config["match"] = tt.match
+			}
+			processor, err := NewDropProcessor(ProcessorConfig{Type: ProcessorTypeDrop, Config: config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if err != nil {
+				t.Fatalf("unexpected error processing message: %v", err)
+			}
+			if (result == nil) != tt.wantDrop {
+				t.Errorf("Process() dropped = %v, want %v", result == nil, tt.wantDrop)
+			}
+		})
+	}
+
+	if _, err := NewDropProcessor(ProcessorConfig{Type: ProcessorTypeDrop, Config: map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"field": "status", "operator": "like", "value": "x"}}}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an invalid condition")
+	}
+}
+
 // ==================== applyTransformation Tests ====================
 
 func TestApplyTransformation_Uppercase(t *testing.T) {
@@ -508,6 +613,63 @@ func TestApplyTransformation_UnknownOperation(t *testing.T) {
 	}
 }
 
+func TestApplyTransformation_Base64RoundTrip(t *testing.T) {
+	for _, value := range []string{"hello", "", "binary \x00\xff blob", "ünïcode"} {
+		encoded, err := applyTransformation(value, "base64_encode", map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("base64_encode(%q) unexpected error: %v", value, err)
+		}
+		decoded, err := applyTransformation(encoded, "base64_decode", map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("base64_decode(%q) unexpected error: %v", encoded, err)
+		}
+		if decoded != value {
+			t.Errorf("round trip of %q = %q", value, decoded)
+		}
+	}
+
+	if encoded, _ := applyTransformation("hello", "base64_encode", map[string]interface{}{}); encoded != "aGVsbG8=" {
+		t.Errorf("base64_encode(hello) = %v, want aGVsbG8=", encoded)
+	}
+}
+
+func TestApplyTransformation_InvalidBase64(t *testing.T) {
+	_, err := applyTransformation("not base64!", "base64_decode", map[string]interface{}{})
+	if err == nil {
+		t.Errorf("expected error for invalid base64, got nil")
+	}
+}
+
+func TestApplyTransformation_JSON(t *testing.T) {
+	parsed, err := applyTransformation(`{"user": {"id": 42, "score": 1.5}, "tags": ["a"]}`, "json_parse", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("json_parse unexpected error: %v", err)
+	}
+	object, ok := parsed.(map[string]interface{})
+	if !ok {
+		t.Fatalf("json_parse = %T, want a map", parsed)
+	}
+	// The parsed object is reachable through dot-paths, with the numbers decoded as the message fields
+	if id, ok := getPath(map[string]interface{}{"payload": object}, "payload.user.id"); !ok || id != int64(42) {
+		t.Errorf("payload.user.id = %#v, want int64(42)", id)
+	}
+
+	stringified, err := applyTransformation(object, "json_stringify", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("json_stringify unexpected error: %v", err)
+	}
+	if stringified != `{"tags":["a"],"user":{"id":42,"score":1.5}}` {
+		t.Errorf("json_stringify = %v", stringified)
+	}
+
+	if _, err := applyTransformation(`{"user": `, "json_parse", map[string]interface{}{}); err == nil {
+		t.Error("expected error for invalid JSON, got nil")
+	}
+	if number, _ := applyTransformation(int64(7), "json_stringify", map[string]interface{}{}); number != "7" {
+		t.Errorf("json_stringify(7) = %#v, want \"7\"", number)
+	}
+}
+
 // ==================== TransformProcessor Tests ====================
 
 func TestTransformProcessor_Name(t *testing.T) {
@@ -559,7 +721,7 @@ func TestTransformProcessor_UppercaseTransform(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "hello world"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -568,6 +730,32 @@ func TestTransformProcessor_UppercaseTransform(t *testing.T) {
 	}
 }
 
+func TestTransformProcessor_WithoutParams(t *testing.T) {
+	cfg := ProcessorConfig{
+		Type: ProcessorTypeTransform,
+		Config: map[string]interface{}{
+			"field_name": "message",
+			"operation":  "base64_encode",
+		},
+		logger: testLogger,
+	}
+
+	processor, err := NewTransformProcessor(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating processor: %v", err)
+	}
+	msg := createTestMessage()
+	msg.ValueFields["message"] = "hello"
+
+	result, err := processor.Process(context.Background(), msg)
+	if err != nil {
+		t.Errorf("unexpected error processing message: %v", err)
+	}
+	if result.ValueFields["message"] != "aGVsbG8=" {
+		t.Errorf("expected aGVsbG8=, got %v", result.ValueFields["message"])
+	}
+}
+
 func TestTransformProcessor_LowercaseTransform(t *testing.T) {
 	cfg := ProcessorConfig{
 		Type: ProcessorTypeTransform,
@@ -583,7 +771,7 @@ func TestTransformProcessor_LowercaseTransform(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "HELLO WORLD"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -609,7 +797,7 @@ func TestTransformProcessor_AddPrefixTransform(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "error occurred"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -635,7 +823,7 @@ func TestTransformProcessor_AddSuffixTransform(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "processing"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -659,7 +847,7 @@ func TestTransformProcessor_FieldNotFound(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "hello"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -682,7 +870,7 @@ func TestTransformProcessor_MissingFieldName(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "hello"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -705,7 +893,7 @@ func TestTransformProcessor_MissingOperation(t *testing.T) {
 	msg := createTestMessage()
 	msg.ValueFields["message"] = "hello"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -714,6 +902,50 @@ func TestTransformProcessor_MissingOperation(t *testing.T) {
 	}
 }
 
+func TestTransformProcessor_KeepOriginalOnError(t *testing.T) {
+	tests := []struct {
+		name    string
+		keep    interface{}
+		wantErr bool
+	}{
+		{"Strict by default", nil, true},
+		{"Original kept", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"field_name": "payload",
+				"operation":  "json_parse",
+				"params":     map[string]interface{}{},
+			}
+			if tt.keep != nil {
+				config["keep_original_on_error"] = tt.keep
+			}
+			processor, err := NewTransformProcessor(ProcessorConfig{Type: ProcessorTypeTransform, Config: config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			msg := createTestMessage()
+			msg.ValueFields["payload"] = "{not json"
+			result, err := processor.Process(context.Background(), msg)
+			if tt.wantErr {
+				if err == nil || result != nil {
+					t.Errorf("Process() = %v, %v, want an error", result, err)
+				}
+				return
+			}
+			if err != nil || result != msg {
+				t.Fatalf("Process() = %v, %v, want the message passed on", result, err)
+			}
+			if result.ValueFields["payload"] != "{not json" {
+				t.Errorf("expected the original value, got %v", result.ValueFields["payload"])
+			}
+		})
+	}
+}
+
 // ==================== PassthroughProcessor Tests ====================
 
 func TestPassthroughProcessor_Name(t *testing.T) {
@@ -741,7 +973,7 @@ func TestPassthroughProcessor_MessageUnchanged(t *testing.T) {
 	msg.ValueFields["test"] = "value"
 	msg.Topic = "my-topic"
 
-	result, err := processor.Process(msg)
+	result, err := processor.Process(context.Background(), msg)
 	if err != nil {
 		t.Errorf("unexpected error processing message: %v", err)
 	}
@@ -767,7 +999,7 @@ func TestPassthroughProcessor_MultipleMessages(t *testing.T) {
 
 	for i := 0; i < 5; i++ {
 		msg := createTestMessage()
-		result, err := processor.Process(msg)
+		result, err := processor.Process(context.Background(), msg)
 		if err != nil {
 			t.Errorf("unexpected error processing message %d: %v", i, err)
 		}
@@ -776,3 +1008,2264 @@ func TestPassthroughProcessor_MultipleMessages(t *testing.T) {
 		}
 	}
 }
+
+// ==================== HeaderFieldProcessor Tests ====================
+
+func TestNewHeaderFieldProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"To header", map[string]interface{}{"header": "routing-key", "direction": "to_header", "field_name": "type"}, false},
+		{"To field", map[string]interface{}{"header": "routing-key", "direction": "to_field", "field_name": "type"}, false},
+		{"Delete", map[string]interface{}{"header": "routing-key", "direction": "delete"}, false},
+		{"Missing header", map[string]interface{}{"direction": "delete"}, true},
+		{"Missing field_name", map[string]interface{}{"header": "routing-key", "direction": "to_field"}, true},
+		{"Invalid direction", map[string]interface{}{"header": "routing-key", "direction": "both", "field_name": "type"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHeaderFieldProcessor(ProcessorConfig{Type: ProcessorTypeHeaderField, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHeaderFieldProcessor_Process(t *testing.T) {
+	newProcessor := func(direction string) Processor {
+		processor, err := NewHeaderFieldProcessor(ProcessorConfig{
+			Type:   ProcessorTypeHeaderField,
+			Config: map[string]interface{}{"header": "routing-key", "direction": direction, "field_name": "type"},
+			logger: testLogger,
+		})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		return processor
+	}
+
+	msg := createTestMessage()
+	msg.ValueFields["type"] = 42
+	if _, err := newProcessor("to_header").Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Headers["routing-key"] != "42" {
+		t.Errorf("expected header routing-key=42, got %q", msg.Headers["routing-key"])
+	}
+
+	msg = createTestMessage()
+	msg.Headers["routing-key"] = "orders"
+	if _, err := newProcessor("to_field").Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.ValueFields["type"] != "orders" {
+		t.Errorf("expected field type=orders, got %v", msg.ValueFields["type"])
+	}
+
+	if _, err := newProcessor("delete").Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := msg.Headers["routing-key"]; ok {
+		t.Errorf("expected header routing-key to be deleted")
+	}
+
+	msg = createTestMessage()
+	result, err := newProcessor("to_header").Process(context.Background(), msg)
+	if err != nil || result != msg || len(msg.Headers) != 0 {
+		t.Errorf("expected message unchanged when the field is missing, got headers %v", msg.Headers)
+	}
+}
+
+// ==================== SampleProcessor Tests ====================
+
+func TestNewSampleProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid rate", map[string]interface{}{"rate": 0.1}, false},
+		{"Integer rate", map[string]interface{}{"rate": 1}, false},
+		{"Valid seed", map[string]interface{}{"rate": 0.5, "seed": 42}, false},
+		{"Missing rate", map[string]interface{}{}, true},
+		{"Rate above 1", map[string]interface{}{"rate": 1.5}, true},
+		{"Negative rate", map[string]interface{}{"rate": -0.1}, true},
+		{"Invalid seed", map[string]interface{}{"rate": 0.5, "seed": "abc"}, true},
+		{"Valid every", map[string]interface{}{"every": 10}, false},
+		{"Zero every", map[string]interface{}{"every": 0}, true},
+		{"Fractional every", map[string]interface{}{"every": 2.5}, true},
+		{"Both rate and every", map[string]interface{}{"rate": 0.5, "every": 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSampleProcessor(ProcessorConfig{Type: ProcessorTypeSample, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSampleProcessor_Process(t *testing.T) {
+	sample := func(rate float64, seed int) []bool {
+		processor, err := NewSampleProcessor(ProcessorConfig{
+			Type:   ProcessorTypeSample,
+			Config: map[string]interface{}{"rate": rate, "seed": seed},
+			logger: testLogger,
+		})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+
+		passed := make([]bool, 1000)
+		for i := range passed {
+			result, err := processor.Process(context.Background(), createTestMessage())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			passed[i] = result != nil
+		}
+		return passed
+	}
+
+	count := func(passed []bool) int {
+		n := 0
+		for _, p := range passed {
+			if p {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := count(sample(0, 1)); n != 0 {
+		t.Errorf("expected rate 0 to drop every message, %d passed", n)
+	}
+	if n := count(sample(1, 1)); n != 1000 {
+		t.Errorf("expected rate 1 to pass every message, %d passed", n)
+	}
+	if n := count(sample(0.1, 1)); n < 50 || n > 150 {
+		t.Errorf("expected about 100 messages passed with rate 0.1, got %d", n)
+	}
+
+	first, second := sample(0.5, 42), sample(0.5, 42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same seed to sample the same messages, differs at message %d", i)
+		}
+	}
+}
+
+func TestSampleProcessor_Every(t *testing.T) {
+	processor, err := NewSampleProcessor(ProcessorConfig{
+		Type:   ProcessorTypeSample,
+		Config: map[string]interface{}{"every": 3},
+		logger: testLogger,
+	})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+
+	// Two partitions interleaved, each one must keep its own count
+	passed := map[int32][]int64{}
+	for i := int64(0); i < 9; i++ {
+		for _, partition := range []int32{0, 1} {
+			msg := createTestMessage()
+			msg.Partition = partition
+			msg.Offset = i
+			result, err := processor.Process(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != nil {
+				passed[partition] = append(passed[partition], msg.Offset)
+			}
+		}
+	}
+
+	for _, partition := range []int32{0, 1} {
+		got := passed[partition]
+		if len(got) != 3 || got[0] != 0 || got[1] != 3 || got[2] != 6 {
+			t.Errorf("partition %d: expected offsets [0 3 6] to pass, got %v", partition, got)
+		}
+	}
+}
+
+// ==================== PaceProcessor Tests ====================
+
+func TestNewPaceProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Default speed", map[string]interface{}{}, false},
+		{"Valid speed", map[string]interface{}{"speed": 2.0}, false},
+		{"Zero speed", map[string]interface{}{"speed": 0}, true},
+		{"Invalid speed", map[string]interface{}{"speed": "fast"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPaceProcessor(ProcessorConfig{Type: ProcessorTypePace, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPaceProcessor_Delay(t *testing.T) {
+	p := &PaceProcessor{logger: testLogger, speed: 2}
+	start := time.Now()
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if d := p.delay(ts, start); d != 0 {
+		t.Errorf("expected no delay for the first message, got %v", d)
+	}
+	// 10s later in the original timing, replayed twice as fast, 1s already elapsed
+	if d := p.delay(ts.Add(10*time.Second), start.Add(time.Second)); d != 4*time.Second {
+		t.Errorf("expected a 4s delay, got %v", d)
+	}
+	if d := p.delay(ts.Add(time.Second), start.Add(time.Second)); d > 0 {
+		t.Errorf("expected no delay for a message behind the replay position, got %v", d)
+	}
+}
+
+func TestPaceProcessor_ContextCancelled(t *testing.T) {
+	processor, err := NewPaceProcessor(ProcessorConfig{Type: ProcessorTypePace, Config: map[string]interface{}{}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	pace := processor.(*PaceProcessor)
+
+	first := createTestMessage()
+	if _, err := pace.Process(context.Background(), first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	msg := createTestMessage()
+	msg.Timestamp = first.Timestamp.Add(time.Hour)
+	start := time.Now()
+	result, err := pace.Process(ctx, msg)
+	if err != nil || result != msg {
+		t.Errorf("expected the message to be returned, got %v, %v", result, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to be interrupted, took %v", elapsed)
+	}
+}
+
+// ==================== ExtractProcessor Tests ====================
+
+func TestNewExtractProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"path": "$.items[*].sku", "target_field": "skus"}, false},
+		{"Invalid expression", map[string]interface{}{"path": "$.items[", "target_field": "skus"}, true},
+		{"Missing path", map[string]interface{}{"target_field": "skus"}, true},
+		{"Missing target_field", map[string]interface{}{"path": "$.items[*].sku"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExtractProcessor(ProcessorConfig{Type: ProcessorTypeExtract, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractProcessor_Process(t *testing.T) {
+	newMessage := func() *consumer.Message {
+		msg := createTestMessage()
+		msg.ValueFields["user"] = map[string]interface{}{"name": "john"}
+		msg.ValueFields["items"] = []interface{}{
+			map[string]interface{}{"sku": "A1"},
+			map[string]interface{}{"sku": "B2"},
+		}
+		return msg
+	}
+	extract := func(path string, msg *consumer.Message) interface{} {
+		processor, err := NewExtractProcessor(ProcessorConfig{
+			Type:   ProcessorTypeExtract,
+			Config: map[string]interface{}{"path": path, "target_field": "result"},
+			logger: testLogger,
+		})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		if _, err := processor.Process(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return msg.ValueFields["result"]
+	}
+
+	skus, ok := extract("$.items[*].sku", newMessage()).([]interface{})
+	if !ok || len(skus) != 2 || skus[0] != "A1" || skus[1] != "B2" {
+		t.Errorf("expected skus [A1 B2], got %v", skus)
+	}
+
+	if name := extract("$.user.name", newMessage()); name != "john" {
+		t.Errorf("expected a singular path to write the value, got %v", name)
+	}
+
+	msg := newMessage()
+	if result := extract("$.user.missing", msg); result != nil {
+		t.Errorf("expected no result for a missing singular path, got %v", result)
+	}
+	if _, ok := msg.ValueFields["result"]; ok {
+		t.Errorf("expected the target field to be left unset")
+	}
+
+	if empty, ok := extract("$.orders[*].id", newMessage()).([]interface{}); !ok || len(empty) != 0 {
+		t.Errorf("expected an empty array for a non-matching wildcard path, got %v", empty)
+	}
+}
+
+// ==================== MergeProcessor Tests ====================
+
+func TestNewMergeProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"source_fields": []interface{}{"first", "last"}, "target_field": "full_name"}, false},
+		{"Single source field", map[string]interface{}{"source_fields": []interface{}{"first"}, "target_field": "full_name"}, true},
+		{"Missing target_field", map[string]interface{}{"source_fields": []interface{}{"first", "last"}}, true},
+		{"Invalid missing", map[string]interface{}{"source_fields": []interface{}{"first", "last"}, "target_field": "full_name", "missing": "fail"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMergeProcessor(ProcessorConfig{Type: ProcessorTypeMerge, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		fields map[string]interface{}
+		want   string
+	}{
+		{
+			"Separator",
+			map[string]interface{}{"separator": " "},
+			map[string]interface{}{"first": "John", "middle": "F", "last": "Doe"},
+			"John F Doe",
+		},
+		{
+			"Separator skipping missing",
+			map[string]interface{}{"separator": " "},
+			map[string]interface{}{"first": "John", "last": "Doe"},
+			"John Doe",
+		},
+		{
+			"Separator with empty missing",
+			map[string]interface{}{"separator": ",", "missing": "empty"},
+			map[string]interface{}{"first": "John", "last": "Doe"},
+			"John,,Doe",
+		},
+		{
+			"Template",
+			map[string]interface{}{"template": "{last}, {first} ({middle})"},
+			map[string]interface{}{"first": "John", "middle": int64(42), "last": "Doe"},
+			"Doe, John (42)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["source_fields"] = []interface{}{"first", "middle", "last"}
+			tt.config["target_field"] = "full_name"
+			processor, err := NewMergeProcessor(ProcessorConfig{Type: ProcessorTypeMerge, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			msg := createTestMessage()
+			msg.ValueFields = tt.fields
+			if _, err := processor.Process(context.Background(), msg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := msg.ValueFields["full_name"]; got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if _, ok := msg.ValueFields["first"]; !ok {
+				t.Errorf("expected source fields to be kept")
+			}
+		})
+	}
+}
+
+// ==================== CopyProcessor Tests ====================
+
+func TestNewCopyProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"source_field": "name", "target_field": "original_name"}, false},
+		{"Missing source_field", map[string]interface{}{"target_field": "original_name"}, true},
+		{"Missing target_field", map[string]interface{}{"source_field": "name"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCopyProcessor(ProcessorConfig{Type: ProcessorTypeCopy, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCopyProcessor_Process(t *testing.T) {
+	newProcessor := func(source, target string, overwrite bool) Processor {
+		processor, err := NewCopyProcessor(ProcessorConfig{
+			Type:   ProcessorTypeCopy,
+			Config: map[string]interface{}{"source_field": source, "target_field": target, "overwrite": overwrite},
+			logger: testLogger,
+		})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		return processor
+	}
+
+	msg := createTestMessage()
+	msg.ValueFields["user"] = map[string]interface{}{"address": map[string]interface{}{"city": "Paris"}}
+	if _, err := newProcessor("user.address", "backup.address", false).Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	city, _ := getPath(msg.ValueFields, "backup.address.city")
+	if city != "Paris" {
+		t.Errorf("expected backup.address.city = Paris, got %v", city)
+	}
+
+	// The copy must be independent from the original
+	setPath(msg.ValueFields, "backup.address.city", "Lyon")
+	if city, _ := getPath(msg.ValueFields, "user.address.city"); city != "Paris" {
+		t.Errorf("expected the original to be kept, got %v", city)
+	}
+
+	msg = createTestMessage()
+	msg.ValueFields["name"] = "new"
+	msg.ValueFields["old_name"] = "old"
+	newProcessor("name", "old_name", false).Process(context.Background(), msg)
+	if msg.ValueFields["old_name"] != "old" {
+		t.Errorf("expected an existing target to be kept without overwrite, got %v", msg.ValueFields["old_name"])
+	}
+	newProcessor("name", "old_name", true).Process(context.Background(), msg)
+	if msg.ValueFields["old_name"] != "new" {
+		t.Errorf("expected an existing target to be replaced with overwrite, got %v", msg.ValueFields["old_name"])
+	}
+
+	msg = createTestMessage()
+	msg.ValueFields["name"] = "john"
+	msg.ValueFields["user"] = "not an object"
+	if _, err := newProcessor("name", "user.name", false).Process(context.Background(), msg); err == nil {
+		t.Errorf("expected an error when the target parent is not an object")
+	}
+}
+
+// ==================== RouteProcessor Tests ====================
+
+func TestNewRouteProcessor(t *testing.T) {
+	when := map[string]interface{}{"field": "level", "value": "error"}
+	tests := []struct {
+		name      string
+		rules     []interface{}
+		expectErr bool
+	}{
+		{"Valid", []interface{}{
+			map[string]interface{}{"label": "errors", "when": when},
+			map[string]interface{}{"label": "other", "default": true},
+		}, false},
+		{"No rules", []interface{}{}, true},
+		{"Missing label", []interface{}{map[string]interface{}{"when": when}}, true},
+		{"Invalid operator", []interface{}{
+			map[string]interface{}{"label": "errors", "when": map[string]interface{}{"field": "level", "operator": "like", "value": "e"}},
+		}, true},
+		{"Default not last", []interface{}{
+			map[string]interface{}{"label": "other", "default": true},
+			map[string]interface{}{"label": "errors", "when": when},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRouteProcessor(ProcessorConfig{Type: ProcessorTypeRoute, Config: map[string]interface{}{"rules": tt.rules}, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRouteProcessor_Process(t *testing.T) {
+	newProcessor := func(rules ...interface{}) Processor {
+		processor, err := NewRouteProcessor(ProcessorConfig{
+			Type:   ProcessorTypeRoute,
+			Config: map[string]interface{}{"rules": rules},
+			logger: testLogger,
+		})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		return processor
+	}
+	rules := []interface{}{
+		map[string]interface{}{"label": "errors", "when": map[string]interface{}{"field": "level", "value": "error"}},
+		map[string]interface{}{"label": "slow", "when": map[string]interface{}{"field": "http.duration", "operator": "gte", "value": 1000}},
+		map[string]interface{}{"label": "tagged", "when": map[string]interface{}{"field": "tags", "operator": "contains", "value": "audit"}},
+	}
+
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+		want   interface{}
+	}{
+		{"First rule wins", map[string]interface{}{"level": "error", "http": map[string]interface{}{"duration": 2000}}, "errors"},
+		{"Numeric comparison on dot-path", map[string]interface{}{"level": "info", "http": map[string]interface{}{"duration": int64(1500)}}, "slow"},
+		{"Contains on array", map[string]interface{}{"tags": []interface{}{"audit", "web"}}, "tagged"},
+		{"No match", map[string]interface{}{"level": "info"}, nil},
+	}
+
+	processor := newProcessor(rules...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createTestMessage()
+			msg.ValueFields = tt.fields
+			processor.Process(context.Background(), msg)
+			if msg.ValueFields["_route"] != tt.want {
+				t.Errorf("expected _route = %v, got %v", tt.want, msg.ValueFields["_route"])
+			}
+		})
+	}
+
+	msg := createTestMessage()
+	msg.ValueFields = map[string]interface{}{"level": "info"}
+	newProcessor(append(rules, map[string]interface{}{"label": "other", "default": true})...).Process(context.Background(), msg)
+	if msg.ValueFields["_route"] != "other" {
+		t.Errorf("expected the default label, got %v", msg.ValueFields["_route"])
+	}
+}
+
+// ==================== FieldExistsProcessor Tests ====================
+
+func TestNewFieldExistsProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"field_name": "email"}, false},
+		{"Missing field_name", map[string]interface{}{"action": "drop"}, true},
+		{"Invalid action", map[string]interface{}{"field_name": "email", "action": "forward"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFieldExistsProcessor(ProcessorConfig{Type: ProcessorTypeFieldExists, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFieldExistsProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name           string
+		requirePresent bool
+		action         string
+		fields         map[string]interface{}
+		wantKept       bool
+	}{
+		{"Keep present", true, "keep", map[string]interface{}{"contact": map[string]interface{}{"email": "a@b.c"}}, true},
+		{"Keep drops missing", true, "keep", map[string]interface{}{"contact": map[string]interface{}{}}, false},
+		{"Null is missing", true, "keep", map[string]interface{}{"contact": map[string]interface{}{"email": nil}}, false},
+		{"Empty string is present", true, "keep", map[string]interface{}{"contact": map[string]interface{}{"email": ""}}, true},
+		{"Drop present", true, "drop", map[string]interface{}{"contact": map[string]interface{}{"email": "a@b.c"}}, false},
+		{"Keep missing", false, "keep", map[string]interface{}{}, true},
+		{"Drop missing", false, "drop", map[string]interface{}{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewFieldExistsProcessor(ProcessorConfig{
+				Type:   ProcessorTypeFieldExists,
+				Config: map[string]interface{}{"field_name": "contact.email", "require_present": tt.requirePresent, "action": tt.action},
+				logger: testLogger,
+			})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			msg := createTestMessage()
+			msg.ValueFields = tt.fields
+			result, err := processor.Process(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result != nil) != tt.wantKept {
+				t.Errorf("expected kept = %v, got %v", tt.wantKept, result != nil)
+			}
+		})
+	}
+}
+
+// ==================== MaxAgeProcessor Tests ====================
+
+func TestNewMaxAgeProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"max_age": "1h"}, false},
+		{"Valid with timestamp field", map[string]interface{}{"max_age": "1h", "timestamp_field": "ts", "timestamp_unit": "s"}, false},
+		{"Missing max_age", map[string]interface{}{}, true},
+		{"Zero max_age", map[string]interface{}{"max_age": "0s"}, true},
+		{"Invalid timestamp unit", map[string]interface{}{"max_age": "1h", "timestamp_unit": "ns"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMaxAgeProcessor(ProcessorConfig{Type: ProcessorTypeMaxAge, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMaxAgeProcessor_Process(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		timestamp time.Time
+		fields    map[string]interface{}
+		wantKept  bool
+		expectErr bool
+	}{
+		{"Recent Kafka timestamp", map[string]interface{}{}, now.Add(-30 * time.Minute), nil, true, false},
+		{"Old Kafka timestamp", map[string]interface{}{}, now.Add(-2 * time.Hour), nil, false, false},
+		{"Recent RFC3339 field", map[string]interface{}{"timestamp_field": "event.time"}, now.Add(-2 * time.Hour),
+			map[string]interface{}{"event": map[string]interface{}{"time": "2026-01-02T11:30:00Z"}}, true, false},
+		{"Old epoch milliseconds field", map[string]interface{}{"timestamp_field": "ts"}, now,
+			map[string]interface{}{"ts": now.Add(-3 * time.Hour).UnixMilli()}, false, false},
+		{"Recent epoch seconds field", map[string]interface{}{"timestamp_field": "ts", "timestamp_unit": "s"}, now,
+			map[string]interface{}{"ts": float64(now.Add(-time.Minute).Unix())}, true, false},
+		{"Missing field", map[string]interface{}{"timestamp_field": "ts"}, now, map[string]interface{}{}, false, true},
+		{"Invalid field", map[string]interface{}{"timestamp_field": "ts"}, now, map[string]interface{}{"ts": "yesterday"}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["max_age"] = "1h"
+			processor, err := NewMaxAgeProcessor(ProcessorConfig{Type: ProcessorTypeMaxAge, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			processor.(*MaxAgeProcessor).now = func() time.Time { return now }
+
+			msg := createTestMessage()
+			msg.Timestamp = tt.timestamp
+			msg.ValueFields = tt.fields
+			result, err := processor.Process(context.Background(), msg)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result != nil) != tt.wantKept {
+				t.Errorf("kept = %v, want %v", result != nil, tt.wantKept)
+			}
+		})
+	}
+}
+
+// ==================== BucketProcessor Tests ====================
+
+func ageBuckets() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"max": 17, "label": "child"},
+		map[string]interface{}{"max": 64, "label": "adult"},
+		map[string]interface{}{"label": "senior"},
+	}
+}
+
+func TestNewBucketProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Valid", map[string]interface{}{"field_name": "age", "target_field": "group", "buckets": ageBuckets()}, false},
+		{"Missing target_field", map[string]interface{}{"field_name": "age", "buckets": ageBuckets()}, true},
+		{"Only catch-all", map[string]interface{}{"field_name": "age", "target_field": "group", "buckets": []interface{}{
+			map[string]interface{}{"label": "all"},
+		}}, true},
+		{"Descending thresholds", map[string]interface{}{"field_name": "age", "target_field": "group", "buckets": []interface{}{
+			map[string]interface{}{"max": 64, "label": "adult"},
+			map[string]interface{}{"max": 17, "label": "child"},
+			map[string]interface{}{"label": "senior"},
+		}}, true},
+		{"Catch-all with max", map[string]interface{}{"field_name": "age", "target_field": "group", "buckets": []interface{}{
+			map[string]interface{}{"max": 17, "label": "child"},
+			map[string]interface{}{"max": 64, "label": "adult"},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBucketProcessor(ProcessorConfig{Type: ProcessorTypeBucket, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBucketProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantLabel interface{} // nil when no label is written
+		expectErr bool
+	}{
+		{"Below first threshold", int64(5), "child", false},
+		{"Threshold is inclusive", int64(17), "child", false},
+		{"Decimal between thresholds", 17.5, "adult", false},
+		{"Catch-all", int64(80), "senior", false},
+		{"Missing field", nil, nil, false},
+		{"Non numeric value", "forty", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewBucketProcessor(ProcessorConfig{
+				Type:   ProcessorTypeBucket,
+				Config: map[string]interface{}{"field_name": "person.age", "target_field": "person.group", "buckets": ageBuckets()},
+				logger: testLogger,
+			})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			person := map[string]interface{}{}
+			if tt.value != nil {
+				person["age"] = tt.value
+			}
+			msg := createTestMessage()
+			msg.ValueFields = map[string]interface{}{"person": person}
+
+			result, err := processor.Process(context.Background(), msg)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := result.ValueFields["person"].(map[string]interface{})["group"]; got != tt.wantLabel {
+				t.Errorf("group = %v, want %v", got, tt.wantLabel)
+			}
+		})
+	}
+}
+
+// ==================== ExplodeProcessor Tests ====================
+
+func TestExplodeProcessor_ProcessMulti(t *testing.T) {
+	processor, err := NewExplodeProcessor(ProcessorConfig{
+		Type:   ProcessorTypeExplode,
+		Config: map[string]interface{}{"field_name": "stats.scores"},
+		logger: testLogger,
+	})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	multi := processor.(MultiProcessor)
+
+	msg := createTestMessage()
+	msg.Headers["source"] = "exam"
+	msg.ValueFields = map[string]interface{}{
+		"id":    int64(1),
+		"stats": map[string]interface{}{"scores": map[string]interface{}{"math": int64(12), "art": int64(15)}, "year": int64(2024)},
+	}
+
+	out, err := multi.ProcessMulti(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(out))
+	}
+
+	want := []struct {
+		key   string
+		value int64
+	}{{"art", 15}, {"math", 12}}
+	for i, m := range out {
+		if m.ValueFields[ExplodeKeyField] != want[i].key || m.ValueFields[ExplodeValueField] != want[i].value {
+			t.Errorf("message %d: _key/_value = %v/%v, want %s/%d", i, m.ValueFields[ExplodeKeyField], m.ValueFields[ExplodeValueField], want[i].key, want[i].value)
+		}
+		if m.ValueFields["id"] != int64(1) {
+			t.Errorf("message %d: id = %v, want 1", i, m.ValueFields["id"])
+		}
+		stats := m.ValueFields["stats"].(map[string]interface{})
+		if _, ok := stats["scores"]; ok {
+			t.Errorf("message %d: the exploded field is still present", i)
+		}
+		if stats["year"] != int64(2024) {
+			t.Errorf("message %d: stats.year = %v, want 2024", i, stats["year"])
+		}
+		if string(m.Key) != "test-key" || m.Headers["source"] != "exam" {
+			t.Errorf("message %d: key and headers must be duplicated, got %s %v", i, m.Key, m.Headers)
+		}
+	}
+
+	// Emitted messages are independent
+	out[0].Headers["source"] = "edited"
+	if out[1].Headers["source"] != "exam" || msg.Headers["source"] != "exam" {
+		t.Error("emitted messages share their headers")
+	}
+}
+
+func TestExplodeProcessor_Edges(t *testing.T) {
+	tests := []struct {
+		name      string
+		fields    map[string]interface{}
+		wantCount int
+		expectErr bool
+	}{
+		{"Missing field", map[string]interface{}{"id": 1}, 1, false},
+		{"Empty map", map[string]interface{}{"scores": map[string]interface{}{}}, 0, false},
+		{"Not a map", map[string]interface{}{"scores": []interface{}{1, 2}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewExplodeProcessor(ProcessorConfig{Type: ProcessorTypeExplode, Config: map[string]interface{}{"field_name": "scores"}, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields = tt.fields
+
+			out, err := processor.(MultiProcessor).ProcessMulti(context.Background(), msg)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(out) != tt.wantCount {
+				t.Errorf("expected %d messages, got %d", tt.wantCount, len(out))
+			}
+		})
+	}
+}
+
+// ==================== ProjectProcessor Tests ====================
+
+func TestNewProjectProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{"Include", map[string]interface{}{"include": []interface{}{"id"}}, false},
+		{"Exclude", map[string]interface{}{"exclude": []interface{}{"user.ssn"}}, false},
+		{"Both", map[string]interface{}{"include": []interface{}{"id"}, "exclude": []interface{}{"user"}}, true},
+		{"Neither", map[string]interface{}{}, true},
+		{"Empty list", map[string]interface{}{"exclude": []interface{}{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProjectProcessor(ProcessorConfig{Type: ProcessorTypeProject, Config: tt.config, logger: testLogger})
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProjectProcessor_Process(t *testing.T) {
+	newFields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id": "42",
+			"user": map[string]interface{}{
+				"name":    "John",
+				"ssn":     "123-45-6789",
+				"address": map[string]interface{}{"city": "Paris", "zip": "75001"},
+			},
+			"debug": true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{"Include nested paths", map[string]interface{}{"include": []interface{}{"id", "user.address.city", "missing"}},
+			`{"id":"42","user":{"address":{"city":"Paris"}}}`},
+		{"Include a subtree", map[string]interface{}{"include": []interface{}{"user.address"}},
+			`{"user":{"address":{"city":"Paris","zip":"75001"}}}`},
+		{"Exclude nested paths", map[string]interface{}{"exclude": []interface{}{"debug", "user.ssn", "user.address", "missing.field"}},
+			`{"id":"42","user":{"name":"John"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewProjectProcessor(ProcessorConfig{Type: ProcessorTypeProject, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields = newFields()
+
+			result, err := processor.Process(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			if string(got) != tt.want {
+				t.Errorf("ValueFields = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== EmptyToNullProcessor Tests ====================
+
+func TestEmptyToNullProcessor_Process(t *testing.T) {
+	newFields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"name":    "John",
+			"email":   "",
+			"phone":   "",
+			"address": map[string]interface{}{"city": "", "zip": "75001"},
+			"tags":    []interface{}{""},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{"Every field nullified", map[string]interface{}{},
+			`{"address":{"city":null,"zip":"75001"},"email":null,"name":"John","phone":null,"tags":[""]}`},
+		{"Every field removed", map[string]interface{}{"mode": "remove"},
+			`{"address":{"zip":"75001"},"name":"John","tags":[""]}`},
+		{"Configured fields nullified", map[string]interface{}{"fields": []interface{}{"email", "address.city", "name", "missing"}},
+			`{"address":{"city":null,"zip":"75001"},"email":null,"name":"John","phone":"","tags":[""]}`},
+		{"Configured fields removed", map[string]interface{}{"fields": []interface{}{"phone", "address.city"}, "mode": "remove"},
+			`{"address":{"zip":"75001"},"email":"","name":"John","tags":[""]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewEmptyToNullProcessor(ProcessorConfig{Type: ProcessorTypeEmptyToNull, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields = newFields()
+
+			result, err := processor.Process(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			if string(got) != tt.want {
+				t.Errorf("ValueFields = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== SchemaProcessor Tests ====================
+
+func TestSchemaProcessor_Process(t *testing.T) {
+	schema := map[string]interface{}{
+		"id":         "int",
+		"price":      "float",
+		"active":     "bool",
+		"name":       "string",
+		"tags":       "array",
+		"user":       "object",
+		"user.email": "string",
+	}
+	newFields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":     int64(1),
+			"price":  int64(10),
+			"active": true,
+			"name":   "John",
+			"tags":   []interface{}{"a"},
+			"user":   map[string]interface{}{"email": nil},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		fields  map[string]interface{} // Overrides of newFields
+		want    string
+		wantErr string
+	}{
+		{"Matching fields", "reject", nil,
+			`{"active":true,"id":1,"name":"John","price":10,"tags":["a"],"user":{"email":null}}`, ""},
+		{"Integral float is an int", "reject", map[string]interface{}{"id": 2.0},
+			`{"active":true,"id":2,"name":"John","price":10,"tags":["a"],"user":{"email":null}}`, ""},
+		{"Mismatch rejected", "reject", map[string]interface{}{"id": "12"}, "", `field "id": expected int, got string`},
+		{"Strings coerced", "coerce", map[string]interface{}{"id": " 12", "price": "9.5", "active": "false"},
+			`{"active":false,"id":12,"name":"John","price":9.5,"tags":["a"],"user":{"email":null}}`, ""},
+		{"Number coerced to string", "coerce", map[string]interface{}{"name": 4.5},
+			`{"active":true,"id":1,"name":"4.5","price":10,"tags":["a"],"user":{"email":null}}`, ""},
+		{"Fractional float not coerced to int", "coerce", map[string]interface{}{"id": 1.5}, "", `field "id": cannot coerce float 1.5 to int`},
+		{"Array never coerced", "coerce", map[string]interface{}{"tags": "a"}, "", `field "tags": cannot coerce string a to array`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewSchemaProcessor(ProcessorConfig{Type: ProcessorTypeSchema, Config: map[string]interface{}{"schema": schema, "mode": tt.mode}, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields = newFields()
+			for field, val := range tt.fields {
+				msg.ValueFields[field] = val
+			}
+
+			result, err := processor.Process(context.Background(), msg)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			if string(got) != tt.want {
+				t.Errorf("ValueFields = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchemaProcessor_Invalid(t *testing.T) {
+	for name, config := range map[string]map[string]interface{}{
+		"Missing schema": {},
+		"Unknown type":   {"schema": map[string]interface{}{"id": "integer"}},
+		"Unknown mode":   {"schema": map[string]interface{}{"id": "int"}, "mode": "drop"},
+	} {
+		if _, err := NewSchemaProcessor(ProcessorConfig{Type: ProcessorTypeSchema, Config: config, logger: testLogger}); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+// ==================== RenameKeysProcessor Tests ====================
+
+func TestRenameKeysProcessor_Process(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		fields  map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{"Snake case to camel case",
+			map[string]interface{}{"pattern": `_(\w)`, "replacement": "$1", "replacement_case": "upper"},
+			map[string]interface{}{"user_first_name": "John", "id": 1, "address": map[string]interface{}{"zip_code": "75001"}},
+			`{"address":{"zip_code":"75001"},"id":1,"userFirstName":"John"}`, ""},
+		{"Prefix removed",
+			map[string]interface{}{"pattern": "^legacy_", "replacement": ""},
+			map[string]interface{}{"legacy_id": 1, "name": "John"},
+			`{"id":1,"name":"John"}`, ""},
+		{"Lower cased",
+			map[string]interface{}{"pattern": "^[A-Z]+", "replacement": "$0", "replacement_case": "lower"},
+			map[string]interface{}{"ID": 1, "URLPath": "/"},
+			`{"id":1,"urlpath":"/"}`, ""},
+		{"Collision",
+			map[string]interface{}{"pattern": "^legacy_", "replacement": ""},
+			map[string]interface{}{"legacy_id": 1, "id": 2},
+			"", `keys "id" and "legacy_id" are both renamed to "id"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewRenameKeysProcessor(ProcessorConfig{Type: ProcessorTypeRenameKeys, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := createTestMessage()
+			msg.ValueFields = tt.fields
+
+			result, err := processor.Process(context.Background(), msg)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			if string(got) != tt.want {
+				t.Errorf("ValueFields = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// ==================== IDProcessor Tests ====================
+
+func TestIDProcessor_UUID(t *testing.T) {
+	processor, err := NewIDProcessor(ProcessorConfig{Type: ProcessorTypeGenerateID, Config: map[string]interface{}{"target_field": "meta.id"}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seen := make(map[interface{}]bool)
+	for i := 0; i < 100; i++ {
+		result, err := processor.Process(context.Background(), createTestMessage())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		id, _ := getPath(result.ValueFields, "meta.id")
+		if s, ok := id.(string); !ok || !uuidPattern.MatchString(s) {
+			t.Fatalf("expected a UUID v4, got %v", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate UUID %v", id)
+		}
+		seen[id] = true
+	}
+
+	// An existing ID is kept
+	msg := createTestMessage()
+	msg.ValueFields["meta"] = map[string]interface{}{"id": "existing"}
+	result, _ := processor.Process(context.Background(), msg)
+	if id, _ := getPath(result.ValueFields, "meta.id"); id != "existing" {
+		t.Errorf("expected the existing ID to be kept, got %v", id)
+	}
+}
+
+func TestIDProcessor_SequenceConcurrent(t *testing.T) {
+	processor, err := NewIDProcessor(ProcessorConfig{Type: ProcessorTypeGenerateID, Config: map[string]interface{}{"target_field": "seq", "generator": "sequence", "overwrite": true}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+
+	const workers, perWorker = 8, 100
+	ids := make(chan int64, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				result, err := processor.Process(context.Background(), createTestMessage())
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				ids <- result.ValueFields["seq"].(int64)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]bool)
+	for id := range ids {
+		if id < 1 || id > workers*perWorker || seen[id] {
+			t.Fatalf("unexpected or duplicate sequence value %d", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("expected %d distinct values, got %d", workers*perWorker, len(seen))
+	}
+}
+
+// ==================== KeyCaseProcessor Tests ====================
+
+func TestKeyCaseProcessor(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		fields  map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "Lower every key",
+			config: map[string]interface{}{"mode": "lower"},
+			fields: map[string]interface{}{"UserID": "1", "Email": "a@b.c", "nested": map[string]interface{}{"Inner": true}},
+			want:   map[string]interface{}{"userid": "1", "email": "a@b.c", "nested": map[string]interface{}{"Inner": true}},
+		},
+		{
+			name:   "Upper listed keys",
+			config: map[string]interface{}{"mode": "upper", "fields": []interface{}{"id", "missing"}},
+			fields: map[string]interface{}{"id": "1", "name": "alice"},
+			want:   map[string]interface{}{"ID": "1", "name": "alice"},
+		},
+		{
+			name:    "Collision",
+			config:  map[string]interface{}{"mode": "lower"},
+			fields:  map[string]interface{}{"ID": "1", "id": "2"},
+			wantErr: true,
+		},
+		{
+			name:    "Collision with an unlisted key",
+			config:  map[string]interface{}{"mode": "upper", "fields": []interface{}{"id"}},
+			fields:  map[string]interface{}{"ID": "1", "id": "2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewKeyCaseProcessor(ProcessorConfig{Type: ProcessorTypeKeyCase, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() error = nil, want a collision")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewKeyCaseProcessor(ProcessorConfig{Type: ProcessorTypeKeyCase, Config: map[string]interface{}{"mode": "title"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+// ==================== HeadersObjectProcessor Tests ====================
+
+func TestHeadersObjectProcessor(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		headers     map[string]string
+		fields      map[string]interface{}
+		wantFields  map[string]interface{}
+		wantHeaders map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Every header to field",
+			config:      map[string]interface{}{"direction": "to_field", "target_field": "meta.headers"},
+			headers:     map[string]string{"trace-id": "t1", "tenant": "acme"},
+			fields:      map[string]interface{}{"id": "1"},
+			wantFields:  map[string]interface{}{"id": "1", "meta": map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme", "trace-id": "t1"}}},
+			wantHeaders: map[string]string{"trace-id": "t1", "tenant": "acme"},
+		},
+		{
+			name:        "Listed headers to field",
+			config:      map[string]interface{}{"direction": "to_field", "target_field": "headers", "headers": []interface{}{"tenant"}},
+			headers:     map[string]string{"trace-id": "t1", "tenant": "acme"},
+			fields:      map[string]interface{}{},
+			wantFields:  map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme"}},
+			wantHeaders: map[string]string{"trace-id": "t1", "tenant": "acme"},
+		},
+		{
+			name:        "No header to field",
+			config:      map[string]interface{}{"direction": "to_field", "target_field": "headers"},
+			fields:      map[string]interface{}{"id": "1"},
+			wantFields:  map[string]interface{}{"id": "1"},
+			wantHeaders: nil,
+		},
+		{
+			name:        "Field to headers",
+			config:      map[string]interface{}{"direction": "to_headers", "target_field": "headers"},
+			headers:     map[string]string{"tenant": "old"},
+			fields:      map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme", "retries": 2, "tags": []interface{}{"a"}}},
+			wantFields:  map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme", "retries": 2, "tags": []interface{}{"a"}}},
+			wantHeaders: map[string]string{"tenant": "acme", "retries": "2", "tags": `["a"]`},
+		},
+		{
+			name:        "Listed fields to headers",
+			config:      map[string]interface{}{"direction": "to_headers", "target_field": "headers", "headers": []interface{}{"tenant"}},
+			fields:      map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme", "trace-id": "t1"}},
+			wantFields:  map[string]interface{}{"headers": map[string]interface{}{"tenant": "acme", "trace-id": "t1"}},
+			wantHeaders: map[string]string{"tenant": "acme"},
+		},
+		{
+			name:        "Missing field to headers",
+			config:      map[string]interface{}{"direction": "to_headers", "target_field": "headers"},
+			fields:      map[string]interface{}{"id": "1"},
+			wantFields:  map[string]interface{}{"id": "1"},
+			wantHeaders: nil,
+		},
+		{
+			name:    "Field not an object",
+			config:  map[string]interface{}{"direction": "to_headers", "target_field": "headers"},
+			fields:  map[string]interface{}{"headers": "tenant=acme"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewHeadersObjectProcessor(ProcessorConfig{Type: ProcessorTypeHeadersObject, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{Headers: tt.headers, ValueFields: tt.fields})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.wantFields)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+			got, _ = json.Marshal(result.Headers)
+			want, _ = json.Marshal(tt.wantHeaders)
+			if string(got) != string(want) {
+				t.Errorf("Headers = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewHeadersObjectProcessor(ProcessorConfig{Type: ProcessorTypeHeadersObject, Config: map[string]interface{}{"direction": "delete", "target_field": "headers"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an invalid direction")
+	}
+}
+
+// ==================== FieldLimitsProcessor Tests ====================
+
+func TestFieldLimitsProcessor(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		fields  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "Within limits",
+			config: map[string]interface{}{"max_depth": 2, "max_fields": 5},
+			fields: map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "alice", "age": 30}, "empty": map[string]interface{}{}},
+		},
+		{
+			name:    "Too deep",
+			config:  map[string]interface{}{"max_depth": 2},
+			fields:  map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "Array elements count as a level",
+			config:  map[string]interface{}{"max_depth": 1},
+			fields:  map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			wantErr: true,
+		},
+		{
+			name:    "Too many fields",
+			config:  map[string]interface{}{"max_fields": 3},
+			fields:  map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+			wantErr: true,
+		},
+		{
+			name:   "Fields in arrays",
+			config: map[string]interface{}{"max_fields": 3},
+			fields: map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "a"}, map[string]interface{}{"sku": "b"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewFieldLimitsProcessor(ProcessorConfig{Type: ProcessorTypeFieldLimits, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			msg := &consumer.Message{ValueFields: tt.fields}
+			result, err := processor.Process(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && result != msg {
+				t.Errorf("Process() = %v, want the message unchanged", result)
+			}
+		})
+	}
+
+	for _, config := range []map[string]interface{}{{}, {"max_depth": 0}, {"max_fields": 2.5}} {
+		if _, err := NewFieldLimitsProcessor(ProcessorConfig{Type: ProcessorTypeFieldLimits, Config: config, logger: testLogger}); err == nil {
+			t.Errorf("expected an error for the config %v", config)
+		}
+	}
+}
+
+func TestFieldLimitsProcessor_DeepNesting(t *testing.T) {
+	// Built iteratively, a recursive walk would exhaust the stack
+	fields := map[string]interface{}{}
+	current := fields
+	for i := 0; i < 100000; i++ {
+		next := map[string]interface{}{}
+		current["child"] = next
+		current = next
+	}
+
+	processor, err := NewFieldLimitsProcessor(ProcessorConfig{Type: ProcessorTypeFieldLimits, Config: map[string]interface{}{"max_depth": 64}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	if _, err := processor.Process(context.Background(), &consumer.Message{ValueFields: fields}); err == nil {
+		t.Error("Process() error = nil, want the depth exceeded")
+	}
+}
+
+// ==================== CoalesceProcessor Tests ====================
+
+func TestCoalesceProcessor(t *testing.T) {
+	sources := []interface{}{"user_id", "userId", "user.id"}
+
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		fields     map[string]interface{}
+		wantFields map[string]interface{}
+	}{
+		{
+			name:       "Target first source",
+			config:     map[string]interface{}{"source_fields": sources, "target_field": "user_id"},
+			fields:     map[string]interface{}{"user_id": "u1", "userId": "u2"},
+			wantFields: map[string]interface{}{"user_id": "u1", "userId": "u2"},
+		},
+		{
+			name:       "Null source skipped",
+			config:     map[string]interface{}{"source_fields": sources, "target_field": "user_id"},
+			fields:     map[string]interface{}{"user_id": nil, "userId": "u2"},
+			wantFields: map[string]interface{}{"user_id": "u2", "userId": "u2"},
+		},
+		{
+			name:       "Nested source",
+			config:     map[string]interface{}{"source_fields": sources, "target_field": "user_id"},
+			fields:     map[string]interface{}{"user": map[string]interface{}{"id": "u3"}},
+			wantFields: map[string]interface{}{"user": map[string]interface{}{"id": "u3"}, "user_id": "u3"},
+		},
+		{
+			name:       "Default",
+			config:     map[string]interface{}{"source_fields": sources, "target_field": "user_id", "default": "anonymous"},
+			fields:     map[string]interface{}{"userId": nil},
+			wantFields: map[string]interface{}{"userId": nil, "user_id": "anonymous"},
+		},
+		{
+			name:       "No default",
+			config:     map[string]interface{}{"source_fields": sources, "target_field": "user_id"},
+			fields:     map[string]interface{}{"name": "alice"},
+			wantFields: map[string]interface{}{"name": "alice"},
+		},
+		{
+			name:       "Nested target",
+			config:     map[string]interface{}{"source_fields": []interface{}{"email", "mail"}, "target_field": "contact.email"},
+			fields:     map[string]interface{}{"mail": "a@example.com"},
+			wantFields: map[string]interface{}{"mail": "a@example.com", "contact": map[string]interface{}{"email": "a@example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewCoalesceProcessor(ProcessorConfig{Type: ProcessorTypeCoalesce, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.wantFields)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+		})
+	}
+
+	// The default is copied, modifying the field of a message leaves the next ones untouched
+	processor, err := NewCoalesceProcessor(ProcessorConfig{Type: ProcessorTypeCoalesce, Config: map[string]interface{}{"source_fields": sources, "target_field": "tags", "default": []interface{}{"none"}}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	first, _ := processor.Process(context.Background(), &consumer.Message{ValueFields: map[string]interface{}{}})
+	first.ValueFields["tags"].([]interface{})[0] = "changed"
+	second, _ := processor.Process(context.Background(), &consumer.Message{ValueFields: map[string]interface{}{}})
+	if second.ValueFields["tags"].([]interface{})[0] != "none" {
+		t.Errorf("default = %v, want it unchanged by the previous message", second.ValueFields["tags"])
+	}
+
+	if _, err := NewCoalesceProcessor(ProcessorConfig{Type: ProcessorTypeCoalesce, Config: map[string]interface{}{"target_field": "user_id"}, logger: testLogger}); err == nil {
+		t.Error("expected an error without source fields")
+	}
+}
+
+// ==================== DedupAdjacentProcessor Tests ====================
+
+func TestDedupAdjacentProcessor(t *testing.T) {
+	type input struct {
+		partition int32
+		fields    map[string]interface{}
+	}
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		messages []input
+		wantKept []bool
+	}{
+		{
+			name:   "Whole value",
+			config: map[string]interface{}{},
+			messages: []input{
+				{0, map[string]interface{}{"status": "up", "host": "a"}},
+				{0, map[string]interface{}{"host": "a", "status": "up"}},
+				{0, map[string]interface{}{"status": "down", "host": "a"}},
+				{0, map[string]interface{}{"status": "up", "host": "a"}},
+			},
+			wantKept: []bool{true, false, true, true},
+		},
+		{
+			name:   "Partitions compared separately",
+			config: map[string]interface{}{},
+			messages: []input{
+				{0, map[string]interface{}{"status": "up"}},
+				{1, map[string]interface{}{"status": "up"}},
+				{0, map[string]interface{}{"status": "up"}},
+			},
+			wantKept: []bool{true, true, false},
+		},
+		{
+			name:   "Key field",
+			config: map[string]interface{}{"key_field": "snapshot.hash"},
+			messages: []input{
+				{0, map[string]interface{}{"snapshot": map[string]interface{}{"hash": "h1"}, "at": 1}},
+				{0, map[string]interface{}{"snapshot": map[string]interface{}{"hash": "h1"}, "at": 2}},
+				{0, map[string]interface{}{"at": 3}},
+				{0, map[string]interface{}{"snapshot": map[string]interface{}{"hash": "h1"}, "at": 4}},
+			},
+			wantKept: []bool{true, false, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewDedupAdjacentProcessor(ProcessorConfig{Type: ProcessorTypeDedupAdjacent, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			for i, in := range tt.messages {
+				result, err := processor.Process(context.Background(), &consumer.Message{Topic: "heartbeats", Partition: in.partition, ValueFields: in.fields})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if kept := result != nil; kept != tt.wantKept[i] {
+					t.Errorf("message %d kept = %v, want %v", i, kept, tt.wantKept[i])
+				}
+			}
+		})
+	}
+
+	if _, err := NewDedupAdjacentProcessor(ProcessorConfig{Type: ProcessorTypeDedupAdjacent, Config: map[string]interface{}{"key_field": ""}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an empty key_field")
+	}
+}
+
+// ==================== EpochConvertProcessor Tests ====================
+
+func TestEpochConvertProcessor(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		fields     map[string]interface{}
+		wantFields map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "Millis to RFC3339",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339"},
+			fields:     map[string]interface{}{"ts": int64(1767323045678)},
+			wantFields: map[string]interface{}{"ts": "2026-01-02T03:04:05.678Z"},
+		},
+		{
+			name:       "Seconds float to RFC3339 into target",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339", "unit": "s", "target_field": "meta.time"},
+			fields:     map[string]interface{}{"ts": 1767323045.0},
+			wantFields: map[string]interface{}{"meta": map[string]interface{}{"time": "2026-01-02T03:04:05Z"}, "ts": 1767323045.0},
+		},
+		{
+			name:       "Nanos keep their precision",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339", "unit": "ns"},
+			fields:     map[string]interface{}{"ts": uint64(1767323045123456789)},
+			wantFields: map[string]interface{}{"ts": "2026-01-02T03:04:05.123456789Z"},
+		},
+		{
+			name:       "Before 1970",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339"},
+			fields:     map[string]interface{}{"ts": -1500},
+			wantFields: map[string]interface{}{"ts": "1969-12-31T23:59:58.500Z"},
+		},
+		{
+			name:       "RFC3339 to micros",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_epoch", "unit": "us"},
+			fields:     map[string]interface{}{"ts": "2026-01-02T04:04:05.123456789+01:00"},
+			wantFields: map[string]interface{}{"ts": int64(1767323045123456)},
+		},
+		{
+			name:       "Missing field",
+			config:     map[string]interface{}{"field_name": "ts", "direction": "to_epoch"},
+			fields:     map[string]interface{}{"id": 1},
+			wantFields: map[string]interface{}{"id": 1},
+		},
+		{
+			name:    "Non numeric epoch",
+			config:  map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339"},
+			fields:  map[string]interface{}{"ts": "1767323045678"},
+			wantErr: true,
+		},
+		{
+			name:    "Epoch out of range",
+			config:  map[string]interface{}{"field_name": "ts", "direction": "to_rfc3339", "unit": "s"},
+			fields:  map[string]interface{}{"ts": int64(1) << 60},
+			wantErr: true,
+		},
+		{
+			name:    "Date out of the nanos range",
+			config:  map[string]interface{}{"field_name": "ts", "direction": "to_epoch", "unit": "ns"},
+			fields:  map[string]interface{}{"ts": "3000-01-01T00:00:00Z"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid RFC3339",
+			config:  map[string]interface{}{"field_name": "ts", "direction": "to_epoch"},
+			fields:  map[string]interface{}{"ts": "02/01/2026"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewEpochConvertProcessor(ProcessorConfig{Type: ProcessorTypeEpochConvert, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.wantFields)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewEpochConvertProcessor(ProcessorConfig{Type: ProcessorTypeEpochConvert, Config: map[string]interface{}{"field_name": "ts", "direction": "to_epoch", "unit": "min"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an invalid unit")
+	}
+}
+
+// ==================== NormalizeProcessor Tests ====================
+
+func TestNormalizeProcessor(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		fields     map[string]interface{}
+		wantFields map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "Email trimmed and lowercased",
+			config:     map[string]interface{}{"field_name": "contact.email", "kind": "email"},
+			fields:     map[string]interface{}{"contact": map[string]interface{}{"email": "  Jane.Doe@Example.COM "}},
+			wantFields: map[string]interface{}{"contact": map[string]interface{}{"email": "jane.doe@example.com"}},
+		},
+		{
+			name:    "Email with display name",
+			config:  map[string]interface{}{"field_name": "email", "kind": "email"},
+			fields:  map[string]interface{}{"email": "Jane <jane@example.com>"},
+			wantErr: true,
+		},
+		{
+			name:       "International phone",
+			config:     map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:     map[string]interface{}{"phone": "+1 (415) 555-0132"},
+			wantFields: map[string]interface{}{"phone": "+14155550132"},
+		},
+		{
+			name:       "Phone with 00 prefix",
+			config:     map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:     map[string]interface{}{"phone": "0033 6 12-34-56-78"},
+			wantFields: map[string]interface{}{"phone": "+33612345678"},
+		},
+		{
+			name:       "National phone with default country code",
+			config:     map[string]interface{}{"field_name": "phone", "kind": "phone", "default_country_code": "33"},
+			fields:     map[string]interface{}{"phone": "06.12.34.56.78"},
+			wantFields: map[string]interface{}{"phone": "+33612345678"},
+		},
+		{
+			name:    "National phone without default country code",
+			config:  map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:  map[string]interface{}{"phone": "06 12 34 56 78"},
+			wantErr: true,
+		},
+		{
+			name:    "Phone too long",
+			config:  map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:  map[string]interface{}{"phone": "+33 6 12 34 56 78 90 12 34"},
+			wantErr: true,
+		},
+		{
+			name:    "Phone outside the numbering plan",
+			config:  map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:  map[string]interface{}{"phone": "+1 123 456 7890"},
+			wantErr: true,
+		},
+		{
+			name:    "Not a string",
+			config:  map[string]interface{}{"field_name": "phone", "kind": "phone"},
+			fields:  map[string]interface{}{"phone": 33612345678},
+			wantErr: true,
+		},
+		{
+			name:       "Invalid value flagged",
+			config:     map[string]interface{}{"field_name": "email", "kind": "email", "flag_field": "quality.invalid_email"},
+			fields:     map[string]interface{}{"email": "not an email"},
+			wantFields: map[string]interface{}{"email": "not an email", "quality": map[string]interface{}{"invalid_email": true}},
+		},
+		{
+			name:       "Valid value flagged",
+			config:     map[string]interface{}{"field_name": "email", "kind": "email", "flag_field": "invalid_email"},
+			fields:     map[string]interface{}{"email": "Jane@Example.com"},
+			wantFields: map[string]interface{}{"email": "jane@example.com", "invalid_email": false},
+		},
+		{
+			name:       "Missing field",
+			config:     map[string]interface{}{"field_name": "email", "kind": "email", "flag_field": "invalid_email"},
+			fields:     map[string]interface{}{"id": 1},
+			wantFields: map[string]interface{}{"id": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewNormalizeProcessor(ProcessorConfig{Type: ProcessorTypeNormalize, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.wantFields)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewNormalizeProcessor(ProcessorConfig{Type: ProcessorTypeNormalize, Config: map[string]interface{}{"field_name": "phone", "kind": "address"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	if _, err := NewNormalizeProcessor(ProcessorConfig{Type: ProcessorTypeNormalize, Config: map[string]interface{}{"field_name": "phone", "kind": "phone", "default_country_code": "999"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an unassigned country calling code")
+	}
+}
+
+// ==================== FingerprintProcessor Tests ====================
+
+func TestFingerprintProcessor(t *testing.T) {
+	fingerprint := func(t *testing.T, config map[string]interface{}, fields map[string]interface{}) (map[string]interface{}, error) {
+		t.Helper()
+		processor, err := NewFingerprintProcessor(ProcessorConfig{Type: ProcessorTypeFingerprint, Config: config, logger: testLogger})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: fields})
+		if err != nil {
+			return nil, err
+		}
+		return result.ValueFields, nil
+	}
+
+	t.Run("Stable whatever the fields order", func(t *testing.T) {
+		var first, second map[string]interface{}
+		json.Unmarshal([]byte(`{"id": 1, "user": {"name": "jane", "age": 30}}`), &first)
+		json.Unmarshal([]byte(`{"user": {"age": 30, "name": "jane"}, "id": 1}`), &second)
+		a, _ := fingerprint(t, map[string]interface{}{}, first)
+		b, _ := fingerprint(t, map[string]interface{}{}, second)
+		if a["_fingerprint"] != b["_fingerprint"] || len(a["_fingerprint"].(string)) != 64 {
+			t.Errorf("fingerprints = %v and %v, want the same sha256", a["_fingerprint"], b["_fingerprint"])
+		}
+	})
+
+	t.Run("Hash of the sorted JSON", func(t *testing.T) {
+		got, _ := fingerprint(t, map[string]interface{}{"fields": []interface{}{"id", "a"}}, map[string]interface{}{"id": int64(1), "a": "x", "ts": "now"})
+		sum := sha256.Sum256([]byte(`{"a":"x","id":1}`))
+		if want := hex.EncodeToString(sum[:]); got["_fingerprint"] != want {
+			t.Errorf("fingerprint = %v, want %s", got["_fingerprint"], want)
+		}
+	})
+
+	t.Run("Subset ignores the other fields", func(t *testing.T) {
+		config := map[string]interface{}{"fields": []interface{}{"id", "user.name"}, "algorithm": "fnv64a", "target_field": "meta.fp"}
+		a, _ := fingerprint(t, config, map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "jane"}, "ts": 1})
+		b, _ := fingerprint(t, config, map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "jane"}, "ts": 2})
+		c, _ := fingerprint(t, config, map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "john"}, "ts": 1})
+		fp := func(m map[string]interface{}) interface{} { return m["meta"].(map[string]interface{})["fp"] }
+		if fp(a) != fp(b) || fp(a) == fp(c) || len(fp(a).(string)) != 16 {
+			t.Errorf("fingerprints = %v, %v, %v, want the first two equal and the third different", fp(a), fp(b), fp(c))
+		}
+	})
+
+	t.Run("Missing and null fields differ", func(t *testing.T) {
+		config := map[string]interface{}{"fields": []interface{}{"id", "name"}, "algorithm": "md5"}
+		a, _ := fingerprint(t, config, map[string]interface{}{"id": 1})
+		b, _ := fingerprint(t, config, map[string]interface{}{"id": 1, "name": nil})
+		if a["_fingerprint"] == b["_fingerprint"] {
+			t.Errorf("fingerprint of a missing field = fingerprint of a null one = %v", a["_fingerprint"])
+		}
+	})
+
+	t.Run("Fingerprint again is stable", func(t *testing.T) {
+		fields, _ := fingerprint(t, map[string]interface{}{"algorithm": "sha1"}, map[string]interface{}{"id": 1})
+		first := fields["_fingerprint"]
+		fields, _ = fingerprint(t, map[string]interface{}{"algorithm": "sha1"}, fields)
+		if fields["_fingerprint"] != first {
+			t.Errorf("fingerprint = %v once fingerprinted, want %v", fields["_fingerprint"], first)
+		}
+	})
+
+	t.Run("Non finite float", func(t *testing.T) {
+		if _, err := fingerprint(t, map[string]interface{}{}, map[string]interface{}{"ratio": math.Inf(1)}); err == nil {
+			t.Error("Process() error = nil, want an error")
+		}
+	})
+
+	if _, err := NewFingerprintProcessor(ProcessorConfig{Type: ProcessorTypeFingerprint, Config: map[string]interface{}{"algorithm": "crc32"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+}
+
+// ==================== TemplateProcessor Tests ====================
+
+func TestTemplateProcessor(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		fields     map[string]interface{}
+		wantFields map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "Fields rendered",
+			config:     map[string]interface{}{"template": "User {{.name}} from {{.country}}", "target_field": "summary"},
+			fields:     map[string]interface{}{"name": "jane", "country": "FR"},
+			wantFields: map[string]interface{}{"country": "FR", "name": "jane", "summary": "User jane from FR"},
+		},
+		{
+			name:       "Nested fields, functions and control structures",
+			config:     map[string]interface{}{"template": `{{.user.name}}: {{printf "%.2f" .amount}}{{range .tags}} #{{.}}{{end}}{{if .vip}} (vip){{end}}`, "target_field": "meta.label"},
+			fields:     map[string]interface{}{"user": map[string]interface{}{"name": "jane"}, "amount": 12.5, "tags": []interface{}{"a", "b"}, "vip": true},
+			wantFields: map[string]interface{}{"amount": 12.5, "meta": map[string]interface{}{"label": "jane: 12.50 #a #b (vip)"}, "tags": []interface{}{"a", "b"}, "user": map[string]interface{}{"name": "jane"}, "vip": true},
+		},
+		{
+			name:       "Missing and null fields render empty",
+			config:     map[string]interface{}{"template": "[{{.name}}][{{.user.name}}][{{.none}}]{{with .user}}{{.name}}{{end}}", "target_field": "label"},
+			fields:     map[string]interface{}{"none": nil},
+			wantFields: map[string]interface{}{"label": "[][][]", "none": nil},
+		},
+		{
+			name:    "Missing field with missing error",
+			config:  map[string]interface{}{"template": "User {{.name}}", "target_field": "label", "missing": "error"},
+			fields:  map[string]interface{}{"id": 1},
+			wantErr: true,
+		},
+		{
+			name:       "Present fields with missing error",
+			config:     map[string]interface{}{"template": "{{.id}}-{{.name}}", "target_field": "label", "missing": "error"},
+			fields:     map[string]interface{}{"id": int64(1), "name": "jane"},
+			wantFields: map[string]interface{}{"id": 1, "label": "1-jane", "name": "jane"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := NewTemplateProcessor(ProcessorConfig{Type: ProcessorTypeTemplate, Config: tt.config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			result, err := processor.Process(context.Background(), &consumer.Message{ValueFields: tt.fields})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := json.Marshal(result.ValueFields)
+			want, _ := json.Marshal(tt.wantFields)
+			if string(got) != string(want) {
+				t.Errorf("ValueFields = %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := NewTemplateProcessor(ProcessorConfig{Type: ProcessorTypeTemplate, Config: map[string]interface{}{"template": "{{.name", "target_field": "label"}, logger: testLogger}); err == nil {
+		t.Error("expected an error for a template failing to compile")
+	}
+}
+
+// ==================== TapProcessor Tests ====================
+
+func TestTapProcessor(t *testing.T) {
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	processor, err := NewTapProcessor(ProcessorConfig{Type: ProcessorTypeTap, Config: map[string]interface{}{"name": "test_tap", "sample_rate": 1}, logger: logger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	counter := metrics.Default.Counter("etelgo_tap_messages_total", "name", "test_tap")
+	before := counter.Value()
+
+	msg := &consumer.Message{Topic: "orders", Offset: 42, ValueFields: map[string]interface{}{"id": 1}}
+	for i := 0; i < 3; i++ {
+		result, err := processor.Process(context.Background(), msg)
+		if err != nil || result != msg {
+			t.Fatalf("Process() = %v, %v, want the message unchanged", result, err)
+		}
+	}
+
+	if got := counter.Value() - before; got != 3 {
+		t.Errorf("etelgo_tap_messages_total{name=test_tap} increased by %d, want 3", got)
+	}
+	if got := strings.Count(logs.String(), "msg=Tap name=test_tap topic=orders"); got != 3 {
+		t.Errorf("logged %d sampled messages, want 3:\n%s", got, logs.String())
+	}
+
+	logs.Reset()
+	unsampled, _ := NewTapProcessor(ProcessorConfig{Type: ProcessorTypeTap, Config: map[string]interface{}{"name": "test_tap"}, logger: logger})
+	unsampled.Process(context.Background(), msg)
+	if logs.Len() != 0 {
+		t.Errorf("logged without sample_rate:\n%s", logs.String())
+	}
+
+	if _, err := NewTapProcessor(ProcessorConfig{Type: ProcessorTypeTap, Config: map[string]interface{}{"name": "test_tap", "sample_rate": 2}, logger: logger}); err == nil {
+		t.Error("expected an error for a sample_rate above 1")
+	}
+}
+
+// ==================== RequireProcessor Tests ====================
+
+func TestRequireProcessor(t *testing.T) {
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	processor, err := NewRequireProcessor(ProcessorConfig{Type: ProcessorTypeRequire, Config: map[string]interface{}{
+		"fields":      []interface{}{"id", "customer.email", "amount"},
+		"log_missing": true,
+	}, logger: logger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		fields  map[string]interface{}
+		wantErr string
+	}{
+		{"Every field present", map[string]interface{}{"id": 1, "customer": map[string]interface{}{"email": "a@b.c"}, "amount": 0}, ""},
+		{"Missing nested field", map[string]interface{}{"id": 1, "customer": map[string]interface{}{}, "amount": 3}, "missing required fields: customer.email"},
+		{"Null fields", map[string]interface{}{"id": nil, "customer": map[string]interface{}{"email": "a@b.c"}, "amount": nil}, "missing required fields: id, amount"},
+		{"No value", nil, "missing required fields: id, customer.email, amount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &consumer.Message{Topic: "orders", Offset: 7, ValueFields: tt.fields}
+			result, err := processor.Process(context.Background(), msg)
+			if tt.wantErr == "" {
+				if err != nil || result != msg {
+					t.Errorf("Process() = %v, %v, want the message kept", result, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Process() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !strings.Contains(logs.String(), "missing required fields") || !strings.Contains(logs.String(), "offset=7") {
+		t.Errorf("expected the missing fields to be logged, got:\n%s", logs.String())
+	}
+
+	if _, err := NewRequireProcessor(ProcessorConfig{Type: ProcessorTypeRequire, Config: map[string]interface{}{}, logger: logger}); err == nil {
+		t.Error("expected an error without fields")
+	}
+}
+
+// ==================== FormatNumberProcessor Tests ====================
+
+func TestFormatNumberProcessor(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{"Default precision", map[string]interface{}{}, 3.14159, "3.14", false},
+		{"Currency with thousands", map[string]interface{}{"thousands_separator": ",", "currency_prefix": "$"}, -1234567.891, "-$1,234,567.89", false},
+		{"Integer formatted exactly", map[string]interface{}{"thousands_separator": ","}, int64(9007199254740993), "9,007,199,254,740,993.00", false},
+		{"Unsigned integer", map[string]interface{}{"precision": uint64(0), "thousands_separator": " "}, uint64(18446744073709551615), "18 446 744 073 709 551 615", false},
+		{"European separators", map[string]interface{}{"thousands_separator": ".", "decimal_separator": ",", "currency_prefix": "€"}, 1000.5, "€1.000,50", false},
+		{"No decimals", map[string]interface{}{"precision": uint64(0)}, 999.5, "1000", false},
+		{"Negative rounded to zero", map[string]interface{}{}, -0.001, "0.00", false},
+		{"Exact thousands", map[string]interface{}{"thousands_separator": ","}, int64(-100000), "-100,000.00", false},
+		{"Numeric string", map[string]interface{}{}, "12.5", "", true},
+		{"Null value", map[string]interface{}{}, nil, "", true},
+		{"NaN", map[string]interface{}{}, math.NaN(), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"field_name": "amount", "target_field": "display"}
+			for key, val := range tt.config {
+				config[key] = val
+			}
+			processor, err := NewFormatNumberProcessor(ProcessorConfig{Type: ProcessorTypeFormatNumber, Config: config, logger: testLogger})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+
+			msg := &consumer.Message{ValueFields: map[string]interface{}{"amount": tt.value}}
+			result, err := processor.Process(context.Background(), msg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Process() = %v, want an error", result.ValueFields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ValueFields["display"] != tt.want {
+				t.Errorf("display = %q, want %q", result.ValueFields["display"], tt.want)
+			}
+			if result.ValueFields["amount"] != tt.value {
+				t.Errorf("expected the source field unchanged, got %v", result.ValueFields["amount"])
+			}
+		})
+	}
+}
+
+func TestFormatNumberProcessor_InPlace(t *testing.T) {
+	processor, err := NewFormatNumberProcessor(ProcessorConfig{Type: ProcessorTypeFormatNumber, Config: map[string]interface{}{"field_name": "order.total"}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+
+	msg := &consumer.Message{ValueFields: map[string]interface{}{"order": map[string]interface{}{"total": 42.0}}}
+	result, err := processor.Process(context.Background(), msg)
+	if err != nil || result.ValueFields["order"].(map[string]interface{})["total"] != "42.00" {
+		t.Errorf("Process() = %v, %v, want the field formatted in place", result, err)
+	}
+
+	missing := &consumer.Message{ValueFields: map[string]interface{}{"id": 1}}
+	if result, err := processor.Process(context.Background(), missing); err != nil || result != missing || len(result.ValueFields) != 1 {
+		t.Errorf("Process() = %v, %v, want the message without the field unchanged", result, err)
+	}
+
+	if _, err := NewFormatNumberProcessor(ProcessorConfig{Type: ProcessorTypeFormatNumber, Config: map[string]interface{}{"field_name": "amount", "thousands_separator": "."}, logger: testLogger}); err == nil {
+		t.Error("expected an error for a thousands_separator equal to the decimal_separator")
+	}
+}
+
+// ==================== LookupProcessor Tests ====================
+
+func TestLookupProcessor(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cities.json")
+	if err := os.WriteFile(file, []byte(`{"42": {"name": "Paris", "zip": 75001}, "7": "Lyon"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two stages looking up the same file share the cached entries
+	var reads int
+	newLookup := func(target string) *LookupProcessor {
+		processor, err := NewLookupProcessor(ProcessorConfig{Type: ProcessorTypeLookup, Config: map[string]interface{}{
+			"key_field": "city_id", "target_field": target, "file": file, "cache_ttl": "1m", "cache_max_size": 10,
+		}, logger: testLogger})
+		if err != nil {
+			t.Fatalf("failed to create processor: %v", err)
+		}
+		lookup := processor.(*LookupProcessor)
+		lookup.readFile = func(name string) ([]byte, error) {
+			reads++
+			return os.ReadFile(name)
+		}
+		return lookup
+	}
+	first, second := newLookup("city"), newLookup("location.city")
+
+	msg := &consumer.Message{ValueFields: map[string]interface{}{"city_id": int64(42)}}
+	if _, err := first.Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := second.Process(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	city, _ := msg.ValueFields["city"].(map[string]interface{})
+	if city["name"] != "Paris" || city["zip"] != int64(75001) {
+		t.Errorf("city = %v, want the entry of key 42", msg.ValueFields["city"])
+	}
+	if located, _ := getPath(msg.ValueFields, "location.city"); located == nil {
+		t.Errorf("expected the second stage to write location.city, got %v", msg.ValueFields)
+	}
+	if reads != 1 {
+		t.Errorf("expected the file read once for both stages, got %d reads", reads)
+	}
+
+	// Each message gets its own copy of the cached value
+	city["name"] = "changed"
+	other := &consumer.Message{ValueFields: map[string]interface{}{"city_id": "42"}}
+	if _, err := first.Process(context.Background(), other); err != nil || other.ValueFields["city"].(map[string]interface{})["name"] != "Paris" {
+		t.Errorf("Process() = %v, %v, want the cached entry unchanged", other.ValueFields, err)
+	}
+
+	for _, fields := range []map[string]interface{}{{"city_id": 99}, {"id": 1}} {
+		unknown := &consumer.Message{ValueFields: fields}
+		if result, err := first.Process(context.Background(), unknown); err != nil || result != unknown || unknown.ValueFields["city"] != nil {
+			t.Errorf("Process(%v) = %v, %v, want the message unchanged", fields, result, err)
+		}
+	}
+
+	missing, err := NewLookupProcessor(ProcessorConfig{Type: ProcessorTypeLookup, Config: map[string]interface{}{
+		"key_field": "city_id", "target_field": "city", "file": filepath.Join(t.TempDir(), "missing.json"),
+	}, logger: testLogger})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	if _, err := missing.Process(context.Background(), &consumer.Message{ValueFields: map[string]interface{}{"city_id": 7}}); err == nil {
+		t.Error("expected an error for a file that can't be read")
+	}
+
+	if _, err := NewLookupProcessor(ProcessorConfig{Type: ProcessorTypeLookup, Config: map[string]interface{}{"key_field": "city_id", "file": file}, logger: testLogger}); err == nil {
+		t.Error("expected an error without target_field")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"etelgo/config"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+// watchReload loads the config file each time a reload signal (SIGHUP, see reloadSignals) is received, until ctx is done,
+// and sends the configurations passing the validation to reloads. It is up to the receiver to only apply the processors,
+// see pipelines.Orchestrator.Reload. An invalid configuration is logged and ignored, the running one staying in place.
+func watchReload(ctx context.Context, configFile string, opts config.LoadOptions, reloads chan<- *config.Config, logger *slog.Logger) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, reloadSignals...)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			logger.Info("Reloading configuration", "file", configFile)
+			cfg, err := config.LoadConfigWithOptions(configFile, logger, opts)
+			if err != nil {
+				logErrors(logger, "configuration reload rejected", err)
+				continue
+			}
+			select {
+			case reloads <- cfg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
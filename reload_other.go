@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// reloadSignals is empty where SIGHUP doesn't exist, the processors are then only changed by a restart
+var reloadSignals []os.Signal
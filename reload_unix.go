@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals trigger a reload of the processors from the config file, see watchReload
+var reloadSignals = []os.Signal{syscall.SIGHUP}
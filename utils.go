@@ -1 +1,44 @@
 package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// warningCollector is a slog handler wrapper keeping track of every warning logged
+// so they can be reported in the machine-readable output of the validate command.
+type warningCollector struct {
+	slog.Handler
+	warnings *[]string
+}
+
+func newWarningCollector(h slog.Handler) *warningCollector {
+	return &warningCollector{Handler: h, warnings: &[]string{}}
+}
+
+func (c *warningCollector) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn && r.Level < slog.LevelError {
+		var sb strings.Builder
+		sb.WriteString(r.Message)
+		r.Attrs(func(a slog.Attr) bool {
+			fmt.Fprintf(&sb, " %s=%v", a.Key, a.Value)
+			return true
+		})
+		*c.warnings = append(*c.warnings, sb.String())
+	}
+	return c.Handler.Handle(ctx, r)
+}
+
+func (c *warningCollector) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningCollector{Handler: c.Handler.WithAttrs(attrs), warnings: c.warnings}
+}
+
+func (c *warningCollector) WithGroup(name string) slog.Handler {
+	return &warningCollector{Handler: c.Handler.WithGroup(name), warnings: c.warnings}
+}
+
+func (c *warningCollector) Warnings() []string {
+	return *c.warnings
+}
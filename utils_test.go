@@ -1 +1,31 @@
 package main
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestWarningCollector(t *testing.T) {
+	collector := newWarningCollector(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger := slog.New(collector)
+
+	logger.Info("not collected")
+	logger.Warn("Workers not set or invalid, defaulting to 1")
+	logger.With("component", "input").Warn("Offset_reset not provided, using default", "default", "latest")
+	logger.Error("not collected either")
+
+	warnings := collector.Warnings()
+	want := []string{
+		"Workers not set or invalid, defaulting to 1",
+		"Offset_reset not provided, using default default=latest",
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Warnings() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("Warnings()[%d] = %q, want %q", i, warnings[i], want[i])
+		}
+	}
+}